@@ -0,0 +1,226 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Limits applied by Kubernetes to the weights of preferred scheduling terms.
+const (
+	minSchedulingTermWeight = 1
+	maxSchedulingTermWeight = 100
+)
+
+// Enumerated affinity values accepted by the API server.
+var (
+	nodeSelectorOperators  = []string{"In", "NotIn", "Exists", "DoesNotExist", "Gt", "Lt"}
+	labelSelectorOperators = []string{"In", "NotIn", "Exists", "DoesNotExist"}
+	podAffinityFields      = []string{"podAffinity", "podAntiAffinity"}
+)
+
+// ValidateAffinity checks spec.affinity of a pod spec: node selector terms use supported
+// operators, with integer values for Gt and Lt; pod affinity and anti-affinity terms name a
+// topologyKey that is a valid label key and select pods with valid label selectors; and
+// preferred terms of either kind have weights between 1 and 100.
+func ValidateAffinity(spec map[string]interface{}) error {
+	affinity, ok := nestedMap(spec, "affinity")
+	if !ok {
+		return nil
+	}
+
+	errs := make([]error, 0)
+	path := NewPath("affinity")
+
+	// Node affinity
+	nodePath := path.Child("nodeAffinity")
+	if required, ok := nestedMap(affinity, "nodeAffinity", "requiredDuringSchedulingIgnoredDuringExecution"); ok {
+		termsPath := nodePath.Child("requiredDuringSchedulingIgnoredDuringExecution", "nodeSelectorTerms")
+		terms, _ := nestedSlice(required, "nodeSelectorTerms")
+		if len(terms) == 0 {
+			errs = append(errs, Required(termsPath, "nodeSelectorTerms must hold at least one term"))
+		}
+		for i, raw := range terms {
+			term, _ := raw.(map[string]interface{})
+			if err := validateNodeSelectorTerm(term); err != nil {
+				errs = append(errs, WithFieldPath(termsPath.Index(i).String(), err))
+			}
+		}
+	}
+	preferred, _ := nestedSlice(affinity, "nodeAffinity", "preferredDuringSchedulingIgnoredDuringExecution")
+	for i, raw := range preferred {
+		termPath := nodePath.Child("preferredDuringSchedulingIgnoredDuringExecution").Index(i)
+		term, _ := raw.(map[string]interface{})
+		if err := validateSchedulingTermWeight(term); err != nil {
+			errs = append(errs, WithFieldPath(termPath.String(), err))
+		}
+		if preference, ok := nestedMap(term, "preference"); !ok {
+			errs = append(errs, Required(termPath.Child("preference"), "preference is required"))
+		} else if err := validateNodeSelectorTerm(preference); err != nil {
+			errs = append(errs, WithFieldPath(termPath.Child("preference").String(), err))
+		}
+	}
+
+	// Pod affinity and anti-affinity
+	for _, field := range podAffinityFields {
+		required, _ := nestedSlice(affinity, field, "requiredDuringSchedulingIgnoredDuringExecution")
+		for i, raw := range required {
+			term, _ := raw.(map[string]interface{})
+			if err := validatePodAffinityTerm(term); err != nil {
+				errs = append(errs, WithFieldPath(path.Child(field, "requiredDuringSchedulingIgnoredDuringExecution").Index(i).String(), err))
+			}
+		}
+		preferred, _ := nestedSlice(affinity, field, "preferredDuringSchedulingIgnoredDuringExecution")
+		for i, raw := range preferred {
+			termPath := path.Child(field, "preferredDuringSchedulingIgnoredDuringExecution").Index(i)
+			term, _ := raw.(map[string]interface{})
+			if err := validateSchedulingTermWeight(term); err != nil {
+				errs = append(errs, WithFieldPath(termPath.String(), err))
+			}
+			if podAffinityTerm, ok := nestedMap(term, "podAffinityTerm"); !ok {
+				errs = append(errs, Required(termPath.Child("podAffinityTerm"), "podAffinityTerm is required"))
+			} else if err := validatePodAffinityTerm(podAffinityTerm); err != nil {
+				errs = append(errs, WithFieldPath(termPath.Child("podAffinityTerm").String(), err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return withDefaultRule(RuleAffinity, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validateSchedulingTermWeight checks that the weight of a preferred scheduling term is an
+// integer between 1 and 100.
+func validateSchedulingTermWeight(term map[string]interface{}) error {
+	value, ok := nestedField(term, "weight")
+	if !ok {
+		return Required(NewPath("weight"), "weight is required")
+	}
+	if weight, ok := toInt64(value); !ok || weight < minSchedulingTermWeight || weight > maxSchedulingTermWeight {
+		return Invalid(NewPath("weight"), value, fmt.Sprintf("weight must be an integer between %d and %d", minSchedulingTermWeight, maxSchedulingTermWeight))
+	}
+	return nil
+}
+
+// validateNodeSelectorTerm checks the matchExpressions and matchFields of a node selector
+// term. Node labels are matched by key; the only node field is metadata.name, which is
+// matched with In or NotIn against a single value.
+func validateNodeSelectorTerm(term map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	expressions, _ := nestedSlice(term, "matchExpressions")
+	for i, raw := range expressions {
+		expression, _ := raw.(map[string]interface{})
+		if err := validateSelectorExpression(expression, nodeSelectorOperators); err != nil {
+			errs = append(errs, WithFieldPath(NewPath("matchExpressions").Index(i).String(), err))
+		}
+	}
+	fields, _ := nestedSlice(term, "matchFields")
+	for i, raw := range fields {
+		fieldPath := NewPath("matchFields").Index(i)
+		field, _ := raw.(map[string]interface{})
+		if key, _ := nestedString(field, "key"); key != "metadata.name" {
+			errs = append(errs, Invalid(fieldPath.Child("key"), key, fmt.Sprintf("field '%s' is not supported; must be metadata.name", key)))
+		}
+		operator, _ := nestedString(field, "operator")
+		if operator != "In" && operator != "NotIn" {
+			errs = append(errs, Invalid(fieldPath.Child("operator"), operator, fmt.Sprintf("operator '%s' is not supported for matchFields; must be one of: In, NotIn", operator)))
+		}
+		if values, _ := nestedSlice(field, "values"); len(values) != 1 {
+			errs = append(errs, Invalid(fieldPath.Child("values"), values, "values must hold exactly one node name"))
+		}
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// validatePodAffinityTerm checks a pod affinity or anti-affinity term: topologyKey is a
+// valid label key, the label and namespace selectors are valid, and namespaces are DNS
+// labels.
+func validatePodAffinityTerm(term map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	if topologyKey, _ := nestedString(term, "topologyKey"); topologyKey == "" {
+		errs = append(errs, Required(NewPath("topologyKey"), "topologyKey is required, e.g. kubernetes.io/hostname or topology.kubernetes.io/zone"))
+	} else if err := ValidateLabelKey(topologyKey); err != nil {
+		errs = append(errs, WithFieldPath("topologyKey", err))
+	}
+	for _, field := range []string{"labelSelector", "namespaceSelector"} {
+		expressions, _ := nestedSlice(term, field, "matchExpressions")
+		for i, raw := range expressions {
+			expression, _ := raw.(map[string]interface{})
+			if err := validateSelectorExpression(expression, labelSelectorOperators); err != nil {
+				errs = append(errs, WithFieldPath(NewPath(field, "matchExpressions").Index(i).String(), err))
+			}
+		}
+	}
+	namespaces, _ := nestedSlice(term, "namespaces")
+	for i, raw := range namespaces {
+		namespace, _ := raw.(string)
+		if err := ValidateDNSLabel(namespace); err != nil {
+			errs = append(errs, WithFieldPath(NewPath("namespaces").Index(i).String(), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// validateSelectorExpression checks a matchExpressions requirement of a label or node
+// selector: the key is a valid label key and the operator one of operators. In and NotIn
+// need values, Exists and DoesNotExist take none, and Gt and Lt take a single integer.
+func validateSelectorExpression(expression map[string]interface{}, operators []string) error {
+	errs := make([]error, 0)
+
+	if key, _ := nestedString(expression, "key"); key == "" {
+		errs = append(errs, Required(NewPath("key"), "key is required"))
+	} else if err := ValidateLabelKey(key); err != nil {
+		errs = append(errs, WithFieldPath("key", err))
+	}
+
+	operator, _ := nestedString(expression, "operator")
+	values, _ := nestedSlice(expression, "values")
+	switch operator {
+	case "":
+		errs = append(errs, Required(NewPath("operator"), fmt.Sprintf("operator is required; must be one of: %s", strings.Join(operators, ", "))))
+	case "In", "NotIn":
+		if len(values) == 0 {
+			errs = append(errs, Required(NewPath("values"), fmt.Sprintf("values must be specified when operator is '%s'", operator)))
+		}
+	case "Exists", "DoesNotExist":
+		if len(values) > 0 {
+			errs = append(errs, Invalid(NewPath("values"), values, fmt.Sprintf("values must not be specified when operator is '%s'", operator)))
+		}
+	case "Gt", "Lt":
+		if !containsString(operators, operator) {
+			errs = append(errs, Invalid(NewPath("operator"), operator, fmt.Sprintf("operator '%s' is only supported by node selectors; must be one of: %s", operator, strings.Join(operators, ", "))))
+			break
+		}
+		if len(values) != 1 {
+			errs = append(errs, Invalid(NewPath("values"), values, fmt.Sprintf("values must hold exactly one integer when operator is '%s'", operator)))
+			break
+		}
+		value := fmt.Sprint(values[0])
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			errs = append(errs, Invalid(NewPath("values").Index(0), values[0], fmt.Sprintf("value '%s' must be an integer when operator is '%s'", value, operator)))
+		}
+	default:
+		errs = append(errs, validateEnumField("operator", operator, operators))
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
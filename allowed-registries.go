@@ -0,0 +1,77 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckAllowedRegistries is the ID of the check built by AllowedRegistriesCheck.
+const CheckAllowedRegistries = "AllowedRegistries"
+
+// defaultImageRegistry is the registry container runtimes pull images without a registry
+// host from.
+const defaultImageRegistry = "docker.io"
+
+// AllowedRegistriesCheck returns an opt-in check, not run by ValidateObject, that reports
+// containers whose images come from registries outside allowed; see
+// ValidateAllowedRegistries. Run it with RunChecks or pass it to the linter's Checks option.
+func AllowedRegistriesCheck(allowed []string) Check {
+	return Check{ID: CheckAllowedRegistries, Validate: func(obj map[string]interface{}) error {
+		return ValidateAllowedRegistries(obj, allowed)
+	}}
+}
+
+// ValidateAllowedRegistries checks that every container of the pod spec embedded in obj
+// pulls its image from one of the allowed registries. Entries are registry hosts, such as
+// "registry.example.com:5000", or repository prefixes ending in '/', such as
+// "docker.io/library/". Images without a registry host come from docker.io, with official
+// images such as nginx under docker.io/library/. Images that
+// are not valid references are left to the image reference rule.
+func ValidateAllowedRegistries(obj map[string]interface{}, allowed []string) error {
+	spec, path, ok := findPodSpec(obj)
+	if !ok {
+		return nil
+	}
+
+	errs := make([]error, 0)
+	for _, container := range podContainers(spec) {
+		image, _ := nestedString(container.fields, "image")
+		ref, err := ParseImageReference(image)
+		if err != nil {
+			continue
+		}
+		if ref.Registry == "" {
+			// Official images such as nginx live under library/ on Docker Hub
+			ref.Registry = defaultImageRegistry
+			if !strings.Contains(ref.Repository, "/") {
+				ref.Repository = "library/" + ref.Repository
+			}
+		}
+		if !imageRegistryAllowed(ref, allowed) {
+			message := fmt.Sprintf("image '%s' is pulled from registry '%s', which is not allowed; must be one of: %s", image, ref.Registry, strings.Join(allowed, ", "))
+			errs = append(errs, &ConstraintError{FieldPath: path + "." + container.path + ".image", BadValue: image, Message: message})
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// imageRegistryAllowed reports whether ref, whose Registry is set, matches an entry of
+// allowed.
+func imageRegistryAllowed(ref ImageReference, allowed []string) bool {
+	for _, entry := range allowed {
+		if strings.HasSuffix(entry, "/") {
+			if strings.HasPrefix(ref.Name()+"/", entry) {
+				return true
+			}
+		} else if ref.Registry == entry {
+			return true
+		}
+	}
+	return false
+}
@@ -1,4 +1,4 @@
-package main
+package k8sconstraints
 
 import (
 	"errors"
@@ -7,6 +7,16 @@ import (
 	"strings"
 )
 
+var (
+	// apiVersionCharsPattern matches the characters allowed in an apiVersion: alphanumerics,
+	// '-' and '.' in the group, and '/'.
+	apiVersionCharsPattern = regexp.MustCompile(`^[a-zA-Z0-9./-]+$`)
+	// apiVersionVersionPattern matches a version such as v1, v2beta1, or v1alpha3.
+	apiVersionVersionPattern = regexp.MustCompile(`^v\d+((alpha|beta)\d+)?$`)
+	// apiGroupPattern matches an API group name, e.g. example.com or My-Group.
+	apiGroupPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9.-]*[A-Za-z0-9])?$`)
+)
+
 // ValidateApiVersion validates the syntax of an apiVersion string.
 func ValidateApiVersion(apiVersion string) error {
 	errs := make([]error, 0)
@@ -33,27 +43,17 @@ func ValidateApiVersion(apiVersion string) error {
 
 	// If there are errors, join and return them
 	if len(errs) > 0 {
-		return JoinErrors(errs)
+		return withRule(RuleAPIVersion, apiVersion, JoinErrors(errs))
 	}
 
 	return nil
 }
 
-// ValidateLength checks if a string exceeds the maximum allowed length.
-func ValidateLength(input string, maxLength int) error {
-	if len(input) > maxLength {
-		return fmt.Errorf("input exceeds maximum length of %d characters", maxLength)
-	}
-	return nil
-}
-
 // ValidateApiVersionAllowedCharacters ensures the string only contains valid characters
 // for an apiVersion and contains at most one slash (/).
 func ValidateApiVersionAllowedCharacters(input string) error {
-	// Valid characters: alphanumeric, hyphen (-), and slash (/)
-	validChars := regexp.MustCompile(`^[a-zA-Z0-9/-]+$`)
-	if !validChars.MatchString(input) {
-		return errors.New("input contains invalid characters; only alphanumeric, hyphen (-), and slash (/) are allowed")
+	if !apiVersionCharsPattern.MatchString(input) {
+		return errors.New("input contains invalid characters; only alphanumeric, hyphen (-), period (.), and slash (/) are allowed")
 	}
 
 	// Ensure at most one slash (/)
@@ -77,9 +77,9 @@ func ValidateGroupVersionFormat(apiVersion string) error {
 		// Non-core API group (e.g., apps/v1)
 		group, version := parts[0], parts[1]
 
-		// Validate group using DNS label conventions
-		if err := ValidateDNSLabel(group); err != nil {
-			return fmt.Errorf("API group is invalid: %v", err)
+		// Validate the group name
+		if err := ValidateAPIGroup(group); err != nil {
+			return withMessagePrefix("API group is invalid: ", err)
 		}
 
 		// Validate version using regex
@@ -95,53 +95,18 @@ func ValidateGroupVersionFormat(apiVersion string) error {
 
 // isValidVersion checks if the version matches valid Kubernetes version patterns.
 func isValidVersion(version string) bool {
-	versionPattern := regexp.MustCompile(`^v\d+((alpha|beta)\d+)?$`)
-	return versionPattern.MatchString(version)
+	return apiVersionVersionPattern.MatchString(version)
 }
 
-// ValidateDNSLabel validates a string against the DNS label format as defined by RFC 1123.
-// Kubernetes uses this for group names, metadata.labels keys/values, and similar fields.
-func ValidateDNSLabel(label string) error {
-	// DNS label format: Alphanumeric, hyphens, periods allowed, must start/end with alphanumeric.
-	// Maximum length of 63 characters.
-	labelPattern := regexp.MustCompile(`^[a-zA-Z0-9]([-a-zA-Z0-9]*[a-zA-Z0-9])?$`)
-	if len(label) > 63 {
-		return fmt.Errorf("label exceeds maximum length of 63 characters")
+// ValidateAPIGroup validates the group part of an apiVersion.
+// Groups may include uppercase and lowercase alphanumeric characters, '.', and '-'.
+// Groups must start and end with an alphanumeric character and have a maximum length of 253 characters.
+func ValidateAPIGroup(group string) error {
+	if len(group) > 253 {
+		return fmt.Errorf("API group exceeds maximum length of 253 characters")
 	}
-	if !labelPattern.MatchString(label) {
-		return errors.New("label must match DNS label format (alphanumeric, hyphens, periods, max 63 characters)")
+	if !apiGroupPattern.MatchString(group) {
+		return errors.New("API group must consist of alphanumeric characters, '-', '.', and must start and end with an alphanumeric character")
 	}
 	return nil
 }
-
-// JoinErrors joins multiple error messages into one error.
-func JoinErrors(errs []error) error {
-	messages := make([]string, len(errs))
-	for i, err := range errs {
-		messages[i] = err.Error()
-	}
-	return errors.New(strings.Join(messages, "; "))
-}
-
-func main() {
-	// Test cases
-	testCases := []string{
-		"v1",
-		"apps/v1",
-		"apps/v1beta1",
-		"",
-		"Apps/v1",             // Invalid due to case sensitivity
-		"apps/v1.1",           // Invalid due to period
-		"apps//v1",            // Invalid due to double slashes
-		"this-is-a-very-long-api-group-name-that-exceeds-the-limit/v1",
-	}
-
-	for _, tc := range testCases {
-		fmt.Printf("Testing apiVersion: %s\n", tc)
-		if err := ValidateApiVersion(tc); err != nil {
-			fmt.Printf("Error: %v\n", err)
-		} else {
-			fmt.Println("Valid!")
-		}
-	}
-}
@@ -0,0 +1,164 @@
+package k8sconstraints
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"testing"
+)
+
+// The regexps the byte-loop checks replaced, kept here as the baseline they are measured
+// against.
+var (
+	benchDNSLabelPattern     = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	benchDNSSubdomainPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	benchNamePartPattern     = regexp.MustCompile(`^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`)
+)
+
+var benchLabels = []string{"web", "kube-system", "my-app-7d9f8c6b5", "a", "Invalid_Label", "-leading", "trailing-"}
+
+var benchSubdomains = []string{"example.com", "app.kubernetes.io", "a.b.c.d.e", "my-app.svc.cluster.local", "Bad.Example", "trailing.", ".leading"}
+
+var benchNameParts = []string{"app", "app.kubernetes.io_name", "MyName", "x", "-bad", "bad_", "has space"}
+
+func BenchmarkDNSLabel(b *testing.B) {
+	b.Run("regexp", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, s := range benchLabels {
+				benchDNSLabelPattern.MatchString(s)
+			}
+		}
+	})
+	b.Run("loop", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, s := range benchLabels {
+				isDNS1123Label(s)
+			}
+		}
+	})
+}
+
+func BenchmarkDNSSubdomain(b *testing.B) {
+	b.Run("regexp", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, s := range benchSubdomains {
+				benchDNSSubdomainPattern.MatchString(s)
+			}
+		}
+	})
+	b.Run("loop", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, s := range benchSubdomains {
+				isDNS1123Subdomain(s)
+			}
+		}
+	})
+}
+
+func BenchmarkQualifiedNamePart(b *testing.B) {
+	b.Run("regexp", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, s := range benchNameParts {
+				benchNamePartPattern.MatchString(s)
+			}
+		}
+	})
+	b.Run("loop", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, s := range benchNameParts {
+				isQualifiedNamePart(s)
+			}
+		}
+	})
+}
+
+// benchDeployment returns the i-th Deployment of a generated manifest set.
+func benchDeployment(i int) map[string]interface{} {
+	name := fmt.Sprintf("app-%d", i)
+	labels := map[string]interface{}{
+		"app.kubernetes.io/name":    name,
+		"app.kubernetes.io/part-of": "shop",
+		"tier":                      "backend",
+	}
+	return map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "production",
+			"labels":    labels,
+			"annotations": map[string]interface{}{
+				"example.com/owner": "team-a",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": 3,
+			"selector": map[string]interface{}{"matchLabels": labels},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": labels},
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":  "app",
+							"image": fmt.Sprintf("registry.example.com/shop/%s:1.%d.0", name, i),
+							"ports": []interface{}{
+								map[string]interface{}{"name": "http", "containerPort": 8080},
+							},
+							"env": []interface{}{
+								map[string]interface{}{"name": "LOG_LEVEL", "value": "info"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func BenchmarkValidateObjectManifestSet(b *testing.B) {
+	objects := make([]map[string]interface{}, 500)
+	for i := range objects {
+		objects[i] = benchDeployment(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, obj := range objects {
+			_ = ValidateObject(obj)
+		}
+	}
+}
+
+// sliceSource serves a fixed list of objects as documents.
+type sliceSource struct {
+	objects []map[string]interface{}
+	next    int
+}
+
+func (s *sliceSource) Next() (Document, error) {
+	if s.next == len(s.objects) {
+		return Document{}, io.EOF
+	}
+	doc := Document{Source: "bench.yaml", Index: s.next, Object: s.objects[s.next]}
+	s.next++
+	return doc, nil
+}
+
+func BenchmarkValidatorValidateAll(b *testing.B) {
+	objects := make([]map[string]interface{}, 500)
+	for i := range objects {
+		objects[i] = benchDeployment(i)
+	}
+	v, err := NewValidator()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := v.ValidateAll(context.Background(), []Source{&sliceSource{objects: objects}}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
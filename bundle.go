@@ -0,0 +1,335 @@
+package k8sconstraints
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OfflineBundleFormat is the format version written into offline bundles. Bundles of a
+// newer format are rejected by ReadOfflineBundle.
+const OfflineBundleFormat = 1
+
+// CheckOfflineBundle is the ID of the check returned by OfflineBundle.Check.
+const CheckOfflineBundle = "OfflineBundle"
+
+// Entries of an offline bundle archive.
+const (
+	bundleManifestEntry    = "manifest.json"
+	bundleLabelSchemaEntry = "label-schema.json"
+	bundleOpenAPIEntry     = "openapi.json"
+	bundleCRDDir           = "crds/"
+)
+
+// OfflineBundle packages everything validation needs beyond the built-in rules, so
+// air-gapped environments get full functionality without network or cluster access: the
+// CustomResourceDefinitions whose schemas custom resources are checked against, a label
+// schema policy, and a snapshot of the GroupVersionKind registry, API deprecation table,
+// and rule documentation of the build that created it. Bundles built from a cluster also
+// hold its release and the OpenAPI document it serves; see SetClusterSnapshot.
+type OfflineBundle struct {
+	// Format is the bundle format version; see OfflineBundleFormat.
+	Format int `json:"format"`
+	// Created is when the bundle was built.
+	Created time.Time `json:"created"`
+	// Kinds lists the types with kind-specific validators in the building release, plus the
+	// custom resource types the bundle holds schemas for.
+	Kinds []string `json:"kinds"`
+	// Rules maps the rule codes of the building release to their descriptions.
+	Rules map[string]string `json:"rules"`
+	// Deprecations is the API deprecation table of the building release.
+	Deprecations []APIDeprecation `json:"deprecations,omitempty"`
+	// KubernetesVersion is the release of the cluster the bundle was built from, e.g.
+	// "1.30.2". Objects are checked for the APIs it deprecates or no longer serves.
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+
+	// CRDs holds the CustomResourceDefinitions whose schemas custom resources are
+	// validated against.
+	CRDs []map[string]interface{} `json:"-"`
+	// LabelSchema, when set, is enforced on every object.
+	LabelSchema *LabelSchema `json:"-"`
+	// OpenAPI holds the OpenAPI document served by the cluster the bundle was built from,
+	// listing the types it serves and their schemas.
+	OpenAPI []byte `json:"-"`
+
+	schemas *CRDSchemas
+	served  *KubernetesSchemas
+}
+
+// NewOfflineBundle builds a bundle from the CustomResourceDefinitions among objects and an
+// optional label schema, snapshotting DefaultRegistry and the built-in rule descriptions.
+// Objects other than CRDs are ignored.
+func NewOfflineBundle(objects []map[string]interface{}, labelSchema *LabelSchema) (*OfflineBundle, error) {
+	bundle := &OfflineBundle{
+		Format:       OfflineBundleFormat,
+		Created:      time.Now().UTC(),
+		Rules:        make(map[string]string, len(ruleDescriptions)),
+		Deprecations: append([]APIDeprecation(nil), APIDeprecations...),
+		CRDs:         make([]map[string]interface{}, 0),
+		LabelSchema:  labelSchema,
+	}
+	for rule, description := range ruleDescriptions {
+		bundle.Rules[rule] = description
+	}
+	for _, obj := range objects {
+		if IsCustomResourceDefinition(obj) {
+			bundle.CRDs = append(bundle.CRDs, obj)
+		}
+	}
+	if err := bundle.compile(); err != nil {
+		return nil, err
+	}
+
+	kinds := make([]string, 0)
+	for _, gvk := range DefaultRegistry.Kinds() {
+		kinds = append(kinds, gvk.String())
+	}
+	kinds = append(kinds, bundle.schemas.Kinds()...)
+	sort.Strings(kinds)
+	bundle.Kinds = kinds
+	return bundle, nil
+}
+
+// SetClusterSnapshot records the release of the cluster the bundle is built from, such as
+// "1.30.2", and the OpenAPI document its API server serves at /openapi/v2, adding the
+// types it serves to Kinds.
+func (b *OfflineBundle) SetClusterSnapshot(kubernetesVersion string, openAPI []byte) error {
+	if err := ValidateKubernetesVersion(kubernetesVersion); err != nil {
+		return err
+	}
+	served, err := LoadKubernetesOpenAPI(openAPI)
+	if err != nil {
+		return fmt.Errorf("invalid OpenAPI document: %v", err)
+	}
+	b.KubernetesVersion = kubernetesVersion
+	b.OpenAPI = openAPI
+	b.served = served
+
+	kinds := append([]string(nil), b.Kinds...)
+	for _, kind := range served.Kinds() {
+		if !containsString(kinds, kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	sort.Strings(kinds)
+	b.Kinds = kinds
+	return nil
+}
+
+// CRDSchemas returns the compiled schemas of the bundle's CRDs.
+func (b *OfflineBundle) CRDSchemas() *CRDSchemas {
+	return b.schemas
+}
+
+// ServedKinds returns the types served by the cluster the bundle was built from, or nil
+// when it was not built from a cluster; see ServedKindsCheck.
+func (b *OfflineBundle) ServedKinds() *KubernetesSchemas {
+	return b.served
+}
+
+// compile compiles the CRD schemas and the OpenAPI document of the bundle.
+func (b *OfflineBundle) compile() error {
+	schemas, err := CompileCRDSchemas(b.CRDs)
+	if err != nil {
+		return fmt.Errorf("invalid offline bundle: %v", err)
+	}
+	b.schemas = schemas
+	if len(b.OpenAPI) > 0 {
+		served, err := LoadKubernetesOpenAPI(b.OpenAPI)
+		if err != nil {
+			return fmt.Errorf("invalid offline bundle %s: %v", bundleOpenAPIEntry, err)
+		}
+		b.served = served
+	}
+	return nil
+}
+
+// Validate checks obj against the bundle: custom resources against the schema of their
+// CRD, other objects against the cluster's OpenAPI schemas, the apiVersion against the
+// deprecations of the cluster's release, and every object against the label schema.
+// Objects of types without a schema in the bundle pass the schema checks.
+func (b *OfflineBundle) Validate(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	gvk := GroupVersionKindOf(obj)
+	if schema, ok := b.schemas.SchemaFor(gvk.APIVersion(), gvk.Kind); ok {
+		body := make(map[string]interface{}, len(obj))
+		for key, value := range obj {
+			if !isTypeMetaField(key) {
+				body[key] = value
+			}
+		}
+		if schemaErrs := schema.Validate(body); len(schemaErrs) > 0 {
+			errs = append(errs, schemaViolations(CheckCRDSchema, schemaErrs))
+		}
+	} else if _, err := b.served.Validate(obj); err != nil {
+		errs = append(errs, err)
+	}
+
+	if b.KubernetesVersion != "" {
+		if err := checkDeprecation(b.Deprecations, gvk, b.KubernetesVersion); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if b.LabelSchema != nil {
+		if err := ValidateLabelSchema(obj, *b.LabelSchema); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// Check returns a check running Validate, for use with RunChecks and CheckFindings.
+func (b *OfflineBundle) Check() Check {
+	return Check{ID: CheckOfflineBundle, Validate: b.Validate}
+}
+
+// WriteOfflineBundle writes b as a gzip-compressed tar archive holding manifest.json, one
+// crds/<name>.json entry per CRD, label-schema.json when a label schema is set, and
+// openapi.json when the bundle was built from a cluster.
+func WriteOfflineBundle(w io.Writer, b *OfflineBundle) error {
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+
+	addData := func(name string, data []byte) error {
+		header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: b.Created}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := archive.Write(data)
+		return err
+	}
+	add := func(name string, value interface{}) error {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		return addData(name, data)
+	}
+
+	if err := add(bundleManifestEntry, b); err != nil {
+		return err
+	}
+	for i, crd := range b.CRDs {
+		name, _ := nestedString(crd, "metadata", "name")
+		if name == "" {
+			name = fmt.Sprintf("crd-%d", i)
+		}
+		if err := add(bundleCRDDir+name+".json", crd); err != nil {
+			return err
+		}
+	}
+	if b.LabelSchema != nil {
+		if err := add(bundleLabelSchemaEntry, b.LabelSchema); err != nil {
+			return err
+		}
+	}
+	if len(b.OpenAPI) > 0 {
+		if err := addData(bundleOpenAPIEntry, b.OpenAPI); err != nil {
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// ReadOfflineBundle reads a bundle written by WriteOfflineBundle and compiles its schemas.
+// Entries this release does not know are skipped, so bundles built by newer releases of
+// the same format still load.
+func ReadOfflineBundle(r io.Reader) (*OfflineBundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid offline bundle: %v", err)
+	}
+	archive := tar.NewReader(gz)
+
+	var bundle *OfflineBundle
+	crds := make([]map[string]interface{}, 0)
+	var labelSchema *LabelSchema
+	var openAPI []byte
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid offline bundle: %v", err)
+		}
+		data, err := io.ReadAll(archive)
+		if err != nil {
+			return nil, fmt.Errorf("invalid offline bundle: %v", err)
+		}
+
+		name := path.Clean(header.Name)
+		switch {
+		case name == bundleManifestEntry:
+			bundle = &OfflineBundle{}
+			if err := json.Unmarshal(data, bundle); err != nil {
+				return nil, fmt.Errorf("invalid offline bundle %s: %v", name, err)
+			}
+		case strings.HasPrefix(name, bundleCRDDir) && strings.HasSuffix(name, ".json"):
+			var crd map[string]interface{}
+			if err := json.Unmarshal(data, &crd); err != nil {
+				return nil, fmt.Errorf("invalid offline bundle %s: %v", name, err)
+			}
+			crds = append(crds, crd)
+		case name == bundleLabelSchemaEntry:
+			labelSchema = &LabelSchema{}
+			if err := json.Unmarshal(data, labelSchema); err != nil {
+				return nil, fmt.Errorf("invalid offline bundle %s: %v", name, err)
+			}
+			if err := ValidateLabelSchemaDefinition(*labelSchema); err != nil {
+				return nil, fmt.Errorf("invalid offline bundle %s: %v", name, err)
+			}
+		case name == bundleOpenAPIEntry:
+			openAPI = data
+		}
+	}
+
+	if bundle == nil {
+		return nil, fmt.Errorf("invalid offline bundle: %s is missing", bundleManifestEntry)
+	}
+	if bundle.Format > OfflineBundleFormat {
+		return nil, fmt.Errorf("offline bundle format %d is newer than the supported format %d", bundle.Format, OfflineBundleFormat)
+	}
+	bundle.CRDs = crds
+	bundle.LabelSchema = labelSchema
+	bundle.OpenAPI = openAPI
+	if err := bundle.compile(); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// LoadOfflineBundle reads an offline bundle from a file; see ReadOfflineBundle.
+func LoadOfflineBundle(path string) (*OfflineBundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	bundle, err := ReadOfflineBundle(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return bundle, nil
+}
@@ -0,0 +1,138 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+// CELRule is a user-defined constraint written as a CEL expression over the object, the way
+// Kubernetes ValidatingAdmissionPolicies are:
+//
+//	id: MaxReplicas
+//	expression: "!has(object.spec.replicas) || object.spec.replicas <= 10"
+//	message: replicas must be at most 10
+//	fieldPath: spec.replicas
+//	kinds: [Deployment.apps]
+//
+// The expression sees the object as the variable object and must evaluate to true for the
+// object to pass. Selecting a field the object does not have is an evaluation error, which
+// is reported as a violation, so optional fields are guarded with has().
+type CELRule struct {
+	// ID is the rule code of the findings, which severity overrides and disable lists
+	// refer to.
+	ID string `yaml:"id" json:"id"`
+	// Expression is the CEL expression; it must evaluate to a bool.
+	Expression string `yaml:"expression" json:"expression"`
+	// Message is reported when the expression is false. Defaults to a message quoting the
+	// expression. A "warning: " prefix makes the findings warnings.
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+	// FieldPath is the field the findings are reported at, e.g. spec.replicas. Empty
+	// reports them against the object.
+	FieldPath string `yaml:"fieldPath,omitempty" json:"fieldPath,omitempty"`
+	// Kinds restricts the rule to the given group-qualified kinds, written Kind.group as
+	// for Check.Kinds. Empty means every kind.
+	Kinds []string `yaml:"kinds,omitempty" json:"kinds,omitempty"`
+}
+
+var (
+	// celEnv declares the variables CEL rules may refer to
+	celEnv     *cel.Env
+	celEnvErr  error
+	celEnvOnce sync.Once
+	// celPrograms caches the compiled programs of CEL rules by expression, since the
+	// checks are rebuilt for every document
+	celPrograms sync.Map
+)
+
+// compileCELExpression compiles expression once, checking that it evaluates to a bool, and
+// returns the cached program on later calls.
+func compileCELExpression(expression string) (cel.Program, error) {
+	if program, ok := celPrograms.Load(expression); ok {
+		return program.(cel.Program), nil
+	}
+
+	celEnvOnce.Do(func() {
+		celEnv, celEnvErr = cel.NewEnv(cel.Variable("object", cel.DynType))
+	})
+	if celEnvErr != nil {
+		return nil, celEnvErr
+	}
+	ast, issues := celEnv.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid expression: %s", strings.TrimSpace(issues.Err().Error()))
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("expression must evaluate to a bool, not %s", ast.OutputType())
+	}
+	program, err := celEnv.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %v", err)
+	}
+	celPrograms.Store(expression, program)
+	return program, nil
+}
+
+// ValidateCELRule checks that rule has an ID and an expression that compiles to a bool.
+func ValidateCELRule(rule CELRule) error {
+	errs := make([]error, 0)
+	if rule.ID == "" {
+		errs = append(errs, fmt.Errorf("id is required"))
+	}
+	if rule.Expression == "" {
+		errs = append(errs, fmt.Errorf("expression is required"))
+	} else if _, err := compileCELExpression(rule.Expression); err != nil {
+		errs = append(errs, err)
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// CELCheck returns an opt-in check, not run by ValidateObject, that evaluates rule against
+// every object of its kinds; see ValidateCELRule for the rules it accepts. Run it with
+// RunChecks or pass it to the linter's Checks option.
+func CELCheck(rule CELRule) (Check, error) {
+	if err := ValidateCELRule(rule); err != nil {
+		return Check{}, fmt.Errorf("rule '%s': %v", rule.ID, err)
+	}
+	return Check{ID: rule.ID, Kinds: rule.Kinds, Validate: func(obj map[string]interface{}) error {
+		return EvaluateCELRule(obj, rule)
+	}}, nil
+}
+
+// EvaluateCELRule evaluates rule against obj, returning a violation when the expression is
+// false or cannot be evaluated, such as when it selects a missing field.
+func EvaluateCELRule(obj map[string]interface{}, rule CELRule) error {
+	program, err := compileCELExpression(rule.Expression)
+	if err != nil {
+		return &ConstraintError{FieldPath: rule.FieldPath, Rule: rule.ID, Message: err.Error()}
+	}
+
+	out, _, err := program.Eval(map[string]interface{}{"object": obj})
+	if err != nil {
+		message := fmt.Sprintf("expression '%s' could not be evaluated: %v", rule.Expression, err)
+		return &ConstraintError{FieldPath: rule.FieldPath, Rule: rule.ID, Message: message}
+	}
+	passed, ok := out.(types.Bool)
+	if !ok {
+		message := fmt.Sprintf("expression '%s' must evaluate to a bool, not %s", rule.Expression, out.Type().TypeName())
+		return &ConstraintError{FieldPath: rule.FieldPath, Rule: rule.ID, Message: message}
+	}
+	if passed {
+		return nil
+	}
+
+	message := rule.Message
+	if message == "" {
+		message = fmt.Sprintf("expression '%s' evaluated to false", rule.Expression)
+	}
+	return &ConstraintError{FieldPath: rule.FieldPath, Rule: rule.ID, Message: message}
+}
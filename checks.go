@@ -0,0 +1,201 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Check is a validator with an identity and dependencies. A check only runs once every
+// check it depends on has run against the same object and passed, so a single root cause,
+// such as a malformed apiVersion, does not produce a cascade of misleading follow-on errors
+// from checks that assume it is valid. Checks that are skipped this way report nothing.
+//
+// Documents that fail to decode never reach any check.
+type Check struct {
+	// ID identifies the check, e.g. "TypeMeta". Violations the check reports without a rule
+	// code of their own carry its ID as their rule code.
+	ID string
+	// DependsOn lists the IDs of the checks that must pass first.
+	DependsOn []string
+	// Kinds restricts the check to the given group-qualified kinds, written Kind.group as
+	// kubectl does ("Kind" alone for the core group). Empty means every kind.
+	Kinds []string
+	// Validate runs the check.
+	Validate func(obj map[string]interface{}) error
+}
+
+// BundleCheck builds, from every object of a bundle, a check that validates each of them
+// against the others, for constraints spanning objects, such as a HorizontalPodAutoscaler
+// and the workload it scales. The linter runs them once every document has been read; see
+// BuiltinBundleChecks.
+type BundleCheck func(objects []map[string]interface{}) Check
+
+// appliesTo reports whether the check runs against objects of the given group-qualified kind.
+func (c Check) appliesTo(groupKind string) bool {
+	return len(c.Kinds) == 0 || containsString(c.Kinds, groupKind)
+}
+
+// ValidateChecks verifies that check IDs are unique and non-empty and that every dependency
+// refers to a known check without forming a cycle.
+func ValidateChecks(checks []Check) error {
+	errs := make([]error, 0)
+
+	byID := make(map[string]Check, len(checks))
+	for i, check := range checks {
+		switch {
+		case check.ID == "":
+			errs = append(errs, fmt.Errorf("checks[%d]: id is required", i))
+		case byID[check.ID].ID != "":
+			errs = append(errs, fmt.Errorf("checks[%d]: duplicate check id '%s'", i, check.ID))
+		case check.Validate == nil:
+			errs = append(errs, fmt.Errorf("check '%s': validate function cannot be nil", check.ID))
+		}
+		byID[check.ID] = check
+	}
+	for _, check := range checks {
+		for _, dependency := range check.DependsOn {
+			if _, ok := byID[dependency]; !ok {
+				errs = append(errs, fmt.Errorf("check '%s' depends on unknown check '%s'", check.ID, dependency))
+			}
+		}
+	}
+	if _, err := orderChecks(checks); err != nil {
+		errs = append(errs, err)
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// RunChecks runs checks against obj in dependency order, skipping checks that do not apply
+// to obj's kind and checks whose dependencies failed or were skipped. The returned error
+// joins the errors of the checks that ran. Invalid check sets are reported as an error
+// without running anything; see ValidateChecks.
+func RunChecks(obj map[string]interface{}, checks []Check) error {
+	ordered, err := orderChecks(checks)
+	if err != nil {
+		return err
+	}
+
+	kind := groupKind(obj)
+	passed := make(map[string]bool, len(ordered))
+	errs := make([]error, 0)
+	for _, check := range ordered {
+		if !check.appliesTo(kind) || !allPassed(passed, check.DependsOn) {
+			continue
+		}
+		if err := check.Validate(obj); err != nil {
+			errs = append(errs, withDefaultRule(check.ID, err))
+			continue
+		}
+		passed[check.ID] = true
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// RunBundleCheck runs the check bundleCheck builds from objects against each of them and
+// joins the errors.
+func RunBundleCheck(objects []map[string]interface{}, bundleCheck BundleCheck) error {
+	checks := []Check{bundleCheck(objects)}
+
+	errs := make([]error, 0)
+	for _, obj := range objects {
+		if err := RunChecks(obj, checks); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// CheckFindings runs checks against obj with RunChecks, or against each item of a List, and
+// returns the findings. Malformed List items are left to ValidateObject to report.
+func CheckFindings(obj map[string]interface{}, checks []Check) []Finding {
+	return CheckFindingsAt(obj, "", checks)
+}
+
+// CheckFindingsAt is CheckFindings for an object found at path within its document, such as
+// a List item yielded on its own by NewStreamSource; see Document.Path.
+func CheckFindingsAt(obj map[string]interface{}, path string, checks []Check) []Finding {
+	if !IsList(obj) {
+		return FindingsFromError(obj, WithFieldPath(path, RunChecks(obj, checks)))
+	}
+
+	items, _ := expandList(obj, path)
+	findings := make([]Finding, 0)
+	for _, item := range items {
+		findings = append(findings, FindingsFromError(item.Object, WithFieldPath(item.Path, RunChecks(item.Object, checks)))...)
+	}
+	return findings
+}
+
+// allPassed reports whether every check in ids passed.
+func allPassed(passed map[string]bool, ids []string) bool {
+	for _, id := range ids {
+		if !passed[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// orderChecks sorts checks so that every check comes after its dependencies, keeping the
+// declared order otherwise. Dependencies on unknown checks are ignored here; such checks
+// never run since the dependency never passes.
+func orderChecks(checks []Check) ([]Check, error) {
+	index := make(map[string]int, len(checks))
+	for i, check := range checks {
+		index[check.ID] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(checks))
+	ordered := make([]Check, 0, len(checks))
+
+	var visit func(i int, chain []string) error
+	visit = func(i int, chain []string) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("check dependency cycle: %s", strings.Join(append(chain, checks[i].ID), " -> "))
+		}
+		state[i] = visiting
+		for _, dependency := range checks[i].DependsOn {
+			if j, ok := index[dependency]; ok {
+				if err := visit(j, append(chain, checks[i].ID)); err != nil {
+					return err
+				}
+			}
+		}
+		state[i] = visited
+		ordered = append(ordered, checks[i])
+		return nil
+	}
+
+	for i := range checks {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,72 @@
+// k8sconstraints.js loads k8sconstraints.wasm and wraps the functions it registers in a
+// small promise-based API. wasm_exec.js from the Go release that built the module must be
+// loaded first, as it defines the global Go class:
+//
+//   <script src="wasm_exec.js"></script>
+//   <script type="module">
+//     import { load } from "./k8sconstraints.js";
+//
+//     const k8sconstraints = await load("k8sconstraints.wasm");
+//     const report = k8sconstraints.validate(manifestText, { kubernetesVersion: "1.29" });
+//     for (const finding of report.findings) {
+//       console.log(finding.rule, finding.message);
+//     }
+//   </script>
+//
+// validate returns the report in the JSON output format of the CLI, already parsed. Its
+// options are all optional:
+//
+//   kubernetesVersion    check apiVersions against this Kubernetes release, as for
+//                        --kubernetes-version
+//   severity             RULE=SEVERITY pairs separated by commas, as for --severity
+//   verbose              report every finding rather than grouping repeated ones
+//   showSensitiveValues  do not redact values of Secrets and sensitive fields
+//
+// validate and describeRule throw an Error for invalid arguments.
+
+let loading;
+
+// load fetches and starts k8sconstraints.wasm from url, resolving to the API once the Go
+// program has registered it. The module is started once per page; later calls resolve to
+// the same API.
+export function load(url = "k8sconstraints.wasm") {
+  if (!loading) {
+    loading = start(url);
+  }
+  return loading;
+}
+
+async function start(url) {
+  if (typeof globalThis.Go !== "function") {
+    throw new Error("k8sconstraints: load wasm_exec.js before k8sconstraints.js");
+  }
+  const go = new globalThis.Go();
+  const response = fetch(url);
+  const { instance } = WebAssembly.instantiateStreaming
+    ? await WebAssembly.instantiateStreaming(response, go.importObject)
+    : await WebAssembly.instantiate(await (await response).arrayBuffer(), go.importObject);
+
+  // run resolves only when the Go program exits, which it does not do while the page lives
+  go.run(instance);
+
+  const api = globalThis.k8sconstraints;
+  if (!api) {
+    throw new Error("k8sconstraints: the module did not register its API");
+  }
+  return {
+    validate(manifests, options = {}) {
+      return JSON.parse(unwrap(api.validate(manifests, options)));
+    },
+    describeRule(rule) {
+      return unwrap(api.describeRule(rule));
+    },
+  };
+}
+
+// unwrap throws the Error the Go functions return in place of a result.
+function unwrap(result) {
+  if (result instanceof Error) {
+    throw result;
+  }
+  return result;
+}
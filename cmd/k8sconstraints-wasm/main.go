@@ -0,0 +1,119 @@
+//go:build js && wasm
+
+// Command k8sconstraints-wasm exposes the k8sconstraints linter to JavaScript, so web UIs
+// such as manifest editors can run the same checks as the CLI in the browser. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o k8sconstraints.wasm ./cmd/k8sconstraints-wasm
+//
+// and serve k8sconstraints.wasm along with k8sconstraints.js from this directory and the
+// wasm_exec.js of the Go release that built it, found in $(go env GOROOT)/lib/wasm
+// (misc/wasm before Go 1.24). k8sconstraints.js documents the JavaScript API.
+//
+// Once started, the program registers a k8sconstraints object on the global object with
+// two functions:
+//
+//	validate(manifests, options) -> string
+//	describeRule(rule) -> string
+//
+// validate lints the YAML or JSON manifests in the string manifests and returns the report
+// in the JSON output format of the CLI, or an Error for invalid options. options may
+// set kubernetesVersion, severity (RULE=SEVERITY pairs as for --severity), verbose, and
+// showSensitiveValues. describeRule returns the description of a rule code.
+package main
+
+import (
+	"bytes"
+	"context"
+	"syscall/js"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+	"github.com/martinflemingdev/k8s_constraints/linter"
+)
+
+func main() {
+	api := js.Global().Get("Object").New()
+	api.Set("validate", js.FuncOf(validate))
+	api.Set("describeRule", js.FuncOf(describeRule))
+	js.Global().Set("k8sconstraints", api)
+
+	// Keep the functions callable for the lifetime of the page
+	select {}
+}
+
+// validate implements k8sconstraints.validate(manifests, options).
+func validate(this js.Value, args []js.Value) interface{} {
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		return jsError("validate: manifests must be a string")
+	}
+	opts, err := lintOptions(optionalArg(args, 1))
+	if err != nil {
+		return jsError("validate: " + err.Error())
+	}
+
+	source := k8sconstraints.NewReaderSource("manifest", bytes.NewReader([]byte(args[0].String())))
+	report, err := linter.New(opts).LintSource(context.Background(), source)
+	if err != nil {
+		return jsError("validate: " + err.Error())
+	}
+
+	var out bytes.Buffer
+	if err := k8sconstraints.WriteReport(&out, report, k8sconstraints.FormatJSON); err != nil {
+		return jsError("validate: " + err.Error())
+	}
+	return out.String()
+}
+
+// lintOptions reads the linter options from the options object passed to validate.
+func lintOptions(options js.Value) (linter.Options, error) {
+	opts := linter.Options{}
+	if options.Type() != js.TypeObject {
+		return opts, nil
+	}
+
+	if version := stringField(options, "kubernetesVersion"); version != "" {
+		if err := k8sconstraints.ValidateKubernetesVersion(version); err != nil {
+			return opts, err
+		}
+		opts.Checks = append(opts.Checks, k8sconstraints.KubernetesVersionChecks(version)...)
+	}
+	if severities := stringField(options, "severity"); severities != "" {
+		overrides, err := k8sconstraints.ParseSeverityOverrides(severities)
+		if err != nil {
+			return opts, err
+		}
+		opts.SeverityOverrides = overrides
+	}
+	opts.Verbose = options.Get("verbose").Truthy()
+	opts.ShowSensitiveValues = options.Get("showSensitiveValues").Truthy()
+	return opts, nil
+}
+
+// describeRule implements k8sconstraints.describeRule(rule).
+func describeRule(this js.Value, args []js.Value) interface{} {
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		return jsError("describeRule: rule must be a string")
+	}
+	return k8sconstraints.RuleDescription(args[0].String())
+}
+
+// optionalArg returns args[i], or undefined when it was not passed.
+func optionalArg(args []js.Value, i int) js.Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return js.Undefined()
+}
+
+// stringField returns the string property name of obj, or "" when it is not a string.
+func stringField(obj js.Value, name string) string {
+	if value := obj.Get(name); value.Type() == js.TypeString {
+		return value.String()
+	}
+	return ""
+}
+
+// jsError returns a JavaScript Error for message. Functions return it rather than panic,
+// which would stop the Go program; k8sconstraints.js throws it.
+func jsError(message string) interface{} {
+	return js.Global().Get("Error").New(message)
+}
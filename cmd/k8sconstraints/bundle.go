@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+	"github.com/martinflemingdev/k8s_constraints/linter"
+	"github.com/martinflemingdev/k8s_constraints/source"
+)
+
+// defaultBundlePath is where the bundle command writes the archive unless -o is given.
+const defaultBundlePath = "k8sconstraints-bundle.tar.gz"
+
+// runBundle packages the CustomResourceDefinitions found in the named files and
+// directories, an optional label schema, and this build's registry, deprecation, and rule
+// tables into an offline bundle for air-gapped environments, loaded with --offline-bundle.
+// With --from-cluster, the installed CRDs, release, and OpenAPI document of the cluster of
+// the current kubeconfig context are added, so CI can validate against the cluster
+// without access to it.
+func runBundle(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("bundle", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	out := flags.String("o", defaultBundlePath, "write the bundle to FILE")
+	labelSchemaPath := flags.String("label-schema", "", "enforce the label schema in FILE (JSON) on every object")
+	fromCluster := flags.Bool("from-cluster", false, "add the installed CRDs, release, and served OpenAPI schemas of the cluster of the current kubeconfig context")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	paths, err := expandGlobs(flags.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	objects, err := readObjects(paths)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	var labelSchema *k8sconstraints.LabelSchema
+	if *labelSchemaPath != "" {
+		if labelSchema, err = k8sconstraints.LoadLabelSchema(*labelSchemaPath); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+
+	var version string
+	var openAPI []byte
+	if *fromCluster {
+		ctx := context.Background()
+		crds, err := source.ClusterCRDs(ctx, "")
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		objects = append(objects, crds...)
+		if version, err = source.ClusterVersion(ctx, ""); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		if openAPI, err = source.ClusterOpenAPIDocument(ctx, ""); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+
+	bundle, err := k8sconstraints.NewOfflineBundle(objects, labelSchema)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if *fromCluster {
+		if err := bundle.SetClusterSnapshot(version, openAPI); err != nil {
+			fmt.Fprintf(stderr, "cluster %s: %v\n", version, err)
+			return exitUsage
+		}
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if err := k8sconstraints.WriteOfflineBundle(f, bundle); err != nil {
+		f.Close()
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if err := f.Close(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	if bundle.KubernetesVersion != "" {
+		fmt.Fprintf(stdout, "wrote %s: Kubernetes %s, %d CRD(s), %d kind(s), %d rule(s)\n", *out, bundle.KubernetesVersion, len(bundle.CRDs), len(bundle.Kinds), len(bundle.Rules))
+		return exitOK
+	}
+	fmt.Fprintf(stdout, "wrote %s: %d CRD(s), %d kind(s), %d rule(s)\n", *out, len(bundle.CRDs), len(bundle.Kinds), len(bundle.Rules))
+	return exitOK
+}
+
+// readObjects decodes every manifest in paths, descending into directories.
+func readObjects(paths []string) ([]map[string]interface{}, error) {
+	discoverer := linter.New(linter.Options{Recursive: true})
+	files := make([]string, 0)
+	for _, path := range paths {
+		discovered, err := discoverer.Discover(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, discovered...)
+	}
+
+	objects := make([]map[string]interface{}, 0)
+	source := k8sconstraints.NewFileSource(files...)
+	for {
+		doc, err := source.Next()
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if doc.Err != nil {
+			return nil, fmt.Errorf("%s: %v", doc.Source, doc.Err)
+		}
+		objects = append(objects, doc.Object)
+	}
+}
+
+// loadOfflineBundle loads the bundle named by an --offline-bundle flag, or returns nil when
+// the flag is empty.
+func loadOfflineBundle(path string) (*k8sconstraints.OfflineBundle, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return k8sconstraints.LoadOfflineBundle(path)
+}
+
+// loadCRDDir compiles the CustomResourceDefinitions in the directory named by a --crd-dir
+// flag, or returns nil when the flag is empty.
+func loadCRDDir(dir string) (*k8sconstraints.CRDSchemas, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	objects, err := readObjects([]string{dir})
+	if err != nil {
+		return nil, err
+	}
+	return k8sconstraints.CompileCRDSchemas(objects)
+}
+
+// loadClusterDiscovery fetches the types served by the cluster of the current kubeconfig
+// context and its installed CustomResourceDefinitions for a --cluster-discovery flag,
+// returning crds with the installed CRDs added. Installed CRDs whose schemas cannot be
+// compiled here are skipped with a warning, as the cluster has already accepted them.
+func loadClusterDiscovery(ctx context.Context, crds *k8sconstraints.CRDSchemas, stderr io.Writer) (*k8sconstraints.CRDSchemas, *k8sconstraints.KubernetesSchemas, error) {
+	served, err := source.ClusterOpenAPI(ctx, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	installed, err := source.ClusterCRDs(ctx, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	crds = crds.Clone()
+	for _, crd := range installed {
+		if err := crds.Add(crd); err != nil {
+			fmt.Fprintf(stderr, "warning: skipping CustomResourceDefinition '%s' of the cluster: %v\n", k8sconstraints.ResourceRefOf(crd).Name, err)
+		}
+	}
+	return crds, served, nil
+}
+
+// builtinOpenAPI is the --openapi value selecting the OpenAPI schemas built into
+// k8sconstraints.
+const builtinOpenAPI = "builtin"
+
+// loadOpenAPISchemaCheck builds the check selected by an --openapi flag: the built-in
+// schemas, or those of an OpenAPI document.
+func loadOpenAPISchemaCheck(source string) (k8sconstraints.Check, error) {
+	if source == builtinOpenAPI {
+		return k8sconstraints.OpenAPISchemaCheck(nil), nil
+	}
+	schemas, err := k8sconstraints.LoadKubernetesOpenAPIFile(source)
+	if err != nil {
+		return k8sconstraints.Check{}, err
+	}
+	return k8sconstraints.OpenAPISchemaCheck(schemas), nil
+}
+
+// applyConstraintProfile pins the built-in rules to those of the release named by a
+// --constraint-profile flag, or leaves them alone when the flag is empty.
+func applyConstraintProfile(version string) error {
+	if version == "" {
+		return nil
+	}
+	return k8sconstraints.UseConstraintProfile(version)
+}
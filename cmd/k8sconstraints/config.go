@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+	"github.com/martinflemingdev/k8s_constraints/linter"
+)
+
+// noConfig is the --config value that turns off configuration file discovery.
+const noConfig = "none"
+
+// loadConfig loads the configuration file named by a --config flag. When the flag is
+// empty, the file is discovered from the working directory upwards; nil is returned when
+// there is none or the flag is "none".
+func loadConfig(path string) (*linter.Config, error) {
+	if path == noConfig {
+		return nil, nil
+	}
+	if path == "" {
+		found, err := linter.FindConfig(".")
+		if err != nil || found == "" {
+			return nil, err
+		}
+		path = found
+	}
+	return linter.LoadConfig(path)
+}
+
+// applyNodePortRange makes the built-in rules check Service node ports against the range
+// of a --node-port-range flag, or else that of the configuration file, if any.
+func applyNodePortRange(flag string, config *linter.Config) error {
+	if flag != "" {
+		nodePorts, err := k8sconstraints.ParseNodePortRange(flag)
+		if err != nil {
+			return fmt.Errorf("invalid --node-port-range: %v", err)
+		}
+		k8sconstraints.UseNodePortRange(nodePorts)
+		return nil
+	}
+	if config != nil {
+		k8sconstraints.UseNodePortRange(config.NodePorts())
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+	"github.com/martinflemingdev/k8s_constraints/linter"
+)
+
+// runFmt rewrites YAML manifests in canonical form; see k8sconstraints.FormatYAML. Like
+// gofmt, it prints the formatted manifests, or with -w rewrites the files in place and with
+// -l lists the files whose formatting differs. Manifests are validated first: findings are
+// reported on stderr, and files with errors are left alone, making the command exit with
+// exitFindings.
+func runFmt(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("w", false, "write the result to the source file instead of stdout")
+	list := flags.Bool("l", false, "list files whose formatting differs instead of printing them")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{linter.StdinPath}
+	}
+
+	code := exitOK
+	for _, path := range paths {
+		if path == linter.StdinPath && *write {
+			fmt.Fprintln(stderr, "cannot use -w with standard input")
+			return exitUsage
+		}
+		if ext := strings.ToLower(filepath.Ext(path)); path != linter.StdinPath && ext != ".yaml" && ext != ".yml" {
+			fmt.Fprintf(stderr, "%s: only YAML files can be formatted\n", path)
+			return exitUsage
+		}
+
+		var data []byte
+		var err error
+		if path == linter.StdinPath {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(path)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+
+		name := path
+		if path == linter.StdinPath {
+			name = "<stdin>"
+		}
+		report, err := linter.New(linter.Options{}).LintSource(context.Background(), k8sconstraints.NewYAMLSource(name, bytes.NewReader(data)))
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		for _, finding := range report.Findings {
+			fmt.Fprintf(stderr, "%s: %s\n", name, k8sconstraints.FormatFinding(finding))
+		}
+		if k8sconstraints.SummarizeTarget(name, report).Errors > 0 {
+			code = exitFindings
+			continue
+		}
+
+		formatted, err := k8sconstraints.FormatYAML(data)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", name, err)
+			return exitUsage
+		}
+		switch {
+		case *list:
+			if !bytes.Equal(data, formatted) {
+				fmt.Fprintln(stdout, name)
+			}
+		case *write:
+			if !bytes.Equal(data, formatted) {
+				if err := os.WriteFile(path, formatted, 0o644); err != nil {
+					fmt.Fprintln(stderr, err)
+					return exitUsage
+				}
+			}
+		default:
+			if _, err := stdout.Write(formatted); err != nil {
+				fmt.Fprintln(stderr, err)
+				return exitUsage
+			}
+		}
+	}
+	return code
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+	"gopkg.in/yaml.v3"
+)
+
+// runGatekeeper prints OPA Gatekeeper ConstraintTemplates and Constraints equivalent to
+// the rules enabled by the configuration file, ready for kubectl apply -f -.
+func runGatekeeper(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gatekeeper", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", "", "configuration file whose requiredLabels and allowedRegistries are exported (default: .k8sconstraints.yaml in the working directory or a parent)")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 0 {
+		fmt.Fprintln(stderr, "usage: k8sconstraints gatekeeper [--config FILE]")
+		return exitUsage
+	}
+
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	policy := k8sconstraints.GatekeeperPolicy{}
+	if config != nil {
+		policy.RequiredLabels = config.RequiredLabels
+		policy.AllowedRegistries = config.AllowedRegistries
+	}
+	objects, err := k8sconstraints.GatekeeperObjects(policy)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	var data bytes.Buffer
+	encoder := yaml.NewEncoder(&data)
+	encoder.SetIndent(2)
+	for _, obj := range objects {
+		if err := encoder.Encode(obj); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	formatted, err := k8sconstraints.FormatYAML(data.Bytes())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if _, err := stdout.Write(formatted); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	return exitOK
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+	"github.com/martinflemingdev/k8s_constraints/linter"
+)
+
+// GitOps gate modes. Both print the rendered manifests unchanged when they are valid, so
+// the gate can sit between rendering and applying; they differ in how violations are
+// reported.
+const (
+	// gateModeArgoCD runs as the generate command of an Argo CD ConfigManagementPlugin and
+	// lists one violation per line on stderr, which Argo CD shows in the ComparisonError
+	// condition of the Application.
+	gateModeArgoCD = "argocd"
+	// gateModeFlux validates the output of `flux build kustomization` or `kustomize build`
+	// and reports violations on a single stderr line, the way Flux reports failed
+	// reconciliations in the Ready condition.
+	gateModeFlux = "flux"
+)
+
+// runGate validates rendered manifests read from a directory, file, or standard input and
+// writes them to stdout unchanged if nothing at or above the --fail-on severity was
+// reported. Otherwise it writes nothing to stdout, reports the violations on stderr, and
+// exits with exitFindings, failing the sync.
+func runGate(mode string, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet(mode, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	exclude := flags.String("exclude", "", "comma-separated globs skipping files and directories")
+	timeout := flags.Duration("timeout", 10*time.Second, "time limit for validating a single document (0 for none)")
+	profile := flags.String("constraint-profile", "", "pin the built-in rules and their limits to those of an earlier k8sconstraints release, e.g. 0.1")
+	warnReserved := flags.Bool("warn-reserved-namespaces", false, "warn about objects deployed to default, kube-system, kube-public, or kube-node-lease")
+	warnPrefixes := flags.Bool("warn-reserved-prefixes", false, "warn about labels and annotations under the kubernetes.io/ and k8s.io/ prefixes")
+	warnImageTags := flags.Bool("warn-image-tags", false, "warn about latest and untagged images, and imagePullPolicy values that do not suit the image's tag or digest")
+	nodePortRange := flags.String("node-port-range", "", "the API server's --service-node-port-range Service node ports must lie within, e.g. 30000-32767, overriding the configuration file's nodePortRange")
+	configPath := flags.String("config", "", "configuration file (default: .k8sconstraints.yaml in the working directory or a parent; none to disable)")
+	severities := flags.String("severity", "", "comma-separated RULE=SEVERITY overrides, e.g. DeprecatedAPI=warning; severities are error, warning, and info")
+	failOn := flags.String("fail-on", string(k8sconstraints.SeverityWarning), "least severity of the findings that fail the run: error, warning, or info")
+	kubernetesVersion := flags.String("kubernetes-version", "", "validate against the given Kubernetes release, e.g. 1.29: report deprecated and removed apiVersions and fields it does not serve")
+	warnRecommended := flags.Bool("warn-recommended-labels", false, "warn about workloads missing the recommended app.kubernetes.io labels")
+	recommendedLabels := flags.String("recommended-labels", "", "comma-separated label keys --warn-recommended-labels expects instead of the app.kubernetes.io ones")
+	podSecurity := flags.String("pod-security", "", "hold pod specs to a Pod Security Standards profile: baseline or restricted, overriding the configuration file's podSecurity")
+	allowReserved := flags.String("allow-reserved-keys", "", "comma-separated reserved label and annotation keys, or prefixes ending in '/', that --warn-reserved-prefixes accepts")
+	offlineBundle := flags.String("offline-bundle", "", "also validate against the CRD schemas and policies of an offline bundle built with the bundle command")
+	flags.StringVar(offlineBundle, "schema-bundle", "", "alias of --offline-bundle")
+	crdDir := flags.String("crd-dir", "", "also validate custom resources against the CustomResourceDefinitions in DIR, in addition to those among the manifests")
+	openAPI := flags.String("openapi", "", "also validate built-in types against OpenAPI schemas, reporting unknown fields, wrong types, and missing required fields: builtin, or an OpenAPI document such as swagger.json")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	// Argo CD runs plugins in the application's source directory
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+		if mode == gateModeFlux {
+			paths = []string{linter.StdinPath}
+		}
+	}
+
+	manifests, source, err := readGateInput(paths, *exclude)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	if err := applyConstraintProfile(*profile); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	bundle, err := loadOfflineBundle(*offlineBundle)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	crds, err := loadCRDDir(*crdDir)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	opts := linter.Options{DocumentTimeout: *timeout, Bundle: bundle, CRDs: crds}
+	if *warnReserved {
+		opts.Checks = append(opts.Checks, k8sconstraints.ReservedNamespacesCheck)
+	}
+	if *warnPrefixes {
+		opts.Checks = append(opts.Checks, k8sconstraints.ReservedPrefixesCheck(splitList(*allowReserved)))
+	}
+	if *warnImageTags {
+		opts.Checks = append(opts.Checks, k8sconstraints.ImageTagsCheck)
+	}
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	opts.Config = config
+	if err := applyNodePortRange(*nodePortRange, config); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	overrides, err := k8sconstraints.ParseSeverityOverrides(*severities)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid --severity: %v\n", err)
+		return exitUsage
+	}
+	opts.SeverityOverrides = overrides
+	failSeverity, err := k8sconstraints.ParseSeverity(*failOn)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid --fail-on: %v\n", err)
+		return exitUsage
+	}
+	if *openAPI != "" {
+		check, err := loadOpenAPISchemaCheck(*openAPI)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		opts.Checks = append(opts.Checks, check)
+	}
+	if *kubernetesVersion != "" {
+		if err := k8sconstraints.ValidateKubernetesVersion(*kubernetesVersion); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		opts.Checks = append(opts.Checks, k8sconstraints.KubernetesVersionChecks(*kubernetesVersion)...)
+	}
+	if *warnRecommended {
+		config := k8sconstraints.RecommendedLabelsConfig{Labels: splitList(*recommendedLabels)}
+		if err := config.Validate(); err != nil {
+			fmt.Fprintf(stderr, "invalid --recommended-labels: %v\n", err)
+			return exitUsage
+		}
+		opts.Checks = append(opts.Checks, k8sconstraints.RecommendedLabelsCheck(config))
+	}
+	if *podSecurity != "" {
+		config := k8sconstraints.PodSecurityConfig{Level: *podSecurity}
+		if err := config.Validate(); err != nil {
+			fmt.Fprintf(stderr, "invalid --pod-security: %v\n", err)
+			return exitUsage
+		}
+		opts.Checks = append(opts.Checks, k8sconstraints.PodSecurityCheck(config))
+	}
+	report, err := linter.New(opts).LintSource(context.Background(), source)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	if report.HasFindingsAtLeast(failSeverity) {
+		lines := make([]string, 0, len(report.Findings))
+		for _, finding := range report.Findings {
+			lines = append(lines, finding.File+": "+k8sconstraints.FormatFinding(finding))
+		}
+		if mode == gateModeFlux {
+			fmt.Fprintf(stderr, "validation failed: %d finding(s): %s\n", len(lines), strings.Join(lines, "; "))
+		} else {
+			fmt.Fprintf(stderr, "k8sconstraints: validation failed with %d finding(s)\n%s\n", len(lines), strings.Join(lines, "\n"))
+		}
+		return exitFindings
+	}
+
+	if _, err := stdout.Write(manifests); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	return exitOK
+}
+
+// readGateInput reads the manifests in paths, descending into directories, and returns
+// them as one multi-document stream along with a source over the same documents.
+func readGateInput(paths []string, exclude string) ([]byte, k8sconstraints.Source, error) {
+	var manifests bytes.Buffer
+	sources := make([]k8sconstraints.Source, 0)
+	discoverer := linter.New(linter.Options{Recursive: true, Exclude: append(splitList(exclude), linter.DefaultExclude...)})
+	add := func(name string, data []byte) {
+		if manifests.Len() > 0 {
+			manifests.WriteString("---\n")
+		}
+		manifests.Write(data)
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			manifests.WriteByte('\n')
+		}
+		sources = append(sources, k8sconstraints.NewReaderSource(name, bytes.NewReader(data)))
+	}
+
+	for _, path := range paths {
+		if path == linter.StdinPath {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return nil, nil, err
+			}
+			add("<stdin>", data)
+			continue
+		}
+		files, err := discoverer.Discover(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, nil, err
+			}
+			add(file, data)
+		}
+	}
+	return manifests.Bytes(), k8sconstraints.MultiSource(sources...), nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+	"github.com/martinflemingdev/k8s_constraints/linter"
+	"github.com/martinflemingdev/k8s_constraints/source"
+)
+
+const helmUsage = "usage: k8sconstraints helm CHART [-f VALUES]... [--set KEY=VALUE]... [flags]"
+
+// stringsFlag is a flag that may be repeated, collecting its values in order.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// runHelm renders a chart as `helm template` does and validates the rendered manifests,
+// reporting findings against the template that rendered each object. It exits with
+// exitUsage if the chart cannot be rendered, and with exitFindings if a finding at or
+// above the --fail-on severity was reported.
+func runHelm(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("helm", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var valuesFiles, set stringsFlag
+	flags.Var(&valuesFiles, "f", "values file to render the chart with, as with helm template; may be repeated")
+	flags.Var(&valuesFiles, "values", "values file to render the chart with, as with helm template; may be repeated")
+	flags.Var(&set, "set", "KEY=VALUE override to render the chart with, as with helm template; may be repeated")
+	release := flags.String("release-name", "release", "release name the chart is rendered as")
+	namespace := flags.String("namespace", "", "release namespace the chart is rendered for")
+	version := flags.String("version", "", "chart version, for repository and oci:// charts")
+	output := flags.String("output", k8sconstraints.FormatText, "output format: text, json, sarif, or junit")
+	verbose := flags.Bool("verbose", false, "report every finding instead of collapsing findings with a shared root cause")
+	timeout := flags.Duration("timeout", 10*time.Second, "time limit for validating a single document (0 for none)")
+	profile := flags.String("constraint-profile", "", "pin the built-in rules and their limits to those of an earlier k8sconstraints release, e.g. 0.1")
+	nodePortRange := flags.String("node-port-range", "", "the API server's --service-node-port-range Service node ports must lie within, e.g. 30000-32767, overriding the configuration file's nodePortRange")
+	configPath := flags.String("config", "", "configuration file (default: .k8sconstraints.yaml in the working directory or a parent; none to disable)")
+	severities := flags.String("severity", "", "comma-separated RULE=SEVERITY overrides, e.g. DeprecatedAPI=warning; severities are error, warning, and info")
+	failOn := flags.String("fail-on", string(k8sconstraints.SeverityWarning), "least severity of the findings that fail the run: error, warning, or info")
+	kubernetesVersion := flags.String("kubernetes-version", "", "validate against the given Kubernetes release, e.g. 1.29: report deprecated and removed apiVersions and fields it does not serve")
+	offlineBundle := flags.String("offline-bundle", "", "also validate against the CRD schemas and policies of an offline bundle built with the bundle command")
+	flags.StringVar(offlineBundle, "schema-bundle", "", "alias of --offline-bundle")
+	crdDir := flags.String("crd-dir", "", "also validate custom resources against the CustomResourceDefinitions in DIR, in addition to those among the manifests")
+	openAPI := flags.String("openapi", "", "also validate built-in types against OpenAPI schemas, reporting unknown fields, wrong types, and missing required fields: builtin, or an OpenAPI document such as swagger.json")
+	againstCluster := flags.Bool("against-cluster", false, "also submit every object to the cluster of the current kubeconfig context with a server-side dry run, reporting the API server's rejections")
+	clusterDiscovery := flags.Bool("cluster-discovery", false, "fetch the installed CRDs and served types of the cluster of the current kubeconfig context: validate custom resources against the installed CRDs, and report types the cluster does not serve")
+	showSensitive := flags.Bool("show-sensitive-values", false, "print Secret data, environment variable, and annotation values in findings instead of redacting them")
+
+	// Accept flags after the chart too, as helm does: helm ./chart -f values.yaml
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(stderr, helmUsage)
+		return exitUsage
+	}
+	chart := flags.Arg(0)
+	if err := flags.Parse(flags.Args()[1:]); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 0 {
+		fmt.Fprintln(stderr, helmUsage)
+		return exitUsage
+	}
+
+	if err := applyConstraintProfile(*profile); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	bundle, err := loadOfflineBundle(*offlineBundle)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	crds, err := loadCRDDir(*crdDir)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	opts := linter.Options{Verbose: *verbose, DocumentTimeout: *timeout, ShowSensitiveValues: *showSensitive, Bundle: bundle, CRDs: crds}
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	opts.Config = config
+	if err := applyNodePortRange(*nodePortRange, config); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	overrides, err := k8sconstraints.ParseSeverityOverrides(*severities)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid --severity: %v\n", err)
+		return exitUsage
+	}
+	opts.SeverityOverrides = overrides
+	failSeverity, err := k8sconstraints.ParseSeverity(*failOn)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid --fail-on: %v\n", err)
+		return exitUsage
+	}
+	ctx := context.Background()
+	if *clusterDiscovery {
+		if opts.CRDs, opts.ServedKinds, err = loadClusterDiscovery(ctx, opts.CRDs, stderr); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+	if *againstCluster {
+		if err := source.CheckClusterAccess(ctx, ""); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		opts.Checks = append(opts.Checks, source.ServerDryRunCheck(ctx, source.DryRunOptions{}))
+	}
+	if *openAPI != "" {
+		check, err := loadOpenAPISchemaCheck(*openAPI)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		opts.Checks = append(opts.Checks, check)
+	}
+	if *kubernetesVersion != "" {
+		if err := k8sconstraints.ValidateKubernetesVersion(*kubernetesVersion); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		opts.Checks = append(opts.Checks, k8sconstraints.KubernetesVersionChecks(*kubernetesVersion)...)
+	}
+
+	rendered := source.Helm(ctx, chart, source.HelmOptions{
+		ReleaseName: *release,
+		Namespace:   *namespace,
+		Version:     *version,
+		ValuesFiles: valuesFiles,
+		Set:         set,
+	})
+	report, err := linter.New(opts).LintSource(ctx, rendered)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if err := k8sconstraints.WriteReport(stdout, report, *output); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	if report.HasFindingsAtLeast(failSeverity) {
+		return exitFindings
+	}
+	return exitOK
+}
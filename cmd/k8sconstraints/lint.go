@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+	"github.com/martinflemingdev/k8s_constraints/linter"
+	"github.com/martinflemingdev/k8s_constraints/source"
+)
+
+// runLint validates the files, globs, and directories named in args and prints the
+// findings grouped per file. It exits with exitFindings if a finding at or above the
+// --fail-on severity was reported.
+func runLint(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("k8sconstraints", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("output", k8sconstraints.FormatText, "output format: text, json, sarif, or junit")
+	recursive := flags.Bool("recursive", false, "descend into subdirectories of directory arguments")
+	include := flags.String("include", strings.Join(linter.DefaultInclude, ","), "comma-separated globs selecting files in directories")
+	exclude := flags.String("exclude", "", "comma-separated globs skipping files and directories, e.g. 'charts/**'; version control directories and configuration files are always skipped")
+	verbose := flags.Bool("verbose", false, "report every finding instead of collapsing findings with a shared root cause")
+	score := flags.Bool("score", false, "grade every resource and the whole bundle from A to F")
+	timeout := flags.Duration("timeout", 10*time.Second, "time limit for validating a single document (0 for none)")
+	stream := flags.Bool("stream", false, "decode files one document or List item at a time instead of reading them whole, for very large exports")
+	profile := flags.String("constraint-profile", "", "pin the built-in rules and their limits to those of an earlier k8sconstraints release, e.g. 0.1")
+	warnReserved := flags.Bool("warn-reserved-namespaces", false, "warn about objects deployed to default, kube-system, kube-public, or kube-node-lease")
+	warnPrefixes := flags.Bool("warn-reserved-prefixes", false, "warn about labels and annotations under the kubernetes.io/ and k8s.io/ prefixes")
+	warnImageTags := flags.Bool("warn-image-tags", false, "warn about latest and untagged images, and imagePullPolicy values that do not suit the image's tag or digest")
+	nodePortRange := flags.String("node-port-range", "", "the API server's --service-node-port-range Service node ports must lie within, e.g. 30000-32767, overriding the configuration file's nodePortRange")
+	configPath := flags.String("config", "", "configuration file (default: .k8sconstraints.yaml in the working directory or a parent; none to disable)")
+	severities := flags.String("severity", "", "comma-separated RULE=SEVERITY overrides, e.g. DeprecatedAPI=warning; severities are error, warning, and info")
+	failOn := flags.String("fail-on", string(k8sconstraints.SeverityWarning), "least severity of the findings that fail the run: error, warning, or info")
+	kubernetesVersion := flags.String("kubernetes-version", "", "validate against the given Kubernetes release, e.g. 1.29: report deprecated and removed apiVersions and fields it does not serve")
+	warnRecommended := flags.Bool("warn-recommended-labels", false, "warn about workloads missing the recommended app.kubernetes.io labels")
+	recommendedLabels := flags.String("recommended-labels", "", "comma-separated label keys --warn-recommended-labels expects instead of the app.kubernetes.io ones")
+	podSecurity := flags.String("pod-security", "", "hold pod specs to a Pod Security Standards profile: baseline or restricted, overriding the configuration file's podSecurity")
+	allowReserved := flags.String("allow-reserved-keys", "", "comma-separated reserved label and annotation keys, or prefixes ending in '/', that --warn-reserved-prefixes accepts")
+	offlineBundle := flags.String("offline-bundle", "", "also validate against the CRD schemas and policies of an offline bundle built with the bundle command")
+	flags.StringVar(offlineBundle, "schema-bundle", "", "alias of --offline-bundle")
+	crdDir := flags.String("crd-dir", "", "also validate custom resources against the CustomResourceDefinitions in DIR, in addition to those among the manifests")
+	openAPI := flags.String("openapi", "", "also validate built-in types against OpenAPI schemas, reporting unknown fields, wrong types, and missing required fields: builtin, or an OpenAPI document such as swagger.json")
+	againstCluster := flags.Bool("against-cluster", false, "also submit every object to the cluster of the current kubeconfig context with a server-side dry run, reporting the API server's rejections")
+	clusterDiscovery := flags.Bool("cluster-discovery", false, "fetch the installed CRDs and served types of the cluster of the current kubeconfig context: validate custom resources against the installed CRDs, and report types the cluster does not serve")
+	showSensitive := flags.Bool("show-sensitive-values", false, "print Secret data, environment variable, and annotation values in findings instead of redacting them")
+	trendStore := flags.String("trend-store", "", "append a summary of this run to the trend store file")
+	trendKey := flags.String("trend-key", "default", "repository or cluster name the run is recorded under")
+	resultsDir := flags.String("results-dir", "", "also write status, findings, errors, warnings, and documents as result files to DIR, e.g. /tekton/results")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	paths, err := expandGlobs(flags.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	if err := applyConstraintProfile(*profile); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	bundle, err := loadOfflineBundle(*offlineBundle)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	crds, err := loadCRDDir(*crdDir)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	opts := linter.Options{
+		Recursive:           *recursive,
+		Include:             splitList(*include),
+		Exclude:             append(splitList(*exclude), linter.DefaultExclude...),
+		Verbose:             *verbose,
+		DocumentTimeout:     *timeout,
+		ShowSensitiveValues: *showSensitive,
+		Bundle:              bundle,
+		CRDs:                crds,
+		Stream:              *stream,
+	}
+	if *warnReserved {
+		opts.Checks = append(opts.Checks, k8sconstraints.ReservedNamespacesCheck)
+	}
+	if *warnPrefixes {
+		opts.Checks = append(opts.Checks, k8sconstraints.ReservedPrefixesCheck(splitList(*allowReserved)))
+	}
+	if *warnImageTags {
+		opts.Checks = append(opts.Checks, k8sconstraints.ImageTagsCheck)
+	}
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	opts.Config = config
+	if err := applyNodePortRange(*nodePortRange, config); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	overrides, err := k8sconstraints.ParseSeverityOverrides(*severities)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid --severity: %v\n", err)
+		return exitUsage
+	}
+	opts.SeverityOverrides = overrides
+	failSeverity, err := k8sconstraints.ParseSeverity(*failOn)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid --fail-on: %v\n", err)
+		return exitUsage
+	}
+	if *clusterDiscovery {
+		if opts.CRDs, opts.ServedKinds, err = loadClusterDiscovery(context.Background(), opts.CRDs, stderr); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+	if *againstCluster {
+		if err := source.CheckClusterAccess(context.Background(), ""); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		opts.Checks = append(opts.Checks, source.ServerDryRunCheck(context.Background(), source.DryRunOptions{}))
+	}
+	if *openAPI != "" {
+		check, err := loadOpenAPISchemaCheck(*openAPI)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		opts.Checks = append(opts.Checks, check)
+	}
+	if *kubernetesVersion != "" {
+		if err := k8sconstraints.ValidateKubernetesVersion(*kubernetesVersion); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		opts.Checks = append(opts.Checks, k8sconstraints.KubernetesVersionChecks(*kubernetesVersion)...)
+	}
+	if *warnRecommended {
+		config := k8sconstraints.RecommendedLabelsConfig{Labels: splitList(*recommendedLabels)}
+		if err := config.Validate(); err != nil {
+			fmt.Fprintf(stderr, "invalid --recommended-labels: %v\n", err)
+			return exitUsage
+		}
+		opts.Checks = append(opts.Checks, k8sconstraints.RecommendedLabelsCheck(config))
+	}
+	if *podSecurity != "" {
+		config := k8sconstraints.PodSecurityConfig{Level: *podSecurity}
+		if err := config.Validate(); err != nil {
+			fmt.Fprintf(stderr, "invalid --pod-security: %v\n", err)
+			return exitUsage
+		}
+		opts.Checks = append(opts.Checks, k8sconstraints.PodSecurityCheck(config))
+	}
+	if *score {
+		opts.Scoring = &k8sconstraints.ScoringOptions{}
+	}
+
+	report, err := linter.New(opts).LintPaths(context.Background(), paths)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if err := k8sconstraints.WriteReport(stdout, report, *output); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if *resultsDir != "" {
+		if err := k8sconstraints.WriteResultFiles(*resultsDir, report); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+	if *trendStore != "" {
+		store := k8sconstraints.FileTrendStore{Path: *trendStore}
+		summary := k8sconstraints.SummarizeReport(*trendKey, time.Now(), report)
+		if err := store.Record(context.Background(), summary); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+
+	if report.HasFindingsAtLeast(failSeverity) {
+		return exitFindings
+	}
+	return exitOK
+}
+
+// expandGlobs expands arguments containing glob metacharacters. A pattern that matches
+// nothing is an error, so typos do not silently pass CI.
+func expandGlobs(args []string) ([]string, error) {
+	paths := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !strings.ContainsAny(arg, "*?[") {
+			paths = append(paths, arg)
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s': %v", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("pattern '%s' matched no files", arg)
+		}
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
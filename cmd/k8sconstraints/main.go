@@ -0,0 +1,179 @@
+// Command k8sconstraints validates Kubernetes manifests against the k8sconstraints rules.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Exit codes. exitUsage also covers I/O failures, so CI can tell broken invocations apart
+// from manifests with findings.
+const (
+	exitOK       = 0
+	exitFindings = 1
+	exitUsage    = 2
+)
+
+const usage = `usage: k8sconstraints [flags] PATH...
+       k8sconstraints <command> [flags] [args]
+
+Validates the manifests in the given files, globs, and directories and prints the
+findings grouped per file. Use - to read YAML or JSON from standard input, e.g.
+kubectl get deploy foo -o yaml | k8sconstraints -. Exits 1 if any error or warning was reported.
+
+flags:
+  --output FORMAT       output format: text, json, sarif, or junit
+  --recursive           descend into subdirectories of directory arguments
+  --include GLOBS       comma-separated globs selecting files in directories (default *.yaml,*.yml,*.json);
+                        add *.md and *.tf to lint manifests embedded in Markdown code fences and
+                        Terraform kubernetes_manifest resources
+  --exclude GLOBS       comma-separated globs skipping files and directories, e.g. 'charts/**'; .git, .svn, .hg, and .k8sconstraints.yaml are always skipped
+  --verbose             report every finding instead of collapsing findings with a shared root cause
+  --score               grade every resource and the whole bundle from A to F
+  --timeout DURATION    time limit for validating a single document (default 10s, 0 for none)
+  --stream              decode YAML and JSON files one document, or one List item, at a time instead of
+                        reading them whole, for multi-hundred-MB exports such as kubectl get -A -o yaml;
+                        custom resources are only checked against CRDs that come before them, and checks
+                        across objects, such as HPA scale targets, are skipped
+  --show-sensitive-values
+                        print Secret data, environment variable, and annotation values in findings;
+                        they are redacted to their length by default
+  --results-dir DIR     also write status, findings, errors, warnings, and documents (and score and
+                        grade with --score) as result files to DIR, for Tekton results or Argo outputs
+  --warn-reserved-namespaces
+                        warn about objects deployed to default, kube-system, kube-public, or
+                        kube-node-lease (also accepted by argocd and flux)
+  --warn-reserved-prefixes
+                        warn about labels and annotations under the kubernetes.io/ and k8s.io/
+                        prefixes, which Kubernetes reserves, other than well-known user-set keys
+                        (also accepted by argocd and flux)
+  --warn-image-tags     warn about untagged and latest images (ImageLatestTag), digest-pinned images
+                        pulled Always (ImagePullPolicyDigest), and mutably tagged images pulled
+                        IfNotPresent (ImagePullPolicyMutableTag); set their severities with
+                        --severity (also accepted by argocd and flux)
+  --allow-reserved-keys KEYS
+                        comma-separated further reserved keys, or prefixes ending in '/', to accept
+  --config FILE         configuration file enabling checks, disabling rules, setting severities,
+                        label and annotation policies, allowed registries, and per-path
+                        overrides; by default .k8sconstraints.yaml is looked up from the working
+                        directory upwards (none disables it; also accepted by argocd and flux)
+  --severity OVERRIDES  comma-separated RULE=SEVERITY pairs changing the severity of a rule's findings
+                        to error, warning, or info, e.g. DeprecatedAPI=info to roll a rule out
+                        without failing runs (also accepted by argocd and flux)
+  --fail-on SEVERITY    least severity that fails the run: error, warning (default), or info
+                        (also accepted by argocd and flux)
+  --kubernetes-version VERSION
+                        target Kubernetes release, e.g. 1.29: apiVersions it no longer serves are
+                        errors and deprecated ones warnings, and pod spec fields it does not serve
+                        yet, such as sidecar init containers before 1.29, are errors (also
+                        accepted by argocd and flux)
+  --warn-recommended-labels
+                        warn about workloads missing the recommended labels app.kubernetes.io/name,
+                        instance, version, component, part-of, and managed-by (also accepted by
+                        argocd and flux)
+  --recommended-labels KEYS
+                        comma-separated label keys to expect instead of the recommended ones
+  --pod-security PROFILE
+                        hold pod specs to the baseline or restricted Pod Security Standards
+                        profile, naming the control each violation falls under; overrides the
+                        configuration file's podSecurity (also accepted by argocd and flux)
+  --node-port-range MIN-MAX
+                        the --service-node-port-range of the target API server, which Service
+                        node ports must lie within, e.g. 30000-32767 (the default); overrides the
+                        configuration file's nodePortRange (also accepted by argocd, flux, and
+                        helm)
+  --constraint-profile VERSION
+                        pin the built-in rules and their limits to those of an earlier release,
+                        e.g. 0.1, so upgrading does not fail pipelines on new or tightened rules
+                        (also accepted by scan, argocd, flux, and helm)
+  --offline-bundle FILE also validate against the CRD schemas and label schema of an offline bundle,
+                        and the served types, schemas, and API deprecations of the cluster it was
+                        built from; --schema-bundle is an alias
+                        (also accepted by scan, argocd, and flux)
+  --against-cluster     also submit every object to the cluster of the current kubeconfig context as a
+                        server-side apply with dryRun=All and report the API server's
+                        rejections and warnings, which include admission webhooks; objects are
+                        submitted one at a time, so they cannot depend on each other (also
+                        accepted by helm)
+  --cluster-discovery   fetch the installed CRDs and the OpenAPI document of the cluster of the current
+                        kubeconfig context: validate custom resources against the installed CRDs,
+                        and report apiVersions and kinds the cluster does not serve (also accepted
+                        by helm)
+  --crd-dir DIR         also validate custom resources, including their x-kubernetes-validations
+                        rules, against the CustomResourceDefinitions in DIR; CRDs among the
+                        manifests are always used (also accepted by scan, argocd, flux, and helm)
+  --openapi SCHEMAS     also validate built-in types against OpenAPI schemas, reporting unknown fields,
+                        wrong types, and missing required fields like the API server: builtin for the
+                        schemas of Kubernetes 1.36, or an OpenAPI document such as the swagger.json of
+                        another release (also accepted by argocd, flux, and helm)
+  --trend-store FILE    append a summary of this run to the trend store FILE
+  --trend-key KEY       repository or cluster name the run is recorded under (default "default")
+
+commands:
+  argocd [PATH...]      Argo CD ConfigManagementPlugin generate command: validates the manifests
+                        in PATH (default .) and prints them unchanged if valid, failing the sync otherwise
+  flux [PATH...]        validates rendered manifests (default stdin, e.g. from flux build kustomization)
+                        and prints them unchanged if valid, with a one-line error for Flux otherwise
+  helm CHART [-f VALUES]... [--set KEY=VALUE]...
+                        render a chart as helm template does, without the helm binary, and validate the
+                        manifests, reporting findings against the template file and line that
+                        rendered them; also accepts --release-name, --namespace, and --version
+  scan                  audit live clusters; --context takes comma-separated contexts or
+                        globs such as 'prod-*', scanned concurrently with a per-cluster breakdown;
+                        --tenant-reports DIR writes a PolicyReport, ConfigMap, or Secret per namespace
+  fmt [-w] [-l] [PATH...]
+                        validate YAML manifests and print them in canonical form: apiVersion, kind,
+                        and metadata first, sorted keys, two-space indentation (-w rewrites files)
+  bundle [-o FILE] [--label-schema FILE] [--from-cluster] [PATH...]
+                        package the CRDs in PATH, a label schema, and this build's kind registry,
+                        deprecation, and rule tables into an offline bundle for air-gapped use;
+                        --from-cluster adds the installed CRDs, release, and served OpenAPI
+                        schemas of the current kubeconfig context's cluster, so CI can check
+                        manifests against that cluster offline
+  gatekeeper [--config FILE]
+                        print OPA Gatekeeper ConstraintTemplates and Constraints enforcing the
+                        metadata.name formats and the configuration's requiredLabels and
+                        allowedRegistries at admission
+  schema [-o FILE]      print a JSON Schema of the syntax rules (name formats and lengths, label keys
+                        and values, port names, enumerated fields) for editors such as the YAML
+                        language server
+  report diff OLD NEW   compare two result files and report new, fixed, and persisting findings
+  report trend STORE    show finding counts per rule across the last runs in a trend store
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run dispatches to a subcommand and returns the process exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "report":
+		return runReport(args[1:], stdout, stderr)
+	case "scan":
+		return runScan(args[1:], stdout, stderr)
+	case "helm":
+		return runHelm(args[1:], stdout, stderr)
+	case "fmt":
+		return runFmt(args[1:], stdout, stderr)
+	case "bundle":
+		return runBundle(args[1:], stdout, stderr)
+	case "gatekeeper":
+		return runGatekeeper(args[1:], stdout, stderr)
+	case "schema":
+		return runSchema(args[1:], stdout, stderr)
+	case gateModeArgoCD, gateModeFlux:
+		return runGate(args[0], args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	}
+
+	return runLint(args, stdout, stderr)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTestFiles writes files, keyed by name, to a new temporary directory and returns it.
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRunExitCodes(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"valid.yaml":   "apiVersion: v1\nkind: ConfigMap\nmetadata: {name: app, namespace: web}\ndata: {key: value}\n",
+		"invalid.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata: {name: Bad_Name, namespace: web}\n",
+		"default.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata: {name: app, namespace: default}\n",
+	})
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"no arguments", nil, exitUsage},
+		{"help", []string{"help"}, exitOK},
+		{"unknown flag", []string{"--no-such-flag", dir}, exitUsage},
+		{"valid file", []string{"--config", "none", filepath.Join(dir, "valid.yaml")}, exitOK},
+		{"invalid file", []string{"--config", "none", filepath.Join(dir, "invalid.yaml")}, exitFindings},
+		{"directory", []string{"--config", "none", dir}, exitFindings},
+		{"glob", []string{"--config", "none", filepath.Join(dir, "val*.yaml")}, exitOK},
+		{"glob matching nothing", []string{"--config", "none", filepath.Join(dir, "*.json")}, exitUsage},
+		{"missing file", []string{"--config", "none", filepath.Join(dir, "missing.yaml")}, exitUsage},
+		{"warning", []string{"--config", "none", "--warn-reserved-namespaces", filepath.Join(dir, "default.yaml")}, exitFindings},
+		{"warning below fail-on", []string{"--config", "none", "--warn-reserved-namespaces", "--fail-on", "error", filepath.Join(dir, "default.yaml")}, exitOK},
+		{"invalid fail-on", []string{"--config", "none", "--fail-on", "fatal", filepath.Join(dir, "valid.yaml")}, exitUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if got := run(tt.args, &stdout, &stderr); got != tt.want {
+				t.Errorf("expected exit code %d, got %d; stderr: %s", tt.want, got, stderr.String())
+			}
+		})
+	}
+}
+
+func TestExpandGlobs(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"a.yaml": "", "b.yaml": "", "c.json": ""})
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{"plain paths are kept", []string{"missing.yaml", "-"}, []string{"missing.yaml", "-"}, false},
+		{"star", []string{filepath.Join(dir, "*.yaml")}, []string{filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.yaml")}, false},
+		{"character class", []string{filepath.Join(dir, "[ac].*")}, []string{filepath.Join(dir, "a.yaml"), filepath.Join(dir, "c.json")}, false},
+		{"no match", []string{filepath.Join(dir, "*.yml")}, nil, true},
+		{"invalid pattern", []string{filepath.Join(dir, "[")}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandGlobs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+)
+
+// runReport implements the "report" subcommands.
+func runReport(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "diff":
+			return runReportDiff(args[1:], stdout, stderr)
+		case "trend":
+			return runReportTrend(args[1:], stdout, stderr)
+		}
+	}
+	fmt.Fprintln(stderr, "usage: k8sconstraints report diff [--output=text|json] OLD NEW")
+	fmt.Fprintln(stderr, "       k8sconstraints report trend [--key=KEY] [--last=N] [--output=text|json] STORE")
+	return exitUsage
+}
+
+// runReportDiff compares two result files. It exits with exitFindings when the newer run
+// introduced findings, so it can gate pull requests on new violations only.
+func runReportDiff(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("report diff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("output", "text", "output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 2 {
+		fmt.Fprintln(stderr, "usage: k8sconstraints report diff [--output=text|json] OLD NEW")
+		return exitUsage
+	}
+
+	oldFindings, err := k8sconstraints.LoadFindings(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	newFindings, err := k8sconstraints.LoadFindings(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	diff := k8sconstraints.DiffFindings(oldFindings, newFindings)
+	switch *output {
+	case "json":
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(diff); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	case "text":
+		writeDiffSection(stdout, "new", diff.New)
+		writeDiffSection(stdout, "fixed", diff.Fixed)
+		fmt.Fprintf(stdout, "%d new, %d fixed, %d persisting\n", len(diff.New), len(diff.Fixed), len(diff.Persisting))
+	default:
+		fmt.Fprintf(stderr, "unsupported output format '%s'; must be one of: text, json\n", *output)
+		return exitUsage
+	}
+
+	if len(diff.New) > 0 {
+		return exitFindings
+	}
+	return exitOK
+}
+
+// writeDiffSection prints one line per finding, prefixed with the section name.
+func writeDiffSection(w io.Writer, section string, findings []k8sconstraints.Finding) {
+	for _, finding := range findings {
+		fmt.Fprintf(w, "%s: %s\n", section, k8sconstraints.FormatFinding(finding))
+	}
+}
+
+// runReportTrend renders the violation counts per rule across the last runs recorded in a
+// trend store with lint --trend-store.
+func runReportTrend(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("report trend", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	key := flags.String("key", "default", "repository or cluster name the runs were recorded under")
+	last := flags.Int("last", 10, "number of most recent runs to show")
+	output := flags.String("output", "text", "output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 || *last < 1 {
+		fmt.Fprintln(stderr, "usage: k8sconstraints report trend [--key=KEY] [--last=N] [--output=text|json] STORE")
+		return exitUsage
+	}
+
+	store := k8sconstraints.FileTrendStore{Path: flags.Arg(0)}
+	runs, err := store.Recent(context.Background(), *key, *last)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	switch *output {
+	case "json":
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		err = encoder.Encode(runs)
+	case "text":
+		err = k8sconstraints.WriteTrend(stdout, runs)
+	default:
+		fmt.Fprintf(stderr, "unsupported output format '%s'; must be one of: text, json\n", *output)
+		return exitUsage
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	return exitOK
+}
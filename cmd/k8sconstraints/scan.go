@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+	"github.com/martinflemingdev/k8s_constraints/linter"
+	"github.com/martinflemingdev/k8s_constraints/source"
+	"gopkg.in/yaml.v3"
+)
+
+// currentContextTarget names the target scanned when no context is given.
+const currentContextTarget = "current-context"
+
+// runScan audits live clusters, one kubeconfig context per target, and prints the merged
+// findings with a per-cluster breakdown. It exits with exitUsage if any cluster could not
+// be read, and otherwise with exitFindings if anything was reported.
+func runScan(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("scan", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	contexts := flags.String("context", "", "comma-separated kubeconfig contexts or context globs, e.g. 'prod-*'; defaults to the current context")
+	resources := flags.String("resources", "all", "comma-separated resource types to read")
+	namespace := flags.String("namespace", "", "only read objects in this namespace; every namespace is read otherwise")
+	output := flags.String("output", k8sconstraints.FormatText, "output format: text, json, sarif, or junit")
+	verbose := flags.Bool("verbose", false, "report every finding instead of collapsing findings with a shared root cause")
+	score := flags.Bool("score", false, "grade every resource, cluster, and the whole fleet from A to F")
+	concurrency := flags.Int("concurrency", linter.DefaultConcurrency, "number of clusters scanned at once")
+	timeout := flags.Duration("timeout", 10*time.Second, "time limit for validating a single document (0 for none)")
+	profile := flags.String("constraint-profile", "", "pin the built-in rules and their limits to those of an earlier k8sconstraints release, e.g. 0.1")
+	offlineBundle := flags.String("offline-bundle", "", "also validate against the CRD schemas and policies of an offline bundle built with the bundle command")
+	flags.StringVar(offlineBundle, "schema-bundle", "", "alias of --offline-bundle")
+	crdDir := flags.String("crd-dir", "", "also validate custom resources against the CustomResourceDefinitions in DIR, in addition to those among the manifests")
+	showSensitive := flags.Bool("show-sensitive-values", false, "print Secret data, environment variable, and annotation values in findings instead of redacting them")
+	resultsDir := flags.String("results-dir", "", "also write status, findings, errors, warnings, and documents as result files to DIR, e.g. /tekton/results")
+	tenantReports := flags.String("tenant-reports", "", "write one report object per cluster and namespace to DIR/CLUSTER/NAMESPACE.yaml")
+	tenantReportKind := flags.String("tenant-report-kind", k8sconstraints.TenantReportPolicyReport, "kind of the tenant report objects: PolicyReport, ConfigMap, or Secret")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 0 {
+		fmt.Fprintln(stderr, "usage: k8sconstraints scan [--context=CTX,...] [--resources=TYPES] [--namespace=NS] [flags]")
+		return exitUsage
+	}
+
+	ctx := context.Background()
+	names := []string{""}
+	if patterns := splitList(*contexts); len(patterns) > 0 {
+		var err error
+		if names, err = resolveContexts(ctx, patterns); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+
+	targets := make([]linter.Target, 0, len(names))
+	for _, name := range names {
+		opts := source.ClusterOptions{
+			Context:       name,
+			Namespace:     *namespace,
+			AllNamespaces: *namespace == "",
+			Resources:     splitList(*resources),
+		}
+		targetName := name
+		if targetName == "" {
+			targetName = currentContextTarget
+		}
+		targets = append(targets, linter.Target{Name: targetName, Source: source.Cluster(ctx, opts)})
+	}
+
+	if err := applyConstraintProfile(*profile); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	bundle, err := loadOfflineBundle(*offlineBundle)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	crds, err := loadCRDDir(*crdDir)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	opts := linter.Options{Verbose: *verbose, DocumentTimeout: *timeout, Concurrency: *concurrency, ShowSensitiveValues: *showSensitive, Bundle: bundle, CRDs: crds}
+	if *score {
+		opts.Scoring = &k8sconstraints.ScoringOptions{}
+	}
+	report, err := linter.New(opts).LintTargets(ctx, targets)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if err := k8sconstraints.WriteReport(stdout, report, *output); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if *resultsDir != "" {
+		if err := k8sconstraints.WriteResultFiles(*resultsDir, report); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+	if *tenantReports != "" {
+		if err := writeTenantReports(*tenantReports, *tenantReportKind, report); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+
+	for _, target := range report.Targets {
+		if target.Error != "" {
+			fmt.Fprintf(stderr, "%s: %s\n", target.Name, target.Error)
+			return exitUsage
+		}
+	}
+	if report.HasFindings() {
+		return exitFindings
+	}
+	return exitOK
+}
+
+// resolveContexts expands context patterns containing glob metacharacters against the
+// kubeconfig, keeping plain names as given. Duplicates are dropped.
+func resolveContexts(ctx context.Context, patterns []string) ([]string, error) {
+	names := make([]string, 0, len(patterns))
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			var err error
+			if matches, err = source.Contexts(ctx, pattern); err != nil {
+				return nil, err
+			}
+		}
+		for _, name := range matches {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names, nil
+}
+
+// writeTenantReports partitions the findings of every scanned cluster by namespace and
+// writes each namespace's report object to dir/cluster/namespace.yaml, ready for
+// `kubectl apply --context cluster -f dir/cluster`. Cluster-scoped resources belong to no
+// tenant and are left out.
+func writeTenantReports(dir, kind string, report k8sconstraints.Report) error {
+	byTarget := make(map[string]k8sconstraints.Report)
+	for _, resource := range report.Resources {
+		target := byTarget[resource.File]
+		target.Resources = append(target.Resources, resource)
+		byTarget[resource.File] = target
+	}
+	for _, finding := range report.Findings {
+		target := byTarget[finding.File]
+		target.Findings = append(target.Findings, finding)
+		byTarget[finding.File] = target
+	}
+
+	for _, summary := range report.Targets {
+		targetDir := filepath.Join(dir, summary.Name)
+		if err := os.MkdirAll(targetDir, 0o755); err != nil {
+			return err
+		}
+		for namespace, partition := range k8sconstraints.PartitionByNamespace(byTarget[summary.Name]) {
+			if namespace == "" {
+				continue
+			}
+			obj, err := k8sconstraints.TenantReportObject(kind, namespace, partition)
+			if err != nil {
+				return err
+			}
+			var data bytes.Buffer
+			encoder := yaml.NewEncoder(&data)
+			encoder.SetIndent(2)
+			if err := encoder.Encode(obj); err != nil {
+				return err
+			}
+			if err := os.WriteFile(filepath.Join(targetDir, namespace+".yaml"), data.Bytes(), 0o644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+)
+
+// runSchema prints the JSON Schema encoding the validators' syntax rules, or writes it to
+// the -o file, for editors to validate manifests with as they are typed.
+func runSchema(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("schema", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	out := flags.String("o", "", "write the schema to FILE instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 0 {
+		fmt.Fprintln(stderr, "usage: k8sconstraints schema [-o FILE]")
+		return exitUsage
+	}
+
+	data, err := json.MarshalIndent(k8sconstraints.ManifestJSONSchema(), "", "  ")
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	data = append(data, '\n')
+	if *out == "" {
+		if _, err := stdout.Write(data); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		return exitOK
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	return exitOK
+}
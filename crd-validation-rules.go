@@ -0,0 +1,133 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+)
+
+// ValidationRule is an x-kubernetes-validations entry of a CRD schema: a CEL expression
+// over the value of the field it is declared on, bound to the variable self, that must
+// evaluate to true.
+type ValidationRule struct {
+	Rule              string `json:"rule"`
+	Message           string `json:"message,omitempty"`
+	MessageExpression string `json:"messageExpression,omitempty"`
+	// FieldPath is the path, relative to the field the rule is declared on, that failures
+	// are reported at, e.g. .spec.replicas.
+	FieldPath string `json:"fieldPath,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+
+	program        cel.Program
+	messageProgram cel.Program
+}
+
+var (
+	// validationRuleEnv declares the variables and libraries validation rules may use: the
+	// standard library and the extensions the API server enables. The Kubernetes-specific
+	// libraries, such as quantity() and isURL(), are not available.
+	validationRuleEnv     *cel.Env
+	validationRuleEnvErr  error
+	validationRuleEnvOnce sync.Once
+	// validationRulePrograms caches compiled rules by expression, as CRDs of several
+	// versions usually share them
+	validationRulePrograms sync.Map
+)
+
+// compileValidationRule compiles expression in the validation rule environment, returning
+// nil when it cannot be compiled there or refers to oldSelf.
+func compileValidationRule(expression string) cel.Program {
+	if program, ok := validationRulePrograms.Load(expression); ok {
+		program, _ := program.(cel.Program)
+		return program
+	}
+
+	validationRuleEnvOnce.Do(func() {
+		validationRuleEnv, validationRuleEnvErr = cel.NewEnv(
+			cel.Variable("self", cel.DynType),
+			cel.Variable("oldSelf", cel.DynType),
+			cel.OptionalTypes(),
+			ext.Strings(),
+			ext.Sets(),
+			ext.Lists(),
+		)
+	})
+	var program cel.Program
+	if validationRuleEnvErr == nil {
+		ast, issues := validationRuleEnv.Compile(expression)
+		if (issues == nil || issues.Err() == nil) && !referencesOldSelf(ast) {
+			program, _ = validationRuleEnv.Program(ast)
+		}
+	}
+	validationRulePrograms.Store(expression, program)
+	return program
+}
+
+// referencesOldSelf reports whether a compiled rule is a transition rule, which compares
+// an object to its previous version and so only applies to updates.
+func referencesOldSelf(ast *cel.Ast) bool {
+	for _, reference := range ast.NativeRep().ReferenceMap() {
+		if reference.Name == "oldSelf" {
+			return true
+		}
+	}
+	return false
+}
+
+// compileValidationRules compiles the x-kubernetes-validations of s. Rules the API server
+// accepts but that cannot be evaluated here, because they use a Kubernetes-specific
+// library or are transition rules, are skipped rather than failing the schema.
+func (s *OpenAPISchema) compileValidationRules() {
+	for i := range s.XValidations {
+		rule := &s.XValidations[i]
+		rule.program = compileValidationRule(rule.Rule)
+		if rule.MessageExpression != "" {
+			rule.messageProgram = compileValidationRule(rule.MessageExpression)
+		}
+	}
+}
+
+// validateRules evaluates the x-kubernetes-validations of s against value, found at path.
+func (s *OpenAPISchema) validateRules(value interface{}, path *Path) []error {
+	errs := make([]error, 0)
+	for _, rule := range s.XValidations {
+		if rule.program == nil {
+			continue
+		}
+
+		rulePath := path
+		if rule.FieldPath != "" {
+			rulePath = path.Child(strings.TrimPrefix(rule.FieldPath, "."))
+		}
+		out, _, err := rule.program.Eval(map[string]interface{}{"self": value})
+		if err != nil {
+			errs = append(errs, &ConstraintError{FieldPath: rulePath.String(), Message: fmt.Sprintf("rule '%s' could not be evaluated: %v", rule.Rule, err)})
+			continue
+		}
+		if passed, ok := out.(types.Bool); ok && bool(passed) {
+			continue
+		}
+		errs = append(errs, &ConstraintError{FieldPath: rulePath.String(), Message: rule.failureMessage(value)})
+	}
+	return errs
+}
+
+// failureMessage returns the message of a failed rule: the result of its message
+// expression, falling back to its message and then to the rule itself, as the API
+// server does.
+func (r ValidationRule) failureMessage(value interface{}) string {
+	if r.messageProgram != nil {
+		out, _, err := r.messageProgram.Eval(map[string]interface{}{"self": value})
+		if message, ok := out.(types.String); err == nil && ok && strings.TrimSpace(string(message)) != "" {
+			return string(message)
+		}
+	}
+	if r.Message != "" {
+		return r.Message
+	}
+	return fmt.Sprintf("failed rule: %s", r.Rule)
+}
@@ -0,0 +1,209 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CheckCRDSchema is the ID of the check returned by CRDSchemaCheck.
+const CheckCRDSchema = "CRDSchema"
+
+// CRDSchemas holds the compiled openAPIV3Schema of every CustomResourceDefinition version
+// found in a bundle, keyed by "group/version, Kind=kind".
+type CRDSchemas struct {
+	schemas map[string]*OpenAPISchema
+}
+
+// crdSchemaKey builds the lookup key for an apiVersion and kind.
+func crdSchemaKey(apiVersion string, kind string) string {
+	return apiVersion + ", Kind=" + kind
+}
+
+// builtinGroups are the API groups served by the Kubernetes API server itself.
+var builtinGroups = map[string]bool{
+	"": true, "admissionregistration.k8s.io": true, "apiextensions.k8s.io": true,
+	"apiregistration.k8s.io": true, "apps": true, "authentication.k8s.io": true,
+	"authorization.k8s.io": true, "autoscaling": true, "batch": true, "certificates.k8s.io": true,
+	"coordination.k8s.io": true, "discovery.k8s.io": true, "events.k8s.io": true, "extensions": true,
+	"flowcontrol.apiserver.k8s.io": true, "internal.apiserver.k8s.io": true, "networking.k8s.io": true,
+	"node.k8s.io": true, "policy": true, "rbac.authorization.k8s.io": true, "resource.k8s.io": true,
+	"scheduling.k8s.io": true, "storage.k8s.io": true, "storagemigration.k8s.io": true,
+}
+
+// IsBuiltinGroup reports whether group is served by the Kubernetes API server itself, so
+// that its types are not custom resources.
+func IsBuiltinGroup(group string) bool {
+	return builtinGroups[group]
+}
+
+// IsCustomResourceDefinition reports whether obj is a CustomResourceDefinition.
+func IsCustomResourceDefinition(obj map[string]interface{}) bool {
+	kind, _ := nestedString(obj, "kind")
+	apiVersion, _ := nestedString(obj, "apiVersion")
+	return kind == "CustomResourceDefinition" && strings.HasPrefix(apiVersion, "apiextensions.k8s.io/")
+}
+
+// CompileCRDSchemas compiles the schemas of every CRD among objects. Both apiextensions.k8s.io/v1
+// (per-version schemas) and v1beta1 (top-level spec.validation) CRDs are supported.
+func CompileCRDSchemas(objects []map[string]interface{}) (*CRDSchemas, error) {
+	set := &CRDSchemas{schemas: make(map[string]*OpenAPISchema)}
+
+	errs := make([]error, 0)
+	for _, obj := range objects {
+		if !IsCustomResourceDefinition(obj) {
+			continue
+		}
+		if err := set.Add(obj); err != nil {
+			name, _ := nestedString(obj, "metadata", "name")
+			errs = append(errs, fmt.Errorf("CustomResourceDefinition '%s': %v", name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return set, JoinErrors(errs)
+	}
+
+	return set, nil
+}
+
+// Add compiles the schemas of a single CRD into the set.
+func (c *CRDSchemas) Add(crd map[string]interface{}) error {
+	group, _ := nestedString(crd, "spec", "group")
+	kind, _ := nestedString(crd, "spec", "names", "kind")
+	if group == "" || kind == "" {
+		return errors.New("spec.group and spec.names.kind are required")
+	}
+
+	// v1beta1 CRDs may declare one schema shared by every version
+	var sharedSchema map[string]interface{}
+	if schema, ok := nestedMap(crd, "spec", "validation", "openAPIV3Schema"); ok {
+		sharedSchema = schema
+	}
+
+	versionNames := make([]string, 0)
+	versionSchemas := make(map[string]map[string]interface{})
+	versions, _ := nestedSlice(crd, "spec", "versions")
+	for _, raw := range versions {
+		version, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := nestedString(version, "name")
+		if name == "" {
+			continue
+		}
+		versionNames = append(versionNames, name)
+		if schema, ok := nestedMap(version, "schema", "openAPIV3Schema"); ok {
+			versionSchemas[name] = schema
+		}
+	}
+	if version, ok := nestedString(crd, "spec", "version"); ok && !containsString(versionNames, version) {
+		versionNames = append(versionNames, version)
+	}
+
+	errs := make([]error, 0)
+	for _, version := range versionNames {
+		raw, ok := versionSchemas[version]
+		if !ok {
+			raw = sharedSchema
+		}
+		if raw == nil {
+			continue
+		}
+
+		schema, err := CompileOpenAPISchema(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("version %s: %v", version, err))
+			continue
+		}
+		c.schemas[crdSchemaKey(group+"/"+version, kind)] = schema
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// Clone returns a copy of the set that schemas can be added to without changing c. The
+// clone of a nil set is empty.
+func (c *CRDSchemas) Clone() *CRDSchemas {
+	clone := &CRDSchemas{schemas: make(map[string]*OpenAPISchema)}
+	if c != nil {
+		for key, schema := range c.schemas {
+			clone.schemas[key] = schema
+		}
+	}
+	return clone
+}
+
+// SchemaFor returns the compiled schema for an apiVersion and kind, if the set has one.
+func (c *CRDSchemas) SchemaFor(apiVersion string, kind string) (*OpenAPISchema, bool) {
+	if c == nil {
+		return nil, false
+	}
+	schema, ok := c.schemas[crdSchemaKey(apiVersion, kind)]
+	return schema, ok
+}
+
+// Kinds returns the types the set holds schemas for, rendered like GroupVersionKind.String
+// and sorted.
+func (c *CRDSchemas) Kinds() []string {
+	if c == nil {
+		return nil
+	}
+	kinds := make([]string, 0, len(c.schemas))
+	for _, key := range sortedKeys(c.schemas) {
+		apiVersion, kind, _ := strings.Cut(key, ", Kind=")
+		kinds = append(kinds, apiVersion+" "+kind)
+	}
+	return kinds
+}
+
+// CRDSchemaCheck returns a check validating custom resources against the schemas of their
+// CRDs in schemas, including the CEL rules of x-kubernetes-validations, and reporting
+// CustomResourceDefinitions whose schemas do not compile. Objects of types without a
+// schema in the set pass, so schemas may grow while the check is in use, as CRDs are found
+// among the objects being linted. Run it with RunChecks or pass it to the linter's Checks
+// option.
+func CRDSchemaCheck(schemas *CRDSchemas) Check {
+	return Check{ID: CheckCRDSchema, Validate: func(obj map[string]interface{}) error {
+		if IsCustomResourceDefinition(obj) {
+			scratch := &CRDSchemas{schemas: make(map[string]*OpenAPISchema)}
+			if err := scratch.Add(obj); err != nil {
+				return &ConstraintError{FieldPath: "spec", Rule: CheckCRDSchema, Message: fmt.Sprintf("invalid schema: %v", err)}
+			}
+			return nil
+		}
+
+		gvk := GroupVersionKindOf(obj)
+		schema, ok := schemas.SchemaFor(gvk.APIVersion(), gvk.Kind)
+		if !ok {
+			return nil
+		}
+		// apiVersion, kind, and metadata are implicit in every custom resource schema
+		body := make(map[string]interface{}, len(obj))
+		for key, value := range obj {
+			if !isTypeMetaField(key) {
+				body[key] = value
+			}
+		}
+		if errs := schema.Validate(body); len(errs) > 0 {
+			return schemaViolations(CheckCRDSchema, errs)
+		}
+		return nil
+	}}
+}
+
+// ValidateBundleCustomResources compiles the CRDs found in objects and runs CRDSchemaCheck
+// against every object, so the custom resources of a bundle are validated against the CRDs
+// in it with zero configuration.
+func ValidateBundleCustomResources(objects []map[string]interface{}) error {
+	return RunBundleCheck(objects, func(objects []map[string]interface{}) Check {
+		// CRDs whose schemas do not compile are reported by the check
+		schemas, _ := CompileCRDSchemas(objects)
+		return CRDSchemaCheck(schemas)
+	})
+}
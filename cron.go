@@ -0,0 +1,137 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// cronMacros are the predefined schedules accepted in place of the five cron fields.
+var cronMacros = []string{"@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly"}
+
+// cronField describes one field of a standard cron schedule.
+type cronField struct {
+	name     string
+	min, max int
+	// names maps the case-insensitive names accepted for values, e.g. JAN or SUN
+	names map[string]int
+}
+
+// cronFields are the five fields of a standard cron schedule, in order.
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	{name: "day of week", min: 0, max: 6, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+// ValidateCronSchedule validates a CronJob schedule as parsed by the Kubernetes controller:
+// five space-separated fields (minute, hour, day of month, month, day of week), each a
+// '*' or '?', a value, a range such as 1-5, or a comma-separated list of those, optionally
+// with a /step; or one of the macros @yearly, @annually, @monthly, @weekly, @daily,
+// @midnight, and @hourly. Months and days of the week may be given by their three-letter
+// names. A leading TZ= or CRON_TZ= prefix naming a time zone is accepted.
+func ValidateCronSchedule(schedule string) error {
+	if strings.TrimSpace(schedule) == "" {
+		return &ConstraintError{Rule: RuleRequired, Message: "schedule cannot be empty"}
+	}
+
+	spec := strings.TrimSpace(schedule)
+	if zone, rest, ok := cutCronTimeZone(spec); ok {
+		if err := ValidateTimeZone(zone); err != nil {
+			return withRule(RuleCronSchedule, schedule, err)
+		}
+		spec = strings.TrimSpace(rest)
+	}
+
+	if strings.HasPrefix(spec, "@") {
+		if !containsString(cronMacros, spec) {
+			return &ConstraintError{Rule: RuleCronSchedule, BadValue: schedule, Message: fmt.Sprintf("unsupported schedule macro '%s'; must be one of: %s%s", spec, strings.Join(cronMacros, ", "), enumSuggestion(spec, cronMacros))}
+		}
+		return nil
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return &ConstraintError{Rule: RuleCronSchedule, BadValue: schedule, Message: fmt.Sprintf("schedule '%s' must have 5 fields (minute, hour, day of month, month, day of week), got %d", schedule, len(fields))}
+	}
+
+	errs := make([]error, 0)
+	for i, field := range fields {
+		if err := cronFields[i].validate(field); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withRule(RuleCronSchedule, schedule, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// cutCronTimeZone splits a TZ= or CRON_TZ= prefix off a schedule, returning the time zone
+// and the remaining schedule.
+func cutCronTimeZone(schedule string) (string, string, bool) {
+	for _, prefix := range []string{"TZ=", "CRON_TZ="} {
+		if strings.HasPrefix(schedule, prefix) {
+			zone, rest, _ := strings.Cut(schedule[len(prefix):], " ")
+			return zone, rest, true
+		}
+	}
+	return "", "", false
+}
+
+// validate checks one field of a schedule: a comma-separated list of '*', '?', values, or
+// ranges, each with an optional /step.
+func (f cronField) validate(field string) error {
+	for _, item := range strings.Split(field, ",") {
+		expr, step, hasStep := strings.Cut(item, "/")
+		if hasStep {
+			if n, err := strconv.Atoi(step); err != nil || n <= 0 {
+				return fmt.Errorf("%s field '%s' has an invalid step '%s'; must be a positive integer", f.name, field, step)
+			}
+		}
+		if expr == "*" || expr == "?" {
+			continue
+		}
+
+		low, high, isRange := strings.Cut(expr, "-")
+		start, err := f.value(low)
+		if err != nil {
+			return fmt.Errorf("%s field '%s': %v", f.name, field, err)
+		}
+		end := start
+		if isRange {
+			if end, err = f.value(high); err != nil {
+				return fmt.Errorf("%s field '%s': %v", f.name, field, err)
+			}
+		}
+		if start > end {
+			return fmt.Errorf("%s field '%s' has a range '%s' that starts after it ends", f.name, field, expr)
+		}
+	}
+	return nil
+}
+
+// value parses a single value of the field, a number or a name, and checks its bounds.
+func (f cronField) value(s string) (int, error) {
+	if n, ok := f.names[strings.ToLower(s)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a number", s)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("value %d must be between %d and %d", n, f.min, f.max)
+	}
+	return n, nil
+}
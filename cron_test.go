@@ -0,0 +1,106 @@
+package k8sconstraints
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateCronSchedule(t *testing.T) {
+	tests := []struct {
+		schedule string
+		// finding is part of the expected message; "" expects no error
+		finding string
+	}{
+		{"*/5 * * * *", ""},
+		{"0 9-17 * * MON-FRI", ""},
+		{"30 2 1,15 jan,jul ?", ""},
+		{"0 0 * * 0", ""},
+		{"@hourly", ""},
+		{"CRON_TZ=Europe/Berlin 0 6 * * *", ""},
+		{"TZ=UTC @daily", ""},
+		{"", "schedule cannot be empty"},
+		{"* * * *", "schedule '* * * *' must have 5 fields (minute, hour, day of month, month, day of week), got 4"},
+		{"60 * * * *", "minute field '60': value 60 must be between 0 and 59"},
+		{"* * 0 * *", "day of month field '0': value 0 must be between 1 and 31"},
+		{"* * * * 7", "day of week field '7': value 7 must be between 0 and 6"},
+		{"* * * foo *", "month field 'foo': 'foo' is not a number"},
+		{"*/0 * * * *", "minute field '*/0' has an invalid step '0'; must be a positive integer"},
+		{"0 17-9 * * *", "hour field '17-9' has a range '17-9' that starts after it ends"},
+		{"@hourley", "unsupported schedule macro '@hourley'"},
+		{"TZ=Mars/Olympus 0 6 * * *", "unknown time zone 'Mars/Olympus'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schedule, func(t *testing.T) {
+			err := ValidateCronSchedule(tt.schedule)
+			if tt.finding == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.finding) {
+				t.Errorf("expected an error containing %q, got %v", tt.finding, err)
+			}
+		})
+	}
+}
+
+func TestValidateCronJob(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		// finding is part of the expected message; "" expects no error
+		finding string
+	}{
+		{"valid", `
+apiVersion: batch/v1
+kind: CronJob
+metadata: {name: backup}
+spec:
+  schedule: "0 3 * * *"
+  timeZone: Europe/Berlin
+  jobTemplate: {spec: {template: {spec: {restartPolicy: OnFailure, containers: [{name: backup, image: "busybox:1.36"}]}}}}`,
+			""},
+		{"time zone in schedule", `
+apiVersion: batch/v1
+kind: CronJob
+metadata: {name: backup}
+spec:
+  schedule: "TZ=Europe/Berlin 0 3 * * *"
+  jobTemplate: {spec: {template: {spec: {restartPolicy: OnFailure, containers: [{name: backup, image: "busybox:1.36"}]}}}}`,
+			"spec.schedule: warning: TZ and CRON_TZ in schedule are not supported on new CronJobs; use spec.timeZone instead"},
+		{"invalid schedule", `
+apiVersion: batch/v1
+kind: CronJob
+metadata: {name: backup}
+spec:
+  schedule: "0 25 * * *"
+  jobTemplate: {spec: {template: {spec: {restartPolicy: OnFailure, containers: [{name: backup, image: "busybox:1.36"}]}}}}`,
+			"spec.schedule: hour field '25': value 25 must be between 0 and 23"},
+		{"Local time zone", `
+apiVersion: batch/v1
+kind: CronJob
+metadata: {name: backup}
+spec:
+  schedule: "0 3 * * *"
+  timeZone: Local
+  jobTemplate: {spec: {template: {spec: {restartPolicy: OnFailure, containers: [{name: backup, image: "busybox:1.36"}]}}}}`,
+			"spec.timeZone: time zone must be an explicit IANA time zone, not 'Local'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCronJob(decodeTestObject(t, tt.manifest))
+			if tt.finding == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.finding) {
+				t.Errorf("expected an error containing %q, got %v", tt.finding, err)
+			}
+		})
+	}
+}
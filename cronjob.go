@@ -0,0 +1,98 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	// Embedded time zone database, so time zones validate the same on every host
+	_ "time/tzdata"
+)
+
+// cronJobConcurrencyPolicies lists the valid spec.concurrencyPolicy values of a CronJob.
+var cronJobConcurrencyPolicies = []string{"Allow", "Forbid", "Replace"}
+
+// ValidateCronJob validates the spec of a batch/v1 CronJob: the schedule, timeZone,
+// concurrencyPolicy, startingDeadlineSeconds, the history limits, and the jobTemplate spec,
+// which is held to the Job rules of ValidateJobSpec. A TZ= or CRON_TZ= prefix in the schedule
+// is reported as a warning, since the API server only keeps it on existing CronJobs and
+// spec.timeZone replaces it.
+func ValidateCronJob(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	spec, ok := nestedMap(obj, "spec")
+	if !ok {
+		return Required(NewPath("spec"), "spec is required")
+	}
+
+	schedule, _ := nestedString(spec, "schedule")
+	if err := ValidateCronSchedule(schedule); err != nil {
+		errs = append(errs, WithFieldPath("spec.schedule", err))
+	} else if zone, rest, ok := cutCronTimeZone(strings.TrimSpace(schedule)); ok {
+		violation := &ConstraintError{FieldPath: "spec.schedule", BadValue: schedule, Message: "warning: TZ and CRON_TZ in schedule are not supported on new CronJobs; use spec.timeZone instead"}
+		if _, hasTimeZone := nestedField(spec, "timeZone"); !hasTimeZone {
+			violation.Fix = []FieldChange{
+				{Op: PatchTest, FieldPath: "spec.schedule", Value: schedule},
+				{Op: PatchReplace, FieldPath: "spec.schedule", Value: strings.TrimSpace(rest)},
+				{Op: PatchAdd, FieldPath: "spec.timeZone", Value: zone},
+			}
+		}
+		errs = append(errs, violation)
+	}
+
+	if value, ok := nestedField(spec, "timeZone"); ok {
+		zone, _ := value.(string)
+		if err := ValidateTimeZone(zone); err != nil {
+			errs = append(errs, WithFieldPath("spec.timeZone", err))
+		}
+	}
+
+	if policy, ok := nestedString(spec, "concurrencyPolicy"); ok {
+		if err := validateEnumField("spec.concurrencyPolicy", policy, cronJobConcurrencyPolicies); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if value, ok := nestedField(spec, "startingDeadlineSeconds"); ok {
+		if n, ok := toInt64(value); !ok || n < 0 {
+			errs = append(errs, &ConstraintError{FieldPath: "spec.startingDeadlineSeconds", BadValue: value, Message: "startingDeadlineSeconds must be a non-negative integer"})
+		}
+	}
+
+	for _, field := range []string{"successfulJobsHistoryLimit", "failedJobsHistoryLimit"} {
+		if value, ok := nestedField(spec, field); ok && value != nil {
+			if n, ok := toInt64(value); !ok || n < 0 {
+				errs = append(errs, &ConstraintError{FieldPath: "spec." + field, BadValue: value, Message: fmt.Sprintf("%s must be a non-negative integer", field)})
+			}
+		}
+	}
+
+	if jobSpec, ok := nestedMap(spec, "jobTemplate", "spec"); !ok {
+		errs = append(errs, Required(NewPath("spec", "jobTemplate", "spec"), "jobTemplate.spec is required"))
+	} else if err := ValidateJobSpec(jobSpec); err != nil {
+		errs = append(errs, WithFieldPath("spec.jobTemplate.spec", err))
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleCronJob, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// ValidateTimeZone validates an IANA time zone name such as "Europe/Berlin", as used by
+// spec.timeZone of a CronJob. "Local" is rejected because it depends on the host the
+// controller runs on.
+func ValidateTimeZone(zone string) error {
+	if zone == "" {
+		return &ConstraintError{Rule: RuleRequired, Message: "time zone cannot be empty"}
+	}
+	if strings.EqualFold(zone, "Local") {
+		return &ConstraintError{Rule: RuleTimeZone, BadValue: zone, Message: "time zone must be an explicit IANA time zone, not 'Local'"}
+	}
+	if _, err := time.LoadLocation(zone); err != nil {
+		return &ConstraintError{Rule: RuleTimeZone, BadValue: zone, Message: fmt.Sprintf("unknown time zone '%s'; must be an IANA time zone such as 'Europe/Berlin'", zone)}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package k8sconstraints
+
+import "fmt"
+
+// CheckDaemonSetScaleTargets is the ID of the check built by DaemonSetScaleTargetsCheck.
+const CheckDaemonSetScaleTargets = "DaemonSetScaleTargets"
+
+// daemonSetUpdateStrategyTypes lists the valid spec.updateStrategy.type values of a DaemonSet.
+var daemonSetUpdateStrategyTypes = []string{"RollingUpdate", "OnDelete"}
+
+// ValidateDaemonSet validates the spec of an apps/v1 DaemonSet: the update strategy, the
+// DaemonSet-specific maxUnavailable/maxSurge rules, and the selector/template relationship.
+func ValidateDaemonSet(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	specPath := NewPath("spec")
+	spec, ok := nestedMap(obj, "spec")
+	if !ok {
+		return Required(specPath, "spec is required")
+	}
+
+	// Update strategy
+	if strategy, ok := nestedMap(spec, "updateStrategy"); ok {
+		if err := ValidateDaemonSetUpdateStrategy(strategy); err != nil {
+			errs = append(errs, WithFieldPath(specPath.Child("updateStrategy").String(), err))
+		}
+	}
+
+	// Non-negative counters
+	for _, field := range []string{"minReadySeconds", "revisionHistoryLimit"} {
+		if value, ok := nestedField(spec, field); ok {
+			if n, ok := toInt64(value); !ok || n < 0 {
+				errs = append(errs, Invalid(specPath.Child(field), value, "must be a non-negative integer"))
+			}
+		}
+	}
+
+	// Selector must be set and match the pod template labels
+	if err := validateTemplateSelector(specPath, spec); err != nil {
+		errs = append(errs, err)
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleDaemonSet, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// ValidateDaemonSetUpdateStrategy validates spec.updateStrategy of a DaemonSet.
+func ValidateDaemonSetUpdateStrategy(strategy map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	strategyType, _ := nestedString(strategy, "type")
+	if strategyType != "" {
+		if err := validateEnumField("type", strategyType, daemonSetUpdateStrategyTypes); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	rollingUpdatePath := NewPath("rollingUpdate")
+	rollingUpdate, hasRollingUpdate := nestedMap(strategy, "rollingUpdate")
+	if hasRollingUpdate && strategyType == "OnDelete" {
+		errs = append(errs, Invalid(rollingUpdatePath, nil, "rollingUpdate may only be set when type is RollingUpdate"))
+	}
+
+	if hasRollingUpdate {
+		var maxUnavailable, maxSurge IntOrStringValue
+		var unavailableSet, surgeSet bool
+
+		if value, ok := nestedField(rollingUpdate, "maxUnavailable"); ok {
+			parsed, err := ParseIntOrStringPercent(value)
+			if err != nil {
+				errs = append(errs, WithFieldPath(rollingUpdatePath.Child("maxUnavailable").String(), err))
+			} else {
+				maxUnavailable, unavailableSet = parsed, true
+			}
+		}
+		if value, ok := nestedField(rollingUpdate, "maxSurge"); ok {
+			parsed, err := ParseIntOrStringPercent(value)
+			if err != nil {
+				errs = append(errs, WithFieldPath(rollingUpdatePath.Child("maxSurge").String(), err))
+			} else {
+				maxSurge, surgeSet = parsed, true
+			}
+		}
+
+		// DaemonSets run one pod per node: an update must be able to either take a node's
+		// pod down or surge a replacement, but not both
+		if unavailableSet && surgeSet {
+			if maxUnavailable.IsZero() && maxSurge.IsZero() {
+				errs = append(errs, Invalid(rollingUpdatePath, nil, "maxUnavailable and maxSurge may not both be 0"))
+			}
+			if !maxUnavailable.IsZero() && !maxSurge.IsZero() {
+				errs = append(errs, Invalid(rollingUpdatePath.Child("maxSurge"), rollingUpdate["maxSurge"], "must be 0 when maxUnavailable is not 0"))
+			}
+		} else if unavailableSet && maxUnavailable.IsZero() {
+			// maxSurge defaults to 0 for DaemonSets
+			errs = append(errs, Invalid(rollingUpdatePath.Child("maxUnavailable"), rollingUpdate["maxUnavailable"], "may not be 0 unless maxSurge is set"))
+		} else if surgeSet && !maxSurge.IsZero() {
+			// maxUnavailable defaults to 1 for DaemonSets
+			errs = append(errs, Invalid(rollingUpdatePath.Child("maxUnavailable"), nil, "must be set to 0 when maxSurge is not 0"))
+		}
+	}
+
+	if len(errs) > 0 {
+		return withDefaultRule(RuleDaemonSet, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// ValidateDaemonSetReplicas reports a warning for a DaemonSet declaring spec.replicas,
+// which is ignored.
+func ValidateDaemonSetReplicas(obj map[string]interface{}) error {
+	if replicas, ok := nestedField(obj, "spec", "replicas"); ok {
+		return &ConstraintError{FieldPath: "spec.replicas", Rule: CheckDaemonSetScaling, BadValue: replicas, Message: "warning: spec.replicas has no effect on a DaemonSet; it runs one pod per eligible node"}
+	}
+	return nil
+}
+
+// DaemonSetScaleTargetsCheck returns a bundle check that warns about
+// HorizontalPodAutoscalers whose scaleTargetRef references a DaemonSet, which has no scale
+// subresource.
+func DaemonSetScaleTargetsCheck(objects []map[string]interface{}) Check {
+	daemonSets := make(map[string]bool)
+	for _, obj := range objects {
+		if gvk := GroupVersionKindOf(obj); gvk.Group == "apps" && gvk.Kind == "DaemonSet" {
+			namespace, _ := nestedString(obj, "metadata", "namespace")
+			name, _ := nestedString(obj, "metadata", "name")
+			daemonSets[namespace+"/"+name] = true
+		}
+	}
+
+	return Check{ID: CheckDaemonSetScaleTargets, Kinds: []string{"HorizontalPodAutoscaler.autoscaling"}, Validate: func(obj map[string]interface{}) error {
+		kind, _ := nestedString(obj, "spec", "scaleTargetRef", "kind")
+		if kind != "DaemonSet" {
+			return nil
+		}
+		namespace, _ := nestedString(obj, "metadata", "namespace")
+		targetName, _ := nestedString(obj, "spec", "scaleTargetRef", "name")
+		message := fmt.Sprintf("warning: scaleTargetRef references DaemonSet '%s', which cannot be scaled", targetName)
+		if daemonSets[namespace+"/"+targetName] {
+			message += "; it runs one pod per eligible node, so remove the HorizontalPodAutoscaler"
+		}
+		return &ConstraintError{FieldPath: "spec.scaleTargetRef.kind", Rule: CheckDaemonSetScaleTargets, BadValue: kind, Message: message}
+	}}
+}
+
+// ValidateDaemonSetScaleTargets runs DaemonSetScaleTargetsCheck against every object of a
+// bundle.
+func ValidateDaemonSetScaleTargets(objects []map[string]interface{}) error {
+	return RunBundleCheck(objects, DaemonSetScaleTargetsCheck)
+}
+
+// validateTemplateSelector checks that the workload spec at path has a selector and that
+// its matchLabels select the pod template's labels.
+func validateTemplateSelector(path *Path, spec map[string]interface{}) error {
+	selectorPath := path.Child("selector")
+	selector, ok := nestedMap(spec, "selector")
+	if !ok {
+		return Required(selectorPath, "selector is required")
+	}
+
+	matchLabels, _ := nestedStringMap(selector, "matchLabels")
+	_, hasExpressions := nestedSlice(selector, "matchExpressions")
+	if len(matchLabels) == 0 && !hasExpressions {
+		return Required(selectorPath, "selector must not be empty")
+	}
+
+	labelsPath := path.Child("template", "metadata", "labels")
+	templateLabels, _ := nestedStringMap(spec, "template", "metadata", "labels")
+	for _, key := range sortedKeys(matchLabels) {
+		if templateLabels[key] != matchLabels[key] {
+			message := fmt.Sprintf("must be '%s' to match %s", matchLabels[key], selectorPath.Child("matchLabels").Key(key))
+			return Invalid(labelsPath.Key(key), templateLabels[key], message)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package k8sconstraints
+
+// deploymentStrategyTypes lists the valid spec.strategy.type values of a Deployment.
+var deploymentStrategyTypes = []string{"Recreate", "RollingUpdate"}
+
+// ValidateDeployment validates the spec of an apps/v1 Deployment: the strategy type, and
+// that rollingUpdate is only set for the RollingUpdate strategy.
+func ValidateDeployment(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	strategyPath := NewPath("spec", "strategy")
+	strategyType, _ := nestedString(obj, "spec", "strategy", "type")
+	if strategyType != "" {
+		if err := validateEnumField(strategyPath.Child("type").String(), strategyType, deploymentStrategyTypes); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if _, ok := nestedMap(obj, "spec", "strategy", "rollingUpdate"); ok && strategyType == "Recreate" {
+		errs = append(errs, Invalid(strategyPath.Child("rollingUpdate"), nil, "rollingUpdate may only be set when type is RollingUpdate"))
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleDeployment, JoinErrors(errs))
+	}
+
+	return nil
+}
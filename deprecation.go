@@ -0,0 +1,124 @@
+package k8sconstraints
+
+import "fmt"
+
+// CheckDeprecatedAPIs is the ID of the check built by DeprecatedAPIsCheck.
+const CheckDeprecatedAPIs = "DeprecatedAPIs"
+
+// APIDeprecation records when Kubernetes deprecated and removed an API version of a kind,
+// and the version that replaces it.
+type APIDeprecation struct {
+	GroupVersionKind
+	// Deprecated is the Kubernetes release that deprecated the version, e.g. "1.19".
+	Deprecated string `json:"deprecated"`
+	// Removed is the Kubernetes release that stopped serving the version.
+	Removed string `json:"removed"`
+	// Replacement is the apiVersion to migrate to, or "" when the API was dropped.
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// APIDeprecations lists the deprecated and removed API versions of the built-in kinds.
+var APIDeprecations = []APIDeprecation{
+	{GroupVersionKind{"extensions", "v1beta1", "Deployment"}, "1.9", "1.16", "apps/v1"},
+	{GroupVersionKind{"extensions", "v1beta1", "DaemonSet"}, "1.9", "1.16", "apps/v1"},
+	{GroupVersionKind{"extensions", "v1beta1", "ReplicaSet"}, "1.9", "1.16", "apps/v1"},
+	{GroupVersionKind{"extensions", "v1beta1", "NetworkPolicy"}, "1.9", "1.16", "networking.k8s.io/v1"},
+	{GroupVersionKind{"extensions", "v1beta1", "PodSecurityPolicy"}, "1.11", "1.16", "policy/v1beta1"},
+	{GroupVersionKind{"apps", "v1beta1", "Deployment"}, "1.9", "1.16", "apps/v1"},
+	{GroupVersionKind{"apps", "v1beta1", "StatefulSet"}, "1.9", "1.16", "apps/v1"},
+	{GroupVersionKind{"apps", "v1beta2", "Deployment"}, "1.9", "1.16", "apps/v1"},
+	{GroupVersionKind{"apps", "v1beta2", "StatefulSet"}, "1.9", "1.16", "apps/v1"},
+	{GroupVersionKind{"apps", "v1beta2", "DaemonSet"}, "1.9", "1.16", "apps/v1"},
+	{GroupVersionKind{"apps", "v1beta2", "ReplicaSet"}, "1.9", "1.16", "apps/v1"},
+	{GroupVersionKind{"extensions", "v1beta1", "Ingress"}, "1.14", "1.22", "networking.k8s.io/v1"},
+	{GroupVersionKind{"networking.k8s.io", "v1beta1", "Ingress"}, "1.19", "1.22", "networking.k8s.io/v1"},
+	{GroupVersionKind{"networking.k8s.io", "v1beta1", "IngressClass"}, "1.19", "1.22", "networking.k8s.io/v1"},
+	{GroupVersionKind{"apiextensions.k8s.io", "v1beta1", "CustomResourceDefinition"}, "1.16", "1.22", "apiextensions.k8s.io/v1"},
+	{GroupVersionKind{"admissionregistration.k8s.io", "v1beta1", "MutatingWebhookConfiguration"}, "1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	{GroupVersionKind{"admissionregistration.k8s.io", "v1beta1", "ValidatingWebhookConfiguration"}, "1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	{GroupVersionKind{"apiregistration.k8s.io", "v1beta1", "APIService"}, "1.19", "1.22", "apiregistration.k8s.io/v1"},
+	{GroupVersionKind{"certificates.k8s.io", "v1beta1", "CertificateSigningRequest"}, "1.19", "1.22", "certificates.k8s.io/v1"},
+	{GroupVersionKind{"coordination.k8s.io", "v1beta1", "Lease"}, "1.19", "1.22", "coordination.k8s.io/v1"},
+	{GroupVersionKind{"rbac.authorization.k8s.io", "v1beta1", "ClusterRole"}, "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{GroupVersionKind{"rbac.authorization.k8s.io", "v1beta1", "ClusterRoleBinding"}, "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{GroupVersionKind{"rbac.authorization.k8s.io", "v1beta1", "Role"}, "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{GroupVersionKind{"rbac.authorization.k8s.io", "v1beta1", "RoleBinding"}, "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{GroupVersionKind{"scheduling.k8s.io", "v1beta1", "PriorityClass"}, "1.14", "1.22", "scheduling.k8s.io/v1"},
+	{GroupVersionKind{"storage.k8s.io", "v1beta1", "CSIDriver"}, "1.19", "1.22", "storage.k8s.io/v1"},
+	{GroupVersionKind{"storage.k8s.io", "v1beta1", "CSINode"}, "1.17", "1.22", "storage.k8s.io/v1"},
+	{GroupVersionKind{"storage.k8s.io", "v1beta1", "StorageClass"}, "1.19", "1.22", "storage.k8s.io/v1"},
+	{GroupVersionKind{"storage.k8s.io", "v1beta1", "VolumeAttachment"}, "1.19", "1.22", "storage.k8s.io/v1"},
+	{GroupVersionKind{"batch", "v1beta1", "CronJob"}, "1.21", "1.25", "batch/v1"},
+	{GroupVersionKind{"discovery.k8s.io", "v1beta1", "EndpointSlice"}, "1.21", "1.25", "discovery.k8s.io/v1"},
+	{GroupVersionKind{"events.k8s.io", "v1beta1", "Event"}, "1.19", "1.25", "events.k8s.io/v1"},
+	{GroupVersionKind{"autoscaling", "v2beta1", "HorizontalPodAutoscaler"}, "1.22", "1.25", "autoscaling/v2"},
+	{GroupVersionKind{"node.k8s.io", "v1beta1", "RuntimeClass"}, "1.22", "1.25", "node.k8s.io/v1"},
+	{GroupVersionKind{"policy", "v1beta1", "PodDisruptionBudget"}, "1.21", "1.25", "policy/v1"},
+	{GroupVersionKind{"policy", "v1beta1", "PodSecurityPolicy"}, "1.21", "1.25", ""},
+	{GroupVersionKind{"autoscaling", "v2beta2", "HorizontalPodAutoscaler"}, "1.23", "1.26", "autoscaling/v2"},
+	{GroupVersionKind{"flowcontrol.apiserver.k8s.io", "v1beta1", "FlowSchema"}, "1.23", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{GroupVersionKind{"flowcontrol.apiserver.k8s.io", "v1beta1", "PriorityLevelConfiguration"}, "1.23", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{GroupVersionKind{"storage.k8s.io", "v1beta1", "CSIStorageCapacity"}, "1.24", "1.27", "storage.k8s.io/v1"},
+	{GroupVersionKind{"flowcontrol.apiserver.k8s.io", "v1beta2", "FlowSchema"}, "1.26", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{GroupVersionKind{"flowcontrol.apiserver.k8s.io", "v1beta2", "PriorityLevelConfiguration"}, "1.26", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{GroupVersionKind{"flowcontrol.apiserver.k8s.io", "v1beta3", "FlowSchema"}, "1.29", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+	{GroupVersionKind{"flowcontrol.apiserver.k8s.io", "v1beta3", "PriorityLevelConfiguration"}, "1.29", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// ValidateKubernetesVersion checks that version is a Kubernetes release such as "1.29" or
+// "v1.29.3".
+func ValidateKubernetesVersion(version string) error {
+	if _, err := parseReleaseVersion(version); err != nil {
+		return fmt.Errorf("invalid Kubernetes version '%s'; must be of the form 1.minor[.patch]", version)
+	}
+	return nil
+}
+
+// CheckDeprecation reports whether the API version of gvk is removed in, or deprecated as
+// of, the Kubernetes release targetVersion, such as "1.29". Removed versions are errors,
+// since the API server rejects them; deprecated ones are warnings. Both name the
+// replacement to migrate to.
+func CheckDeprecation(gvk GroupVersionKind, targetVersion string) error {
+	return checkDeprecation(APIDeprecations, gvk, targetVersion)
+}
+
+// checkDeprecation is CheckDeprecation against the deprecation table deprecations, such as
+// the one of the release that built an offline bundle.
+func checkDeprecation(deprecations []APIDeprecation, gvk GroupVersionKind, targetVersion string) error {
+	target, err := parseReleaseVersion(targetVersion)
+	if err != nil {
+		return ValidateKubernetesVersion(targetVersion)
+	}
+
+	for _, deprecation := range deprecations {
+		if deprecation.GroupVersionKind != gvk {
+			continue
+		}
+		migrate := "it has no replacement"
+		if deprecation.Replacement != "" {
+			migrate = fmt.Sprintf("use %s instead", deprecation.Replacement)
+		}
+		removed, _ := parseReleaseVersion(deprecation.Removed)
+		deprecated, _ := parseReleaseVersion(deprecation.Deprecated)
+		switch {
+		case compareReleaseVersions(target, removed) >= 0:
+			message := fmt.Sprintf("%s was removed in Kubernetes %s and is not served by %s; %s", gvk, deprecation.Removed, targetVersion, migrate)
+			return &ConstraintError{FieldPath: "apiVersion", Rule: RuleDeprecatedAPI, BadValue: gvk.APIVersion(), Message: message}
+		case compareReleaseVersions(target, deprecated) >= 0:
+			message := fmt.Sprintf("warning: %s is deprecated since Kubernetes %s and will be removed in %s; %s", gvk, deprecation.Deprecated, deprecation.Removed, migrate)
+			return &ConstraintError{FieldPath: "apiVersion", Rule: RuleDeprecatedAPI, BadValue: gvk.APIVersion(), Message: message}
+		}
+		return nil
+	}
+	return nil
+}
+
+// DeprecatedAPIsCheck returns an opt-in check, not run by ValidateObject, that reports
+// objects using API versions deprecated or removed as of the Kubernetes release
+// targetVersion; see CheckDeprecation. Run it with RunChecks or pass it to the linter's
+// Checks option.
+func DeprecatedAPIsCheck(targetVersion string) Check {
+	return Check{ID: CheckDeprecatedAPIs, Validate: func(obj map[string]interface{}) error {
+		return CheckDeprecation(GroupVersionKindOf(obj), targetVersion)
+	}}
+}
@@ -0,0 +1,70 @@
+package k8sconstraints
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// FindingsDiff is the result of comparing two validation runs by fingerprint.
+type FindingsDiff struct {
+	// New holds findings present only in the newer run.
+	New []Finding `json:"new"`
+	// Fixed holds findings present only in the older run.
+	Fixed []Finding `json:"fixed"`
+	// Persisting holds findings present in both runs, as reported by the newer run.
+	Persisting []Finding `json:"persisting"`
+}
+
+// DiffFindings compares two result sets by fingerprint. Findings without a fingerprint
+// get one computed, and duplicates within each run are ignored.
+func DiffFindings(oldFindings, newFindings []Finding) FindingsDiff {
+	oldFindings = DeduplicateFindings(oldFindings)
+	newFindings = DeduplicateFindings(newFindings)
+
+	oldSet := make(map[string]bool, len(oldFindings))
+	for _, finding := range oldFindings {
+		oldSet[finding.Fingerprint] = true
+	}
+	newSet := make(map[string]bool, len(newFindings))
+	for _, finding := range newFindings {
+		newSet[finding.Fingerprint] = true
+	}
+
+	diff := FindingsDiff{New: []Finding{}, Fixed: []Finding{}, Persisting: []Finding{}}
+	for _, finding := range newFindings {
+		if oldSet[finding.Fingerprint] {
+			diff.Persisting = append(diff.Persisting, finding)
+		} else {
+			diff.New = append(diff.New, finding)
+		}
+	}
+	for _, finding := range oldFindings {
+		if !newSet[finding.Fingerprint] {
+			diff.Fixed = append(diff.Fixed, finding)
+		}
+	}
+	return diff
+}
+
+// LoadFindings reads a result set from a JSON file. The file may hold either an array of
+// findings or an object with a "findings" array.
+func LoadFindings(path string) ([]Finding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	if err := json.Unmarshal(data, &findings); err == nil {
+		return findings, nil
+	}
+
+	var results struct {
+		Findings []Finding `json:"findings"`
+	}
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("invalid results file '%s': %v", path, err)
+	}
+	return results.Findings, nil
+}
@@ -0,0 +1,88 @@
+package k8sconstraints
+
+// ValidateDNSLabel validates a string against the DNS label format as defined by RFC 1123.
+// Kubernetes uses this for names that may not contain dots, such as namespaces.
+func ValidateDNSLabel(label string) error {
+	// DNS label format: Lowercase alphanumeric, hyphens allowed, must start/end with alphanumeric.
+	// Maximum length of 63 characters.
+	if len(label) > 63 {
+		return &ConstraintError{Rule: RuleDNS1123Label, BadValue: label, Message: "label exceeds maximum length of 63 characters"}
+	}
+	if !isDNS1123Label(label) {
+		return &ConstraintError{Rule: RuleDNS1123Label, BadValue: label, Message: "label must match DNS label format (lowercase alphanumeric, hyphens, max 63 characters, must start and end with alphanumeric)"}
+	}
+	return nil
+}
+
+// ValidateDNSSubdomain validates a string against the DNS subdomain format as defined by RFC 1123.
+// DNS subdomain format: Lowercase alphanumeric, `-`, `.` allowed.
+// Must start/end with alphanumeric, max 253 characters.
+func ValidateDNSSubdomain(subdomain string) error {
+	if len(subdomain) > 253 {
+		return &ConstraintError{Rule: RuleDNS1123Subdomain, BadValue: subdomain, Message: "subdomain exceeds maximum length of 253 characters"}
+	}
+	if !isDNS1123Subdomain(subdomain) {
+		return &ConstraintError{Rule: RuleDNS1123Subdomain, BadValue: subdomain, Message: "subdomain must match DNS subdomain format (lowercase alphanumeric, `-`, `.`, max 253 characters, must start and end with alphanumeric)"}
+	}
+	return nil
+}
+
+// ValidateDNS1035Label validates a string against the DNS label format as defined by RFC 1035.
+// It differs from ValidateDNSLabel in that the label must start with a letter rather than a
+// digit. Kubernetes uses this for names that may become DNS names or environment variable
+// prefixes, such as Services.
+func ValidateDNS1035Label(label string) error {
+	// DNS label format: Lowercase alphanumeric, hyphens allowed, must start with a letter and
+	// end with an alphanumeric. Maximum length of 63 characters.
+	if len(label) > 63 {
+		return &ConstraintError{Rule: RuleDNS1035Label, BadValue: label, Message: "label exceeds maximum length of 63 characters"}
+	}
+	if !isDNS1123Label(label) || !isLowerAlpha(label[0]) {
+		return &ConstraintError{Rule: RuleDNS1035Label, BadValue: label, Message: "label must match RFC 1035 DNS label format (lowercase alphanumeric, hyphens, max 63 characters, must start with a letter and end with alphanumeric)"}
+	}
+	return nil
+}
+
+// isDNS1123Label reports whether s matches [a-z0-9]([-a-z0-9]*[a-z0-9])?, ignoring length.
+// Names are validated on every object, so this is a byte loop rather than a regexp.
+func isDNS1123Label(s string) bool {
+	if s == "" || !isLowerAlphanumeric(s[0]) || !isLowerAlphanumeric(s[len(s)-1]) {
+		return false
+	}
+	for i := 1; i < len(s)-1; i++ {
+		if !isLowerAlphanumeric(s[i]) && s[i] != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// isDNS1123Subdomain reports whether s is a dot-separated list of DNS labels, ignoring
+// length: [a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*
+func isDNS1123Subdomain(s string) bool {
+	start := 0
+	for i := 0; i <= len(s); i++ {
+		if i == len(s) || s[i] == '.' {
+			if !isDNS1123Label(s[start:i]) {
+				return false
+			}
+			start = i + 1
+		}
+	}
+	return true
+}
+
+// isLowerAlpha reports whether c is a lowercase ASCII letter.
+func isLowerAlpha(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
+
+// isLowerAlphanumeric reports whether c is a lowercase ASCII letter or a digit.
+func isLowerAlphanumeric(c byte) bool {
+	return isLowerAlpha(c) || ('0' <= c && c <= '9')
+}
+
+// isAlphanumeric reports whether c is an ASCII letter or a digit.
+func isAlphanumeric(c byte) bool {
+	return isLowerAlphanumeric(c) || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,8 @@
+// Package k8sconstraints validates the syntax of Kubernetes manifest fields against the
+// constraints enforced by the API server: apiVersion and kind, metadata names, labels, and
+// annotations, and the kind-specific rules of common resources.
+//
+// Validators take either a single field value (e.g. ValidateDNSSubdomain, ValidateLabelKey)
+// or a decoded object as map[string]interface{} (e.g. ValidateDaemonSet), and return nil
+// when the input is valid or an error describing every violation found.
+package k8sconstraints
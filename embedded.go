@@ -0,0 +1,96 @@
+package k8sconstraints
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// Opening fence of a Markdown code block, capturing the fence and its info string
+	markdownFencePattern = regexp.MustCompile("^ {0,3}(```+|~~~+)[ \t]*([^`\\s]*)")
+	// Lines that mark a YAML snippet as a Kubernetes manifest rather than a values file or
+	// configuration excerpt
+	manifestMarkerPattern = regexp.MustCompile(`(?m)^\s*(apiVersion|kind)\s*:`)
+)
+
+// markdownYAMLLanguages are the info strings of fenced code blocks holding YAML.
+var markdownYAMLLanguages = []string{"yaml", "yml"}
+
+// offsetSource shifts the lines and indexes of the documents of an embedded source so they
+// refer to the host file, and renames their source to it.
+type offsetSource struct {
+	name   string
+	source Source
+	line   int
+	index  *int
+}
+
+// Next returns the next embedded document, relocated to the host file.
+func (s offsetSource) Next() (Document, error) {
+	doc, err := s.source.Next()
+	if err != nil {
+		return doc, err
+	}
+	doc.Source = s.name
+	doc.Index = *s.index
+	*s.index++
+	if doc.Line > 0 {
+		doc.Line += s.line
+	}
+	return doc, nil
+}
+
+// NewMarkdownSource returns a Source over the Kubernetes manifests embedded in the YAML code
+// fences of a Markdown document, for linting documentation. Only fences with a yaml or yml
+// info string that contain an apiVersion or kind line are decoded, so configuration
+// snippets are not mistaken for manifests. Documents are numbered across fences, and lines
+// refer to the Markdown file.
+func NewMarkdownSource(name string, data []byte) Source {
+	sources := make([]Source, 0)
+	index := 0
+
+	lines := strings.SplitAfter(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		match := markdownFencePattern.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+		fence, language := match[1], strings.ToLower(match[2])
+
+		// The block runs to the next fence of the same character at least as long
+		start, end := i+1, len(lines)
+		for j := start; j < len(lines); j++ {
+			trimmed := strings.TrimSpace(lines[j])
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				end = j
+				break
+			}
+		}
+		i = end
+
+		block := strings.Join(lines[start:end], "")
+		if !containsString(markdownYAMLLanguages, language) || !manifestMarkerPattern.MatchString(block) {
+			continue
+		}
+		sources = append(sources, offsetSource{name: name, source: NewYAMLSource(name, strings.NewReader(block)), line: start, index: &index})
+	}
+	return MultiSource(sources...)
+}
+
+// NewEmbeddedSource returns a Source over the manifests embedded in a host file, chosen by
+// the file's extension: NewMarkdownSource for .md and .markdown files, and
+// NewTerraformSource for .tf files. Other files are decoded as YAML or JSON, which also
+// covers the List or multi-document output of Docker Compose converters such as kompose.
+func NewEmbeddedSource(name string, data []byte) Source {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".md", ".markdown":
+		return NewMarkdownSource(name, data)
+	case ".tf":
+		return NewTerraformSource(name, data)
+	case ".json":
+		return NewJSONSource(name, data)
+	}
+	return NewYAMLSource(name, bytes.NewReader(data))
+}
@@ -0,0 +1,56 @@
+package k8sconstraints
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RuleEngineError is the rule code of findings for documents whose validation panicked or
+// ran out of time.
+const RuleEngineError = "EngineError"
+
+// ValidateDocumentIsolated runs ValidateDocument in its own goroutine so that one document
+// cannot take down the whole run. A panic is recovered and reported as an EngineError
+// finding, and so is a validation that outlives timeout (when positive) or ctx. Go cannot
+// stop a running goroutine, so a timed-out validation keeps running in the background until
+// it returns; its result is discarded.
+func ValidateDocumentIsolated(ctx context.Context, doc Document, timeout time.Duration) DocumentResult {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	done := make(chan DocumentResult, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- engineErrorResult(doc, fmt.Sprintf("validation panicked: %v", recovered))
+			}
+		}()
+		done <- ValidateDocument(doc)
+	}()
+
+	select {
+	case result := <-done:
+		return result
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded && timeout > 0 {
+			return engineErrorResult(doc, fmt.Sprintf("validation did not finish within %s", timeout))
+		}
+		return engineErrorResult(doc, fmt.Sprintf("validation was interrupted: %v", ctx.Err()))
+	}
+}
+
+// engineErrorResult reports a document whose validation failed inside the engine.
+func engineErrorResult(doc Document, message string) DocumentResult {
+	result := DocumentResult{Index: doc.Index, Line: doc.Line, Path: doc.Path, Object: doc.Object}
+	finding := Finding{Rule: RuleEngineError, Severity: SeverityError, Message: message}
+	if doc.Object != nil {
+		finding.Resource = ResourceRefOf(doc.Object)
+	}
+	finding.Fingerprint = Fingerprint(finding)
+	result.Findings = []Finding{finding}
+	return result
+}
@@ -0,0 +1,89 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateEnum validates that value is one of allowed, which Kubernetes compares case
+// sensitively. The error suggests the allowed value closest to value, e.g.
+// "value 'Recreat' is not supported; must be one of: Recreate, RollingUpdate; did you mean
+// 'Recreate'?", and fixes values that differ from an allowed one only in case.
+func ValidateEnum(value string, allowed ...string) error {
+	if containsString(allowed, value) {
+		return nil
+	}
+	return enumViolation("value", value, allowed)
+}
+
+// validateEnumField validates the value of the enum field at path with ValidateEnum, naming
+// the field by the last element of path, e.g. "restartPolicy 'Alway' is not supported".
+func validateEnumField(path string, value string, allowed []string) error {
+	if containsString(allowed, value) {
+		return nil
+	}
+	name := path[strings.LastIndex(path, ".")+1:]
+	if i := strings.Index(name, "["); i > 0 {
+		name = name[:i]
+	}
+	return WithFieldPath(path, enumViolation(name, value, allowed))
+}
+
+// enumViolation returns the violation for value, which is not one of allowed, describing
+// the value as name.
+func enumViolation(name string, value string, allowed []string) *ConstraintError {
+	message := fmt.Sprintf("%s '%s' is not supported; must be one of: %s", name, value, strings.Join(allowed, ", "))
+	return &ConstraintError{Rule: RuleEnum, BadValue: value, Message: message + enumSuggestion(value, allowed), Fix: enumCaseFix("", allowed, value)}
+}
+
+// enumSuggestion returns "; did you mean '<value>'?" naming the allowed value closest to
+// value, for appending to messages about values outside an enum, or "" when none is close.
+func enumSuggestion(value string, allowed []string) string {
+	if suggestion, ok := closestEnumValue(value, allowed); ok {
+		return fmt.Sprintf("; did you mean '%s'?", suggestion)
+	}
+	return ""
+}
+
+// closestEnumValue returns the allowed value closest to value by case-insensitive
+// Levenshtein distance, provided it is close enough to be a likely typo: at most a third of
+// its length away, and at least one edit for short values. Ties go to the earlier value.
+func closestEnumValue(value string, allowed []string) (string, bool) {
+	if value == "" {
+		return "", false
+	}
+	best, bestDistance := "", -1
+	for _, candidate := range allowed {
+		distance := levenshtein(strings.ToLower(value), strings.ToLower(candidate))
+		if distance > max(1, len(candidate)/3) {
+			continue
+		}
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best, bestDistance >= 0
+}
+
+// levenshtein returns the number of single-rune insertions, deletions, and substitutions
+// turning a into b.
+func levenshtein(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(target)]
+}
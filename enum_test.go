@@ -0,0 +1,75 @@
+package k8sconstraints
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateEnum(t *testing.T) {
+	tests := []struct {
+		value string
+		// finding is part of the expected message; "" expects no error
+		finding string
+	}{
+		{"Recreate", ""},
+		{"Recreat", "value 'Recreat' is not supported; must be one of: Recreate, RollingUpdate; did you mean 'Recreate'?"},
+		{"recreate", "did you mean 'Recreate'?"},
+		{"BlueGreen", "value 'BlueGreen' is not supported; must be one of: Recreate, RollingUpdate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			err := ValidateEnum(tt.value, "Recreate", "RollingUpdate")
+			if tt.finding == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.finding) {
+				t.Errorf("expected an error containing %q, got %v", tt.finding, err)
+			}
+		})
+	}
+}
+
+func TestValidateObjectWorkloadStrategyTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		finding  string
+	}{
+		{"Deployment strategy", `
+apiVersion: apps/v1
+kind: Deployment
+metadata: {name: web}
+spec:
+  selector: {matchLabels: {app: web}}
+  strategy: {type: Recreat}
+  template:
+    metadata: {labels: {app: web}}
+    spec: {containers: [{name: web, image: "nginx:1.27"}]}`,
+			"spec.strategy.type: type 'Recreat' is not supported; must be one of: Recreate, RollingUpdate; did you mean 'Recreate'?"},
+		{"StatefulSet update strategy", `
+apiVersion: apps/v1
+kind: StatefulSet
+metadata: {name: db}
+spec:
+  serviceName: db
+  selector: {matchLabels: {app: db}}
+  updateStrategy: {type: OnDelet}
+  template:
+    metadata: {labels: {app: db}}
+    spec: {containers: [{name: db, image: "postgres:16"}]}`,
+			"spec.updateStrategy.type: type 'OnDelet' is not supported; must be one of: RollingUpdate, OnDelete; did you mean 'OnDelete'?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateObject(decodeTestObject(t, tt.manifest))
+			if err == nil || !strings.Contains(err.Error(), tt.finding) {
+				t.Errorf("expected a finding containing %q, got %v", tt.finding, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// C identifiers, as required of envFrom prefixes
+var cIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateEnvVarName validates an env[].name under the relaxed rules Kubernetes applies
+// since v1.34: any non-empty run of printable ASCII characters other than '='.
+func ValidateEnvVarName(name string) error {
+	if name == "" {
+		return &ConstraintError{Rule: RuleRequired, BadValue: name, Message: "environment variable name cannot be empty"}
+	}
+	for _, c := range name {
+		if c < ' ' || c > '~' || c == '=' {
+			return &ConstraintError{Rule: RuleEnvVarName, BadValue: name, Message: fmt.Sprintf("environment variable name '%s' must consist of printable ASCII characters other than '='", name)}
+		}
+	}
+	return nil
+}
+
+// ValidateCIdentifier validates a C identifier: letters, digits, and '_', not starting with
+// a digit. Kubernetes requires envFrom[].prefix to be one.
+func ValidateCIdentifier(name string) error {
+	if !cIdentifierPattern.MatchString(name) {
+		return &ConstraintError{Rule: RuleCIdentifier, BadValue: name, Message: fmt.Sprintf("'%s' must be a C identifier: letters, digits, or '_', not starting with a digit", name)}
+	}
+	return nil
+}
+
+// validateContainerEnv validates the env[].name and envFrom[].prefix fields of a container.
+// Names listed more than once are accepted by the API server, which keeps the last value,
+// so they are reported as warnings.
+func validateContainerEnv(container map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	env, _ := nestedSlice(container, "env")
+	seen := make(map[string]int)
+	for i, raw := range env {
+		variable, _ := raw.(map[string]interface{})
+		name, _ := nestedString(variable, "name")
+		path := fmt.Sprintf("env[%d].name", i)
+		if name == "" {
+			errs = append(errs, &ConstraintError{FieldPath: path, Rule: RuleRequired, Message: "name is required"})
+			continue
+		}
+		if err := ValidateEnvVarName(name); err != nil {
+			errs = append(errs, WithFieldPath(path, err))
+		}
+		if first, ok := seen[name]; ok {
+			// Removing the overridden definition keeps the container's environment unchanged
+			errs = append(errs, &ConstraintError{FieldPath: path, BadValue: name, Message: fmt.Sprintf("warning: environment variable '%s' is already defined in env[%d] and overrides it", name, first), Fix: []FieldChange{
+				{Op: PatchTest, FieldPath: fmt.Sprintf("env[%d].name", first), Value: name},
+				{Op: PatchRemove, FieldPath: fmt.Sprintf("env[%d]", first)},
+			}})
+			seen[name] = i
+			continue
+		}
+		seen[name] = i
+	}
+
+	envFrom, _ := nestedSlice(container, "envFrom")
+	for i, raw := range envFrom {
+		source, _ := raw.(map[string]interface{})
+		if prefix, _ := nestedString(source, "prefix"); prefix != "" {
+			if err := ValidateCIdentifier(prefix); err != nil {
+				errs = append(errs, WithFieldPath(fmt.Sprintf("envFrom[%d].prefix", i), err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
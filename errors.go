@@ -0,0 +1,223 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"strings"
+)
+
+// Rule codes carried by ConstraintError.Rule for the built-in primitive validators.
+const (
+	RuleMaxLength        = "MaxLength"
+	RuleRequired         = "Required"
+	RuleDNS1123Label     = "DNS1123Label"
+	RuleDNS1123Subdomain = "DNS1123Subdomain"
+	RuleDNS1035Label     = "DNS1035Label"
+	RuleQualifiedName    = "QualifiedName"
+	RuleLabelValue       = "LabelValue"
+	RuleAnnotationValue  = "AnnotationValue"
+	RuleAPIVersion       = "APIVersion"
+	RuleKind             = "Kind"
+	RuleImageReference   = "ImageReference"
+	RuleEnvVarName       = "EnvVarName"
+	RuleCIdentifier      = "CIdentifier"
+	RulePortName         = "PortName"
+	RulePortNumber       = "PortNumber"
+	RuleQuantity         = "Quantity"
+	RuleCronSchedule     = "CronSchedule"
+	RuleTimeZone         = "TimeZone"
+	RuleSelector         = "Selector"
+	RuleDeprecatedAPI    = "DeprecatedAPI"
+	RuleIP               = "IP"
+	RuleCIDR             = "CIDR"
+	RuleEnum             = "Enum"
+)
+
+// Rule codes carried by ConstraintError.Rule for the violations of the built-in kind and pod
+// spec validators that no primitive rule code describes.
+const (
+	RuleGenerateName         = "GenerateName"
+	RuleOwnerReferences      = "OwnerReferences"
+	RulePodSpec              = "PodSpec"
+	RuleAffinity             = "Affinity"
+	RulePodDNS               = "PodDNS"
+	RuleProbe                = "Probe"
+	RuleResourceRequirements = "ResourceRequirements"
+	RuleSecurityContext      = "SecurityContext"
+	RuleVolumeMount          = "VolumeMount"
+	RuleDaemonSet            = "DaemonSet"
+	RuleDeployment           = "Deployment"
+	RuleStatefulSet          = "StatefulSet"
+	RuleJob                  = "Job"
+	RuleCronJob              = "CronJob"
+	RuleHPA                  = "HorizontalPodAutoscaler"
+	RuleService              = "Service"
+	RuleIngress              = "Ingress"
+	RuleNetworkPolicy        = "NetworkPolicy"
+	RuleRBAC                 = "RBAC"
+	RuleSecret               = "Secret"
+	RuleResourceQuota        = "ResourceQuota"
+	RuleLimitRange           = "LimitRange"
+	RuleFlowControl          = "FlowControl"
+	RuleLease                = "Lease"
+	RuleEvent                = "Event"
+)
+
+// ConstraintError describes a single constraint violation. FieldPath locates the offending
+// field (e.g. `metadata.labels["app"]`), Rule names the constraint that failed (e.g.
+// `DNS1123Label`), and BadValue holds the rejected value.
+//
+// Use errors.As to extract the first violation from a validator's error, or
+// ConstraintErrors to get all of them.
+type ConstraintError struct {
+	FieldPath string      `json:"fieldPath,omitempty"`
+	Rule      string      `json:"rule,omitempty"`
+	BadValue  interface{} `json:"badValue,omitempty"`
+	Message   string      `json:"message"`
+	// Fix, when set, is the change that resolves the violation, with field paths relative
+	// to the same object as FieldPath.
+	Fix []FieldChange `json:"fix,omitempty"`
+
+	// cause is the original error when a plain error was converted into a ConstraintError.
+	cause error
+}
+
+// Error renders the violation as "<field path>: <message>".
+func (e *ConstraintError) Error() string {
+	if e.FieldPath == "" {
+		return e.Message
+	}
+	return e.FieldPath + ": " + e.Message
+}
+
+// Unwrap returns the error this violation was converted from, if any, so sentinel errors
+// such as ErrNameAndGenerateName still match with errors.Is.
+func (e *ConstraintError) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether target is a *ConstraintError with the same Rule and, when target sets
+// one, the same FieldPath. This allows checks such as
+// errors.Is(err, &ConstraintError{Rule: RuleDNS1123Label}).
+func (e *ConstraintError) Is(target error) bool {
+	t, ok := target.(*ConstraintError)
+	if !ok || t.Rule == "" {
+		return false
+	}
+	return t.Rule == e.Rule && (t.FieldPath == "" || t.FieldPath == e.FieldPath)
+}
+
+// ErrorList is a list of violations returned by validators that check more than one
+// constraint. Its message joins the individual messages with "; ".
+type ErrorList []error
+
+// Error joins the messages of all errors in the list.
+func (l ErrorList) Error() string {
+	messages := make([]string, len(l))
+	for i, err := range l {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the errors in the list, so errors.Is and errors.As inspect each of them.
+func (l ErrorList) Unwrap() []error {
+	return l
+}
+
+// JoinErrors joins multiple errors into one ErrorList.
+func JoinErrors(errs []error) error {
+	return ErrorList(errs)
+}
+
+// ConstraintErrors flattens err into its individual violations. Errors that are not
+// ConstraintErrors are converted into one with their message and, when they wrap a
+// ConstraintError, its Rule and BadValue.
+func ConstraintErrors(err error) []*ConstraintError {
+	if err == nil {
+		return nil
+	}
+	if list, ok := err.(ErrorList); ok {
+		result := make([]*ConstraintError, 0, len(list))
+		for _, item := range list {
+			result = append(result, ConstraintErrors(item)...)
+		}
+		return result
+	}
+	return []*ConstraintError{asConstraintError(err)}
+}
+
+// WithFieldPath prefixes the field path of every violation in err with path. Paths are
+// joined with '.', except for subscripts such as `["app"]` or `[0]`.
+func WithFieldPath(path string, err error) error {
+	return mapConstraintErrors(err, func(e *ConstraintError) {
+		e.FieldPath = joinFieldPath(path, e.FieldPath)
+		if len(e.Fix) > 0 {
+			fix := make([]FieldChange, len(e.Fix))
+			for i, change := range e.Fix {
+				change.FieldPath = joinFieldPath(path, change.FieldPath)
+				fix[i] = change
+			}
+			e.Fix = fix
+		}
+	})
+}
+
+// withMessagePrefix prefixes the message of every violation in err, keeping their rule
+// codes and bad values.
+func withMessagePrefix(prefix string, err error) error {
+	return mapConstraintErrors(err, func(e *ConstraintError) {
+		e.Message = prefix + e.Message
+	})
+}
+
+// withRule sets the rule code and bad value of every violation in err that has no rule yet.
+func withRule(rule string, badValue interface{}, err error) error {
+	return mapConstraintErrors(err, func(e *ConstraintError) {
+		if e.Rule == "" {
+			e.Rule = rule
+			e.BadValue = badValue
+		}
+	})
+}
+
+// withDefaultRule sets the rule code of every violation in err that has none, such as those
+// built with Invalid, to rule.
+func withDefaultRule(rule string, err error) error {
+	return mapConstraintErrors(err, func(e *ConstraintError) {
+		if e.Rule == "" {
+			e.Rule = rule
+		}
+	})
+}
+
+// mapConstraintErrors returns a copy of err with fn applied to each of its violations.
+func mapConstraintErrors(err error, fn func(*ConstraintError)) error {
+	if err == nil {
+		return nil
+	}
+	if list, ok := err.(ErrorList); ok {
+		mapped := make(ErrorList, len(list))
+		for i, item := range list {
+			mapped[i] = mapConstraintErrors(item, fn)
+		}
+		return mapped
+	}
+	e := asConstraintError(err)
+	fn(e)
+	return e
+}
+
+// asConstraintError returns a copy of err as a ConstraintError.
+func asConstraintError(err error) *ConstraintError {
+	if e, ok := err.(*ConstraintError); ok {
+		copied := *e
+		return &copied
+	}
+	converted := &ConstraintError{Message: err.Error(), cause: err}
+	var inner *ConstraintError
+	if errors.As(err, &inner) {
+		converted.Rule = inner.Rule
+		converted.BadValue = inner.BadValue
+	}
+	return converted
+}
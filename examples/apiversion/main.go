@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+)
+
+func main() {
+	// Test cases
+	testCases := []string{
+		"v1",
+		"apps/v1",
+		"apps/v1beta1",
+		"networking.k8s.io/v1",
+		"",
+		"Apps/v1",   // Valid: API groups may contain uppercase characters
+		"apps/v1.1", // Invalid due to period in the version
+		"apps//v1",  // Invalid due to double slashes
+		"this-is-a-very-long-api-group-name-that-exceeds-the-limit/v1",
+	}
+
+	for _, tc := range testCases {
+		fmt.Printf("Testing apiVersion: %s\n", tc)
+		if err := k8sconstraints.ValidateApiVersion(tc); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		} else {
+			fmt.Println("Valid!")
+		}
+	}
+}
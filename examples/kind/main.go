@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+)
+
+func main() {
+	// Test cases for ValidateKind
+	testCases := []string{
+		"Pod",              // Valid
+		"Service",          // Valid
+		"deployment",       // Invalid: does not start with uppercase
+		"123Pod",           // Invalid: starts with a number
+		"MyCustomResource", // Valid
+		"",                 // Invalid: empty
+		"thisisaverylongkindnamethatexceedsthemaxlengthallowedbyvalidation", // Invalid: too long
+		"Pod-Service", // Invalid: contains non-alphanumeric characters
+	}
+
+	for _, tc := range testCases {
+		fmt.Printf("Testing kind: %s\n", tc)
+		if err := k8sconstraints.ValidateKind(tc); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		} else {
+			fmt.Println("Valid!")
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+)
+
+func main() {
+	// Test cases for ValidateMetadataAnnotations
+	testAnnotations := map[string]string{
+		"example.com/description": "This is a valid annotation value", // Valid
+		"example.com/role":        "",                                 // Valid: empty value
+		"Example.com/name":        "Uppercase prefix, should fail",    // Invalid: uppercase in key prefix
+		"invalid key":             "value",                            // Invalid: space in key
+		"example.com/utf8":        string([]byte{0xff, 0xfe}),         // Invalid: non-UTF-8 value
+		"example.com/another":     "Another valid value",              // Valid
+	}
+
+	if err := k8sconstraints.ValidateMetadataAnnotations(testAnnotations); err != nil {
+		fmt.Printf("Errors: %v\n", err)
+	} else {
+		fmt.Println("All annotations are valid!")
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+)
+
+func main() {
+	// Test cases for ValidateMetadataLabels
+	testLabels := map[string]string{
+		"app.kubernetes.io/name":       "my-app",      // Valid
+		"app.kubernetes.io/role":       "frontend",    // Valid
+		"app.kubernetes.io/role/extra": "invalid",     // Invalid: key contains extra `/`
+		"App.kubernetes.io/Name":       "My-App",      // Invalid: uppercase in key prefix
+		"app.kubernetes.io/part-of":    "",            // Valid: empty value
+		"invalid key":                  "value",       // Invalid: space in key
+		"example.com/123":              "valid-value", // Valid: numeric in key and value
+	}
+
+	if err := k8sconstraints.ValidateMetadataLabels(testLabels); err != nil {
+		fmt.Printf("Errors: %v\n", err)
+	} else {
+		fmt.Println("All labels are valid!")
+	}
+}
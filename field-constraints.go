@@ -0,0 +1,163 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CheckFieldConstraints is the ID of the check built by FieldConstraintsCheck.
+const CheckFieldConstraints = "FieldConstraints"
+
+// FieldConstraint pairs a JSONPath with a constraint evaluated against every value it selects.
+// All constraint fields are optional; only the ones set are checked.
+type FieldConstraint struct {
+	Path      string   `yaml:"path" json:"path"`
+	Pattern   string   `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	MaxLength *int     `yaml:"maxLength,omitempty" json:"maxLength,omitempty"`
+	Enum      []string `yaml:"enum,omitempty" json:"enum,omitempty"`
+	Minimum   *float64 `yaml:"minimum,omitempty" json:"minimum,omitempty"`
+	Maximum   *float64 `yaml:"maximum,omitempty" json:"maximum,omitempty"`
+	// Kinds restricts the constraint to the given kinds. When empty it applies to every document.
+	Kinds []string `yaml:"kinds,omitempty" json:"kinds,omitempty"`
+}
+
+// FieldConstraintsCheck returns an opt-in check, not run by ValidateObject, that evaluates
+// constraints against every object; see ValidateFieldConstraints. The constraints must
+// have passed ValidateFieldConstraintDefinition. Run it with RunChecks or pass it to the
+// linter's Checks option.
+func FieldConstraintsCheck(constraints []FieldConstraint) Check {
+	return Check{ID: CheckFieldConstraints, Validate: func(obj map[string]interface{}) error {
+		return ValidateFieldConstraints(obj, constraints)
+	}}
+}
+
+// ValidateFieldConstraintDefinition checks that a constraint's path and pattern compile and
+// that its numeric bounds are consistent.
+func ValidateFieldConstraintDefinition(constraint FieldConstraint) error {
+	errs := make([]error, 0)
+
+	if _, err := CompileJSONPath(constraint.Path); err != nil {
+		errs = append(errs, err)
+	}
+	if constraint.Pattern != "" {
+		if _, err := regexp.Compile(constraint.Pattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid pattern: %v", err))
+		}
+	}
+	if constraint.MaxLength != nil && *constraint.MaxLength < 0 {
+		errs = append(errs, errors.New("maxLength must not be negative"))
+	}
+	if constraint.Minimum != nil && constraint.Maximum != nil && *constraint.Minimum > *constraint.Maximum {
+		errs = append(errs, fmt.Errorf("minimum %v is greater than maximum %v", *constraint.Minimum, *constraint.Maximum))
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// ValidateFieldConstraints evaluates every constraint against obj. Paths that select
+// nothing are not an error.
+func ValidateFieldConstraints(obj map[string]interface{}, constraints []FieldConstraint) error {
+	errs := make([]error, 0)
+
+	kind, _ := nestedString(obj, "kind")
+	for _, constraint := range constraints {
+		if len(constraint.Kinds) > 0 && !containsString(constraint.Kinds, kind) {
+			continue
+		}
+
+		path, err := CompileJSONPath(constraint.Path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, match := range path.Find(obj) {
+			if err := validateFieldConstraintValue(constraint, match.Value); err != nil {
+				errs = append(errs, WithFieldPath(match.Path, withRule(CheckFieldConstraints, match.Value, err)))
+			}
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// validateFieldConstraintValue checks a single selected value against constraint.
+func validateFieldConstraintValue(constraint FieldConstraint, value interface{}) error {
+	errs := make([]error, 0)
+
+	// String constraints apply to the string form of scalar values
+	s, isScalar := scalarString(value)
+	if !isScalar && (constraint.Pattern != "" || constraint.MaxLength != nil || len(constraint.Enum) > 0) {
+		return fmt.Errorf("expected a scalar value for constraint on '%s'", constraint.Path)
+	}
+	if err := validateValueConstraints(constraint.Pattern, constraint.Enum, s); isScalar && err != nil {
+		errs = append(errs, err)
+	}
+	if constraint.MaxLength != nil {
+		if err := ValidateLength(s, *constraint.MaxLength); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Numeric range constraints
+	if constraint.Minimum != nil || constraint.Maximum != nil {
+		n, ok := toFloat64(value)
+		if !ok {
+			errs = append(errs, fmt.Errorf("value '%v' is not a number", value))
+		} else {
+			if constraint.Minimum != nil && n < *constraint.Minimum {
+				errs = append(errs, fmt.Errorf("value %v is less than minimum %v", n, *constraint.Minimum))
+			}
+			if constraint.Maximum != nil && n > *constraint.Maximum {
+				errs = append(errs, fmt.Errorf("value %v is greater than maximum %v", n, *constraint.Maximum))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// scalarString returns the string form of a decoded scalar value.
+func scalarString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case bool, int, int64, float64:
+		return fmt.Sprint(v), true
+	case nil:
+		return "", true
+	}
+	return "", false
+}
+
+// toFloat64 converts a decoded number, or a string holding one, into a float64.
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
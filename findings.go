@@ -0,0 +1,256 @@
+package k8sconstraints
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResourceRef identifies the object a finding was reported against.
+type ResourceRef struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name,omitempty"`
+}
+
+// ResourceRefOf returns the reference of obj, taken from its type and object metadata.
+// generateName is used when the object has no name.
+func ResourceRefOf(obj map[string]interface{}) ResourceRef {
+	ref := ResourceRef{}
+	ref.APIVersion, _ = nestedString(obj, "apiVersion")
+	ref.Kind, _ = nestedString(obj, "kind")
+	ref.Namespace, _ = nestedString(obj, "metadata", "namespace")
+	ref.Name, _ = nestedString(obj, "metadata", "name")
+	if ref.Name == "" {
+		ref.Name, _ = nestedString(obj, "metadata", "generateName")
+	}
+	return ref
+}
+
+// Group returns the API group of the resource, or "" for the core group.
+func (r ResourceRef) Group() string {
+	if i := strings.Index(r.APIVersion, "/"); i >= 0 {
+		return r.APIVersion[:i]
+	}
+	return ""
+}
+
+// String renders the reference as "Kind namespace/name", omitting empty parts.
+func (r ResourceRef) String() string {
+	name := r.Name
+	if r.Namespace != "" {
+		name = r.Namespace + "/" + name
+	}
+	return strings.TrimSpace(r.Kind + " " + name)
+}
+
+// Severity classifies how serious a finding is.
+type Severity string
+
+// Severities of findings. Validators mark advisory findings by starting their message with
+// "warning: "; everything else is an error. Info findings only arise from severity
+// overrides; see ApplySeverityOverrides.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// severityOf returns the severity implied by a violation message.
+func severityOf(message string) Severity {
+	if strings.HasPrefix(message, "warning: ") {
+		return SeverityWarning
+	}
+	return SeverityError
+}
+
+// Finding is a single violation reported against a resource. Fingerprint identifies the
+// finding across runs and commits; see Fingerprint. File, Document, and Line locate the
+// document the finding was reported in, when it was read from a file.
+type Finding struct {
+	File        string      `json:"file,omitempty"`
+	Document    int         `json:"document"`
+	Line        int         `json:"line,omitempty"`
+	Rule        string      `json:"rule,omitempty"`
+	Severity    Severity    `json:"severity"`
+	Resource    ResourceRef `json:"resource"`
+	FieldPath   string      `json:"fieldPath,omitempty"`
+	BadValue    interface{} `json:"badValue,omitempty"`
+	Message     string      `json:"message"`
+	Fingerprint string      `json:"fingerprint"`
+	// Fix, when the finding can be fixed automatically, is the RFC 6902 JSON Patch that
+	// fixes it, addressing the resource the finding was reported against.
+	Fix []PatchOperation `json:"fix,omitempty"`
+
+	// Related holds follow-on findings caused by the same root cause; see GroupFindings.
+	Related []Finding `json:"related,omitempty"`
+}
+
+// FindingsFromError converts the violations in err, as returned by a validator run against
+// obj, into fingerprinted findings.
+func FindingsFromError(obj map[string]interface{}, err error) []Finding {
+	ref := ResourceRefOf(obj)
+	violations := ConstraintErrors(err)
+
+	findings := make([]Finding, 0, len(violations))
+	for _, violation := range violations {
+		finding := Finding{
+			Rule:      violation.Rule,
+			Severity:  severityOf(violation.Message),
+			Resource:  ref,
+			FieldPath: violation.FieldPath,
+			BadValue:  violation.BadValue,
+			Message:   violation.Message,
+		}
+		if len(violation.Fix) > 0 {
+			// A fix whose paths cannot be expressed as JSON Pointers is left out
+			finding.Fix, _ = JSONPatch(violation.Fix)
+		}
+		finding.Fingerprint = Fingerprint(finding)
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// Fingerprint returns a stable hash of a finding's rule, resource, field path, and
+// normalized bad value. The file location is left out so that moving a manifest between
+// files keeps its fingerprints, the API version is reduced to its group so that moving a
+// resource to a newer version does too, and the message is ignored so rewording a message
+// does not change them either. Findings without a rule code fall back to hashing the message.
+func Fingerprint(f Finding) string {
+	parts := []string{
+		f.Rule,
+		f.Resource.Group(),
+		f.Resource.Kind,
+		f.Resource.Namespace,
+		f.Resource.Name,
+		f.FieldPath,
+		normalizeFindingValue(f.BadValue),
+	}
+	if f.Rule == "" {
+		parts = append(parts, f.Message)
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// normalizeFindingValue renders a bad value canonically: JSON with sorted map keys, and
+// whole numbers rendered the same whether they were decoded as int or float64.
+func normalizeFindingValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if n, ok := toInt64(value); ok {
+		return fmt.Sprint(n)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// DeduplicateFindings removes findings whose fingerprint was already seen, keeping the first
+// occurrence and the original order. Findings without a fingerprint get one computed.
+func DeduplicateFindings(findings []Finding) []Finding {
+	seen := make(map[string]bool)
+	unique := make([]Finding, 0, len(findings))
+	for _, finding := range findings {
+		if finding.Fingerprint == "" {
+			finding.Fingerprint = Fingerprint(finding)
+		}
+		if seen[finding.Fingerprint] {
+			continue
+		}
+		seen[finding.Fingerprint] = true
+		unique = append(unique, finding)
+	}
+	return unique
+}
+
+// GroupFindings collapses findings that share a root cause: findings reported against the
+// same document, resource, field path, and bad value are merged into the first of them,
+// with the others attached to it as Related. For example, an empty name that fails the
+// length, format, and subdomain checks becomes a single finding. Order is preserved and
+// findings without a field path or bad value are never grouped.
+func GroupFindings(findings []Finding) []Finding {
+	grouped := make([]Finding, 0, len(findings))
+	primaries := make(map[string]int)
+	for _, finding := range findings {
+		if finding.FieldPath == "" && finding.BadValue == nil {
+			grouped = append(grouped, finding)
+			continue
+		}
+
+		key := strings.Join([]string{
+			finding.File,
+			fmt.Sprint(finding.Document),
+			finding.Resource.String(),
+			finding.Resource.APIVersion,
+			finding.FieldPath,
+			normalizeFindingValue(finding.BadValue),
+		}, "\x00")
+		if i, ok := primaries[key]; ok {
+			grouped[i].Related = append(grouped[i].Related, finding)
+			continue
+		}
+		primaries[key] = len(grouped)
+		grouped = append(grouped, finding)
+	}
+	return grouped
+}
+
+// CompareFindings orders findings by file, document index, field path, and rule code, then
+// by message and fingerprint so that the order is total. It returns -1, 0, or 1.
+func CompareFindings(a, b Finding) int {
+	switch {
+	case a.File != b.File:
+		return strings.Compare(a.File, b.File)
+	case a.Document != b.Document:
+		if a.Document < b.Document {
+			return -1
+		}
+		return 1
+	case a.FieldPath != b.FieldPath:
+		return strings.Compare(a.FieldPath, b.FieldPath)
+	case a.Rule != b.Rule:
+		return strings.Compare(a.Rule, b.Rule)
+	case a.Message != b.Message:
+		return strings.Compare(a.Message, b.Message)
+	}
+	return strings.Compare(a.Fingerprint, b.Fingerprint)
+}
+
+// SortFindings sorts findings in place with CompareFindings, so reports come out in the
+// same order regardless of map iteration or goroutine scheduling. Related findings are
+// sorted too.
+func SortFindings(findings []Finding) {
+	for i := range findings {
+		if len(findings[i].Related) > 0 {
+			SortFindings(findings[i].Related)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return CompareFindings(findings[i], findings[j]) < 0
+	})
+}
+
+// SortConstraintErrors sorts violations in place by field path, rule code, and message.
+// Use it with ConstraintErrors to compare a validator's output against golden files.
+func SortConstraintErrors(errs []*ConstraintError) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		a, b := errs[i], errs[j]
+		if a.FieldPath != b.FieldPath {
+			return a.FieldPath < b.FieldPath
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Message < b.Message
+	})
+}
@@ -0,0 +1,357 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Bounds of FlowSchema spec.matchingPrecedence.
+const (
+	minFlowSchemaMatchingPrecedence = 1
+	maxFlowSchemaMatchingPrecedence = 10000
+)
+
+var (
+	// flowDistinguisherMethods lists the valid FlowSchema distinguisherMethod types.
+	flowDistinguisherMethods = []string{"ByUser", "ByNamespace"}
+	// flowSubjectKinds lists the valid FlowSchema subject kinds.
+	flowSubjectKinds = []string{"User", "Group", "ServiceAccount"}
+	// priorityLevelTypes lists the valid PriorityLevelConfiguration types.
+	priorityLevelTypes = []string{"Exempt", "Limited"}
+	// limitResponseTypes lists the valid limitResponse types of a limited priority level.
+	limitResponseTypes = []string{"Queue", "Reject"}
+)
+
+// builtinPriorityLevels are the PriorityLevelConfigurations every API server creates, which
+// FlowSchemas may reference without declaring them.
+var builtinPriorityLevels = []string{
+	"exempt",
+	"system",
+	"node-high",
+	"leader-election",
+	"workload-high",
+	"workload-low",
+	"global-default",
+	"catch-all",
+}
+
+// isFlowControlAPIVersion reports whether apiVersion belongs to flowcontrol.apiserver.k8s.io.
+func isFlowControlAPIVersion(apiVersion string) bool {
+	return strings.HasPrefix(apiVersion, "flowcontrol.apiserver.k8s.io/")
+}
+
+// ValidateFlowSchema validates the spec of a flowcontrol.apiserver.k8s.io FlowSchema.
+func ValidateFlowSchema(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	specPath := NewPath("spec")
+	spec, ok := nestedMap(obj, "spec")
+	if !ok {
+		return Required(specPath, "spec is required")
+	}
+
+	// Referenced priority level
+	levelPath := specPath.Child("priorityLevelConfiguration", "name")
+	if name, _ := nestedString(spec, "priorityLevelConfiguration", "name"); name == "" {
+		errs = append(errs, Required(levelPath, "priority level name is required"))
+	} else if err := ValidateDNSSubdomain(name); err != nil {
+		errs = append(errs, WithFieldPath(levelPath.String(), err))
+	}
+
+	// Matching precedence
+	if value, ok := nestedField(spec, "matchingPrecedence"); ok {
+		n, ok := toInt64(value)
+		if !ok || n < minFlowSchemaMatchingPrecedence || n > maxFlowSchemaMatchingPrecedence {
+			errs = append(errs, Invalid(specPath.Child("matchingPrecedence"), value, fmt.Sprintf("must be an integer between %d and %d inclusive", minFlowSchemaMatchingPrecedence, maxFlowSchemaMatchingPrecedence)))
+		}
+	}
+
+	// Distinguisher method
+	if method, ok := nestedString(spec, "distinguisherMethod", "type"); ok {
+		if err := validateEnumField(specPath.Child("distinguisherMethod", "type").String(), method, flowDistinguisherMethods); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Rules
+	rules, _ := nestedSlice(spec, "rules")
+	for i, raw := range rules {
+		rulePath := specPath.Child("rules").Index(i)
+		rule, ok := raw.(map[string]interface{})
+		if !ok {
+			errs = append(errs, Invalid(rulePath, raw, "must be an object"))
+			continue
+		}
+		if err := validateFlowSchemaRule(rulePath, rule); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleFlowControl, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validateFlowSchemaRule validates the subjects and resource rules of the FlowSchema rule at
+// path.
+func validateFlowSchemaRule(path *Path, rule map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	subjects, _ := nestedSlice(rule, "subjects")
+	if len(subjects) == 0 {
+		errs = append(errs, Required(path.Child("subjects"), "subjects must contain at least one subject"))
+	}
+	for i, raw := range subjects {
+		subjectPath := path.Child("subjects").Index(i)
+		subject, ok := raw.(map[string]interface{})
+		if !ok {
+			errs = append(errs, Invalid(subjectPath, raw, "must be an object"))
+			continue
+		}
+		if err := ValidateFlowSubject(subject); err != nil {
+			errs = append(errs, WithFieldPath(subjectPath.String(), err))
+		}
+	}
+
+	resourceRules, _ := nestedSlice(rule, "resourceRules")
+	nonResourceRules, _ := nestedSlice(rule, "nonResourceRules")
+	if len(resourceRules) == 0 && len(nonResourceRules) == 0 {
+		errs = append(errs, Required(path, "at least one of resourceRules or nonResourceRules is required"))
+	}
+	for i, raw := range resourceRules {
+		rulePath := path.Child("resourceRules").Index(i)
+		resourceRule, _ := raw.(map[string]interface{})
+		for _, field := range []string{"verbs", "apiGroups", "resources"} {
+			if values, _ := nestedSlice(resourceRule, field); len(values) == 0 {
+				errs = append(errs, Required(rulePath.Child(field), fmt.Sprintf("%s must not be empty", field)))
+			}
+		}
+		clusterScope, _ := nestedBool(resourceRule, "clusterScope")
+		if namespaces, _ := nestedSlice(resourceRule, "namespaces"); len(namespaces) == 0 && !clusterScope {
+			errs = append(errs, Required(rulePath, "at least one of clusterScope or namespaces must be set"))
+		}
+	}
+	for i, raw := range nonResourceRules {
+		rulePath := path.Child("nonResourceRules").Index(i)
+		nonResourceRule, _ := raw.(map[string]interface{})
+		for _, field := range []string{"verbs", "nonResourceURLs"} {
+			if values, _ := nestedSlice(nonResourceRule, field); len(values) == 0 {
+				errs = append(errs, Required(rulePath.Child(field), fmt.Sprintf("%s must not be empty", field)))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// ValidateFlowSubject validates a FlowSchema subject: the kind enum and the matching
+// user, group, or serviceAccount block.
+func ValidateFlowSubject(subject map[string]interface{}) error {
+	kind, _ := nestedString(subject, "kind")
+	if err := validateEnumField("kind", kind, flowSubjectKinds); err != nil {
+		return err
+	}
+
+	// Exactly the block matching the kind must be set
+	blocks := map[string]string{"User": "user", "Group": "group", "ServiceAccount": "serviceAccount"}
+	for _, otherKind := range sortedKeys(blocks) {
+		field := blocks[otherKind]
+		if _, ok := nestedField(subject, field); ok && otherKind != kind {
+			return Invalid(NewPath(field), nil, fmt.Sprintf("%s must not be set when kind is '%s'", field, kind))
+		}
+	}
+
+	field := NewPath(blocks[kind])
+	if name, _ := nestedString(subject, blocks[kind], "name"); name == "" {
+		return Required(field.Child("name"), "name is required")
+	}
+	if kind == "ServiceAccount" {
+		namespacePath := field.Child("namespace")
+		namespace, _ := nestedString(subject, blocks[kind], "namespace")
+		if namespace == "" {
+			return Required(namespacePath, "namespace is required")
+		}
+		if namespace != "*" {
+			if err := ValidateDNSLabel(namespace); err != nil {
+				return WithFieldPath(namespacePath.String(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidatePriorityLevelConfiguration validates the spec of a flowcontrol.apiserver.k8s.io
+// PriorityLevelConfiguration.
+func ValidatePriorityLevelConfiguration(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	specPath := NewPath("spec")
+	spec, ok := nestedMap(obj, "spec")
+	if !ok {
+		return Required(specPath, "spec is required")
+	}
+
+	levelType, _ := nestedString(spec, "type")
+	if err := validateEnumField(specPath.Child("type").String(), levelType, priorityLevelTypes); err != nil {
+		errs = append(errs, err)
+	}
+
+	limitedPath := specPath.Child("limited")
+	limited, hasLimited := nestedMap(spec, "limited")
+	_, hasExempt := nestedMap(spec, "exempt")
+	switch {
+	case levelType == "Limited" && !hasLimited:
+		errs = append(errs, Required(limitedPath, "limited is required when type is 'Limited'"))
+	case levelType != "Limited" && hasLimited:
+		errs = append(errs, Invalid(limitedPath, nil, "limited must not be set when type is not 'Limited'"))
+	}
+	if levelType == "Limited" && hasExempt {
+		errs = append(errs, Invalid(specPath.Child("exempt"), nil, "exempt must not be set when type is 'Limited'"))
+	}
+
+	if hasLimited {
+		if err := validateLimitedPriorityLevel(limitedPath, limited); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleFlowControl, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validateLimitedPriorityLevel validates concurrency shares, lending limits, and the
+// limitResponse of the limited priority level at path.
+func validateLimitedPriorityLevel(path *Path, limited map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	nonNegative := func(field string) (int64, bool) {
+		value, ok := nestedField(limited, field)
+		if !ok {
+			return 0, false
+		}
+		n, ok := toInt64(value)
+		if !ok || n < 0 {
+			errs = append(errs, Invalid(path.Child(field), value, "must be a non-negative integer"))
+			return 0, false
+		}
+		return n, true
+	}
+
+	nonNegative("nominalConcurrencyShares")
+	nonNegative("assuredConcurrencyShares")
+	nonNegative("borrowingLimitPercent")
+	if lendable, ok := nonNegative("lendablePercent"); ok && lendable > 100 {
+		errs = append(errs, Invalid(path.Child("lendablePercent"), lendable, "must be between 0 and 100"))
+	}
+
+	responsePath := path.Child("limitResponse")
+	response, ok := nestedMap(limited, "limitResponse")
+	if ok {
+		responseType, _ := nestedString(response, "type")
+		queuing, hasQueuing := nestedMap(response, "queuing")
+		switch {
+		case !containsString(limitResponseTypes, responseType):
+			errs = append(errs, validateEnumField(responsePath.Child("type").String(), responseType, limitResponseTypes))
+		case responseType == "Queue" && !hasQueuing:
+			errs = append(errs, Required(responsePath.Child("queuing"), "queuing is required when type is 'Queue'"))
+		case responseType == "Reject" && hasQueuing:
+			errs = append(errs, Invalid(responsePath.Child("queuing"), nil, "queuing must not be set when type is 'Reject'"))
+		}
+		if hasQueuing {
+			if err := validateQueuingConfiguration(responsePath.Child("queuing"), queuing); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// validateQueuingConfiguration checks that queues, handSize, and queueLengthLimit of the
+// queuing configuration at path are positive and that the hand fits in the deck.
+func validateQueuingConfiguration(path *Path, queuing map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	values := make(map[string]int64)
+	for _, field := range []string{"queues", "handSize", "queueLengthLimit"} {
+		value, ok := nestedField(queuing, field)
+		if !ok {
+			continue
+		}
+		n, ok := toInt64(value)
+		if !ok || n <= 0 {
+			errs = append(errs, Invalid(path.Child(field), value, "must be a positive integer"))
+			continue
+		}
+		values[field] = n
+	}
+
+	queues, hasQueues := values["queues"]
+	handSize, hasHandSize := values["handSize"]
+	if hasQueues && hasHandSize && handSize > queues {
+		errs = append(errs, Invalid(path.Child("handSize"), handSize, fmt.Sprintf("handSize %d must not be greater than queues %d", handSize, queues)))
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// CheckFlowControlReferences is the ID of the check built by FlowControlReferencesCheck.
+const CheckFlowControlReferences = "FlowControlReferences"
+
+// FlowControlReferencesCheck returns a bundle check that verifies that every FlowSchema
+// references a PriorityLevelConfiguration among objects or built into the API server.
+func FlowControlReferencesCheck(objects []map[string]interface{}) Check {
+	declared := make(map[string]bool)
+	for _, name := range builtinPriorityLevels {
+		declared[name] = true
+	}
+	for _, obj := range objects {
+		apiVersion, _ := nestedString(obj, "apiVersion")
+		kind, _ := nestedString(obj, "kind")
+		if isFlowControlAPIVersion(apiVersion) && kind == "PriorityLevelConfiguration" {
+			name, _ := nestedString(obj, "metadata", "name")
+			declared[name] = true
+		}
+	}
+
+	return Check{ID: CheckFlowControlReferences, Kinds: []string{"FlowSchema.flowcontrol.apiserver.k8s.io"}, Validate: func(obj map[string]interface{}) error {
+		apiVersion, _ := nestedString(obj, "apiVersion")
+		if !isFlowControlAPIVersion(apiVersion) {
+			return nil
+		}
+		name, _ := nestedString(obj, "metadata", "name")
+		level, _ := nestedString(obj, "spec", "priorityLevelConfiguration", "name")
+		if level != "" && !declared[level] {
+			path := NewPath("spec", "priorityLevelConfiguration", "name")
+			message := fmt.Sprintf("FlowSchema '%s' references PriorityLevelConfiguration '%s', which is not defined in the bundle", name, level)
+			return &ConstraintError{FieldPath: path.String(), Rule: CheckFlowControlReferences, BadValue: level, Message: message}
+		}
+		return nil
+	}}
+}
+
+// ValidateFlowControlReferences runs FlowControlReferencesCheck against every object of a
+// bundle.
+func ValidateFlowControlReferences(objects []map[string]interface{}) error {
+	return RunBundleCheck(objects, FlowControlReferencesCheck)
+}
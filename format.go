@@ -0,0 +1,125 @@
+package k8sconstraints
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// canonicalTopLevelKeys are the object fields FormatYAML puts first, in this order. Other
+// top-level fields follow alphabetically, with status last.
+var canonicalTopLevelKeys = []string{"apiVersion", "kind", "metadata", "spec"}
+
+// canonicalMetadataKeys are the metadata fields FormatYAML puts first, in this order.
+var canonicalMetadataKeys = []string{"name", "generateName", "namespace", "labels", "annotations"}
+
+// FormatYAML rewrites the multi-document YAML in data in canonical form: apiVersion, kind,
+// metadata, and spec first and status last at the top level; name, generateName,
+// namespace, labels, and annotations first within metadata; name first and the other keys
+// sorted alphabetically in every other mapping; block style with two-space indentation
+// throughout. Comments and scalar styles, such as literal blocks, are preserved, and empty
+// documents are dropped. Formatting is idempotent.
+func FormatYAML(data []byte) ([]byte, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid YAML: %v", err)
+		}
+		if isEmptyYAMLDocument(&node) {
+			continue
+		}
+		canonicalizeYAMLNode(node.Content[0], canonicalTopLevelKeys, true)
+		if err := encoder.Encode(&node); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// canonicalizeYAMLNode sorts the keys of node and its descendants in place. first lists
+// the keys that lead node, if it is a mapping; object marks Kubernetes objects, whose
+// metadata gets its own key order and whose status goes last.
+func canonicalizeYAMLNode(node *yaml.Node, first []string, object bool) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		node.Style &^= yaml.FlowStyle
+		sortYAMLMapping(node, first, object)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			switch {
+			case object && key.Value == "metadata":
+				canonicalizeYAMLNode(value, canonicalMetadataKeys, false)
+			case object && key.Value == "items" && value.Kind == yaml.SequenceNode:
+				// The items of a List are objects themselves
+				value.Style &^= yaml.FlowStyle
+				for _, item := range value.Content {
+					canonicalizeYAMLNode(item, canonicalTopLevelKeys, true)
+				}
+			case isEmbeddedObject(key.Value):
+				canonicalizeYAMLNode(value, canonicalTopLevelKeys, true)
+			default:
+				canonicalizeYAMLNode(value, []string{"name"}, false)
+			}
+		}
+	case yaml.SequenceNode:
+		node.Style &^= yaml.FlowStyle
+		for _, item := range node.Content {
+			canonicalizeYAMLNode(item, []string{"name"}, false)
+		}
+	}
+}
+
+// isEmbeddedObject reports whether a field holds an object with its own metadata, such as
+// the pod template of a workload.
+func isEmbeddedObject(field string) bool {
+	return field == "template" || field == "jobTemplate"
+}
+
+// sortYAMLMapping orders the key/value pairs of a mapping node: the keys in first, in that
+// order, then the others alphabetically, with status last when object is set.
+func sortYAMLMapping(node *yaml.Node, first []string, object bool) {
+	type pair struct{ key, value *yaml.Node }
+	pairs := make([]pair, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, pair{node.Content[i], node.Content[i+1]})
+	}
+
+	rank := func(key string) int {
+		for i, k := range first {
+			if k == key {
+				return i
+			}
+		}
+		if object && key == "status" {
+			return len(first) + 1
+		}
+		return len(first)
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		ri, rj := rank(pairs[i].key.Value), rank(pairs[j].key.Value)
+		if ri != rj {
+			return ri < rj
+		}
+		return pairs[i].key.Value < pairs[j].key.Value
+	})
+
+	for i, p := range pairs {
+		node.Content[2*i], node.Content[2*i+1] = p.key, p.value
+	}
+}
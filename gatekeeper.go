@@ -0,0 +1,318 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Gatekeeper API versions of the exported objects.
+const (
+	gatekeeperTemplateAPIVersion   = "templates.gatekeeper.sh/v1"
+	gatekeeperConstraintAPIVersion = "constraints.gatekeeper.sh/v1beta1"
+	gatekeeperTarget               = "admission.k8s.gatekeeper.sh"
+)
+
+// GatekeeperPolicy selects the rules GatekeeperObjects exports. The metadata.name format
+// rules are always exported; required labels and the registry allowlist are exported when
+// set, as they are only checked when enabled.
+type GatekeeperPolicy struct {
+	// RequiredLabels lists the labels every workload and Namespace must carry; see
+	// ValidateRequiredLabels.
+	RequiredLabels []string
+	// AllowedRegistries lists the registries container images may be pulled from; see
+	// ValidateAllowedRegistries.
+	AllowedRegistries []string
+}
+
+// gatekeeperRule is a ConstraintTemplate and the Rego that implements it.
+type gatekeeperRule struct {
+	// kind is the kind of the template's constraints.
+	kind string
+	// parameters is the OpenAPI schema of the constraint parameters.
+	parameters map[string]interface{}
+	rego       string
+}
+
+// gatekeeperRequiredLabels mirrors ValidateRequiredLabels.
+var gatekeeperRequiredLabels = gatekeeperRule{
+	kind: "K8sConstraintsRequiredLabels",
+	parameters: map[string]interface{}{
+		"labels": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+	},
+	rego: `package k8sconstraintsrequiredlabels
+
+violation[{"msg": msg}] {
+  label := input.parameters.labels[_]
+  not has_label(label)
+  msg := sprintf("metadata.labels: missing required label '%v'", [label])
+}
+
+has_label(label) {
+  _ = input.review.object.metadata.labels[label]
+}
+`,
+}
+
+// gatekeeperAllowedRegistries mirrors ValidateAllowedRegistries, including its defaulting
+// of images without a registry host to docker.io and of official images to library/.
+var gatekeeperAllowedRegistries = gatekeeperRule{
+	kind: "K8sConstraintsAllowedRegistries",
+	parameters: map[string]interface{}{
+		"registries": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+	},
+	rego: `package k8sconstraintsallowedregistries
+
+violation[{"msg": msg}] {
+  spec := pod_spec
+  field := ["containers", "initContainers", "ephemeralContainers"][_]
+  container := spec[field][_]
+  name := normalized(image_name(container.image))
+  not allowed(name)
+  msg := sprintf("image '%v' is pulled from registry '%v', which is not allowed; must be one of: %v", [container.image, split(name, "/")[0], concat(", ", input.parameters.registries)])
+}
+
+pod_spec := input.review.object.spec {
+  input.review.object.kind == "Pod"
+}
+
+pod_spec := input.review.object.spec.template.spec {
+  input.review.object.kind != "Pod"
+  input.review.object.kind != "CronJob"
+}
+
+pod_spec := input.review.object.spec.jobTemplate.spec.template.spec {
+  input.review.object.kind == "CronJob"
+}
+
+# image_name drops the digest and the tag of an image reference
+image_name(image) := name {
+  parts := split(split(image, "@")[0], "/")
+  last := count(parts) - 1
+  name := concat("/", array.concat(array.slice(parts, 0, last), [split(parts[last], ":")[0]]))
+}
+
+has_registry(name) {
+  parts := split(name, "/")
+  count(parts) > 1
+  is_host(parts[0])
+}
+
+is_host(s) {
+  contains(s, ".")
+}
+
+is_host(s) {
+  contains(s, ":")
+}
+
+is_host(s) {
+  s == "localhost"
+}
+
+normalized(name) := name {
+  has_registry(name)
+}
+
+normalized(name) := concat("", ["docker.io/library/", name]) {
+  not has_registry(name)
+  not contains(name, "/")
+}
+
+normalized(name) := concat("", ["docker.io/", name]) {
+  not has_registry(name)
+  contains(name, "/")
+}
+
+allowed(name) {
+  entry := input.parameters.registries[_]
+  endswith(entry, "/")
+  startswith(concat("", [name, "/"]), entry)
+}
+
+allowed(name) {
+  entry := input.parameters.registries[_]
+  not endswith(entry, "/")
+  split(name, "/")[0] == entry
+}
+`,
+}
+
+// gatekeeperNameFormat mirrors the metadata.name rules: ValidateMetadataName, and the
+// stricter formats of Namespaces and Services.
+var gatekeeperNameFormat = gatekeeperRule{
+	kind: "K8sConstraintsNameFormat",
+	parameters: map[string]interface{}{
+		"rule":      map[string]interface{}{"type": "string"},
+		"pattern":   map[string]interface{}{"type": "string"},
+		"maxLength": map[string]interface{}{"type": "integer"},
+		"format":    map[string]interface{}{"type": "string"},
+	},
+	rego: `package k8sconstraintsnameformat
+
+violation[{"msg": msg, "details": {"rule": input.parameters.rule}}] {
+  name := input.review.object.metadata.name
+  count(name) > input.parameters.maxLength
+  msg := sprintf("metadata.name: name '%v' exceeds maximum length of %v characters", [name, input.parameters.maxLength])
+}
+
+violation[{"msg": msg, "details": {"rule": input.parameters.rule}}] {
+  name := input.review.object.metadata.name
+  not regex.match(input.parameters.pattern, name)
+  msg := sprintf("metadata.name: name '%v' must match %v", [name, input.parameters.format])
+}
+`,
+}
+
+// gatekeeperNameFormats are the constraints of gatekeeperNameFormat, one per name format.
+// Every object name must be a DNS subdomain; Namespaces and Services additionally get the
+// label formats they are held to.
+var gatekeeperNameFormats = []struct {
+	suffix     string
+	match      []map[string]interface{}
+	parameters map[string]interface{}
+}{
+	{
+		suffix: "dns1123-subdomain",
+		match:  []map[string]interface{}{{"apiGroups": []interface{}{"*"}, "kinds": []interface{}{"*"}}},
+		parameters: map[string]interface{}{
+			"rule":      RuleDNS1123Subdomain,
+			"pattern":   `^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`,
+			"maxLength": 253,
+			"format":    "DNS subdomain format (lowercase alphanumeric, '-', '.', must start and end with alphanumeric)",
+		},
+	},
+	{
+		suffix: "dns1123-label",
+		match:  []map[string]interface{}{{"apiGroups": []interface{}{""}, "kinds": []interface{}{"Namespace"}}},
+		parameters: map[string]interface{}{
+			"rule":      RuleDNS1123Label,
+			"pattern":   `^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`,
+			"maxLength": 63,
+			"format":    "DNS label format (lowercase alphanumeric, hyphens, must start and end with alphanumeric)",
+		},
+	},
+	{
+		suffix: "dns1035-label",
+		match:  []map[string]interface{}{{"apiGroups": []interface{}{""}, "kinds": []interface{}{"Service"}}},
+		parameters: map[string]interface{}{
+			"rule":      RuleDNS1035Label,
+			"pattern":   `^[a-z]([-a-z0-9]*[a-z0-9])?$`,
+			"maxLength": 63,
+			"format":    "RFC 1035 DNS label format (lowercase alphanumeric, hyphens, must start with a letter and end with alphanumeric)",
+		},
+	},
+}
+
+// GatekeeperObjects returns OPA Gatekeeper ConstraintTemplates and Constraints enforcing
+// the rules selected by policy at admission, so the policy checked in CI can also be
+// enforced in the cluster. Each rule yields a ConstraintTemplate followed by its
+// Constraints; the Rego reproduces the checks and messages of the corresponding
+// validators. Required labels apply to workloads and Namespaces, and the registry
+// allowlist to the workload kinds, as they do here.
+func GatekeeperObjects(policy GatekeeperPolicy) ([]map[string]interface{}, error) {
+	errs := make([]error, 0)
+	for i, key := range policy.RequiredLabels {
+		if err := ValidateLabelKey(key); err != nil {
+			errs = append(errs, fmt.Errorf("requiredLabels[%d]: invalid key '%s': %v", i, key, err))
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return nil, JoinErrors(errs)
+	}
+
+	objects := make([]map[string]interface{}, 0)
+	objects = append(objects, gatekeeperNameFormat.template())
+	for _, format := range gatekeeperNameFormats {
+		objects = append(objects, gatekeeperNameFormat.constraint("k8sconstraints-name-"+format.suffix, format.match, format.parameters))
+	}
+	if len(policy.RequiredLabels) > 0 {
+		objects = append(objects, gatekeeperRequiredLabels.template())
+		kinds := append([]map[string]interface{}{{"apiGroups": []interface{}{""}, "kinds": []interface{}{"Namespace"}}}, gatekeeperWorkloadKinds()...)
+		objects = append(objects, gatekeeperRequiredLabels.constraint("k8sconstraints-required-labels", kinds, map[string]interface{}{
+			"labels": stringsToInterfaces(policy.RequiredLabels),
+		}))
+	}
+	if len(policy.AllowedRegistries) > 0 {
+		objects = append(objects, gatekeeperAllowedRegistries.template())
+		objects = append(objects, gatekeeperAllowedRegistries.constraint("k8sconstraints-allowed-registries", gatekeeperWorkloadKinds(), map[string]interface{}{
+			"registries": stringsToInterfaces(policy.AllowedRegistries),
+		}))
+	}
+	return objects, nil
+}
+
+// template returns the ConstraintTemplate of the rule.
+func (r gatekeeperRule) template() map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": gatekeeperTemplateAPIVersion,
+		"kind":       "ConstraintTemplate",
+		"metadata":   map[string]interface{}{"name": strings.ToLower(r.kind)},
+		"spec": map[string]interface{}{
+			"crd": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"names": map[string]interface{}{"kind": r.kind},
+					"validation": map[string]interface{}{
+						"openAPIV3Schema": map[string]interface{}{"type": "object", "properties": r.parameters},
+					},
+				},
+			},
+			"targets": []interface{}{
+				map[string]interface{}{"target": gatekeeperTarget, "rego": r.rego},
+			},
+		},
+	}
+}
+
+// constraint returns a Constraint of the rule's kind named name, matching kinds.
+func (r gatekeeperRule) constraint(name string, kinds []map[string]interface{}, parameters map[string]interface{}) map[string]interface{} {
+	match := make([]interface{}, 0, len(kinds))
+	for _, kind := range kinds {
+		match = append(match, kind)
+	}
+	return map[string]interface{}{
+		"apiVersion": gatekeeperConstraintAPIVersion,
+		"kind":       r.kind,
+		"metadata":   map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"match":      map[string]interface{}{"kinds": match},
+			"parameters": parameters,
+		},
+	}
+}
+
+// gatekeeperWorkloadKinds returns the workloadKinds as Gatekeeper kind matchers, grouped
+// by API group.
+func gatekeeperWorkloadKinds() []map[string]interface{} {
+	groups := make([]string, 0)
+	kinds := make(map[string][]interface{})
+	for _, kind := range workloadKinds {
+		group := ""
+		switch kind {
+		case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet":
+			group = "apps"
+		case "Job", "CronJob":
+			group = "batch"
+		}
+		if _, ok := kinds[group]; !ok {
+			groups = append(groups, group)
+		}
+		kinds[group] = append(kinds[group], kind)
+	}
+
+	matchers := make([]map[string]interface{}, 0, len(groups))
+	for _, group := range groups {
+		matchers = append(matchers, map[string]interface{}{"apiGroups": []interface{}{group}, "kinds": kinds[group]})
+	}
+	return matchers
+}
+
+// stringsToInterfaces converts values to the []interface{} of decoded manifests.
+func stringsToInterfaces(values []string) []interface{} {
+	out := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		out = append(out, value)
+	}
+	return out
+}
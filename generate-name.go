@@ -0,0 +1,76 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNameAndGenerateName is returned when a manifest sets both metadata.name and
+	// metadata.generateName. The API server uses name and ignores generateName.
+	ErrNameAndGenerateName = errors.New("metadata.name and metadata.generateName are both set; metadata.name takes precedence and generateName is ignored")
+
+	// ErrNoNameOrGenerateName is returned when a manifest sets neither metadata.name nor
+	// metadata.generateName.
+	ErrNoNameOrGenerateName = errors.New("one of metadata.name or metadata.generateName is required")
+
+	// ErrNameGenerateNameMismatch is returned when a manifest sets both metadata.name and
+	// metadata.generateName and the name does not start with the prefix, so it cannot have
+	// been generated from it.
+	ErrNameGenerateNameMismatch = errors.New("metadata.name does not start with metadata.generateName")
+)
+
+// maxGenerateNameLength is the longest generateName whose generated names, with the random
+// suffix appended, still fit in the 253-character limit on object names.
+const maxGenerateNameLength = 253 - generatedNameSuffixLength
+
+// ValidateGenerateName validates metadata.generateName for kinds whose names are DNS
+// subdomains. The prefix must leave room for the 5-character random suffix within the
+// 253-character name limit and, apart from an optional trailing '-', must be a valid DNS
+// subdomain. Prefixes longer than 58 characters produce a warning; see
+// ValidateGenerateNamePrefix.
+func ValidateGenerateName(generateName string) error {
+	if generateName == "" {
+		return &ConstraintError{Rule: RuleRequired, BadValue: generateName, Message: "metadata.generateName cannot be empty"}
+	}
+	if len(generateName) > maxGenerateNameLength {
+		return &ConstraintError{Rule: RuleMaxLength, BadValue: generateName, Message: fmt.Sprintf("generateName exceeds maximum length of %d characters, leaving no room for the %d-character random suffix", maxGenerateNameLength, generatedNameSuffixLength)}
+	}
+	return ValidateGenerateNamePrefix(generateName, ValidateDNSSubdomain)
+}
+
+// ValidateNameMatchesGenerateName checks that a name set alongside generateName starts with
+// the prefix, as the names of objects created from generateName and exported from a cluster
+// do. Other combinations pass; see ValidateNameGenerateNamePrecedence for setting both at
+// all.
+func ValidateNameMatchesGenerateName(name, generateName string) error {
+	if name == "" || generateName == "" || strings.HasPrefix(name, generateName) {
+		return nil
+	}
+	message := fmt.Sprintf("%s: name '%s' cannot have been generated from generateName '%s'; remove one of them", ErrNameGenerateNameMismatch, name, generateName)
+	return &ConstraintError{Rule: RuleGenerateName, BadValue: name, Message: message, cause: ErrNameGenerateNameMismatch}
+}
+
+// ValidateNameGenerateNamePrecedence checks the mutual exclusion of metadata.name and
+// metadata.generateName. Each case produces a distinct error that callers can match with
+// errors.Is; setting both is a warning, since the API server accepts it and ignores
+// generateName. List documents are skipped since they carry no object metadata.
+func ValidateNameGenerateNamePrecedence(obj map[string]interface{}) error {
+	if IsList(obj) {
+		return nil
+	}
+
+	name, _ := nestedString(obj, "metadata", "name")
+	generateName, _ := nestedString(obj, "metadata", "generateName")
+
+	switch {
+	case name != "" && generateName != "":
+		message := fmt.Sprintf("warning: %s (name '%s', generateName '%s')", ErrNameAndGenerateName, name, generateName)
+		return &ConstraintError{FieldPath: "metadata.generateName", Rule: RuleGenerateName, BadValue: generateName, Message: message, cause: ErrNameAndGenerateName}
+	case name == "" && generateName == "":
+		return &ConstraintError{FieldPath: "metadata.name", Rule: RuleRequired, Message: ErrNoNameOrGenerateName.Error(), cause: ErrNoNameOrGenerateName}
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateNameGenerateNamePrecedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		// want is the error the finding wraps, nil when there is none
+		want error
+	}{
+		{"name", `
+apiVersion: v1
+kind: ConfigMap
+metadata: {name: web}`, nil},
+		{"generateName", `
+apiVersion: v1
+kind: ConfigMap
+metadata: {generateName: web-}`, nil},
+		{"both", `
+apiVersion: v1
+kind: ConfigMap
+metadata: {name: web-x7k2p, generateName: web-}`, ErrNameAndGenerateName},
+		{"neither", `
+apiVersion: v1
+kind: ConfigMap
+metadata: {namespace: app}`, ErrNoNameOrGenerateName},
+		{"list", `
+apiVersion: v1
+kind: List
+metadata: {}
+items: []`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNameGenerateNamePrecedence(decodeTestObject(t, tt.manifest))
+			if tt.want == nil && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("expected an error wrapping %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateManifestNameAndGenerateName(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		// finding is part of the expected message; "" expects no error
+		finding string
+	}{
+		{"generated name", `
+apiVersion: v1
+kind: ConfigMap
+metadata: {name: web-x7k2p, generateName: web-}`, "metadata.generateName: warning: metadata.name and metadata.generateName are both set"},
+		{"name not generated", `
+apiVersion: v1
+kind: ConfigMap
+metadata: {name: api, generateName: web-}`, "metadata.name: metadata.name does not start with metadata.generateName"},
+		{"neither", `
+apiVersion: v1
+kind: ConfigMap
+metadata: {namespace: app}`, "metadata.name: one of metadata.name or metadata.generateName is required"},
+		{"empty list", `
+apiVersion: v1
+kind: List
+metadata: {}
+items: []`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateManifest(decodeTestObject(t, tt.manifest))
+			if tt.finding == "" {
+				if err != nil {
+					t.Errorf("unexpected findings: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.finding) {
+				t.Errorf("expected a finding containing %q, got %v", tt.finding, err)
+			}
+		})
+	}
+}
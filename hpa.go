@@ -0,0 +1,354 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Limits applied by Kubernetes to HorizontalPodAutoscaler scaling behavior.
+const (
+	maxStabilizationWindowSeconds = 3600
+	maxScalingPolicyPeriodSeconds = 1800
+)
+
+// Enumerated HorizontalPodAutoscaler values accepted by the API server.
+var (
+	hpaMetricTypes        = []string{"Object", "Pods", "Resource", "ContainerResource", "External"}
+	hpaTargetTypes        = []string{"Utilization", "Value", "AverageValue"}
+	hpaSelectPolicies     = []string{"Max", "Min", "Disabled"}
+	hpaScalingPolicyTypes = []string{"Pods", "Percent"}
+)
+
+// hpaMetricSources maps each metric type to the field holding its source.
+var hpaMetricSources = map[string]string{
+	"Object":            "object",
+	"Pods":              "pods",
+	"Resource":          "resource",
+	"ContainerResource": "containerResource",
+	"External":          "external",
+}
+
+// ValidateHorizontalPodAutoscaler validates an autoscaling/v2 HorizontalPodAutoscaler:
+// maxReplicas is required and minReplicas, when set, at least 1 and at most maxReplicas;
+// scaleTargetRef has a valid apiVersion, kind, and name; every metric has a supported type
+// with only the matching source set and a target fitting it; and the scaleUp and
+// scaleDown behavior have valid stabilization windows, select policies, and scaling
+// policies.
+func ValidateHorizontalPodAutoscaler(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	spec, _ := nestedMap(obj, "spec")
+	maxValue, hasMax := nestedField(spec, "maxReplicas")
+	maxReplicas, maxOK := toInt64(maxValue)
+	switch {
+	case !hasMax:
+		errs = append(errs, Required(NewPath("spec", "maxReplicas"), "maxReplicas is required"))
+	case !maxOK || maxReplicas < 1:
+		errs = append(errs, Invalid(NewPath("spec", "maxReplicas"), maxValue, "maxReplicas must be an integer of at least 1"))
+		maxOK = false
+	}
+	if minValue, ok := nestedField(spec, "minReplicas"); ok {
+		minReplicas, minOK := toInt64(minValue)
+		switch {
+		case !minOK || minReplicas < 1:
+			errs = append(errs, Invalid(NewPath("spec", "minReplicas"), minValue, "minReplicas must be an integer of at least 1"))
+		case maxOK && minReplicas > maxReplicas:
+			errs = append(errs, Invalid(NewPath("spec", "minReplicas"), minValue, fmt.Sprintf("minReplicas %d must not be greater than maxReplicas %d", minReplicas, maxReplicas)))
+		}
+	}
+
+	if ref, ok := nestedMap(spec, "scaleTargetRef"); ok {
+		if err := validateCrossVersionObjectReference(ref); err != nil {
+			errs = append(errs, WithFieldPath("spec.scaleTargetRef", err))
+		}
+	} else {
+		errs = append(errs, Required(NewPath("spec", "scaleTargetRef"), "scaleTargetRef is required"))
+	}
+
+	metrics, _ := nestedSlice(spec, "metrics")
+	for i, raw := range metrics {
+		metric, _ := raw.(map[string]interface{})
+		if err := validateHPAMetric(metric); err != nil {
+			errs = append(errs, WithFieldPath(fmt.Sprintf("spec.metrics[%d]", i), err))
+		}
+	}
+
+	for _, direction := range []string{"scaleUp", "scaleDown"} {
+		if rules, ok := nestedMap(spec, "behavior", direction); ok {
+			if err := validateHPAScalingRules(rules); err != nil {
+				errs = append(errs, WithFieldPath("spec.behavior."+direction, err))
+			}
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleHPA, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validateCrossVersionObjectReference checks a reference to an object by apiVersion, kind,
+// and name, such as the scaleTargetRef of a HorizontalPodAutoscaler.
+func validateCrossVersionObjectReference(ref map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	if apiVersion, ok := nestedString(ref, "apiVersion"); ok && apiVersion != "" {
+		if err := ValidateApiVersion(apiVersion); err != nil {
+			errs = append(errs, WithFieldPath("apiVersion", withRule(RuleAPIVersion, apiVersion, err)))
+		}
+	}
+	if kind, _ := nestedString(ref, "kind"); kind == "" {
+		errs = append(errs, &ConstraintError{FieldPath: "kind", Rule: RuleRequired, Message: "kind is required"})
+	} else if err := ValidateKind(kind); err != nil {
+		errs = append(errs, WithFieldPath("kind", withRule(RuleKind, kind, err)))
+	}
+	if name, _ := nestedString(ref, "name"); name == "" {
+		errs = append(errs, &ConstraintError{FieldPath: "name", Rule: RuleRequired, Message: "name is required"})
+	} else if err := ValidateMetadataName(name); err != nil {
+		errs = append(errs, WithFieldPath("name", err))
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// validateHPAMetric checks a metric of a HorizontalPodAutoscaler: its type, the source
+// matching it, and the source's metric and target.
+func validateHPAMetric(metric map[string]interface{}) error {
+	metricType, ok := nestedString(metric, "type")
+	switch {
+	case !ok || metricType == "":
+		return &ConstraintError{FieldPath: "type", Rule: RuleRequired, Message: fmt.Sprintf("type is required; must be one of: %s", strings.Join(hpaMetricTypes, ", "))}
+	case !containsString(hpaMetricTypes, metricType):
+		return validateEnumField("type", metricType, hpaMetricTypes)
+	}
+
+	errs := make([]error, 0)
+	for _, other := range hpaMetricTypes {
+		if field := hpaMetricSources[other]; other != metricType {
+			if _, ok := metric[field]; ok {
+				errs = append(errs, &ConstraintError{FieldPath: field, Message: fmt.Sprintf("%s may only be set when type is %s, not %s", field, other, metricType)})
+			}
+		}
+	}
+	field := hpaMetricSources[metricType]
+	source, ok := nestedMap(metric, field)
+	if !ok {
+		errs = append(errs, &ConstraintError{FieldPath: field, Rule: RuleRequired, Message: fmt.Sprintf("%s is required when type is %s", field, metricType)})
+		return JoinErrors(errs)
+	}
+
+	// Resource metrics name a resource; the others name a metric
+	allowedTargets := hpaTargetTypes
+	switch metricType {
+	case "Resource", "ContainerResource":
+		if name, _ := nestedString(source, "name"); name == "" {
+			errs = append(errs, &ConstraintError{FieldPath: field + ".name", Rule: RuleRequired, Message: "resource name is required, e.g. cpu or memory"})
+		}
+		if metricType == "ContainerResource" {
+			if container, _ := nestedString(source, "container"); container == "" {
+				errs = append(errs, &ConstraintError{FieldPath: field + ".container", Rule: RuleRequired, Message: "container is required"})
+			} else if err := ValidateDNSLabel(container); err != nil {
+				errs = append(errs, WithFieldPath(field+".container", err))
+			}
+		}
+	default:
+		if name, _ := nestedString(source, "metric", "name"); name == "" {
+			errs = append(errs, &ConstraintError{FieldPath: field + ".metric.name", Rule: RuleRequired, Message: "metric name is required"})
+		}
+		allowedTargets = []string{"Value", "AverageValue"}
+		if metricType == "Pods" {
+			allowedTargets = []string{"AverageValue"}
+		}
+	}
+	if metricType == "Object" {
+		if ref, ok := nestedMap(source, "describedObject"); !ok {
+			errs = append(errs, &ConstraintError{FieldPath: field + ".describedObject", Rule: RuleRequired, Message: "describedObject is required"})
+		} else if err := validateCrossVersionObjectReference(ref); err != nil {
+			errs = append(errs, WithFieldPath(field+".describedObject", err))
+		}
+	}
+	if target, ok := nestedMap(source, "target"); !ok {
+		errs = append(errs, &ConstraintError{FieldPath: field + ".target", Rule: RuleRequired, Message: "target is required"})
+	} else if err := validateHPAMetricTarget(target, allowedTargets); err != nil {
+		errs = append(errs, WithFieldPath(field+".target", err))
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// validateHPAMetricTarget checks the target of a metric: its type, one of allowed, and the
+// value the type requires, a utilization percentage of at least 1 or a positive quantity.
+func validateHPAMetricTarget(target map[string]interface{}, allowed []string) error {
+	targetType, ok := nestedString(target, "type")
+	switch {
+	case !ok || targetType == "":
+		return &ConstraintError{FieldPath: "type", Rule: RuleRequired, Message: fmt.Sprintf("type is required; must be one of: %s", strings.Join(allowed, ", "))}
+	case !containsString(hpaTargetTypes, targetType):
+		return validateEnumField("type", targetType, allowed)
+	case !containsString(allowed, targetType):
+		return &ConstraintError{FieldPath: "type", BadValue: targetType, Message: fmt.Sprintf("type '%s' is not supported for this metric; must be one of: %s", targetType, strings.Join(allowed, ", "))}
+	}
+
+	switch targetType {
+	case "Utilization":
+		value, ok := nestedField(target, "averageUtilization")
+		if !ok {
+			return &ConstraintError{FieldPath: "averageUtilization", Rule: RuleRequired, Message: "averageUtilization is required when type is Utilization"}
+		}
+		if n, ok := toInt64(value); !ok || n < 1 {
+			return &ConstraintError{FieldPath: "averageUtilization", BadValue: value, Message: "averageUtilization must be a percentage of at least 1"}
+		}
+	default:
+		field := "value"
+		if targetType == "AverageValue" {
+			field = "averageValue"
+		}
+		value, ok := nestedField(target, field)
+		if !ok {
+			return &ConstraintError{FieldPath: field, Rule: RuleRequired, Message: fmt.Sprintf("%s is required when type is %s", field, targetType)}
+		}
+		quantity, err := quantityValue(value)
+		if err != nil {
+			return WithFieldPath(field, withRule(RuleQuantity, value, err))
+		}
+		if quantity.Sign() <= 0 {
+			return &ConstraintError{FieldPath: field, Rule: RuleQuantity, BadValue: value, Message: fmt.Sprintf("%s must be greater than 0", field)}
+		}
+	}
+	return nil
+}
+
+// validateHPAScalingRules checks the scaleUp or scaleDown behavior of a
+// HorizontalPodAutoscaler.
+func validateHPAScalingRules(rules map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	if value, ok := nestedField(rules, "stabilizationWindowSeconds"); ok {
+		if n, ok := toInt64(value); !ok || n < 0 || n > maxStabilizationWindowSeconds {
+			errs = append(errs, &ConstraintError{FieldPath: "stabilizationWindowSeconds", BadValue: value, Message: fmt.Sprintf("stabilizationWindowSeconds must be an integer between 0 and %d", maxStabilizationWindowSeconds)})
+		}
+	}
+	if policy, ok := nestedString(rules, "selectPolicy"); ok {
+		if err := validateEnumField("selectPolicy", policy, hpaSelectPolicies); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	policies, _ := nestedSlice(rules, "policies")
+	for i, raw := range policies {
+		policy, _ := raw.(map[string]interface{})
+		path := fmt.Sprintf("policies[%d]", i)
+		if policyType, _ := nestedString(policy, "type"); !containsString(hpaScalingPolicyTypes, policyType) {
+			errs = append(errs, validateEnumField(path+".type", policyType, hpaScalingPolicyTypes))
+		}
+		if value, _ := nestedField(policy, "value"); value == nil {
+			errs = append(errs, &ConstraintError{FieldPath: path + ".value", Rule: RuleRequired, Message: "value is required"})
+		} else if n, ok := toInt64(value); !ok || n < 1 {
+			errs = append(errs, &ConstraintError{FieldPath: path + ".value", BadValue: value, Message: "value must be an integer of at least 1"})
+		}
+		if value, _ := nestedField(policy, "periodSeconds"); value == nil {
+			errs = append(errs, &ConstraintError{FieldPath: path + ".periodSeconds", Rule: RuleRequired, Message: "periodSeconds is required"})
+		} else if n, ok := toInt64(value); !ok || n < 1 || n > maxScalingPolicyPeriodSeconds {
+			errs = append(errs, &ConstraintError{FieldPath: path + ".periodSeconds", BadValue: value, Message: fmt.Sprintf("periodSeconds must be an integer between 1 and %d", maxScalingPolicyPeriodSeconds)})
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// CheckHPATargets is the ID of the check built by HPATargetsCheck.
+const CheckHPATargets = "HPATargets"
+
+// HPATargetsCheck returns a bundle check that cross-checks HorizontalPodAutoscalers against
+// the objects they scale, when those are among objects too: a workload of the referenced
+// name but another kind or API group is reported, as the reference most likely has a typo,
+// and a target that also sets spec.replicas is reported as a warning, since every apply
+// resets the replica count the autoscaler chose. Targets missing from objects are skipped,
+// as they may be deployed separately.
+func HPATargetsCheck(objects []map[string]interface{}) Check {
+	type object struct {
+		gvk      GroupVersionKind
+		replicas bool
+	}
+	// Index the objects an autoscaler could scale: workloads, and custom resources with a
+	// replica count
+	byName := make(map[string][]object)
+	for _, obj := range objects {
+		gvk := GroupVersionKindOf(obj)
+		_, replicas := nestedField(obj, "spec", "replicas")
+		if !replicas && !isPodWorkload(gvk) {
+			continue
+		}
+		namespace, _ := nestedString(obj, "metadata", "namespace")
+		name, _ := nestedString(obj, "metadata", "name")
+		key := namespace + "/" + name
+		byName[key] = append(byName[key], object{gvk: gvk, replicas: replicas})
+	}
+
+	return Check{ID: CheckHPATargets, Kinds: []string{"HorizontalPodAutoscaler.autoscaling"}, Validate: func(obj map[string]interface{}) error {
+		name, _ := nestedString(obj, "metadata", "name")
+		namespace, _ := nestedString(obj, "metadata", "namespace")
+		ref, _ := nestedMap(obj, "spec", "scaleTargetRef")
+		apiVersion, _ := nestedString(ref, "apiVersion")
+		kind, _ := nestedString(ref, "kind")
+		targetName, _ := nestedString(ref, "name")
+		group, _, _ := strings.Cut(apiVersion, "/")
+		if !strings.Contains(apiVersion, "/") {
+			group = ""
+		}
+
+		candidates := byName[namespace+"/"+targetName]
+		if targetName == "" || len(candidates) == 0 {
+			return nil
+		}
+		var target *object
+		for i := range candidates {
+			if candidates[i].gvk.Kind == kind && candidates[i].gvk.Group == group {
+				target = &candidates[i]
+			}
+		}
+		path := NewPath("spec", "scaleTargetRef")
+		switch {
+		case target == nil:
+			found := candidates[0].gvk
+			message := fmt.Sprintf("HorizontalPodAutoscaler '%s' scales %s '%s' of apiVersion '%s', but the bundle defines '%s' as a %s of apiVersion '%s'", name, kind, targetName, apiVersion, targetName, found.Kind, found.APIVersion())
+			return &ConstraintError{FieldPath: path.String(), Rule: CheckHPATargets, BadValue: targetName, Message: message}
+		case target.replicas:
+			message := fmt.Sprintf("warning: HorizontalPodAutoscaler '%s' scales %s '%s', which also sets spec.replicas; remove it so applying the manifests does not reset the replica count", name, kind, targetName)
+			return &ConstraintError{FieldPath: path.Child("name").String(), Rule: CheckHPATargets, BadValue: targetName, Message: message}
+		}
+		return nil
+	}}
+}
+
+// ValidateHPATargets runs HPATargetsCheck against every object of a bundle.
+func ValidateHPATargets(objects []map[string]interface{}) error {
+	return RunBundleCheck(objects, HPATargetsCheck)
+}
+
+// isPodWorkload reports whether gvk is one of podWorkloadKinds, in any version.
+func isPodWorkload(gvk GroupVersionKind) bool {
+	for _, kind := range podWorkloadKinds {
+		if kind.Group == gvk.Group && kind.Kind == gvk.Kind {
+			return true
+		}
+	}
+	return false
+}
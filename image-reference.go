@@ -0,0 +1,121 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Limits of the Docker/OCI reference grammar.
+const (
+	maxImageNameLength = 255
+	maxImageTagLength  = 128
+)
+
+var (
+	// Registry host: dot-separated DNS components, or a bracketed IPv6 address, with an
+	// optional port
+	imageDomainPattern = regexp.MustCompile(`^(([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(\.([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))*|\[[a-fA-F0-9:]+\])(:[0-9]+)?$`)
+	// Repository path component: lowercase alphanumerics joined by '.', '_', '__', or '-'s
+	imagePathComponentPattern = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*$`)
+	imageTagPattern           = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	imageDigestPattern        = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*([-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}$`)
+	sha256DigestPattern       = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+)
+
+// ImageReference holds the components of a container image reference such as
+// registry.example.com:5000/team/app:1.2@sha256:....
+type ImageReference struct {
+	// Registry is the registry host and port, or "" when the reference names none and the
+	// container runtime's default registry (usually docker.io) applies.
+	Registry string
+	// Repository is the slash-separated repository path, e.g. "team/app".
+	Repository string
+	// Tag is the tag, or "" when the reference has none.
+	Tag string
+	// Digest is the "algorithm:hex" content digest, or "" when the reference has none.
+	Digest string
+}
+
+// Name returns the registry and repository, e.g. "registry.example.com/team/app".
+func (r ImageReference) Name() string {
+	if r.Registry == "" {
+		return r.Repository
+	}
+	return r.Registry + "/" + r.Repository
+}
+
+// String reassembles the reference.
+func (r ImageReference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// ParseImageReference splits a container image reference into its registry, repository,
+// tag, and digest, enforcing the Docker/OCI reference grammar: lowercase repository path
+// components, tags of at most 128 word characters, '.', or '-', and well-formed digests,
+// with sha256 digests holding exactly 64 lowercase hex characters. The first path component
+// is taken as the registry when it contains a '.' or ':' or is "localhost".
+func ParseImageReference(image string) (ImageReference, error) {
+	ref := ImageReference{}
+	if image == "" {
+		return ref, &ConstraintError{Rule: RuleRequired, Message: "image reference cannot be empty"}
+	}
+
+	errs := make([]error, 0)
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.Digest = name[:i], name[i+1:]
+		if strings.HasPrefix(ref.Digest, "sha256:") {
+			if !sha256DigestPattern.MatchString(ref.Digest) {
+				errs = append(errs, fmt.Errorf("sha256 digest '%s' must hold exactly 64 lowercase hex characters", ref.Digest))
+			}
+		} else if !imageDigestPattern.MatchString(ref.Digest) {
+			errs = append(errs, fmt.Errorf("digest '%s' must be of the form algorithm:hex", ref.Digest))
+		}
+	}
+	// A ':' after the last '/' starts the tag; earlier ones belong to the registry port
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.Tag = name[:i], name[i+1:]
+		if !imageTagPattern.MatchString(ref.Tag) {
+			errs = append(errs, fmt.Errorf("tag '%s' must be at most %d word characters, '.', or '-', and must not start with '.' or '-'", ref.Tag, maxImageTagLength))
+		}
+	}
+
+	if len(name) > maxImageNameLength {
+		errs = append(errs, fmt.Errorf("repository name must be no more than %d characters", maxImageNameLength))
+	}
+	components := strings.Split(name, "/")
+	if first := components[0]; len(components) > 1 && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.Registry, components = first, components[1:]
+		if !imageDomainPattern.MatchString(ref.Registry) {
+			errs = append(errs, fmt.Errorf("registry '%s' must be a host name or IP address with an optional port", ref.Registry))
+		}
+	}
+	ref.Repository = strings.Join(components, "/")
+	for _, component := range components {
+		if !imagePathComponentPattern.MatchString(component) {
+			errs = append(errs, fmt.Errorf("repository '%s' must consist of lowercase alphanumeric components separated by '/', optionally joined by '.', '_', '__', or '-'", ref.Repository))
+			break
+		}
+	}
+
+	if len(errs) > 0 {
+		return ref, withRule(RuleImageReference, image, JoinErrors(errs))
+	}
+
+	return ref, nil
+}
+
+// ValidateImageReference validates a container image reference against the Docker/OCI
+// reference grammar; see ParseImageReference.
+func ValidateImageReference(image string) error {
+	_, err := ParseImageReference(image)
+	return err
+}
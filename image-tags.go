@@ -0,0 +1,77 @@
+package k8sconstraints
+
+import "fmt"
+
+// CheckImageTags is the ID of ImageTagsCheck.
+const CheckImageTags = "ImageTags"
+
+// Rule codes of the findings reported by ImageTagsCheck, so each can be given its own
+// severity.
+const (
+	RuleImageLatestTag            = "ImageLatestTag"
+	RuleImagePullPolicyDigest     = "ImagePullPolicyDigest"
+	RuleImagePullPolicyMutableTag = "ImagePullPolicyMutableTag"
+)
+
+// mutableImageTags are tags registries conventionally move to new images, besides latest.
+var mutableImageTags = []string{"latest", "stable", "edge", "main", "master", "develop", "dev", "nightly", "canary"}
+
+// ImageTagsCheck is an opt-in check, not run by ValidateObject, that warns about container
+// images whose tag and imagePullPolicy do not make for reproducible deployments; see
+// ValidateImageTags. Run it with RunChecks or pass it to the linter's Checks option.
+var ImageTagsCheck = Check{ID: CheckImageTags, Validate: ValidateImageTags}
+
+// ValidateImageTags warns about the containers of the pod spec embedded in obj whose
+// images are untagged or tagged latest (RuleImageLatestTag), are pinned by digest but
+// pulled Always, which only adds registry round trips (RuleImagePullPolicyDigest), or carry
+// a mutable tag such as latest or stable but are pulled IfNotPresent, so nodes keep running
+// whichever image they pulled first (RuleImagePullPolicyMutableTag). Images that are not
+// valid references are left to the image reference rule.
+func ValidateImageTags(obj map[string]interface{}) error {
+	spec, path, ok := findPodSpec(obj)
+	if !ok {
+		return nil
+	}
+
+	errs := make([]error, 0)
+	for _, container := range podContainers(spec) {
+		image, _ := nestedString(container.fields, "image")
+		ref, err := ParseImageReference(image)
+		if err != nil {
+			continue
+		}
+		imagePath := path + "." + container.path + ".image"
+		policyPath := path + "." + container.path + ".imagePullPolicy"
+		policy, _ := nestedString(container.fields, "imagePullPolicy")
+
+		if ref.Digest == "" {
+			switch ref.Tag {
+			case "":
+				errs = append(errs, &ConstraintError{FieldPath: imagePath, Rule: RuleImageLatestTag, BadValue: image, Message: fmt.Sprintf("warning: image '%s' has no tag, so it resolves to latest; pin a version tag or digest", image)})
+			case "latest":
+				errs = append(errs, &ConstraintError{FieldPath: imagePath, Rule: RuleImageLatestTag, BadValue: image, Message: fmt.Sprintf("warning: image '%s' uses the latest tag; pin a version tag or digest", image)})
+			}
+		}
+
+		switch {
+		case policy == "Always" && ref.Digest != "":
+			errs = append(errs, &ConstraintError{FieldPath: policyPath, Rule: RuleImagePullPolicyDigest, BadValue: policy, Message: fmt.Sprintf("warning: image '%s' is pinned by digest, so imagePullPolicy Always only adds registry round trips; use IfNotPresent", image), Fix: []FieldChange{
+				{Op: PatchTest, FieldPath: policyPath, Value: policy},
+				{Op: PatchReplace, FieldPath: policyPath, Value: "IfNotPresent"},
+			}})
+		case policy == "IfNotPresent" && ref.Digest == "" && (ref.Tag == "" || containsString(mutableImageTags, ref.Tag)):
+			tag := ref.Tag
+			if tag == "" {
+				tag = "latest"
+			}
+			errs = append(errs, &ConstraintError{FieldPath: policyPath, Rule: RuleImagePullPolicyMutableTag, BadValue: policy, Message: fmt.Sprintf("warning: image '%s' has the mutable tag '%s', so with imagePullPolicy IfNotPresent nodes keep running whichever image they pulled first; pin a version tag or digest, or use Always", image, tag)})
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ingressClassAnnotation is the annotation that selected the Ingress controller before
+// spec.ingressClassName replaced it.
+const ingressClassAnnotation = "kubernetes.io/ingress.class"
+
+// ingressPathTypes lists the valid pathType values of an Ingress path.
+var ingressPathTypes = []string{"Exact", "Prefix", "ImplementationSpecific"}
+
+// Sequences the API server rejects in Exact and Prefix paths, as they are ambiguous once
+// normalized.
+var (
+	invalidIngressPathSequences = []string{"//", "/./", "/../", "%2f", "%2F"}
+	invalidIngressPathSuffixes  = []string{"/..", "/."}
+)
+
+// ValidateIngress validates a networking.k8s.io/v1 Ingress: rule hosts must be DNS
+// subdomains, optionally with one leading wildcard label, and not IP addresses; every
+// path needs a pathType of Exact, Prefix, or ImplementationSpecific and a path valid for
+// it; backends must name exactly one of a Service or a resource; TLS hosts must be valid
+// hosts and are expected to match a rule host; and the deprecated kubernetes.io/ingress.class
+// annotation may not be set along with spec.ingressClassName.
+func ValidateIngress(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	spec, _ := nestedMap(obj, "spec")
+	if class, ok := nestedString(spec, "ingressClassName"); ok && class != "" {
+		if annotation, ok := nestedString(obj, "metadata", "annotations", ingressClassAnnotation); ok {
+			errs = append(errs, &ConstraintError{FieldPath: fmt.Sprintf("metadata.annotations[%q]", ingressClassAnnotation), BadValue: annotation, Message: fmt.Sprintf("the deprecated %s annotation cannot be set along with spec.ingressClassName; remove it", ingressClassAnnotation)})
+		}
+	}
+
+	if backend, ok := nestedMap(spec, "defaultBackend"); ok {
+		if err := validateIngressBackend(backend); err != nil {
+			errs = append(errs, WithFieldPath("spec.defaultBackend", err))
+		}
+	}
+
+	hosts := make([]string, 0)
+	rules, _ := nestedSlice(spec, "rules")
+	for i, raw := range rules {
+		rule, _ := raw.(map[string]interface{})
+		path := fmt.Sprintf("spec.rules[%d]", i)
+		if host, ok := nestedString(rule, "host"); ok && host != "" {
+			if err := validateIngressHost(host); err != nil {
+				errs = append(errs, WithFieldPath(path+".host", err))
+			}
+			hosts = append(hosts, host)
+		}
+		paths, _ := nestedSlice(rule, "http", "paths")
+		for j, raw := range paths {
+			httpPath, _ := raw.(map[string]interface{})
+			if err := validateIngressPath(httpPath); err != nil {
+				errs = append(errs, WithFieldPath(fmt.Sprintf("%s.http.paths[%d]", path, j), err))
+			}
+		}
+	}
+
+	tls, _ := nestedSlice(spec, "tls")
+	for i, raw := range tls {
+		entry, _ := raw.(map[string]interface{})
+		tlsHosts, _ := nestedSlice(entry, "hosts")
+		for j, rawHost := range tlsHosts {
+			path := fmt.Sprintf("spec.tls[%d].hosts[%d]", i, j)
+			host, _ := rawHost.(string)
+			if err := validateIngressHost(host); err != nil {
+				errs = append(errs, WithFieldPath(path, err))
+				continue
+			}
+			if len(hosts) > 0 && !ingressHostCovered(host, hosts) {
+				errs = append(errs, &ConstraintError{FieldPath: path, BadValue: host, Message: fmt.Sprintf("warning: TLS host '%s' does not match the host of any rule, so its certificate is never served", host)})
+			}
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleIngress, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validateIngressHost checks an Ingress host, which is a DNS subdomain with an optional
+// leading wildcard label, and not an IP address.
+func validateIngressHost(host string) error {
+	if net.ParseIP(host) != nil {
+		return &ConstraintError{BadValue: host, Message: fmt.Sprintf("host '%s' must be a DNS name, not an IP address", host)}
+	}
+	if err := ValidateDNSSubdomainOrWildcard(host); err != nil {
+		return withRule(RuleDNS1123Subdomain, host, withMessagePrefix("invalid host: ", err))
+	}
+	return nil
+}
+
+// ingressHostCovered reports whether a TLS host matches one of the rule hosts, exactly or
+// by covering them with a wildcard.
+func ingressHostCovered(tlsHost string, ruleHosts []string) bool {
+	for _, host := range ruleHosts {
+		if host == tlsHost {
+			return true
+		}
+		suffix, wildcard := strings.CutPrefix(tlsHost, "*")
+		if wildcard && strings.HasSuffix(host, suffix) && !strings.Contains(strings.TrimSuffix(host, suffix), ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// validateIngressPath checks an HTTP path of an Ingress rule: its pathType and the path
+// syntax the pathType requires, and its backend.
+func validateIngressPath(httpPath map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	value, _ := nestedString(httpPath, "path")
+	pathType, ok := nestedString(httpPath, "pathType")
+	switch {
+	case !ok || pathType == "":
+		errs = append(errs, &ConstraintError{FieldPath: "pathType", Rule: RuleRequired, Message: fmt.Sprintf("pathType is required; must be one of: %s", strings.Join(ingressPathTypes, ", "))})
+	case !containsString(ingressPathTypes, pathType):
+		errs = append(errs, validateEnumField("pathType", pathType, ingressPathTypes))
+	case pathType == "ImplementationSpecific":
+		if value != "" && !strings.HasPrefix(value, "/") {
+			errs = append(errs, &ConstraintError{FieldPath: "path", BadValue: value, Message: fmt.Sprintf("path '%s' must be an absolute path starting with '/'", value)})
+		}
+	default:
+		if err := validateIngressExactOrPrefixPath(value); err != nil {
+			errs = append(errs, WithFieldPath("path", err))
+		}
+	}
+
+	if backend, ok := nestedMap(httpPath, "backend"); ok {
+		if err := validateIngressBackend(backend); err != nil {
+			errs = append(errs, WithFieldPath("backend", err))
+		}
+	} else {
+		errs = append(errs, &ConstraintError{FieldPath: "backend", Rule: RuleRequired, Message: "backend is required"})
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// validateIngressExactOrPrefixPath checks the path of an Exact or Prefix path type, which
+// must be absolute and free of sequences that change meaning once normalized.
+func validateIngressExactOrPrefixPath(value string) error {
+	if !strings.HasPrefix(value, "/") {
+		return &ConstraintError{BadValue: value, Message: fmt.Sprintf("path '%s' must be an absolute path starting with '/'", value)}
+	}
+	for _, sequence := range invalidIngressPathSequences {
+		if strings.Contains(value, sequence) {
+			return &ConstraintError{BadValue: value, Message: fmt.Sprintf("path '%s' must not contain '%s'", value, sequence)}
+		}
+	}
+	for _, suffix := range invalidIngressPathSuffixes {
+		if strings.HasSuffix(value, suffix) {
+			return &ConstraintError{BadValue: value, Message: fmt.Sprintf("path '%s' must not end with '%s'", value, suffix)}
+		}
+	}
+	return nil
+}
+
+// validateIngressBackend checks an Ingress backend, which names either a Service, by name
+// and port number or name, or a resource.
+func validateIngressBackend(backend map[string]interface{}) error {
+	service, hasService := nestedMap(backend, "service")
+	_, hasResource := nestedMap(backend, "resource")
+	switch {
+	case hasService && hasResource:
+		return &ConstraintError{Message: "backend cannot set both service and resource"}
+	case !hasService && !hasResource:
+		return &ConstraintError{Rule: RuleRequired, Message: "backend must set service or resource"}
+	case hasResource:
+		return nil
+	}
+
+	errs := make([]error, 0)
+	if name, _ := nestedString(service, "name"); name == "" {
+		errs = append(errs, &ConstraintError{FieldPath: "service.name", Rule: RuleRequired, Message: "service name is required"})
+	} else if err := ValidateServiceName(name); err != nil {
+		errs = append(errs, WithFieldPath("service.name", err))
+	}
+	port, _ := nestedMap(service, "port")
+	number, hasNumber := nestedField(port, "number")
+	name, _ := nestedString(port, "name")
+	switch {
+	case hasNumber && name != "":
+		errs = append(errs, &ConstraintError{FieldPath: "service.port", Message: "service port cannot set both number and name"})
+	case hasNumber:
+		if err := ValidatePortNumber(number); err != nil {
+			errs = append(errs, WithFieldPath("service.port.number", err))
+		}
+	case name != "":
+		if err := ValidatePortName(name); err != nil {
+			errs = append(errs, WithFieldPath("service.port.name", err))
+		}
+	default:
+		errs = append(errs, &ConstraintError{FieldPath: "service.port", Rule: RuleRequired, Message: "service port number or name is required"})
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
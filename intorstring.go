@@ -0,0 +1,51 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// percentPattern matches the string form of a percentage IntOrString, e.g. "25%".
+var percentPattern = regexp.MustCompile(`^[0-9]+%$`)
+
+// IntOrStringValue is a decoded IntOrString field such as maxUnavailable or maxSurge.
+type IntOrStringValue struct {
+	Value     int64
+	IsPercent bool
+}
+
+// IsZero reports whether the value is 0 or 0%.
+func (v IntOrStringValue) IsZero() bool {
+	return v.Value == 0
+}
+
+// ParseIntOrStringPercent parses an IntOrString field that must be either a non-negative
+// integer or a percentage between 0% and 100%.
+func ParseIntOrStringPercent(value interface{}) (IntOrStringValue, error) {
+	if n, ok := toInt64(value); ok {
+		if n < 0 {
+			return IntOrStringValue{}, fmt.Errorf("value %d must be greater than or equal to 0", n)
+		}
+		return IntOrStringValue{Value: n}, nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return IntOrStringValue{}, errors.New("value must be an integer or a percentage string")
+	}
+	if !percentPattern.MatchString(s) {
+		return IntOrStringValue{}, fmt.Errorf("value '%s' must be an integer or a percentage (e.g. '25%%')", s)
+	}
+
+	n, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil {
+		return IntOrStringValue{}, fmt.Errorf("value '%s' is not a valid percentage", s)
+	}
+	if n > 100 {
+		return IntOrStringValue{}, fmt.Errorf("value '%s' must not be greater than 100%%", s)
+	}
+
+	return IntOrStringValue{Value: n, IsPercent: true}, nil
+}
@@ -0,0 +1,103 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"net"
+)
+
+// IP families and the ipFamilyPolicy values of a Service.
+var (
+	ipFamilies       = []string{"IPv4", "IPv6"}
+	ipFamilyPolicies = []string{"SingleStack", "PreferDualStack", "RequireDualStack"}
+)
+
+// ValidateIP validates an IPv4 address such as 10.0.0.1 or an IPv6 address such as
+// fd00::1. As in the API server, IPv4 octets with leading zeros are rejected, since they
+// are read as octal by some tools.
+func ValidateIP(ip string) error {
+	if net.ParseIP(ip) == nil {
+		return &ConstraintError{Rule: RuleIP, BadValue: ip, Message: fmt.Sprintf("'%s' is not a valid IP address, such as 10.0.0.1 or fd00::1", ip)}
+	}
+	return nil
+}
+
+// ValidateCIDR validates an IPv4 CIDR such as 10.0.0.0/8 or an IPv6 CIDR such as
+// fd00::/64.
+func ValidateCIDR(cidr string) error {
+	if _, _, err := net.ParseCIDR(cidr); err != nil {
+		return &ConstraintError{Rule: RuleCIDR, BadValue: cidr, Message: fmt.Sprintf("'%s' is not a valid CIDR, such as 10.0.0.0/8 or fd00::/64", cidr)}
+	}
+	return nil
+}
+
+// ipFamily returns the IP family, IPv4 or IPv6, of an IP address or CIDR, or an empty
+// string when value is neither.
+func ipFamily(value string) string {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		ip, _, _ = net.ParseCIDR(value)
+	}
+	switch {
+	case ip == nil:
+		return ""
+	case ip.To4() != nil:
+		return "IPv4"
+	default:
+		return "IPv6"
+	}
+}
+
+// ValidateIPFamilyPolicy validates the IP family fields of a Service spec: ipFamilyPolicy
+// must be SingleStack, PreferDualStack, or RequireDualStack; ipFamilies must hold distinct
+// IPv4 or IPv6 entries, only one of them for SingleStack Services; and the addresses in
+// clusterIPs must belong to the families in ipFamilies, in the same order.
+func ValidateIPFamilyPolicy(spec map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	policy, hasPolicy := nestedString(spec, "ipFamilyPolicy")
+	if hasPolicy && !containsString(ipFamilyPolicies, policy) {
+		errs = append(errs, validateEnumField("ipFamilyPolicy", policy, ipFamilyPolicies))
+	}
+
+	// Invalid entries are left empty in families, so it stays aligned with ipFamilies
+	list, _ := nestedSlice(spec, "ipFamilies")
+	families := make([]string, len(list))
+	for i, raw := range list {
+		path := fmt.Sprintf("ipFamilies[%d]", i)
+		family, _ := raw.(string)
+		switch {
+		case !containsString(ipFamilies, family):
+			errs = append(errs, WithFieldPath(path, enumViolation("IP family", family, ipFamilies)))
+		case containsString(families[:i], family):
+			errs = append(errs, &ConstraintError{FieldPath: path, BadValue: family, Message: fmt.Sprintf("duplicate IP family '%s'", family)})
+		default:
+			families[i] = family
+		}
+	}
+	if len(list) > 2 {
+		errs = append(errs, &ConstraintError{FieldPath: "ipFamilies", Message: fmt.Sprintf("ipFamilies may hold at most 2 entries, got %d", len(list))})
+	}
+	if policy == "SingleStack" && len(list) > 1 {
+		errs = append(errs, &ConstraintError{FieldPath: "ipFamilies", Message: "ipFamilies may hold only one entry when ipFamilyPolicy is SingleStack"})
+	}
+
+	// Cluster IPs, whose families the API server derives ipFamilies from
+	clusterIPs, _ := nestedSlice(spec, "clusterIPs")
+	if policy == "SingleStack" && len(clusterIPs) > 1 {
+		errs = append(errs, &ConstraintError{FieldPath: "clusterIPs", Message: "clusterIPs may hold only one address when ipFamilyPolicy is SingleStack"})
+	}
+	for i, raw := range clusterIPs {
+		ip, _ := raw.(string)
+		family := ipFamily(ip)
+		if family != "" && i < len(families) && families[i] != "" && family != families[i] {
+			errs = append(errs, &ConstraintError{FieldPath: fmt.Sprintf("clusterIPs[%d]", i), BadValue: ip, Message: fmt.Sprintf("cluster IP '%s' is an %s address, but ipFamilies[%d] is %s", ip, family, i, families[i])})
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Limits applied by Kubernetes to Job pod failure policies.
+const (
+	maxPodFailurePolicyRules    = 20
+	maxPodFailurePolicyExitCode = 255
+)
+
+// Enumerated Job values accepted by the API server.
+var (
+	jobCompletionModes          = []string{"NonIndexed", "Indexed"}
+	jobRestartPolicies          = []string{"OnFailure", "Never"}
+	podFailurePolicyActions     = []string{"FailJob", "FailIndex", "Ignore", "Count"}
+	podFailurePolicyOperators   = []string{"In", "NotIn"}
+	podFailurePolicyStatuses    = []string{"True", "False", "Unknown"}
+	jobNonNegativeFields        = []string{"completions", "parallelism", "backoffLimit", "backoffLimitPerIndex", "maxFailedIndexes", "ttlSecondsAfterFinished"}
+	podFailurePolicyRuleSources = []string{"onExitCodes", "onPodConditions"}
+)
+
+// ValidateJob validates the spec of a batch/v1 Job with ValidateJobSpec.
+func ValidateJob(obj map[string]interface{}) error {
+	spec, ok := nestedMap(obj, "spec")
+	if !ok {
+		return Required(NewPath("spec"), "spec is required")
+	}
+	if err := ValidateJobSpec(spec); err != nil {
+		return WithFieldPath("spec", err)
+	}
+	return nil
+}
+
+// ValidateJobSpec validates the spec of a Job, or the jobTemplate spec of a CronJob: the
+// counters are non-negative integers and activeDeadlineSeconds is positive; completionMode
+// is NonIndexed or Indexed, with completions set when Indexed; the pod template's
+// restartPolicy is OnFailure or Never, as Jobs reject Always; and podFailurePolicy has
+// supported actions, operators, and condition statuses.
+func ValidateJobSpec(spec map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	for _, field := range jobNonNegativeFields {
+		if value, ok := nestedField(spec, field); ok && value != nil {
+			if n, ok := toInt64(value); !ok || n < 0 {
+				errs = append(errs, &ConstraintError{FieldPath: field, BadValue: value, Message: fmt.Sprintf("%s must be a non-negative integer", field)})
+			}
+		}
+	}
+	if value, ok := nestedField(spec, "activeDeadlineSeconds"); ok && value != nil {
+		if n, ok := toInt64(value); !ok || n < 1 {
+			errs = append(errs, &ConstraintError{FieldPath: "activeDeadlineSeconds", BadValue: value, Message: "activeDeadlineSeconds must be a positive integer"})
+		}
+	}
+
+	mode, hasMode := nestedString(spec, "completionMode")
+	if hasMode && !containsString(jobCompletionModes, mode) {
+		errs = append(errs, validateEnumField("completionMode", mode, jobCompletionModes))
+	}
+	if _, ok := nestedField(spec, "completions"); mode == "Indexed" && !ok {
+		errs = append(errs, &ConstraintError{FieldPath: "completions", Rule: RuleRequired, Message: "completions is required when completionMode is Indexed"})
+	}
+	for _, field := range []string{"backoffLimitPerIndex", "maxFailedIndexes"} {
+		if _, ok := nestedField(spec, field); ok && mode != "Indexed" && (!hasMode || containsString(jobCompletionModes, mode)) {
+			errs = append(errs, &ConstraintError{FieldPath: field, Message: fmt.Sprintf("%s may only be set when completionMode is Indexed", field)})
+		}
+	}
+
+	restartPolicy, hasRestartPolicy := nestedString(spec, "template", "spec", "restartPolicy")
+	switch {
+	case !hasRestartPolicy:
+		if _, ok := nestedMap(spec, "template", "spec"); ok {
+			errs = append(errs, &ConstraintError{FieldPath: "template.spec.restartPolicy", Rule: RuleRequired, Message: fmt.Sprintf("restartPolicy is required, as the default Always is not allowed for Jobs; must be one of: %s", strings.Join(jobRestartPolicies, ", "))})
+		}
+	case restartPolicy == "Always":
+		// Values unknown to pods altogether are reported by ValidatePodSpec
+		errs = append(errs, &ConstraintError{FieldPath: "template.spec.restartPolicy", BadValue: restartPolicy, Message: fmt.Sprintf("restartPolicy 'Always' is not supported for Jobs; must be one of: %s", strings.Join(jobRestartPolicies, ", "))})
+	}
+
+	if policy, ok := nestedMap(spec, "podFailurePolicy"); ok {
+		if hasRestartPolicy && restartPolicy != "Never" {
+			errs = append(errs, &ConstraintError{FieldPath: "podFailurePolicy", Message: "podFailurePolicy requires the pod template's restartPolicy to be Never"})
+		}
+		_, hasBackoffLimitPerIndex := nestedField(spec, "backoffLimitPerIndex")
+		if err := ValidatePodFailurePolicy(policy, hasBackoffLimitPerIndex); err != nil {
+			errs = append(errs, WithFieldPath("podFailurePolicy", err))
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleJob, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// ValidatePodFailurePolicy validates the podFailurePolicy of a Job: it has at most 20
+// rules, each with a supported action and exactly one of onExitCodes and onPodConditions.
+// Exit code rules use the In or NotIn operator over unique codes between 0 and 255, without
+// 0 for In; pod condition rules name a condition type with a True, False, or Unknown status.
+// The FailIndex action requires backoffLimitPerIndex, given by perIndex.
+func ValidatePodFailurePolicy(policy map[string]interface{}, perIndex bool) error {
+	errs := make([]error, 0)
+
+	rules, _ := nestedSlice(policy, "rules")
+	if len(rules) > maxPodFailurePolicyRules {
+		errs = append(errs, &ConstraintError{FieldPath: "rules", BadValue: len(rules), Message: fmt.Sprintf("rules may hold at most %d rules, but holds %d", maxPodFailurePolicyRules, len(rules))})
+	}
+	for i, raw := range rules {
+		rule, _ := raw.(map[string]interface{})
+		if err := validatePodFailurePolicyRule(rule, perIndex); err != nil {
+			errs = append(errs, WithFieldPath(fmt.Sprintf("rules[%d]", i), err))
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleJob, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validatePodFailurePolicyRule checks a rule of a Job's podFailurePolicy.
+func validatePodFailurePolicyRule(rule map[string]interface{}, perIndex bool) error {
+	errs := make([]error, 0)
+
+	action, _ := nestedString(rule, "action")
+	switch {
+	case action == "":
+		errs = append(errs, &ConstraintError{FieldPath: "action", Rule: RuleRequired, Message: fmt.Sprintf("action is required; must be one of: %s", strings.Join(podFailurePolicyActions, ", "))})
+	case !containsString(podFailurePolicyActions, action):
+		errs = append(errs, validateEnumField("action", action, podFailurePolicyActions))
+	case action == "FailIndex" && !perIndex:
+		errs = append(errs, &ConstraintError{FieldPath: "action", BadValue: action, Message: "action FailIndex requires backoffLimitPerIndex to be set"})
+	}
+
+	sources := make([]string, 0)
+	for _, field := range podFailurePolicyRuleSources {
+		if _, ok := rule[field]; ok {
+			sources = append(sources, field)
+		}
+	}
+	if len(sources) != 1 {
+		errs = append(errs, fmt.Errorf("rule must set exactly one of: %s", strings.Join(podFailurePolicyRuleSources, ", ")))
+	}
+
+	if onExitCodes, ok := nestedMap(rule, "onExitCodes"); ok {
+		if err := validatePodFailurePolicyExitCodes(onExitCodes); err != nil {
+			errs = append(errs, WithFieldPath("onExitCodes", err))
+		}
+	}
+	conditions, _ := nestedSlice(rule, "onPodConditions")
+	for i, raw := range conditions {
+		condition, _ := raw.(map[string]interface{})
+		path := fmt.Sprintf("onPodConditions[%d]", i)
+		if conditionType, _ := nestedString(condition, "type"); conditionType == "" {
+			errs = append(errs, &ConstraintError{FieldPath: path + ".type", Rule: RuleRequired, Message: "type is required"})
+		} else if err := ValidateQualifiedName(conditionType); err != nil {
+			errs = append(errs, WithFieldPath(path+".type", err))
+		}
+		if status, ok := nestedString(condition, "status"); ok {
+			if err := validateEnumField(path+".status", status, podFailurePolicyStatuses); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// validatePodFailurePolicyExitCodes checks the onExitCodes requirement of a
+// podFailurePolicy rule.
+func validatePodFailurePolicyExitCodes(onExitCodes map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	operator, _ := nestedString(onExitCodes, "operator")
+	switch {
+	case operator == "":
+		errs = append(errs, &ConstraintError{FieldPath: "operator", Rule: RuleRequired, Message: fmt.Sprintf("operator is required; must be one of: %s", strings.Join(podFailurePolicyOperators, ", "))})
+	case !containsString(podFailurePolicyOperators, operator):
+		errs = append(errs, validateEnumField("operator", operator, podFailurePolicyOperators))
+	}
+	if container, ok := nestedString(onExitCodes, "containerName"); ok {
+		if err := ValidateDNSLabel(container); err != nil {
+			errs = append(errs, WithFieldPath("containerName", err))
+		}
+	}
+
+	values, _ := nestedSlice(onExitCodes, "values")
+	if len(values) == 0 {
+		errs = append(errs, &ConstraintError{FieldPath: "values", Rule: RuleRequired, Message: "values must hold at least one exit code"})
+	}
+	seen := make(map[int64]bool)
+	for i, value := range values {
+		path := fmt.Sprintf("values[%d]", i)
+		code, ok := toInt64(value)
+		switch {
+		case !ok || code < 0 || code > maxPodFailurePolicyExitCode:
+			errs = append(errs, &ConstraintError{FieldPath: path, BadValue: value, Message: fmt.Sprintf("exit code must be an integer between 0 and %d", maxPodFailurePolicyExitCode)})
+		case code == 0 && operator == "In":
+			errs = append(errs, &ConstraintError{FieldPath: path, BadValue: value, Message: "exit code 0 is not allowed with the In operator"})
+		case seen[code]:
+			errs = append(errs, &ConstraintError{FieldPath: path, BadValue: value, Message: fmt.Sprintf("duplicate exit code %d", code)})
+		}
+		seen[code] = true
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
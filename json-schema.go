@@ -0,0 +1,171 @@
+package k8sconstraints
+
+// JSONSchemaID identifies the schema returned by ManifestJSONSchema.
+const JSONSchemaID = "https://github.com/martinflemingdev/k8s_constraints/manifest.schema.json"
+
+// Patterns of the formats the validators check with byte loops or in several steps,
+// written as regular expressions that JSON Schema validators can evaluate. They use the
+// subset of syntax shared by RE2 and ECMA 262.
+const (
+	dns1123LabelSchemaPattern     = `^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	dns1123SubdomainSchemaPattern = `^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	dns1035LabelSchemaPattern     = `^[a-z]([-a-z0-9]*[a-z0-9])?$`
+	qualifiedNameSchemaPattern    = `^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`
+	labelValueSchemaPattern       = `^(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?$`
+	apiVersionSchemaPattern       = `^([A-Za-z0-9]([A-Za-z0-9.-]*[A-Za-z0-9])?/)?v[0-9]+((alpha|beta)[0-9]+)?$`
+	kindSchemaPattern             = `^[A-Z][a-zA-Z0-9]*$`
+)
+
+// podTemplateKinds are the kinds whose pod spec lives at spec.template.spec.
+var podTemplateKinds = []string{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job"}
+
+// ManifestJSONSchema returns a JSON Schema (draft 2020-12) document encoding the syntax
+// rules of the validators: name formats and lengths, label and annotation keys, label
+// values, apiVersion and kind formats, port numbers and names, and the enumerated values
+// of pod specs, CronJobs, and DaemonSets. Editors such as the YAML language server can use
+// it for completion and inline validation. The schema is a subset of the rules: checks
+// spanning several fields, such as selector matching, are only run by the validators.
+func ManifestJSONSchema() map[string]interface{} {
+	defs := map[string]interface{}{
+		"dns1123Subdomain": stringSchema(RuleDNS1123Subdomain, "RFC 1123 DNS subdomain", dns1123SubdomainSchemaPattern, 253),
+		"dns1123Label":     stringSchema(RuleDNS1123Label, "RFC 1123 DNS label", dns1123LabelSchemaPattern, 63),
+		"dns1035Label":     stringSchema(RuleDNS1035Label, "RFC 1035 DNS label", dns1035LabelSchemaPattern, 63),
+		"qualifiedName":    stringSchema(RuleQualifiedName, "label or annotation key: an optional DNS subdomain prefix and '/', and a name part of at most 63 characters", qualifiedNameSchemaPattern, 253+1+63),
+		"labelValue":       stringSchema(RuleLabelValue, "label value", labelValueSchemaPattern, 63),
+		"apiVersion":       stringSchema(RuleAPIVersion, "apiVersion: an optional API group and '/', and a version such as v1 or v2beta1", apiVersionSchemaPattern, 63),
+		"kind":             stringSchema(RuleKind, "kind: alphanumeric, starting with an uppercase letter", kindSchemaPattern, 63),
+		"portName": map[string]interface{}{
+			"description": "IANA service name (" + RulePortName + "): at most 15 lowercase alphanumerics or '-', containing a letter, with no leading, trailing, or adjacent hyphens",
+			"type":        "string",
+			"maxLength":   maxPortNameLength,
+			"allOf": []interface{}{
+				map[string]interface{}{"pattern": portNamePattern.String()},
+				map[string]interface{}{"pattern": portNameLetterPattern.String()},
+				map[string]interface{}{"not": map[string]interface{}{"pattern": "--"}},
+			},
+		},
+		"portNumber": map[string]interface{}{"type": "integer", "minimum": 1, "maximum": maxPortNumber},
+		"objectMeta": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name":        schemaRef("dns1123Subdomain"),
+				"namespace":   schemaRef("dns1123Label"),
+				"labels":      map[string]interface{}{"type": "object", "propertyNames": schemaRef("qualifiedName"), "additionalProperties": schemaRef("labelValue")},
+				"annotations": map[string]interface{}{"type": "object", "propertyNames": schemaRef("qualifiedName"), "additionalProperties": map[string]interface{}{"type": "string"}},
+			},
+		},
+		"containerPort": map[string]interface{}{
+			"type":     "object",
+			"required": []interface{}{"containerPort"},
+			"properties": map[string]interface{}{
+				"containerPort": schemaRef("portNumber"),
+				"hostPort":      map[string]interface{}{"type": "integer", "minimum": 0, "maximum": maxPortNumber},
+				"name":          schemaRef("portName"),
+				"protocol":      enumSchema(containerProtocols),
+			},
+		},
+		"container": map[string]interface{}{
+			"type":     "object",
+			"required": []interface{}{"name"},
+			"properties": map[string]interface{}{
+				"name":            schemaRef("dns1123Label"),
+				"imagePullPolicy": enumSchema(imagePullPolicies),
+				"ports":           map[string]interface{}{"type": "array", "items": schemaRef("containerPort")},
+			},
+		},
+		"podSpec": map[string]interface{}{
+			"type":     "object",
+			"required": []interface{}{"containers"},
+			"properties": map[string]interface{}{
+				"containers":     map[string]interface{}{"type": "array", "minItems": 1, "items": schemaRef("container")},
+				"initContainers": map[string]interface{}{"type": "array", "items": schemaRef("container")},
+				"restartPolicy":  enumSchema(podRestartPolicies),
+				"dnsPolicy":      enumSchema(dnsPolicies),
+			},
+		},
+	}
+
+	kindRules := []interface{}{
+		kindRule("Namespace", map[string]interface{}{
+			"metadata": map[string]interface{}{"properties": map[string]interface{}{"name": schemaRef("dns1123Label")}},
+		}),
+		kindRule("Service", map[string]interface{}{
+			"metadata": map[string]interface{}{"properties": map[string]interface{}{"name": schemaRef("dns1035Label")}},
+		}),
+		kindRule("Pod", map[string]interface{}{"spec": schemaRef("podSpec")}),
+		kindRule("CronJob", map[string]interface{}{
+			"spec": objectSchema(map[string]interface{}{
+				"concurrencyPolicy": enumSchema(cronJobConcurrencyPolicies),
+				"jobTemplate": objectSchema(map[string]interface{}{
+					"spec": objectSchema(map[string]interface{}{
+						"template": objectSchema(map[string]interface{}{"spec": schemaRef("podSpec")}),
+					}),
+				}),
+			}),
+		}),
+		kindRule("DaemonSet", map[string]interface{}{
+			"spec": objectSchema(map[string]interface{}{
+				"updateStrategy": objectSchema(map[string]interface{}{"type": enumSchema(daemonSetUpdateStrategyTypes)}),
+			}),
+		}),
+	}
+	for _, kind := range podTemplateKinds {
+		kindRules = append(kindRules, kindRule(kind, map[string]interface{}{
+			"spec": objectSchema(map[string]interface{}{
+				"template": objectSchema(map[string]interface{}{"spec": schemaRef("podSpec")}),
+			}),
+		}))
+	}
+
+	return map[string]interface{}{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"$id":         JSONSchemaID,
+		"title":       "Kubernetes manifest",
+		"description": "Syntax rules checked by k8sconstraints",
+		"type":        "object",
+		"required":    []interface{}{"apiVersion", "kind", "metadata"},
+		"properties": map[string]interface{}{
+			"apiVersion": schemaRef("apiVersion"),
+			"kind":       schemaRef("kind"),
+			"metadata":   schemaRef("objectMeta"),
+		},
+		"allOf": kindRules,
+		"$defs": defs,
+	}
+}
+
+// stringSchema returns the schema of a string format checked by rule.
+func stringSchema(rule, description, pattern string, maxLength int) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description + " (" + rule + ")",
+		"type":        "string",
+		"pattern":     pattern,
+		"maxLength":   maxLength,
+	}
+}
+
+// enumSchema returns the schema of a string limited to values.
+func enumSchema(values []string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "enum": stringsToInterfaces(values)}
+}
+
+// objectSchema returns the schema of an object with the given property schemas.
+func objectSchema(properties map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+// schemaRef returns a reference to a definition of the schema.
+func schemaRef(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/$defs/" + name}
+}
+
+// kindRule applies the property schemas to the objects of kind.
+func kindRule(kind string, properties map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"if": map[string]interface{}{
+			"required":   []interface{}{"kind"},
+			"properties": map[string]interface{}{"kind": map[string]interface{}{"const": kind}},
+		},
+		"then": map[string]interface{}{"properties": properties},
+	}
+}
@@ -0,0 +1,63 @@
+package k8sconstraints
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ValidateJSON decodes a single JSON object, such as the output of `kubectl get -o json` or
+// a rendered jsonnet manifest, and runs ValidateObject against it. List objects are
+// expanded and their findings are reported against the individual items. Malformed JSON
+// and values other than a single object are returned as an error.
+func ValidateJSON(data []byte) (DocumentResult, error) {
+	obj, err := decodeJSONObject(data)
+	if err != nil {
+		return DocumentResult{}, err
+	}
+	return DocumentResult{Line: 1, Object: obj, Findings: objectFindings(obj)}, nil
+}
+
+// decodeJSONObject decodes data, which must hold exactly one JSON object.
+func decodeJSONObject(data []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("invalid JSON: unexpected data after the top-level object")
+	}
+
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid JSON: document must be an object, not a JSON %s", jsonTypeName(value))
+	}
+	return obj, nil
+}
+
+// objectFindings runs ValidateObject and the registered rules against obj and returns their
+// findings. The items of List objects are validated one by one, so their findings name the
+// item rather than the List and carry the item's path, e.g. items[2].metadata.name.
+func objectFindings(obj map[string]interface{}) []Finding {
+	return objectFindingsAt(obj, "", ValidateObject)
+}
+
+// objectFindingsAt is objectFindings for an object found at path within its document, such
+// as a streamed List item, with validate in place of ValidateObject.
+func objectFindingsAt(obj map[string]interface{}, path string, validate func(map[string]interface{}) error) []Finding {
+	if !IsList(obj) {
+		return append(FindingsFromError(obj, WithFieldPath(path, validate(obj))), ruleFindings(obj, path)...)
+	}
+
+	items, err := expandList(obj, path)
+	findings := FindingsFromError(obj, err)
+	for _, item := range items {
+		findings = append(findings, FindingsFromError(item.Object, WithFieldPath(item.Path, validate(item.Object)))...)
+		findings = append(findings, ruleFindings(item.Object, item.Path)...)
+	}
+	return findings
+}
@@ -0,0 +1,217 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPathSegmentType identifies a step in a parsed JSONPath expression.
+type jsonPathSegmentType int
+
+const (
+	segmentField     jsonPathSegmentType = iota // .name or ['name']
+	segmentIndex                                // [3] or [-1]
+	segmentWildcard                             // [*] or .*
+	segmentRecursive                            // ..name
+)
+
+// jsonPathSegment is a single step in a parsed JSONPath expression.
+type jsonPathSegment struct {
+	typ   jsonPathSegmentType
+	name  string
+	index int
+}
+
+// JSONPath is a compiled JSONPath expression. It supports the subset of the Kubernetes
+// JSONPath syntax used to address fields: an optional `{...}` wrapper and leading `$`,
+// child fields (`.name`, `['name']`), array indexes (`[0]`, `[-1]`), wildcards (`[*]`, `.*`),
+// and recursive descent (`..name`). Filters, unions, and slices are not supported.
+type JSONPath struct {
+	expression string
+	segments   []jsonPathSegment
+}
+
+// JSONPathMatch is a value selected by a JSONPath together with its concrete field path,
+// e.g. spec.containers[0].image.
+type JSONPathMatch struct {
+	Path  string
+	Value interface{}
+}
+
+// CompileJSONPath parses a JSONPath expression.
+func CompileJSONPath(expression string) (*JSONPath, error) {
+	s := strings.TrimSpace(expression)
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	s = strings.TrimPrefix(s, "$")
+	if s == "" {
+		return nil, errors.New("JSONPath expression cannot be empty")
+	}
+
+	segments := make([]jsonPathSegment, 0)
+	for len(s) > 0 {
+		switch {
+		case strings.HasPrefix(s, ".."):
+			name, rest := readJSONPathName(s[2:])
+			if name == "" {
+				return nil, fmt.Errorf("invalid JSONPath '%s': recursive descent must be followed by a field name", expression)
+			}
+			segments = append(segments, jsonPathSegment{typ: segmentRecursive, name: name})
+			s = rest
+		case strings.HasPrefix(s, "."):
+			name, rest := readJSONPathName(s[1:])
+			switch name {
+			case "":
+				return nil, fmt.Errorf("invalid JSONPath '%s': expected a field name after '.'", expression)
+			case "*":
+				segments = append(segments, jsonPathSegment{typ: segmentWildcard})
+			default:
+				segments = append(segments, jsonPathSegment{typ: segmentField, name: name})
+			}
+			s = rest
+		case strings.HasPrefix(s, "["):
+			end := strings.Index(s, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSONPath '%s': unterminated '['", expression)
+			}
+			segment, err := parseJSONPathBracket(strings.TrimSpace(s[1:end]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSONPath '%s': %v", expression, err)
+			}
+			segments = append(segments, segment)
+			s = s[end+1:]
+		default:
+			// Allow a bare leading field name, e.g. "spec.replicas"
+			if len(segments) > 0 {
+				return nil, fmt.Errorf("invalid JSONPath '%s': unexpected '%s'", expression, s)
+			}
+			name, rest := readJSONPathName(s)
+			if name == "" {
+				return nil, fmt.Errorf("invalid JSONPath '%s': unexpected '%s'", expression, s)
+			}
+			segments = append(segments, jsonPathSegment{typ: segmentField, name: name})
+			s = rest
+		}
+	}
+
+	return &JSONPath{expression: expression, segments: segments}, nil
+}
+
+// readJSONPathName reads a field name up to the next '.' or '['.
+func readJSONPathName(s string) (string, string) {
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// parseJSONPathBracket parses the contents of a [...] segment.
+func parseJSONPathBracket(inner string) (jsonPathSegment, error) {
+	if inner == "*" {
+		return jsonPathSegment{typ: segmentWildcard}, nil
+	}
+	if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+		return jsonPathSegment{typ: segmentField, name: inner[1 : len(inner)-1]}, nil
+	}
+	index, err := strconv.Atoi(inner)
+	if err != nil {
+		return jsonPathSegment{}, fmt.Errorf("unsupported bracket expression '[%s]'; only indexes, '*', and quoted field names are supported", inner)
+	}
+	return jsonPathSegment{typ: segmentIndex, index: index}, nil
+}
+
+// String returns the original expression.
+func (p *JSONPath) String() string {
+	return p.expression
+}
+
+// Find returns every value in obj selected by the path, in document order.
+func (p *JSONPath) Find(obj interface{}) []JSONPathMatch {
+	matches := []JSONPathMatch{{Path: "", Value: obj}}
+	for _, segment := range p.segments {
+		next := make([]JSONPathMatch, 0)
+		for _, match := range matches {
+			next = append(next, applyJSONPathSegment(segment, match)...)
+		}
+		matches = next
+	}
+	return matches
+}
+
+// applyJSONPathSegment applies a single segment to a matched value.
+func applyJSONPathSegment(segment jsonPathSegment, match JSONPathMatch) []JSONPathMatch {
+	switch segment.typ {
+	case segmentField:
+		m, ok := match.Value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value, ok := m[segment.name]
+		if !ok {
+			return nil
+		}
+		return []JSONPathMatch{{Path: jsonPathField(match.Path, segment.name), Value: value}}
+	case segmentIndex:
+		list, ok := match.Value.([]interface{})
+		if !ok {
+			return nil
+		}
+		index := segment.index
+		if index < 0 {
+			index += len(list)
+		}
+		if index < 0 || index >= len(list) {
+			return nil
+		}
+		return []JSONPathMatch{{Path: fmt.Sprintf("%s[%d]", match.Path, index), Value: list[index]}}
+	case segmentWildcard:
+		return jsonPathChildren(match)
+	case segmentRecursive:
+		results := make([]JSONPathMatch, 0)
+		if m, ok := match.Value.(map[string]interface{}); ok {
+			if value, ok := m[segment.name]; ok {
+				results = append(results, JSONPathMatch{Path: jsonPathField(match.Path, segment.name), Value: value})
+			}
+		}
+		for _, child := range jsonPathChildren(match) {
+			results = append(results, applyJSONPathSegment(segment, child)...)
+		}
+		return results
+	}
+	return nil
+}
+
+// jsonPathField returns the field path of the field name of the map at path. Names other
+// than plain identifiers, such as label keys, are written as quoted keys, e.g.
+// metadata.labels["app.kubernetes.io/name"], so the path stays unambiguous.
+func jsonPathField(path string, name string) string {
+	if name == "" || strings.ContainsFunc(name, func(r rune) bool {
+		return !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		return path + fmt.Sprintf("[%q]", name)
+	}
+	return joinFieldPath(path, name)
+}
+
+// jsonPathChildren returns the direct children of a matched map or list.
+func jsonPathChildren(match JSONPathMatch) []JSONPathMatch {
+	switch v := match.Value.(type) {
+	case map[string]interface{}:
+		children := make([]JSONPathMatch, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			children = append(children, JSONPathMatch{Path: jsonPathField(match.Path, key), Value: v[key]})
+		}
+		return children
+	case []interface{}:
+		children := make([]JSONPathMatch, 0, len(v))
+		for i, item := range v {
+			children = append(children, JSONPathMatch{Path: fmt.Sprintf("%s[%d]", match.Path, i), Value: item})
+		}
+		return children
+	}
+	return nil
+}
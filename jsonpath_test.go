@@ -0,0 +1,151 @@
+package k8sconstraints
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompileJSONPath(t *testing.T) {
+	tests := []struct {
+		expression string
+		// finding is part of the expected message; "" expects no error
+		finding string
+	}{
+		{"spec.replicas", ""},
+		{".spec.replicas", ""},
+		{"$.spec.containers[*].image", ""},
+		{"{.metadata.labels['app.kubernetes.io/name']}", ""},
+		{"spec.containers[-1]", ""},
+		{"..image", ""},
+		{"", "JSONPath expression cannot be empty"},
+		{"{$}", "JSONPath expression cannot be empty"},
+		{"spec.", "invalid JSONPath 'spec.': expected a field name after '.'"},
+		{"spec..", "invalid JSONPath 'spec..': recursive descent must be followed by a field name"},
+		{"spec.containers[0", "invalid JSONPath 'spec.containers[0': unterminated '['"},
+		{"spec.containers[?(@.name=='web')]", "unsupported bracket expression"},
+		{"spec.containers[0:2]", "unsupported bracket expression '[0:2]'"},
+		{"spec[0]x", "invalid JSONPath 'spec[0]x': unexpected 'x'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			_, err := CompileJSONPath(tt.expression)
+			if tt.finding == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.finding) {
+				t.Errorf("expected an error containing %q, got %v", tt.finding, err)
+			}
+		})
+	}
+}
+
+func TestJSONPathFind(t *testing.T) {
+	obj := decodeTestObject(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels: {app.kubernetes.io/name: web}
+spec:
+  replicas: 3
+  template:
+    spec:
+      initContainers: [{name: init, image: "busybox:1.36"}]
+      containers: [{name: web, image: "nginx:1.27"}, {name: proxy, image: "envoy:1.30"}]`)
+
+	tests := []struct {
+		expression string
+		// want holds the paths of the matches, in order
+		want []string
+	}{
+		{"spec.replicas", []string{"spec.replicas"}},
+		{"{.metadata.labels['app.kubernetes.io/name']}", []string{`metadata.labels["app.kubernetes.io/name"]`}},
+		{"spec.template.spec.containers[*].image", []string{"spec.template.spec.containers[0].image", "spec.template.spec.containers[1].image"}},
+		{"spec.template.spec.containers[-1].name", []string{"spec.template.spec.containers[1].name"}},
+		{"spec.template.spec.containers[2].name", []string{}},
+		{"..image", []string{"spec.template.spec.containers[0].image", "spec.template.spec.containers[1].image", "spec.template.spec.initContainers[0].image"}},
+		{"metadata.*", []string{"metadata.labels", "metadata.name"}},
+		{"spec.strategy.type", []string{}},
+		{"spec.replicas.value", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			path, err := CompileJSONPath(tt.expression)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			got := make([]string, 0)
+			for _, match := range path.Find(obj) {
+				got = append(got, match.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateFieldConstraints(t *testing.T) {
+	obj := decodeTestObject(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata: {name: web}
+spec:
+  replicas: 12
+  template:
+    spec:
+      containers: [{name: web, image: "nginx:1.27"}, {name: proxy, image: "docker.io/envoy:1.30"}]`)
+
+	maxReplicas := 10.0
+	tests := []struct {
+		name       string
+		constraint FieldConstraint
+		// finding is part of the expected message; "" expects no error
+		finding string
+	}{
+		{"pattern", FieldConstraint{Path: "spec.template.spec.containers[*].image", Pattern: `^registry\.example\.com/`},
+			"spec.template.spec.containers[0].image: "},
+		{"maximum", FieldConstraint{Path: "spec.replicas", Maximum: &maxReplicas},
+			"spec.replicas: value 12 is greater than maximum 10"},
+		{"enum", FieldConstraint{Path: "spec.template.spec.containers[*].name", Enum: []string{"web", "sidecar"}},
+			"spec.template.spec.containers[1].name: "},
+		{"other kind", FieldConstraint{Path: "spec.replicas", Maximum: &maxReplicas, Kinds: []string{"StatefulSet"}}, ""},
+		{"path selecting nothing", FieldConstraint{Path: "spec.strategy.type", Enum: []string{"Recreate"}}, ""},
+		{"non-scalar value", FieldConstraint{Path: "spec.template", Pattern: ".*"},
+			"spec.template: expected a scalar value for constraint on 'spec.template'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFieldConstraints(obj, []FieldConstraint{tt.constraint})
+			if tt.finding == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.finding) {
+				t.Errorf("expected an error containing %q, got %v", tt.finding, err)
+			}
+			if !errorsIsRule(err, CheckFieldConstraints) {
+				t.Errorf("expected findings with rule %s, got %v", CheckFieldConstraints, err)
+			}
+		})
+	}
+}
+
+// errorsIsRule reports whether every violation of err carries rule.
+func errorsIsRule(err error, rule string) bool {
+	for _, violation := range ConstraintErrors(err) {
+		if violation.Rule != rule {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,145 @@
+package k8sconstraints
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FormatJUnit renders a report as JUnit XML for test-report dashboards.
+const FormatJUnit = "junit"
+
+// JUnit XML structure as understood by Jenkins and GitLab.
+type (
+	junitTestSuites struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Name     string           `xml:"name,attr"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Suites   []junitTestSuite `xml:"testsuite"`
+	}
+	junitTestSuite struct {
+		Name     string          `xml:"name,attr"`
+		Tests    int             `xml:"tests,attr"`
+		Failures int             `xml:"failures,attr"`
+		Cases    []junitTestCase `xml:"testcase"`
+	}
+	junitTestCase struct {
+		ClassName string        `xml:"classname,attr"`
+		Name      string        `xml:"name,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+		SystemOut string        `xml:"system-out,omitempty"`
+	}
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+// junitDocument identifies a document within a report.
+type junitDocument struct {
+	file     string
+	document int
+}
+
+// writeJUnitReport encodes the report as JUnit XML with one test suite per file and one test
+// case per document and rule pair. Documents without findings get a single passing test
+// case. Error findings are failures; warnings are attached as output of passing test cases,
+// so they show up without failing the pipeline.
+func writeJUnitReport(w io.Writer, report Report) error {
+	suites := make([]junitTestSuite, 0)
+	suiteIndex := make(map[string]int)
+	suiteFor := func(file string) *junitTestSuite {
+		i, ok := suiteIndex[file]
+		if !ok {
+			i = len(suites)
+			suiteIndex[file] = i
+			suites = append(suites, junitTestSuite{Name: displayFileName(file)})
+		}
+		return &suites[i]
+	}
+
+	// Group findings per document and rule, keeping their order
+	type caseKey struct {
+		doc  junitDocument
+		rule string
+	}
+	caseFindings := make(map[caseKey][]Finding)
+	caseOrder := make(map[junitDocument][]string)
+	for _, finding := range report.Findings {
+		doc := junitDocument{finding.File, finding.Document}
+		rule := sarifRuleID(finding)
+		key := caseKey{doc, rule}
+		if _, ok := caseFindings[key]; !ok {
+			caseOrder[doc] = append(caseOrder[doc], rule)
+		}
+		caseFindings[key] = append(caseFindings[key], finding)
+	}
+
+	// Documents are taken from the resource inventory, falling back to the findings for
+	// reports built without one
+	documents := make([]junitDocument, 0)
+	names := make(map[junitDocument]string)
+	addDocument := func(doc junitDocument, resource ResourceRef) {
+		if _, ok := names[doc]; ok {
+			return
+		}
+		name := resource.String()
+		if name == "" {
+			name = "<unknown>"
+		}
+		names[doc] = fmt.Sprintf("%s (document %d)", name, doc.document)
+		documents = append(documents, doc)
+	}
+	for _, resource := range report.Resources {
+		addDocument(junitDocument{resource.File, resource.Document}, resource.Resource)
+	}
+	for _, finding := range report.Findings {
+		addDocument(junitDocument{finding.File, finding.Document}, finding.Resource)
+	}
+
+	for _, doc := range documents {
+		suite := suiteFor(doc.file)
+		rules := caseOrder[doc]
+		if len(rules) == 0 {
+			suite.Cases = append(suite.Cases, junitTestCase{ClassName: displayFileName(doc.file), Name: names[doc]})
+		}
+		for _, rule := range rules {
+			testCase := junitTestCase{ClassName: displayFileName(doc.file), Name: names[doc] + ": " + rule}
+			errors, warnings := make([]string, 0), make([]string, 0)
+			for _, finding := range caseFindings[caseKey{doc, rule}] {
+				if finding.Severity != SeverityError {
+					warnings = append(warnings, FormatFinding(finding))
+				} else {
+					errors = append(errors, FormatFinding(finding))
+				}
+			}
+			if len(errors) > 0 {
+				testCase.Failure = &junitFailure{Message: errors[0], Type: rule, Text: strings.Join(errors, "\n")}
+				suite.Failures++
+			}
+			testCase.SystemOut = strings.Join(warnings, "\n")
+			suite.Cases = append(suite.Cases, testCase)
+		}
+	}
+
+	result := junitTestSuites{Name: "k8sconstraints", Suites: suites}
+	for i := range suites {
+		suites[i].Tests = len(suites[i].Cases)
+		result.Tests += suites[i].Tests
+		result.Failures += suites[i].Failures
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
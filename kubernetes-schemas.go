@@ -0,0 +1,316 @@
+package k8sconstraints
+
+import (
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// CheckOpenAPISchema is the ID of the check built by OpenAPISchemaCheck, and the rule code
+// of its findings.
+const CheckOpenAPISchema = "OpenAPISchema"
+
+// EmbeddedKubernetesVersion is the Kubernetes release whose OpenAPI definitions are built
+// in; see EmbeddedKubernetesSchemas.
+const EmbeddedKubernetesVersion = "1.36"
+
+// embeddedKubernetesOpenAPI holds the definitions of api/openapi-spec/swagger.json of
+// Kubernetes v1.36.3, gzip-compressed, with descriptions and the x-kubernetes-* extensions
+// the validator does not use removed by schemas/generate.go. Properties named after the
+// extensions, such as those of CustomResourceDefinition schemas, are kept.
+//
+//go:generate go run schemas/generate.go -version v1.36.3 -o schemas/kubernetes-1.36.json.gz
+//go:embed schemas/kubernetes-1.36.json.gz
+var embeddedKubernetesOpenAPI []byte
+
+// quantityDefinition is the definition of resource quantities, which the OpenAPI documents
+// declare as strings although manifests commonly write them as numbers, e.g. cpu: 1.
+const quantityDefinition = "io.k8s.apimachinery.pkg.api.resource.Quantity"
+
+// KubernetesSchemas holds the schemas of the built-in Kubernetes types, keyed like
+// CRDSchemas, for structural validation: unknown fields, wrong types, and missing required
+// fields, as the API server reports them.
+type KubernetesSchemas struct {
+	schemas map[string]*OpenAPISchema
+}
+
+// openAPIDefinition is a schema of a Kubernetes OpenAPI document, which may refer to the
+// other definitions of the document.
+type openAPIDefinition struct {
+	Ref                  string                        `json:"$ref,omitempty"`
+	Type                 string                        `json:"type,omitempty"`
+	Format               string                        `json:"format,omitempty"`
+	Properties           map[string]*openAPIDefinition `json:"properties,omitempty"`
+	Required             []string                      `json:"required,omitempty"`
+	Items                *openAPIDefinition            `json:"items,omitempty"`
+	AdditionalProperties *openAPIDefinition            `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}                 `json:"enum,omitempty"`
+	AllOf                []*openAPIDefinition          `json:"allOf,omitempty"`
+
+	GroupVersionKinds      []GroupVersionKind `json:"x-kubernetes-group-version-kind,omitempty"`
+	XPreserveUnknownFields *bool              `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+	XEmbeddedResource      bool               `json:"x-kubernetes-embedded-resource,omitempty"`
+	XIntOrString           bool               `json:"x-kubernetes-int-or-string,omitempty"`
+}
+
+// openAPIDocument holds the definitions of an OpenAPI v2 document (swagger.json) or the
+// component schemas of an OpenAPI v3 document, such as api/openapi-spec/v3/apis__apps__v1_openapi.json.
+type openAPIDocument struct {
+	Definitions map[string]*openAPIDefinition `json:"definitions"`
+	Components  struct {
+		Schemas map[string]*openAPIDefinition `json:"schemas"`
+	} `json:"components"`
+}
+
+var (
+	embeddedSchemas     *KubernetesSchemas
+	embeddedSchemasErr  error
+	embeddedSchemasOnce sync.Once
+)
+
+// EmbeddedKubernetesSchemas returns the schemas of the Kubernetes release built into this
+// package, EmbeddedKubernetesVersion. They are decoded on first use.
+func EmbeddedKubernetesSchemas() (*KubernetesSchemas, error) {
+	embeddedSchemasOnce.Do(func() {
+		embeddedSchemas, embeddedSchemasErr = LoadKubernetesOpenAPI(embeddedKubernetesOpenAPI)
+	})
+	return embeddedSchemas, embeddedSchemasErr
+}
+
+// LoadKubernetesOpenAPIFile reads the schemas of a Kubernetes release from an OpenAPI
+// document; see LoadKubernetesOpenAPI.
+func LoadKubernetesOpenAPIFile(path string) (*KubernetesSchemas, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	schemas, err := LoadKubernetesOpenAPI(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document '%s': %v", path, err)
+	}
+	return schemas, nil
+}
+
+// LoadKubernetesOpenAPI decodes the schemas of the types in an OpenAPI document published
+// by Kubernetes or served by an API server at /openapi/v2 or /openapi/v3: swagger.json, or
+// one of the per-group OpenAPI v3 documents. data may be gzip-compressed. Types are
+// identified by their x-kubernetes-group-version-kind extension; definitions without it are
+// only reachable through references.
+func LoadKubernetesOpenAPI(data []byte) (*KubernetesSchemas, error) {
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if data, err = io.ReadAll(reader); err != nil {
+			return nil, err
+		}
+	}
+
+	var document openAPIDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	definitions := document.Definitions
+	if len(definitions) == 0 {
+		definitions = document.Components.Schemas
+	}
+	if len(definitions) == 0 {
+		return nil, fmt.Errorf("document has no definitions or component schemas")
+	}
+
+	converter := openAPIConverter{definitions: definitions, converted: make(map[string]*OpenAPISchema)}
+	set := &KubernetesSchemas{schemas: make(map[string]*OpenAPISchema)}
+	errs := make([]error, 0)
+	for _, name := range sortedKeys(definitions) {
+		for _, gvk := range definitions[name].GroupVersionKinds {
+			schema, err := converter.definition(name)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("definition '%s': %v", name, err))
+				break
+			}
+			set.schemas[crdSchemaKey(gvk.APIVersion(), gvk.Kind)] = schema
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return nil, JoinErrors(errs)
+	}
+
+	return set, nil
+}
+
+// openAPIConverter converts the definitions of a document into OpenAPISchemas, resolving
+// references. Definitions are converted once and shared, which keeps recursive types such
+// as JSONSchemaProps finite.
+type openAPIConverter struct {
+	definitions map[string]*openAPIDefinition
+	converted   map[string]*OpenAPISchema
+}
+
+// definition returns the converted definition name.
+func (c *openAPIConverter) definition(name string) (*OpenAPISchema, error) {
+	if schema, ok := c.converted[name]; ok {
+		return schema, nil
+	}
+	definition, ok := c.definitions[name]
+	if !ok {
+		return nil, fmt.Errorf("reference to unknown definition '%s'", name)
+	}
+
+	schema := &OpenAPISchema{}
+	c.converted[name] = schema
+	if name == quantityDefinition {
+		// Any scalar; the Quantity rule checks the format
+		schema.Nullable = true
+		return schema, nil
+	}
+	return schema, c.fill(schema, definition)
+}
+
+// schema converts an inline schema, which may be a reference.
+func (c *openAPIConverter) schema(definition *openAPIDefinition) (*OpenAPISchema, error) {
+	if definition == nil {
+		return nil, nil
+	}
+	if definition.Ref != "" {
+		return c.definition(definition.Ref[strings.LastIndex(definition.Ref, "/")+1:])
+	}
+	// OpenAPI v3 documents wrap references that carry a default in allOf
+	if len(definition.AllOf) == 1 && definition.Type == "" && len(definition.Properties) == 0 {
+		return c.schema(definition.AllOf[0])
+	}
+	schema := &OpenAPISchema{}
+	return schema, c.fill(schema, definition)
+}
+
+// fill converts definition into schema. Every value may be null, which the API server
+// treats as unset, as kubectl writes for empty fields such as creationTimestamp.
+func (c *openAPIConverter) fill(schema *OpenAPISchema, definition *openAPIDefinition) error {
+	schema.Type = definition.Type
+	schema.Format = definition.Format
+	schema.Required = definition.Required
+	schema.Enum = definition.Enum
+	schema.Nullable = true
+	schema.XPreserveUnknownFields = definition.XPreserveUnknownFields
+	schema.XEmbeddedResource = definition.XEmbeddedResource
+	schema.XIntOrString = definition.XIntOrString || definition.Format == "int-or-string"
+	if schema.XIntOrString {
+		schema.Type = ""
+	}
+
+	errs := make([]error, 0)
+	if len(definition.Properties) > 0 {
+		schema.Properties = make(map[string]*OpenAPISchema, len(definition.Properties))
+		for _, name := range sortedKeys(definition.Properties) {
+			property, err := c.schema(definition.Properties[name])
+			if err != nil {
+				errs = append(errs, err)
+			}
+			schema.Properties[name] = property
+		}
+	}
+	items, err := c.schema(definition.Items)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	schema.Items = items
+	if definition.AdditionalProperties != nil {
+		additional, err := c.schema(definition.AdditionalProperties)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		schema.AdditionalProperties = &OpenAPISchemaOrBool{Allows: true, Schema: additional}
+	}
+	for _, sub := range definition.AllOf {
+		converted, err := c.schema(sub)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		schema.AllOf = append(schema.AllOf, converted)
+	}
+
+	// Objects without declared fields, such as RawExtension, hold arbitrary content
+	if schema.Type == "object" && len(schema.Properties) == 0 && schema.AdditionalProperties == nil {
+		preserve := true
+		schema.XPreserveUnknownFields = &preserve
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// SchemaFor returns the schema for an apiVersion and kind, if the set has one.
+func (k *KubernetesSchemas) SchemaFor(apiVersion string, kind string) (*OpenAPISchema, bool) {
+	if k == nil {
+		return nil, false
+	}
+	schema, ok := k.schemas[crdSchemaKey(apiVersion, kind)]
+	return schema, ok
+}
+
+// Kinds returns the types the set holds schemas for, rendered like GroupVersionKind.String
+// and sorted.
+func (k *KubernetesSchemas) Kinds() []string {
+	if k == nil {
+		return nil
+	}
+	kinds := make([]string, 0, len(k.schemas))
+	for _, key := range sortedKeys(k.schemas) {
+		apiVersion, kind, _ := strings.Cut(key, ", Kind=")
+		kinds = append(kinds, apiVersion+" "+kind)
+	}
+	return kinds
+}
+
+// Validate checks obj against the schema of its type: unknown fields, values of the wrong
+// type, values outside an enum, and missing required fields. The boolean result reports
+// whether a schema was known for obj; objects of other types, such as custom resources,
+// are not checked.
+func (k *KubernetesSchemas) Validate(obj map[string]interface{}) (bool, error) {
+	apiVersion, _ := nestedString(obj, "apiVersion")
+	kind, _ := nestedString(obj, "kind")
+	schema, ok := k.SchemaFor(apiVersion, kind)
+	if !ok {
+		return false, nil
+	}
+
+	errs := schema.Validate(obj)
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return true, schemaViolations(CheckOpenAPISchema, errs)
+	}
+
+	return true, nil
+}
+
+// OpenAPISchemaCheck returns an opt-in check, not run by ValidateObject, that validates
+// objects of the built-in Kubernetes types against schemas, complementing the format rules
+// with the structural checks of the API server; see KubernetesSchemas.Validate. When
+// schemas is nil, the embedded schemas of EmbeddedKubernetesVersion are used. Run it with
+// RunChecks or pass it to the linter's Checks option.
+func OpenAPISchemaCheck(schemas *KubernetesSchemas) Check {
+	return Check{ID: CheckOpenAPISchema, Validate: func(obj map[string]interface{}) error {
+		set := schemas
+		if set == nil {
+			var err error
+			if set, err = EmbeddedKubernetesSchemas(); err != nil {
+				return err
+			}
+		}
+		_, err := set.Validate(obj)
+		return err
+	}}
+}
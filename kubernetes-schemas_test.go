@@ -0,0 +1,97 @@
+package k8sconstraints
+
+import (
+	"strings"
+	"testing"
+)
+
+// decodeTestObject decodes a single YAML manifest.
+func decodeTestObject(t *testing.T, manifest string) map[string]interface{} {
+	t.Helper()
+	doc, err := NewYAMLSource("test.yaml", strings.NewReader(manifest)).Next()
+	if err != nil {
+		t.Fatalf("reading manifest: %v", err)
+	}
+	if doc.Err != nil {
+		t.Fatalf("decoding manifest: %v", doc.Err)
+	}
+	return doc.Object
+}
+
+func TestEmbeddedSchemasAcceptCRDExtensions(t *testing.T) {
+	tests := []struct {
+		name      string
+		extension string
+	}{
+		{"validations", `
+              x-kubernetes-validations:
+                - rule: self.minReplicas <= self.maxReplicas
+                  message: minReplicas must not exceed maxReplicas`},
+		{"list type", `
+              x-kubernetes-list-type: map`},
+		{"list map keys", `
+              x-kubernetes-list-map-keys: [name]`},
+		{"map type", `
+              x-kubernetes-map-type: atomic`},
+		{"preserve unknown fields", `
+              x-kubernetes-preserve-unknown-fields: true`},
+		{"embedded resource", `
+              x-kubernetes-embedded-resource: true`},
+		{"int or string", `
+              x-kubernetes-int-or-string: true`},
+	}
+
+	check := OpenAPISchemaCheck(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crd := decodeTestObject(t, `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names: {kind: Widget, plural: widgets}
+  scope: Namespaced
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object`+tt.extension+`
+`)
+			if err := RunChecks(crd, []Check{check}); err != nil {
+				t.Errorf("unexpected findings: %v", err)
+			}
+		})
+	}
+}
+
+func TestEmbeddedSchemasReportUnknownCRDFields(t *testing.T) {
+	crd := decodeTestObject(t, `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names: {kind: Widget, plural: widgets}
+  scope: Namespaced
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      schema:
+        openAPIV3Schema:
+          type: object
+          x-kubernetes-validation: []
+`)
+	err := RunChecks(crd, []Check{OpenAPISchemaCheck(nil)})
+	if err == nil || !strings.Contains(err.Error(), "x-kubernetes-validation") {
+		t.Errorf("expected an unknown field finding for x-kubernetes-validation, got %v", err)
+	}
+}
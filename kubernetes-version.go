@@ -0,0 +1,149 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckKubernetesVersionFields is the ID of the check built by KubernetesVersionFieldsCheck.
+const CheckKubernetesVersionFields = "KubernetesVersionFields"
+
+// podFieldRequirement is a pod spec field that older API servers do not know, or only know
+// behind a disabled feature gate, and drop silently.
+type podFieldRequirement struct {
+	// field names the field in messages, relative to the pod spec
+	field string
+	// since is the first release that serves the field by default
+	since string
+	// find returns the paths of the uses of the field, relative to the pod spec
+	find func(spec map[string]interface{}) []string
+}
+
+// podFieldRequirements lists the version-dependent pod spec fields.
+var podFieldRequirements = []podFieldRequirement{
+	{"os", "1.24", presentAt("os")},
+	{"schedulingGates", "1.27", presentAt("schedulingGates")},
+	{"initContainers[].restartPolicy", "1.29", func(spec map[string]interface{}) []string {
+		// Init containers with restartPolicy Always are sidecars
+		paths := make([]string, 0)
+		initContainers, _ := nestedSlice(spec, "initContainers")
+		for i, raw := range initContainers {
+			container, _ := raw.(map[string]interface{})
+			if _, ok := nestedField(container, "restartPolicy"); ok {
+				paths = append(paths, fmt.Sprintf("initContainers[%d].restartPolicy", i))
+			}
+		}
+		return paths
+	}},
+	{"securityContext.appArmorProfile", "1.30", presentAt("securityContext", "appArmorProfile")},
+}
+
+// presentAt returns a podFieldRequirement finder for a single field.
+func presentAt(fields ...string) func(spec map[string]interface{}) []string {
+	return func(spec map[string]interface{}) []string {
+		if _, ok := nestedField(spec, fields...); ok {
+			return []string{strings.Join(fields, ".")}
+		}
+		return nil
+	}
+}
+
+// KubernetesVersionFieldsCheck returns an opt-in check, not run by ValidateObject, that
+// reports pod spec fields the Kubernetes release targetVersion does not serve by default,
+// such as os before 1.24 or sidecar init containers before 1.29. API servers drop such
+// fields silently, so the workload runs without them.
+func KubernetesVersionFieldsCheck(targetVersion string) Check {
+	return Check{ID: CheckKubernetesVersionFields, Validate: func(obj map[string]interface{}) error {
+		return ValidateKubernetesVersionFields(obj, targetVersion)
+	}}
+}
+
+// ValidateKubernetesVersionFields reports the fields of the pod spec embedded in obj that
+// the Kubernetes release targetVersion does not serve by default; see
+// KubernetesVersionFieldsCheck.
+func ValidateKubernetesVersionFields(obj map[string]interface{}, targetVersion string) error {
+	target, err := parseReleaseVersion(targetVersion)
+	if err != nil {
+		return ValidateKubernetesVersion(targetVersion)
+	}
+	spec, path, ok := findPodSpec(obj)
+	if !ok {
+		return nil
+	}
+
+	errs := make([]error, 0)
+	for _, requirement := range podFieldRequirements {
+		since, _ := parseReleaseVersion(requirement.since)
+		if compareReleaseVersions(target, since) >= 0 {
+			continue
+		}
+		for _, fieldPath := range requirement.find(spec) {
+			message := fmt.Sprintf("%s requires Kubernetes %s or later; the API server of %s drops it", requirement.field, requirement.since, targetVersion)
+			errs = append(errs, &ConstraintError{FieldPath: path + "." + fieldPath, Message: message})
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// KubernetesVersionChecks returns the opt-in checks that depend on the Kubernetes release
+// targetVersion, such as "1.28": DeprecatedAPIsCheck and KubernetesVersionFieldsCheck.
+func KubernetesVersionChecks(targetVersion string) []Check {
+	return []Check{DeprecatedAPIsCheck(targetVersion), KubernetesVersionFieldsCheck(targetVersion)}
+}
+
+// Option configures a Validator.
+type Option func(*Validator)
+
+// WithKubernetesVersion makes a Validator check manifests against the Kubernetes release
+// version, such as "1.28", adding the KubernetesVersionChecks. Create one Validator per
+// version to validate the same manifests against several clusters.
+func WithKubernetesVersion(version string) Option {
+	return func(v *Validator) {
+		v.kubernetesVersion = version
+	}
+}
+
+// Validator runs the built-in checks of ValidateObject along with the checks its options
+// enable.
+type Validator struct {
+	kubernetesVersion string
+	concurrency       int
+	checks            []Check
+}
+
+// NewValidator returns a Validator configured by opts.
+func NewValidator(opts ...Option) (*Validator, error) {
+	v := &Validator{}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	v.checks = BuiltinChecks()
+	if v.kubernetesVersion != "" {
+		if err := ValidateKubernetesVersion(v.kubernetesVersion); err != nil {
+			return nil, err
+		}
+		v.checks = append(v.checks, KubernetesVersionChecks(v.kubernetesVersion)...)
+	}
+	return v, nil
+}
+
+// Checks returns a copy of the checks the Validator runs.
+func (v *Validator) Checks() []Check {
+	return append([]Check(nil), v.checks...)
+}
+
+// Validate runs the checks of the Validator against obj, expanding List documents like
+// ValidateObject.
+func (v *Validator) Validate(obj map[string]interface{}) error {
+	if IsList(obj) {
+		return ValidateListItems(obj, v.Validate)
+	}
+	return RunChecks(obj, v.checks)
+}
@@ -0,0 +1,136 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckLabelValueEnums is the ID of the check built by LabelValueEnumsCheck.
+const CheckLabelValueEnums = "LabelValueEnums"
+
+// LabelValueEnums maps a label key to the values it may take, e.g.
+// environment: [dev, staging, prod].
+type LabelValueEnums map[string][]string
+
+// Validate checks that every key is a valid label key and every value a valid label value.
+func (enums LabelValueEnums) Validate() error {
+	errs := make([]error, 0)
+	for _, key := range sortedKeys(enums) {
+		if err := ValidateLabelKey(key); err != nil {
+			errs = append(errs, fmt.Errorf("invalid label key '%s': %v", key, err))
+		}
+		for _, value := range enums[key] {
+			if err := ValidateLabelValue(value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid value '%s' for key '%s': %v", value, key, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// LabelValueEnumsCheck returns an opt-in check, not run by ValidateObject, that reports
+// labels and label selectors whose values are outside enums; see ValidateLabelValueEnums.
+// Run it with RunChecks or pass it to the linter's Checks option.
+func LabelValueEnumsCheck(enums LabelValueEnums) Check {
+	return Check{ID: CheckLabelValueEnums, Validate: func(obj map[string]interface{}) error {
+		return ValidateLabelValueEnums(obj, enums)
+	}}
+}
+
+// ValidateLabelValueEnums checks every place a label key can appear in obj —
+// metadata.labels (including pod templates), label selectors, matchExpressions, and
+// nodeSelector — against the configured enumerations.
+func ValidateLabelValueEnums(obj map[string]interface{}, enums LabelValueEnums) error {
+	if len(enums) == 0 {
+		return nil
+	}
+
+	errs := make([]error, 0)
+	walkLabelMaps(obj, "", "", func(path string, labels map[string]string) {
+		for _, key := range sortedKeys(labels) {
+			allowed, ok := enums[key]
+			if !ok {
+				continue
+			}
+			if !containsString(allowed, labels[key]) {
+				errs = append(errs, &ConstraintError{FieldPath: fmt.Sprintf("%s[%q]", path, key), Rule: CheckLabelValueEnums, BadValue: labels[key], Message: labelEnumMessage(key, labels[key], allowed)})
+			}
+		}
+	}, func(path string, key string, values []string) {
+		allowed, ok := enums[key]
+		if !ok {
+			return
+		}
+		for i, value := range values {
+			if !containsString(allowed, value) {
+				errs = append(errs, &ConstraintError{FieldPath: fmt.Sprintf("%s.values[%d]", path, i), Rule: CheckLabelValueEnums, BadValue: value, Message: labelEnumMessage(key, value, allowed)})
+			}
+		}
+	})
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// labelEnumMessage describes a value of the label key outside allowed.
+func labelEnumMessage(key string, value string, allowed []string) string {
+	return fmt.Sprintf("value '%s' for label '%s' must be one of: %s%s", value, key, strings.Join(allowed, ", "), enumSuggestion(value, allowed))
+}
+
+// walkLabelMaps recursively visits the label maps and selector expressions in value.
+// onMap is called for metadata.labels, matchLabels, nodeSelector, and plain map selectors
+// (as used by Services); onExpression is called for each matchExpressions entry.
+func walkLabelMaps(value interface{}, path string, parentKey string, onMap func(path string, labels map[string]string), onExpression func(path string, key string, values []string)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			child := v[key]
+			childPath := joinFieldPath(path, key)
+
+			isLabelMap := key == "matchLabels" || key == "nodeSelector" ||
+				(key == "labels" && parentKey == "metadata")
+			if key == "selector" {
+				// Services and ReplicationControllers use a plain map selector
+				if m, ok := child.(map[string]interface{}); ok && allStringValues(m) {
+					isLabelMap = true
+				}
+			}
+
+			if isLabelMap {
+				if m, ok := child.(map[string]interface{}); ok {
+					onMap(childPath, toStringMap(m))
+				}
+				continue
+			}
+
+			if key == "matchExpressions" {
+				expressions, _ := child.([]interface{})
+				for i, expression := range expressions {
+					e, ok := expression.(map[string]interface{})
+					if !ok {
+						continue
+					}
+					labelKey, _ := e["key"].(string)
+					values, _ := e["values"].([]interface{})
+					onExpression(fmt.Sprintf("%s[%d]", childPath, i), labelKey, toStringSlice(values))
+				}
+				continue
+			}
+
+			walkLabelMaps(child, childPath, key, onMap, onExpression)
+		}
+	case []interface{}:
+		for i, item := range v {
+			walkLabelMaps(item, fmt.Sprintf("%s[%d]", path, i), parentKey, onMap, onExpression)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package k8sconstraints
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// CheckLabelSchema is the ID of the check built by LabelSchemaCheck.
+const CheckLabelSchema = "LabelSchema"
+
+// LabelKeySchema constrains the values allowed for a single label key.
+type LabelKeySchema struct {
+	Pattern string   `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	Enum    []string `yaml:"enum,omitempty" json:"enum,omitempty"`
+}
+
+// LabelSchema declares which metadata.labels keys an object must and may carry, and which
+// values each key accepts. Kinds holds per-kind schemas that extend the top-level one.
+type LabelSchema struct {
+	Required []string                  `yaml:"required,omitempty" json:"required,omitempty"`
+	Optional []string                  `yaml:"optional,omitempty" json:"optional,omitempty"`
+	Keys     map[string]LabelKeySchema `yaml:"keys,omitempty" json:"keys,omitempty"`
+	// AllowUndeclared permits keys that are neither required, optional, nor listed in Keys.
+	AllowUndeclared bool                   `yaml:"allowUndeclared,omitempty" json:"allowUndeclared,omitempty"`
+	Kinds           map[string]LabelSchema `yaml:"kinds,omitempty" json:"kinds,omitempty"`
+}
+
+// LoadLabelSchema reads a label schema from a JSON file and checks that it is well formed.
+func LoadLabelSchema(path string) (*LabelSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema LabelSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("invalid label schema file '%s': %v", path, err)
+	}
+
+	if err := ValidateLabelSchemaDefinition(schema); err != nil {
+		return nil, fmt.Errorf("invalid label schema file '%s': %v", path, err)
+	}
+
+	return &schema, nil
+}
+
+// LabelSchemaCheck returns an opt-in check, not run by ValidateObject, that enforces
+// schema on the labels of every object; see ValidateLabelSchema. Run it with RunChecks or
+// pass it to the linter's Checks option.
+func LabelSchemaCheck(schema LabelSchema) Check {
+	return Check{ID: CheckLabelSchema, Validate: func(obj map[string]interface{}) error {
+		return ValidateLabelSchema(obj, schema)
+	}}
+}
+
+// ValidateLabelSchemaDefinition checks that every key declared in schema is a valid label
+// key and every pattern compiles.
+func ValidateLabelSchemaDefinition(schema LabelSchema) error {
+	errs := make([]error, 0)
+
+	declared := append(append([]string{}, schema.Required...), schema.Optional...)
+	declared = append(declared, sortedKeys(schema.Keys)...)
+	for _, key := range declared {
+		if err := ValidateLabelKey(key); err != nil {
+			errs = append(errs, fmt.Errorf("invalid label key '%s': %v", key, err))
+		}
+	}
+
+	for _, key := range sortedKeys(schema.Keys) {
+		keySchema := schema.Keys[key]
+		if keySchema.Pattern == "" {
+			continue
+		}
+		if _, err := regexp.Compile(keySchema.Pattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid pattern for label key '%s': %v", key, err))
+		}
+	}
+
+	for _, kind := range sortedKeys(schema.Kinds) {
+		if err := ValidateLabelSchemaDefinition(schema.Kinds[kind]); err != nil {
+			errs = append(errs, WithFieldPath(fmt.Sprintf("kinds.%s", kind), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// SchemaForKind returns the effective schema for kind: the top-level schema merged with the
+// kind-specific one, whose key constraints take precedence.
+func (s LabelSchema) SchemaForKind(kind string) LabelSchema {
+	effective := LabelSchema{
+		Required:        append([]string{}, s.Required...),
+		Optional:        append([]string{}, s.Optional...),
+		Keys:            make(map[string]LabelKeySchema, len(s.Keys)),
+		AllowUndeclared: s.AllowUndeclared,
+	}
+	for key, keySchema := range s.Keys {
+		effective.Keys[key] = keySchema
+	}
+
+	kindSchema, ok := s.Kinds[kind]
+	if !ok {
+		return effective
+	}
+
+	effective.Required = append(effective.Required, kindSchema.Required...)
+	effective.Optional = append(effective.Optional, kindSchema.Optional...)
+	for key, keySchema := range kindSchema.Keys {
+		effective.Keys[key] = keySchema
+	}
+	effective.AllowUndeclared = effective.AllowUndeclared || kindSchema.AllowUndeclared
+
+	return effective
+}
+
+// ValidateLabelSchema enforces schema against the metadata.labels of obj. It runs the
+// label schema rule group: required keys, undeclared keys, and per-key value constraints.
+func ValidateLabelSchema(obj map[string]interface{}, schema LabelSchema) error {
+	errs := make([]error, 0)
+
+	kind, _ := nestedString(obj, "kind")
+	labels, _ := nestedStringMap(obj, "metadata", "labels")
+	effective := schema.SchemaForKind(kind)
+
+	// Required keys must be present
+	labelsPath := NewPath("metadata", "labels")
+	for _, key := range effective.Required {
+		if _, ok := labels[key]; !ok {
+			errs = append(errs, &ConstraintError{FieldPath: labelsPath.String(), Rule: CheckLabelSchema, Message: fmt.Sprintf("missing required label '%s'", key)})
+		}
+	}
+
+	for _, key := range sortedKeys(labels) {
+		value := labels[key]
+		path := labelsPath.Key(key)
+
+		// Keys outside the schema are rejected unless the schema is open
+		if !effective.AllowUndeclared && !effective.declares(key) {
+			errs = append(errs, &ConstraintError{FieldPath: path.String(), Rule: CheckLabelSchema, BadValue: key, Message: fmt.Sprintf("label '%s' is not declared in the label schema for kind '%s'", key, kind)})
+			continue
+		}
+
+		// Value constraints apply to every declared key that has them
+		if keySchema, ok := effective.Keys[key]; ok {
+			if err := validateValueConstraints(keySchema.Pattern, keySchema.Enum, value); err != nil {
+				errs = append(errs, WithFieldPath(path.String(), withRule(CheckLabelSchema, value, withMessagePrefix(fmt.Sprintf("invalid value for label '%s': ", key), err))))
+			}
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// declares reports whether key is required, optional, or constrained by the schema.
+func (s LabelSchema) declares(key string) bool {
+	if _, ok := s.Keys[key]; ok {
+		return true
+	}
+	return containsString(s.Required, key) || containsString(s.Optional, key)
+}
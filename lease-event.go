@@ -0,0 +1,196 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kubernetes generates names from metadata.generateName by appending a 5-character random
+// suffix, truncating the prefix so the generated name fits in 63 characters.
+const (
+	generatedNameSuffixLength = 5
+	maxGenerateNamePrefix     = 63 - generatedNameSuffixLength
+)
+
+// Limits on events.k8s.io/v1 Event string fields.
+const (
+	maxEventReportingInstanceLength = 128
+	maxEventActionLength            = 128
+	maxEventReasonLength            = 128
+	maxEventNoteLength              = 1024
+)
+
+// eventTypes lists the valid type values of an Event.
+var eventTypes = []string{"Normal", "Warning"}
+
+// ValidateLeaseName validates the name of a coordination.k8s.io Lease, which must be a DNS
+// subdomain. Leader election libraries use the Lease name as the lock name.
+func ValidateLeaseName(name string) error {
+	if name == "" {
+		return errors.New("Lease name cannot be empty")
+	}
+	return ValidateDNSSubdomain(name)
+}
+
+// ValidateEventName validates the name of a core/v1 or events.k8s.io/v1 Event, which must be
+// a DNS subdomain. Event recorders name events "<involvedObject.name>.<hex timestamp>", so
+// long object names can push an Event name over the 253-character limit.
+func ValidateEventName(name string) error {
+	if name == "" {
+		return errors.New("Event name cannot be empty")
+	}
+	return ValidateDNSSubdomain(name)
+}
+
+// ValidateGenerateNamePrefix validates metadata.generateName with the name rule of the kind.
+// A trailing '-' is allowed since it separates the prefix from the random suffix. Prefixes
+// longer than 58 characters are silently truncated by the API server, which breaks tools
+// that expect generated names to start with the full prefix.
+func ValidateGenerateNamePrefix(prefix string, validateName func(string) error) error {
+	errs := make([]error, 0)
+
+	// Replace a trailing '-' so the prefix can be checked with the full name rule
+	masked := prefix
+	if strings.HasSuffix(masked, "-") {
+		masked = masked[:len(masked)-1] + "a"
+	}
+	if err := validateName(masked); err != nil {
+		errs = append(errs, err)
+	}
+	if len(prefix) > maxGenerateNamePrefix {
+		errs = append(errs, fmt.Errorf("warning: generateName is longer than %d characters and will be truncated before the %d-character random suffix is appended", maxGenerateNamePrefix, generatedNameSuffixLength))
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// ValidateCoordinationObject applies the kind-aware name rules to Leases and Events, plus the
+// basic field constraints of their specs. Other kinds are ignored.
+func ValidateCoordinationObject(obj map[string]interface{}) error {
+	apiVersion, _ := nestedString(obj, "apiVersion")
+	kind, _ := nestedString(obj, "kind")
+
+	var validateName func(string) error
+	var validateSpec func(map[string]interface{}) error
+	rule := RuleEvent
+	switch {
+	case kind == "Lease" && apiVersion == "coordination.k8s.io/v1":
+		validateName, validateSpec, rule = ValidateLeaseName, validateLeaseSpec, RuleLease
+	case kind == "Event" && apiVersion == "events.k8s.io/v1":
+		validateName, validateSpec = ValidateEventName, validateEventsV1Event
+	case kind == "Event" && apiVersion == "v1":
+		validateName = ValidateEventName
+	default:
+		return nil
+	}
+
+	errs := make([]error, 0)
+
+	name, hasName := nestedString(obj, "metadata", "name")
+	generateName, hasGenerateName := nestedString(obj, "metadata", "generateName")
+	if hasName && name != "" {
+		if err := validateName(name); err != nil {
+			errs = append(errs, WithFieldPath("metadata.name", err))
+		}
+	}
+	if hasGenerateName && generateName != "" {
+		if err := ValidateGenerateNamePrefix(generateName, validateName); err != nil {
+			errs = append(errs, WithFieldPath("metadata.generateName", err))
+		}
+	}
+
+	if validateSpec != nil {
+		if err := validateSpec(obj); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(rule, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validateLeaseSpec checks the Lease fields set by leader election.
+func validateLeaseSpec(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	for _, field := range []string{"leaseDurationSeconds", "leaseTransitions"} {
+		path := NewPath("spec", field)
+		value, ok := nestedField(obj, "spec", field)
+		if !ok {
+			continue
+		}
+		n, ok := toInt64(value)
+		if !ok {
+			errs = append(errs, Invalid(path, value, "must be an integer"))
+			continue
+		}
+		if field == "leaseDurationSeconds" && n <= 0 {
+			errs = append(errs, Invalid(path, n, "must be greater than 0"))
+		}
+		if field == "leaseTransitions" && n < 0 {
+			errs = append(errs, Invalid(path, n, "must be greater than or equal to 0"))
+		}
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// validateEventsV1Event checks the required fields of an events.k8s.io/v1 Event.
+func validateEventsV1Event(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	if eventType, ok := nestedString(obj, "type"); ok {
+		if err := validateEnumField("type", eventType, eventTypes); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if _, ok := nestedField(obj, "eventTime"); !ok {
+		errs = append(errs, errors.New("eventTime is required"))
+	}
+
+	if controller, _ := nestedString(obj, "reportingController"); controller == "" {
+		errs = append(errs, errors.New("reportingController is required"))
+	} else if err := ValidateQualifiedName(controller); err != nil {
+		errs = append(errs, WithFieldPath("reportingController", err))
+	}
+
+	limits := []struct {
+		field string
+		max   int
+	}{
+		{"reportingInstance", maxEventReportingInstanceLength},
+		{"action", maxEventActionLength},
+		{"reason", maxEventReasonLength},
+		{"note", maxEventNoteLength},
+	}
+	for _, limit := range limits {
+		value, _ := nestedString(obj, limit.field)
+		if limit.field != "note" && value == "" {
+			errs = append(errs, fmt.Errorf("%s is required", limit.field))
+			continue
+		}
+		if err := ValidateLength(value, limit.max); err != nil {
+			errs = append(errs, WithFieldPath(limit.field, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
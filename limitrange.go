@@ -0,0 +1,115 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// limitRangeTypes lists the valid LimitRange spec.limits[].type values.
+var limitRangeTypes = []string{"Pod", "Container", "PersistentVolumeClaim"}
+
+// limitRangeQuantityFields are the per-resource quantity maps of a LimitRange item.
+var limitRangeQuantityFields = []string{"max", "min", "default", "defaultRequest", "maxLimitRequestRatio"}
+
+// ValidateLimitRange validates the spec.limits items of a v1 LimitRange.
+func ValidateLimitRange(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	limitsPath := NewPath("spec", "limits")
+	limits, ok := nestedSlice(obj, "spec", "limits")
+	if !ok || len(limits) == 0 {
+		return Required(limitsPath, "limits must contain at least one item")
+	}
+
+	for i, raw := range limits {
+		item, ok := raw.(map[string]interface{})
+		if !ok {
+			errs = append(errs, Invalid(limitsPath.Index(i), raw, "must be an object"))
+			continue
+		}
+		if err := ValidateLimitRangeItem(item); err != nil {
+			errs = append(errs, WithFieldPath(limitsPath.Index(i).String(), err))
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleLimitRange, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// ValidateLimitRangeItem validates a single LimitRange item: the type enum, which fields the
+// type allows, quantity syntax, and the min <= defaultRequest <= default <= max relationships.
+func ValidateLimitRangeItem(item map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	limitType, _ := nestedString(item, "type")
+	if err := validateEnumField("type", limitType, limitRangeTypes); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Parse every quantity once, keyed by field and then resource name
+	quantities := make(map[string]map[string]*big.Rat)
+	for _, field := range limitRangeQuantityFields {
+		values, ok := nestedMap(item, field)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case limitType == "Pod" && (field == "default" || field == "defaultRequest"):
+			errs = append(errs, Invalid(NewPath(field), nil, fmt.Sprintf("%s may not be specified when type is 'Pod'", field)))
+		case limitType == "PersistentVolumeClaim" && field != "min" && field != "max":
+			errs = append(errs, Invalid(NewPath(field), nil, fmt.Sprintf("%s may not be specified when type is 'PersistentVolumeClaim'", field)))
+		}
+
+		quantities[field] = make(map[string]*big.Rat)
+		for _, resource := range sortedKeys(values) {
+			quantity, err := quantityValue(values[resource])
+			if err != nil {
+				errs = append(errs, WithFieldPath(NewPath(field).Key(resource).String(), withRule(RuleQuantity, values[resource], err)))
+				continue
+			}
+			if quantity.Sign() < 0 {
+				errs = append(errs, &ConstraintError{FieldPath: NewPath(field).Key(resource).String(), Rule: RuleQuantity, BadValue: values[resource], Message: "quantity must be greater than or equal to 0"})
+				continue
+			}
+			quantities[field][resource] = quantity
+		}
+	}
+
+	// Ordering between the bounds and defaults of each resource
+	ordered := [][2]string{
+		{"min", "max"},
+		{"min", "defaultRequest"},
+		{"min", "default"},
+		{"defaultRequest", "default"},
+		{"defaultRequest", "max"},
+		{"default", "max"},
+	}
+	for _, pair := range ordered {
+		lower, upper := quantities[pair[0]], quantities[pair[1]]
+		for _, resource := range sortedKeys(lower) {
+			if upperValue, ok := upper[resource]; ok && lower[resource].Cmp(upperValue) > 0 {
+				message := fmt.Sprintf("must be less than or equal to %s", NewPath(pair[1]).Key(resource))
+				errs = append(errs, Invalid(NewPath(pair[0]).Key(resource), nil, message))
+			}
+		}
+	}
+
+	// maxLimitRequestRatio is a multiplier and must be at least 1
+	one := big.NewRat(1, 1)
+	for _, resource := range sortedKeys(quantities["maxLimitRequestRatio"]) {
+		if quantities["maxLimitRequestRatio"][resource].Cmp(one) < 0 {
+			errs = append(errs, Invalid(NewPath("maxLimitRequestRatio").Key(resource), nil, "must be greater than or equal to 1"))
+		}
+	}
+
+	if len(errs) > 0 {
+		return withDefaultRule(RuleLimitRange, JoinErrors(errs))
+	}
+
+	return nil
+}
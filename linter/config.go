@@ -0,0 +1,408 @@
+package linter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+)
+
+// ConfigFileName is the name of the configuration file FindConfig looks for.
+const ConfigFileName = ".k8sconstraints.yaml"
+
+// configChecks are the opt-in checks a configuration file can enable by ID.
+var configChecks = map[string]k8sconstraints.Check{
+	k8sconstraints.CheckReservedNamespaces: k8sconstraints.ReservedNamespacesCheck,
+	k8sconstraints.CheckReservedPrefixes:   k8sconstraints.ReservedPrefixesCheck(nil),
+	k8sconstraints.CheckRecommendedLabels:  k8sconstraints.RecommendedLabelsCheck(k8sconstraints.RecommendedLabelsConfig{}),
+	k8sconstraints.CheckOpenAPISchema:      k8sconstraints.OpenAPISchemaCheck(nil),
+	k8sconstraints.CheckImageTags:          k8sconstraints.ImageTagsCheck,
+}
+
+// impliedCheckIDs are the IDs of the checks configuration settings other than Enable imply,
+// which custom rules cannot reuse.
+var impliedCheckIDs = []string{
+	k8sconstraints.CheckRequiredLabels,
+	k8sconstraints.CheckLabelValueEnums,
+	k8sconstraints.CheckLabelSchema,
+	k8sconstraints.CheckRequiredAnnotations,
+	k8sconstraints.CheckAllowedRegistries,
+	k8sconstraints.CheckPodSecurity,
+	k8sconstraints.CheckWorkloadOwnership,
+	k8sconstraints.CheckFieldConstraints,
+}
+
+// Config is the contents of a configuration file, which lets a repository record how its
+// manifests are linted:
+//
+//	enable: [ReservedNamespaces, RecommendedLabels]
+//	disable: [PortName]
+//	severity:
+//	  DeprecatedAPI: warning
+//	requiredLabels: [team]
+//	labelEnums:
+//	  environment: [dev, staging, prod]
+//	labelSchema:
+//	  required: [app.kubernetes.io/name]
+//	  keys:
+//	    tier: {enum: [frontend, backend]}
+//	  allowUndeclared: true
+//	requiredAnnotations:
+//	  - key: example.com/owner
+//	  - key: example.com/cost-center
+//	    pattern: "^cc-[0-9]{4}$"
+//	allowedRegistries: [registry.example.com, docker.io/library/]
+//	podSecurity:
+//	  level: baseline
+//	  namespaces: {payments: restricted}
+//	production: true
+//	nodePortRange: 30000-32767
+//	ownership: {allowReplicaSets: true}
+//	rules:
+//	  - id: MaxReplicas
+//	    expression: "!has(object.spec.replicas) || object.spec.replicas <= 10"
+//	    message: replicas must be at most 10
+//	    kinds: [Deployment.apps]
+//	fieldConstraints:
+//	  - path: .spec.replicas
+//	    maximum: 10
+//	    kinds: [Deployment]
+//	overrides:
+//	  - paths: ["legacy/**"]
+//	    disable: [DeprecatedAPI]
+type Config struct {
+	// Enable lists the IDs of opt-in checks to run: ReservedNamespaces, ReservedPrefixes,
+	// RecommendedLabels, OpenAPISchema, and ImageTags.
+	Enable []string `yaml:"enable,omitempty"`
+	// Disable lists the rule codes whose findings are dropped.
+	Disable []string `yaml:"disable,omitempty"`
+	// Severity changes the severity of findings by rule code.
+	Severity k8sconstraints.SeverityOverrides `yaml:"severity,omitempty"`
+	// RequiredLabels lists the labels every workload and Namespace must carry.
+	RequiredLabels []string `yaml:"requiredLabels,omitempty"`
+	// LabelEnums restricts the values of labels, wherever they appear in metadata or label
+	// selectors; see k8sconstraints.ValidateLabelValueEnums.
+	LabelEnums k8sconstraints.LabelValueEnums `yaml:"labelEnums,omitempty"`
+	// LabelSchema declares the labels objects must and may carry and the values each
+	// accepts; see k8sconstraints.ValidateLabelSchema.
+	LabelSchema *k8sconstraints.LabelSchema `yaml:"labelSchema,omitempty"`
+	// RequiredAnnotations lists the annotations workloads and Namespaces, or the kinds each
+	// names, must carry, with optional pattern and enum constraints on their values; see
+	// k8sconstraints.ValidateRequiredAnnotations.
+	RequiredAnnotations []k8sconstraints.AnnotationRequirement `yaml:"requiredAnnotations,omitempty"`
+	// AllowedRegistries lists the registries container images may be pulled from; see
+	// k8sconstraints.ValidateAllowedRegistries. Empty allows every registry.
+	AllowedRegistries []string `yaml:"allowedRegistries,omitempty"`
+	// PodSecurity holds pod specs to the Pod Security Standards profile selected for their
+	// namespace; see k8sconstraints.ValidatePodSecurity.
+	PodSecurity *k8sconstraints.PodSecurityConfig `yaml:"podSecurity,omitempty"`
+	// Production marks the manifests as destined for production, which warns about Pods and
+	// ReplicaSets not owned by a controller; see k8sconstraints.ValidateWorkloadOwnership.
+	Production bool `yaml:"production,omitempty"`
+	// Ownership exempts bare Pods or ReplicaSets from the Production warnings.
+	Ownership k8sconstraints.OwnershipRules `yaml:"ownership,omitempty"`
+	// NodePortRange is the --service-node-port-range of the API server the manifests are
+	// meant for, e.g. 30000-32767, which Service node ports must lie within. The built-in
+	// checks use it once passed to k8sconstraints.UseNodePortRange; see NodePorts.
+	NodePortRange string `yaml:"nodePortRange,omitempty"`
+	// Rules declares custom rules as CEL expressions over the object; see
+	// k8sconstraints.CELRule. Their findings are reported under their IDs.
+	Rules []k8sconstraints.CELRule `yaml:"rules,omitempty"`
+	// FieldConstraints constrain the values JSONPaths select in every object; see
+	// k8sconstraints.ValidateFieldConstraints.
+	FieldConstraints []k8sconstraints.FieldConstraint `yaml:"fieldConstraints,omitempty"`
+	// Overrides adjust Disable and Severity for the files matching their paths. Later
+	// overrides take precedence.
+	Overrides []ConfigOverride `yaml:"overrides,omitempty"`
+
+	// Dir is the directory override paths are relative to: the directory holding the
+	// configuration file.
+	Dir string `yaml:"-"`
+}
+
+// ConfigOverride adjusts the configuration for some files.
+type ConfigOverride struct {
+	// Paths holds glob patterns selecting the files, relative to the configuration file;
+	// see Options.Exclude for the pattern syntax.
+	Paths []string `yaml:"paths"`
+	// Disable lists further rule codes whose findings are dropped in these files.
+	Disable []string `yaml:"disable,omitempty"`
+	// Severity changes the severity of findings by rule code in these files.
+	Severity k8sconstraints.SeverityOverrides `yaml:"severity,omitempty"`
+}
+
+// FindConfig looks for ConfigFileName in dir and each of its parents, the way linters such
+// as golangci-lint discover their configuration, and returns the path of the first one
+// found, or "" if there is none.
+func FindConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, ConfigFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// LoadConfig reads a configuration file and checks that it is well formed. Unknown fields
+// are rejected so misspelled settings do not go unnoticed.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid config file '%s': %v", path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file '%s': %v", path, err)
+	}
+
+	if config.Dir, err = filepath.Abs(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// Validate checks that every enabled check is known, every severity is valid, every
+// required label is a valid label key, every label enumeration, the label schema, and every
+// required annotation are well formed, every Pod Security Standards profile is known, the
+// node port range parses, every field constraint compiles, and every rule compiles under a
+// unique ID, normalizing the severities.
+func (c *Config) Validate() error {
+	errs := make([]error, 0)
+
+	for _, id := range c.Enable {
+		if _, ok := configChecks[id]; !ok {
+			errs = append(errs, fmt.Errorf("enable: unknown check '%s'; must be one of: %s", id, strings.Join(configCheckIDs(), ", ")))
+		}
+	}
+	if err := normalizeSeverities(c.Severity); err != nil {
+		errs = append(errs, fmt.Errorf("severity: %v", err))
+	}
+	for i, key := range c.RequiredLabels {
+		if err := k8sconstraints.ValidateLabelKey(key); err != nil {
+			errs = append(errs, fmt.Errorf("requiredLabels[%d]: invalid key '%s': %v", i, key, err))
+		}
+	}
+	if err := c.LabelEnums.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("labelEnums: %v", err))
+	}
+	if c.LabelSchema != nil {
+		if err := k8sconstraints.ValidateLabelSchemaDefinition(*c.LabelSchema); err != nil {
+			errs = append(errs, fmt.Errorf("labelSchema: %v", err))
+		}
+	}
+	for i, req := range c.RequiredAnnotations {
+		if err := req.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("requiredAnnotations[%d]: %v", i, err))
+		}
+	}
+	if c.PodSecurity != nil {
+		if err := c.PodSecurity.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("podSecurity: %v", err))
+		}
+	}
+	if c.NodePortRange != "" {
+		if _, err := k8sconstraints.ParseNodePortRange(c.NodePortRange); err != nil {
+			errs = append(errs, fmt.Errorf("nodePortRange: %v", err))
+		}
+	}
+	for i, constraint := range c.FieldConstraints {
+		if err := k8sconstraints.ValidateFieldConstraintDefinition(constraint); err != nil {
+			errs = append(errs, fmt.Errorf("fieldConstraints[%d]: %v", i, err))
+		}
+	}
+	ids := make(map[string]bool)
+	for i, rule := range c.Rules {
+		if err := k8sconstraints.ValidateCELRule(rule); err != nil {
+			errs = append(errs, fmt.Errorf("rules[%d]: %v", i, err))
+			continue
+		}
+		if _, ok := configChecks[rule.ID]; ok || ids[rule.ID] || containsString(impliedCheckIDs, rule.ID) {
+			errs = append(errs, fmt.Errorf("rules[%d]: duplicate id '%s'", i, rule.ID))
+		}
+		ids[rule.ID] = true
+	}
+	for i, override := range c.Overrides {
+		if len(override.Paths) == 0 {
+			errs = append(errs, fmt.Errorf("overrides[%d]: paths is required", i))
+		}
+		if err := normalizeSeverities(override.Severity); err != nil {
+			errs = append(errs, fmt.Errorf("overrides[%d].severity: %v", i, err))
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return k8sconstraints.JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// Checks returns the checks the configuration enables, including those implied by
+// RequiredLabels, LabelEnums, LabelSchema, RequiredAnnotations, AllowedRegistries,
+// PodSecurity, Production, Rules, and FieldConstraints.
+func (c *Config) Checks() []k8sconstraints.Check {
+	checks := make([]k8sconstraints.Check, 0)
+	for _, id := range c.Enable {
+		if check, ok := configChecks[id]; ok {
+			checks = append(checks, check)
+		}
+	}
+	if len(c.RequiredLabels) > 0 {
+		checks = append(checks, k8sconstraints.RequiredLabelsCheck(c.RequiredLabels))
+	}
+	if len(c.LabelEnums) > 0 {
+		checks = append(checks, k8sconstraints.LabelValueEnumsCheck(c.LabelEnums))
+	}
+	if c.LabelSchema != nil {
+		checks = append(checks, k8sconstraints.LabelSchemaCheck(*c.LabelSchema))
+	}
+	if len(c.RequiredAnnotations) > 0 {
+		checks = append(checks, k8sconstraints.RequiredAnnotationsCheck(c.RequiredAnnotations))
+	}
+	if len(c.AllowedRegistries) > 0 {
+		checks = append(checks, k8sconstraints.AllowedRegistriesCheck(c.AllowedRegistries))
+	}
+	if c.PodSecurity != nil {
+		checks = append(checks, k8sconstraints.PodSecurityCheck(*c.PodSecurity))
+	}
+	if c.Production {
+		rules := c.Ownership
+		rules.Production = true
+		checks = append(checks, k8sconstraints.WorkloadOwnershipCheck(rules))
+	}
+	for _, rule := range c.Rules {
+		// Rules were compiled by Validate
+		if check, err := k8sconstraints.CELCheck(rule); err == nil {
+			checks = append(checks, check)
+		}
+	}
+	if len(c.FieldConstraints) > 0 {
+		checks = append(checks, k8sconstraints.FieldConstraintsCheck(c.FieldConstraints))
+	}
+	return checks
+}
+
+// NodePorts returns the node port range set by NodePortRange, or
+// k8sconstraints.DefaultNodePortRange when it is empty.
+func (c *Config) NodePorts() k8sconstraints.NodePortRange {
+	if c.NodePortRange == "" {
+		return k8sconstraints.DefaultNodePortRange
+	}
+	// NodePortRange was parsed by Validate
+	nodePorts, _ := k8sconstraints.ParseNodePortRange(c.NodePortRange)
+	return nodePorts
+}
+
+// Apply drops the findings of disabled rules and applies the severity overrides to the
+// findings reported in file, taking the overrides whose paths match file into account.
+func (c *Config) Apply(file string, findings []k8sconstraints.Finding) []k8sconstraints.Finding {
+	disabled := append([]string(nil), c.Disable...)
+	severity := make(k8sconstraints.SeverityOverrides, len(c.Severity))
+	for rule, s := range c.Severity {
+		severity[rule] = s
+	}
+	for _, override := range c.Overrides {
+		if !c.matches(override.Paths, file) {
+			continue
+		}
+		disabled = append(disabled, override.Disable...)
+		for rule, s := range override.Severity {
+			severity[rule] = s
+		}
+	}
+
+	kept := make([]k8sconstraints.Finding, 0, len(findings))
+	for _, finding := range findings {
+		if finding.Rule == "" || !containsString(disabled, finding.Rule) {
+			kept = append(kept, finding)
+		}
+	}
+	return k8sconstraints.ApplySeverityOverrides(kept, severity)
+}
+
+// matches reports whether file lies below the configuration directory and its relative
+// path matches any of patterns.
+func (c *Config) matches(patterns []string, file string) bool {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(c.Dir, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	return matchAnyGlob(patterns, filepath.ToSlash(rel))
+}
+
+// normalizeSeverities checks and lowercases the severities of overrides.
+func normalizeSeverities(overrides k8sconstraints.SeverityOverrides) error {
+	errs := make([]error, 0)
+	for _, rule := range sortedRules(overrides) {
+		severity, err := k8sconstraints.ParseSeverity(string(overrides[rule]))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule '%s': %v", rule, err))
+			continue
+		}
+		overrides[rule] = severity
+	}
+	if len(errs) > 0 {
+		return k8sconstraints.JoinErrors(errs)
+	}
+	return nil
+}
+
+// configCheckIDs returns the IDs of the checks a configuration file can enable, sorted.
+func configCheckIDs() []string {
+	ids := make([]string, 0, len(configChecks))
+	for id := range configChecks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// sortedRules returns the rule codes of overrides, sorted.
+func sortedRules(overrides k8sconstraints.SeverityOverrides) []string {
+	rules := make([]string, 0, len(overrides))
+	for rule := range overrides {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	return rules
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
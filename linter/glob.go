@@ -0,0 +1,54 @@
+package linter
+
+import (
+	"path"
+	"strings"
+)
+
+// matchAnyGlob reports whether rel, a slash-separated path relative to the directory being
+// walked, matches any of patterns. See matchGlob.
+func matchAnyGlob(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a slash-separated path against a glob pattern. Patterns containing a
+// '/' are matched against the whole path, with "**" matching any number of path segments,
+// including none; so "charts/**" matches "charts" and everything below it. Patterns
+// without a '/' are matched against the last path segment only, so "*.yaml" matches YAML
+// files at any depth.
+func matchGlob(pattern string, rel string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+// matchSegments matches path segments against pattern segments.
+func matchSegments(pattern []string, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Try every possible number of segments consumed by "**"
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
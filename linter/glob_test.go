@@ -0,0 +1,96 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		rel     string
+		want    bool
+	}{
+		{"*.yaml", "deploy.yaml", true},
+		{"*.yaml", "apps/web/deploy.yaml", true},
+		{"*.yaml", "deploy.yml", false},
+		{".git", ".git", true},
+		{"charts/**", "charts", true},
+		{"charts/**", "charts/web/templates/deploy.yaml", true},
+		{"charts/**", "apps/charts/deploy.yaml", false},
+		{"./charts/*", "charts/values.yaml", true},
+		{"charts/*", "charts/web/values.yaml", false},
+		{"**/templates/*.yaml", "templates/deploy.yaml", true},
+		{"**/templates/*.yaml", "charts/web/templates/deploy.yaml", true},
+		{"**/templates/*.yaml", "charts/web/templates/tests/pod.yaml", false},
+		{"apps/**/kustomization.yaml", "apps/web/overlays/prod/kustomization.yaml", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.rel, func(t *testing.T) {
+			if got := matchGlob(tt.pattern, tt.rel); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"app.yaml",
+		"app.json",
+		"notes.txt",
+		ConfigFileName,
+		"nested/service.yml",
+		"charts/web/templates/deploy.yaml",
+		".git/config.yaml",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{"defaults", Options{}, []string{"app.json", "app.yaml"}},
+		{"recursive", Options{Recursive: true}, []string{"app.json", "app.yaml", "charts/web/templates/deploy.yaml", "nested/service.yml"}},
+		{"include", Options{Recursive: true, Include: []string{"*.yml"}}, []string{"nested/service.yml"}},
+		{"exclude directory", Options{Recursive: true, Exclude: append([]string{"charts/**"}, DefaultExclude...)}, []string{"app.json", "app.yaml", "nested/service.yml"}},
+		{"exclude base name", Options{Recursive: true, Exclude: []string{"*.json"}}, []string{".git/config.yaml", ConfigFileName, "app.yaml", "charts/web/templates/deploy.yaml", "nested/service.yml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := New(tt.opts).Discover(dir)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			got := make([]string, 0, len(files))
+			for _, file := range files {
+				rel, _ := filepath.Rel(dir, file)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("file", func(t *testing.T) {
+		file := filepath.Join(dir, "notes.txt")
+		got, err := New(Options{}).Discover(file)
+		if err != nil || !reflect.DeepEqual(got, []string{file}) {
+			t.Errorf("expected files named explicitly to be kept, got %v, %v", got, err)
+		}
+	})
+}
@@ -0,0 +1,359 @@
+// Package linter bundles file discovery, decoding, validation, and report formatting into a
+// single facade for tools that embed k8sconstraints:
+//
+//	report, err := linter.New(linter.Options{Recursive: true}).LintPaths(ctx, []string{"deploy/"})
+//	if err != nil {
+//		return err
+//	}
+//	return k8sconstraints.WriteReport(os.Stdout, report, k8sconstraints.FormatText)
+package linter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+)
+
+// StdinPath is the path argument that makes LintPaths read standard input.
+const StdinPath = "-"
+
+var (
+	// DefaultInclude selects the files picked up when walking directories.
+	DefaultInclude = []string{"*.yaml", "*.yml", "*.json"}
+	// DefaultExclude skips version control metadata and configuration files when walking
+	// directories.
+	DefaultExclude = []string{".git", ".svn", ".hg", ConfigFileName}
+)
+
+// Options configures a Linter.
+type Options struct {
+	// Recursive descends into subdirectories of directory arguments. Otherwise only the
+	// files directly inside them are linted.
+	Recursive bool
+	// Include holds glob patterns selecting the files linted when walking directories.
+	// Defaults to DefaultInclude. See Exclude for the pattern syntax.
+	Include []string
+	// Exclude holds glob patterns for files and directories skipped when walking
+	// directories, matched against paths relative to the directory argument. Patterns
+	// without a '/' match base names, e.g. "*_test.yaml"; patterns with one match the
+	// whole path, where "**" matches any number of directories, e.g. "charts/**".
+	// Defaults to DefaultExclude.
+	Exclude []string
+	// Verbose reports every finding on its own. Otherwise findings sharing a root cause
+	// are collapsed into one; see k8sconstraints.GroupFindings.
+	Verbose bool
+	// Scoring enables scoring: when set, the report carries resource and bundle grades
+	// computed with these options; see k8sconstraints.ScoreReport.
+	Scoring *k8sconstraints.ScoringOptions
+	// DocumentTimeout limits the time spent validating a single document. Documents that
+	// exceed it are reported with an EngineError finding. Zero means no limit.
+	DocumentTimeout time.Duration
+	// Concurrency is the number of targets LintTargets lints at once. Defaults to
+	// DefaultConcurrency.
+	Concurrency int
+	// ShowSensitiveValues keeps the values of Secret data, environment variables, and
+	// annotations in findings. Otherwise they are redacted; see
+	// k8sconstraints.RedactFinding.
+	ShowSensitiveValues bool
+	// Bundle, when set, additionally validates every object against the CRD schemas and
+	// label schema of an offline bundle; see k8sconstraints.OfflineBundle.
+	Bundle *k8sconstraints.OfflineBundle
+	// CRDs holds CustomResourceDefinition schemas custom resources are validated against,
+	// in addition to those of the CRDs found among the linted documents; see
+	// k8sconstraints.CRDSchemaCheck.
+	CRDs *k8sconstraints.CRDSchemas
+	// ServedKinds, when set, holds the types served by the cluster the manifests are meant
+	// for, and objects of other types are reported, unless their CRD is in CRDs or among
+	// the linted documents; see k8sconstraints.ServedKindsCheck. Defaults to the types
+	// served by the cluster Bundle was built from, if any.
+	ServedKinds *k8sconstraints.KubernetesSchemas
+	// Checks are run against every object in addition to the built-in checks, such as
+	// k8sconstraints.ReservedNamespacesCheck. They cannot depend on built-in checks.
+	Checks []k8sconstraints.Check
+	// SeverityOverrides changes the severity of findings by rule code; see
+	// k8sconstraints.ApplySeverityOverrides.
+	SeverityOverrides k8sconstraints.SeverityOverrides
+	// Config, when set, applies a configuration file: its checks run along with Checks,
+	// and its disabled rules and severities apply before SeverityOverrides; see LoadConfig.
+	Config *Config
+	// Stream makes LintPaths decode YAML and JSON files and standard input one document, or
+	// one List item, at a time instead of reading them whole, for very large exports; see
+	// k8sconstraints.NewStreamSource. So that no more than one object is held at a time,
+	// LintSource then validates custom resources against the CRDs seen before them only,
+	// and the bundle checks, which need every object at once, are not run.
+	Stream bool
+}
+
+// Linter lints manifest files and directories.
+type Linter struct {
+	opts Options
+}
+
+// New returns a Linter configured with opts.
+func New(opts Options) *Linter {
+	if opts.Include == nil {
+		opts.Include = DefaultInclude
+	}
+	if opts.Exclude == nil {
+		opts.Exclude = DefaultExclude
+	}
+	return &Linter{opts: opts}
+}
+
+// LintPaths validates every manifest in paths, which may name files or directories, and
+// returns the aggregated report. Files named explicitly are always linted; directories
+// contribute the files selected by the Include and Exclude options. The path "-" reads
+// standard input, detecting YAML or JSON from its content. Unreadable paths and
+// cancellation of ctx are returned as errors; invalid documents become findings.
+func (l *Linter) LintPaths(ctx context.Context, paths []string) (k8sconstraints.Report, error) {
+	sources := make([]k8sconstraints.Source, 0, len(paths))
+	files := 0
+	for _, path := range paths {
+		if path == StdinPath {
+			if l.opts.Stream {
+				sources = append(sources, k8sconstraints.NewStreamSource("<stdin>", os.Stdin))
+			} else {
+				sources = append(sources, k8sconstraints.NewReaderSource("<stdin>", os.Stdin))
+			}
+			files++
+			continue
+		}
+		discovered, err := l.Discover(path)
+		if err != nil {
+			return k8sconstraints.Report{Findings: []k8sconstraints.Finding{}}, err
+		}
+		if l.opts.Stream {
+			sources = append(sources, k8sconstraints.NewStreamFileSource(discovered...))
+		} else {
+			sources = append(sources, k8sconstraints.NewFileSource(discovered...))
+		}
+		files += len(discovered)
+	}
+
+	report, err := l.LintSource(ctx, k8sconstraints.MultiSource(sources...))
+	report.Files = files
+	return report, err
+}
+
+// LintSource validates every document produced by source and returns the aggregated
+// report, sorted with Report.Sort. Report.Files counts the distinct document sources seen.
+// Custom resources are validated against the schemas of the CRDs among the documents, so
+// those of types whose CRD has not been seen yet are held back until source is exhausted.
+// Once it is, the bundle checks cross-check the objects of every document; see
+// k8sconstraints.BuiltinBundleChecks. Neither applies with the Stream option.
+func (l *Linter) LintSource(ctx context.Context, source k8sconstraints.Source) (k8sconstraints.Report, error) {
+	report := k8sconstraints.Report{Findings: []k8sconstraints.Finding{}}
+	seen := make(map[string]bool)
+	crds := l.opts.CRDs.Clone()
+	pending := make([]pendingDocument, 0)
+	bundle := make([]pendingDocument, 0)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		doc, err := source.Next()
+		if errors.Is(err, io.EOF) {
+			for _, held := range pending {
+				l.addDocument(&report, held.source, held.result, crds)
+			}
+			l.addBundleFindings(&report, bundle)
+			report.Sort()
+			if l.opts.Scoring != nil {
+				score := k8sconstraints.ScoreReport(report, *l.opts.Scoring)
+				report.Score = &score
+			}
+			return report, nil
+		}
+		if err != nil {
+			return report, err
+		}
+
+		if !seen[doc.Source] {
+			seen[doc.Source] = true
+			report.Files++
+		}
+		result := k8sconstraints.ValidateDocumentIsolated(ctx, doc, l.opts.DocumentTimeout)
+		if result.Object != nil && !l.opts.Stream {
+			bundle = append(bundle, pendingDocument{source: doc.Source, result: k8sconstraints.DocumentResult{Index: result.Index, Line: result.Line, Path: result.Path, Object: result.Object}})
+		}
+		if awaitsCRD(result.Object, crds) && !l.opts.Stream {
+			pending = append(pending, pendingDocument{source: doc.Source, result: result})
+			continue
+		}
+		l.addDocument(&report, doc.Source, result, crds)
+	}
+}
+
+// pendingDocument is a validated document held back until the CRDs of its custom
+// resources, or the other objects bundle checks cross-check it with, may have been seen.
+type pendingDocument struct {
+	source string
+	result k8sconstraints.DocumentResult
+}
+
+// addDocument runs the checks against a validated document, applies the configuration and
+// the finding filters, and adds the document to report.
+func (l *Linter) addDocument(report *k8sconstraints.Report, source string, result k8sconstraints.DocumentResult, crds *k8sconstraints.CRDSchemas) {
+	if result.Object != nil {
+		checks := append(l.checks(), k8sconstraints.CRDSchemaCheck(crds))
+		served, known := l.opts.ServedKinds, []*k8sconstraints.CRDSchemas{crds}
+		if l.opts.Bundle != nil {
+			if served == nil {
+				served = l.opts.Bundle.ServedKinds()
+			}
+			known = append(known, l.opts.Bundle.CRDSchemas())
+		}
+		if served != nil {
+			checks = append(checks, k8sconstraints.ServedKindsCheck(served, known...))
+		}
+		result.Findings = append(result.Findings, k8sconstraints.CheckFindingsAt(result.Object, result.Path, checks)...)
+	}
+	result.Findings = l.filterFindings(source, result.Findings)
+	report.AddDocument(source, result)
+}
+
+// filterFindings applies the configuration and the finding filters to the findings reported
+// in source.
+func (l *Linter) filterFindings(source string, findings []k8sconstraints.Finding) []k8sconstraints.Finding {
+	if l.opts.Config != nil {
+		findings = l.opts.Config.Apply(source, findings)
+	}
+	findings = k8sconstraints.ApplySeverityOverrides(findings, l.opts.SeverityOverrides)
+	if !l.opts.Verbose {
+		findings = k8sconstraints.GroupFindings(findings)
+	}
+	if !l.opts.ShowSensitiveValues {
+		findings = k8sconstraints.RedactFindings(findings)
+	}
+	return findings
+}
+
+// addBundleFindings runs the bundle checks, built from the objects of every document in
+// bundle, against each of them, and adds their findings to report.
+func (l *Linter) addBundleFindings(report *k8sconstraints.Report, bundle []pendingDocument) {
+	objects := make([]map[string]interface{}, 0, len(bundle))
+	for _, doc := range bundle {
+		if !k8sconstraints.IsList(doc.result.Object) {
+			objects = append(objects, doc.result.Object)
+			continue
+		}
+		items, _ := k8sconstraints.ExpandList(doc.result.Object)
+		for _, item := range items {
+			objects = append(objects, item.Object)
+		}
+	}
+
+	checks := k8sconstraints.BuiltinBundleChecks(objects)
+	for _, doc := range bundle {
+		result := doc.result
+		result.Findings = l.filterFindings(doc.source, k8sconstraints.CheckFindingsAt(result.Object, result.Path, checks))
+		report.AddFindings(doc.source, result)
+	}
+}
+
+// awaitsCRD adds the CRDs among obj, or among its items if it is a List, to crds, and
+// reports whether obj holds a custom resource of a type crds has no schema for yet.
+func awaitsCRD(obj map[string]interface{}, crds *k8sconstraints.CRDSchemas) bool {
+	if obj == nil {
+		return false
+	}
+	objects := []map[string]interface{}{obj}
+	if k8sconstraints.IsList(obj) {
+		objects = objects[:0]
+		items, _ := k8sconstraints.ExpandList(obj)
+		for _, item := range items {
+			objects = append(objects, item.Object)
+		}
+	}
+
+	awaits := false
+	for _, object := range objects {
+		if k8sconstraints.IsCustomResourceDefinition(object) {
+			// Invalid CRDs are reported by the CRD schema check
+			_ = crds.Add(object)
+			continue
+		}
+		gvk := k8sconstraints.GroupVersionKindOf(object)
+		if _, ok := crds.SchemaFor(gvk.APIVersion(), gvk.Kind); !ok && !k8sconstraints.IsBuiltinGroup(gvk.Group) {
+			awaits = true
+		}
+	}
+	return awaits
+}
+
+// checks returns the checks run in addition to the built-in ones: the Checks option, the
+// configuration's checks, and the offline bundle's check. Checks enabled both ways run once.
+func (l *Linter) checks() []k8sconstraints.Check {
+	checks := append([]k8sconstraints.Check(nil), l.opts.Checks...)
+	if l.opts.Config != nil {
+		for _, check := range l.opts.Config.Checks() {
+			if !hasCheck(checks, check.ID) {
+				checks = append(checks, check)
+			}
+		}
+	}
+	if l.opts.Bundle != nil {
+		checks = append(checks, l.opts.Bundle.Check())
+	}
+	return checks
+}
+
+// hasCheck reports whether checks holds a check with the given ID.
+func hasCheck(checks []k8sconstraints.Check, id string) bool {
+	for _, check := range checks {
+		if check.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Discover expands a file or directory path into the list of files LintPaths would lint, in
+// walk order, applying the Recursive, Include, and Exclude options.
+func (l *Linter) Discover(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	files := make([]string, 0)
+	err = filepath.WalkDir(path, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if current == path {
+			return nil
+		}
+
+		rel, err := filepath.Rel(path, current)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if entry.IsDir() {
+			if !l.opts.Recursive || matchAnyGlob(l.opts.Exclude, rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if matchAnyGlob(l.opts.Include, rel) && !matchAnyGlob(l.opts.Exclude, rel) {
+			files = append(files, current)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
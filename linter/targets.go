@@ -0,0 +1,79 @@
+package linter
+
+import (
+	"context"
+	"sync"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+)
+
+// DefaultConcurrency is the number of targets LintTargets lints at once by default.
+const DefaultConcurrency = 4
+
+// Target is a named document source linted by LintTargets, such as a cluster context.
+type Target struct {
+	Name   string
+	Source k8sconstraints.Source
+}
+
+// namedSource reports every document of a target under the target's name, so findings
+// are grouped per target in the merged report.
+type namedSource struct {
+	name   string
+	source k8sconstraints.Source
+}
+
+// Next returns the next document of the underlying source.
+func (s namedSource) Next() (k8sconstraints.Document, error) {
+	doc, err := s.source.Next()
+	doc.Source = s.name
+	return doc, err
+}
+
+// LintTargets lints targets concurrently, at most Options.Concurrency at a time, and
+// merges their results into one report in target order, with Report.Targets breaking it
+// down per target. Findings are filed under the target name. A target that cannot be read
+// completely, such as an unreachable cluster, does not fail the others: its error is
+// recorded in its summary and the documents read before it are kept. Only cancellation of
+// ctx is returned as an error.
+func (l *Linter) LintTargets(ctx context.Context, targets []Target) (k8sconstraints.Report, error) {
+	concurrency := l.opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	reports := make([]k8sconstraints.Report, len(targets))
+	errs := make([]error, len(targets))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			reports[i], errs[i] = l.LintSource(ctx, namedSource{name: target.Name, source: target.Source})
+		}()
+	}
+	wg.Wait()
+
+	merged := k8sconstraints.Report{Findings: []k8sconstraints.Finding{}, Targets: []k8sconstraints.TargetSummary{}}
+	if err := ctx.Err(); err != nil {
+		return merged, err
+	}
+	for i, target := range targets {
+		summary := k8sconstraints.SummarizeTarget(target.Name, reports[i])
+		if errs[i] != nil {
+			summary.Error = errs[i].Error()
+		}
+		merged.Merge(reports[i])
+		merged.Targets = append(merged.Targets, summary)
+	}
+	// Each target was scored on its own for its summary's grade; the merged report is
+	// scored as a whole.
+	if l.opts.Scoring != nil {
+		score := k8sconstraints.ScoreReport(merged, *l.opts.Scoring)
+		merged.Score = &score
+	}
+	return merged, nil
+}
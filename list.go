@@ -0,0 +1,124 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ListItem is an object expanded out of a List document, with the field path it was found at
+// (e.g. items[3]). Objects that are not lists expand to themselves with an empty path.
+type ListItem struct {
+	Path   string
+	Object map[string]interface{}
+}
+
+// IsList reports whether obj is a v1 List or any typed *List (DeploymentList, PodList, ...)
+// carrying an items array.
+func IsList(obj map[string]interface{}) bool {
+	kind, _ := nestedString(obj, "kind")
+	if !strings.HasSuffix(kind, "List") {
+		return false
+	}
+	_, ok := nestedSlice(obj, "items")
+	return ok
+}
+
+// ExpandList flattens obj into the objects it contains. Nested lists are expanded
+// recursively. Items of typed lists that omit apiVersion or kind inherit them from the list
+// (e.g. items of a DeploymentList default to kind Deployment).
+func ExpandList(obj map[string]interface{}) ([]ListItem, error) {
+	return expandList(obj, "")
+}
+
+func expandList(obj map[string]interface{}, path string) ([]ListItem, error) {
+	if !IsList(obj) {
+		return []ListItem{{Path: path, Object: obj}}, nil
+	}
+
+	kind, _ := nestedString(obj, "kind")
+	apiVersion, _ := nestedString(obj, "apiVersion")
+	itemKind := strings.TrimSuffix(kind, "List")
+
+	errs := make([]error, 0)
+	items, _ := nestedSlice(obj, "items")
+	expanded := make([]ListItem, 0, len(items))
+	for i, raw := range items {
+		itemPath := joinFieldPath(path, fmt.Sprintf("items[%d]", i))
+
+		item, ok := raw.(map[string]interface{})
+		if !ok {
+			errs = append(errs, &ConstraintError{FieldPath: itemPath, Rule: RuleDecode, Message: "list item must be an object"})
+			continue
+		}
+
+		// Typed lists may omit the type information on their items
+		if itemKind != "" {
+			item = withDefaultTypeMeta(item, apiVersion, itemKind)
+		}
+
+		nested, err := expandList(item, itemPath)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		expanded = append(expanded, nested...)
+	}
+
+	if len(errs) > 0 {
+		return expanded, JoinErrors(errs)
+	}
+
+	return expanded, nil
+}
+
+// ValidateListItems expands obj and runs validate against each contained object, prefixing
+// every error with the item's path. Non-list objects are validated directly.
+func ValidateListItems(obj map[string]interface{}, validate func(map[string]interface{}) error) error {
+	if validate == nil {
+		return errors.New("validate function cannot be nil")
+	}
+
+	items, err := ExpandList(obj)
+
+	errs := make([]error, 0)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, item := range items {
+		if err := validate(item.Object); err != nil {
+			if item.Path != "" {
+				err = WithFieldPath(item.Path, err)
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// withDefaultTypeMeta returns a shallow copy of item with apiVersion and kind filled in when
+// missing.
+func withDefaultTypeMeta(item map[string]interface{}, apiVersion string, kind string) map[string]interface{} {
+	_, hasAPIVersion := item["apiVersion"]
+	_, hasKind := item["kind"]
+	if hasAPIVersion && hasKind {
+		return item
+	}
+
+	copied := make(map[string]interface{}, len(item)+2)
+	for key, value := range item {
+		copied[key] = value
+	}
+	if !hasAPIVersion && apiVersion != "" {
+		copied["apiVersion"] = apiVersion
+	}
+	if !hasKind {
+		copied["kind"] = kind
+	}
+	return copied
+}
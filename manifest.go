@@ -0,0 +1,139 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ValidateManifest validates the fields every Kubernetes object shares: apiVersion, kind,
+// metadata.name (or metadata.generateName), metadata.namespace, metadata.ownerReferences,
+// metadata.labels, and metadata.annotations. Once apiVersion and kind pass, it also runs
+// the kind-specific validators registered for the object's type in DefaultRegistry.
+// Violations carry field paths such as `metadata.labels["app"]`.
+func ValidateManifest(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+	typeErr := validateTypeMeta(obj)
+	if typeErr != nil {
+		errs = append(errs, typeErr)
+	}
+	if err := validateObjectMeta(obj); err != nil {
+		errs = append(errs, err)
+	}
+	if typeErr == nil {
+		if err := DefaultRegistry.Validate(obj); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// validateTypeMeta validates apiVersion and kind.
+func validateTypeMeta(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+	if apiVersion, ok := nestedString(obj, "apiVersion"); !ok || apiVersion == "" {
+		errs = append(errs, &ConstraintError{FieldPath: "apiVersion", Rule: RuleRequired, Message: "apiVersion is required"})
+	} else if err := ValidateApiVersion(apiVersion); err != nil {
+		errs = append(errs, WithFieldPath("apiVersion", err))
+	}
+	if kind, ok := nestedString(obj, "kind"); !ok || kind == "" {
+		errs = append(errs, &ConstraintError{FieldPath: "kind", Rule: RuleRequired, Message: "kind is required"})
+	} else if err := ValidateKind(kind); err != nil {
+		errs = append(errs, WithFieldPath("kind", err))
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// validateObjectMeta validates metadata.name or metadata.generateName, metadata.namespace,
+// ownerReferences, labels, and annotations.
+func validateObjectMeta(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	metadata, ok := nestedMap(obj, "metadata")
+	if !ok {
+		return &ConstraintError{FieldPath: "metadata", Rule: RuleRequired, Message: "metadata is required"}
+	}
+
+	// Name, or generateName when the server should pick the name
+	name, _ := nestedString(metadata, "name")
+	generateName, _ := nestedString(metadata, "generateName")
+	switch {
+	case name != "":
+		if err := ValidateMetadataName(name); err != nil {
+			errs = append(errs, WithFieldPath("metadata.name", err))
+		}
+		// A name that cannot have come from generateName is an error; otherwise generateName
+		// is merely ignored
+		if err := ValidateNameMatchesGenerateName(name, generateName); err != nil {
+			errs = append(errs, WithFieldPath("metadata.name", err))
+		} else if err := ValidateNameGenerateNamePrecedence(obj); err != nil {
+			errs = append(errs, err)
+		}
+	case generateName != "":
+		if err := ValidateGenerateName(generateName); err != nil {
+			errs = append(errs, WithFieldPath("metadata.generateName", err))
+		}
+	default:
+		if err := ValidateNameGenerateNamePrecedence(obj); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Namespace
+	if namespace, ok := nestedString(metadata, "namespace"); ok {
+		if err := ValidateNamespaceName(namespace); err != nil {
+			errs = append(errs, WithFieldPath("metadata.namespace", err))
+		}
+	}
+
+	// Owner references
+	if refs, ok := nestedSlice(metadata, "ownerReferences"); ok {
+		if err := ValidateOwnerReferences(refs); err != nil {
+			errs = append(errs, WithFieldPath("metadata", err))
+		}
+	}
+
+	// Labels and annotations
+	if err := validateStringMapField(metadata, "labels", ValidateMetadataLabels); err != nil {
+		errs = append(errs, WithFieldPath("metadata", err))
+	}
+	if err := validateStringMapField(metadata, "annotations", ValidateMetadataAnnotations); err != nil {
+		errs = append(errs, WithFieldPath("metadata", err))
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// validateStringMapField checks that metadata[field] is a map of strings and runs validate
+// on it.
+func validateStringMapField(metadata map[string]interface{}, field string, validate func(map[string]string) error) error {
+	raw, ok := nestedField(metadata, field)
+	if !ok || raw == nil {
+		return nil
+	}
+	values, ok := raw.(map[string]interface{})
+	if !ok {
+		return &ConstraintError{FieldPath: field, BadValue: raw, Message: fmt.Sprintf("%s must be a map of strings", field)}
+	}
+	if !allStringValues(values) {
+		return WithFieldPath(field, errors.New("all values must be strings"))
+	}
+	if err := validate(toStringMap(values)); err != nil {
+		return WithFieldPath(field, err)
+	}
+	return nil
+}
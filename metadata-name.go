@@ -0,0 +1,16 @@
+package k8sconstraints
+
+// ValidateMetadataName validates the syntax of the metadata.name field in a Kubernetes manifest.
+func ValidateMetadataName(name string) error {
+	// Check if the string is empty
+	if name == "" {
+		return &ConstraintError{Rule: RuleRequired, BadValue: name, Message: "metadata.name cannot be empty"}
+	}
+
+	// Validate DNS Subdomain format (which also enforces the 253 character limit)
+	if err := ValidateDNSSubdomain(name); err != nil {
+		return withMessagePrefix("invalid metadata.name: ", err)
+	}
+
+	return nil
+}
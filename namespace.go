@@ -0,0 +1,60 @@
+package k8sconstraints
+
+import "fmt"
+
+// CheckReservedNamespaces is the ID of ReservedNamespacesCheck.
+const CheckReservedNamespaces = "ReservedNamespaces"
+
+// ReservedNamespaces are the namespaces Kubernetes creates for itself: kube-system and
+// kube-public for system components and cluster information, kube-node-lease for node
+// heartbeats, and default for objects created without a namespace.
+var ReservedNamespaces = []string{"default", "kube-node-lease", "kube-public", "kube-system"}
+
+// ReservedNamespacesCheck is an opt-in check, not run by ValidateObject, that warns about
+// namespaced objects deployed to one of the ReservedNamespaces. Run it with RunChecks or
+// pass it to the linter's Checks option.
+var ReservedNamespacesCheck = Check{ID: CheckReservedNamespaces, Validate: ValidateReservedNamespace}
+
+// ValidateNamespaceName validates the name of a namespace, used both as metadata.name of a
+// Namespace and as metadata.namespace of namespaced objects. Unlike most object names,
+// namespace names must be RFC 1123 DNS labels: at most 63 lowercase alphanumeric characters
+// or '-', starting and ending with an alphanumeric character, and in particular without
+// dots.
+func ValidateNamespaceName(name string) error {
+	if name == "" {
+		return &ConstraintError{Rule: RuleRequired, Message: "namespace name cannot be empty"}
+	}
+	if err := ValidateDNSLabel(name); err != nil {
+		return withMessagePrefix("invalid namespace name: ", err)
+	}
+	return nil
+}
+
+// ValidateNamespace validates a core v1 Namespace, whose name must be a valid namespace name.
+func ValidateNamespace(obj map[string]interface{}) error {
+	name, ok := nestedString(obj, "metadata", "name")
+	if !ok {
+		return nil
+	}
+	if err := ValidateNamespaceName(name); err != nil {
+		return WithFieldPath("metadata.name", err)
+	}
+	return nil
+}
+
+// ValidateReservedNamespace warns when obj is deployed to one of the ReservedNamespaces.
+// Namespace objects themselves and objects without metadata.namespace pass.
+func ValidateReservedNamespace(obj map[string]interface{}) error {
+	if kind, _ := nestedString(obj, "kind"); kind == "Namespace" {
+		return nil
+	}
+	namespace, _ := nestedString(obj, "metadata", "namespace")
+	if !containsString(ReservedNamespaces, namespace) {
+		return nil
+	}
+	message := fmt.Sprintf("warning: namespace '%s' is reserved for Kubernetes system components; deploy to a dedicated namespace instead", namespace)
+	if namespace == "default" {
+		message = "warning: namespace 'default' is where objects without a namespace end up; deploy to a dedicated namespace instead"
+	}
+	return &ConstraintError{FieldPath: "metadata.namespace", Rule: CheckReservedNamespaces, BadValue: namespace, Message: message}
+}
@@ -0,0 +1,150 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"net"
+)
+
+// networkPolicyTypes lists the valid policyTypes of a NetworkPolicy.
+var networkPolicyTypes = []string{"Ingress", "Egress"}
+
+// ValidateNetworkPolicy validates a networking.k8s.io/v1 NetworkPolicy: policyTypes must be
+// distinct Ingress or Egress entries; every ingress from and egress to peer sets either an
+// ipBlock or selectors, with the ipBlock's cidr a CIDR and each except entry a CIDR of the
+// same IP family strictly inside it; and every port has a TCP, UDP, or SCTP protocol and a
+// port number or name, with an endPort only after a port number and not below it.
+func ValidateNetworkPolicy(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	spec, _ := nestedMap(obj, "spec")
+	policyTypes, _ := nestedSlice(spec, "policyTypes")
+	seen := make(map[string]bool)
+	for i, raw := range policyTypes {
+		path := fmt.Sprintf("spec.policyTypes[%d]", i)
+		policyType, _ := raw.(string)
+		if !containsString(networkPolicyTypes, policyType) {
+			errs = append(errs, WithFieldPath(path, enumViolation("policy type", policyType, networkPolicyTypes)))
+		} else if seen[policyType] {
+			errs = append(errs, &ConstraintError{FieldPath: path, BadValue: policyType, Message: fmt.Sprintf("duplicate policy type '%s'", policyType)})
+		}
+		seen[policyType] = true
+	}
+
+	for _, direction := range []struct{ field, peers string }{{"ingress", "from"}, {"egress", "to"}} {
+		rules, _ := nestedSlice(spec, direction.field)
+		for i, raw := range rules {
+			rule, _ := raw.(map[string]interface{})
+			path := fmt.Sprintf("spec.%s[%d]", direction.field, i)
+			peers, _ := nestedSlice(rule, direction.peers)
+			for j, rawPeer := range peers {
+				peer, _ := rawPeer.(map[string]interface{})
+				if err := validateNetworkPolicyPeer(peer); err != nil {
+					errs = append(errs, WithFieldPath(fmt.Sprintf("%s.%s[%d]", path, direction.peers, j), err))
+				}
+			}
+			ports, _ := nestedSlice(rule, "ports")
+			for j, rawPort := range ports {
+				port, _ := rawPort.(map[string]interface{})
+				if err := validateNetworkPolicyPort(port); err != nil {
+					errs = append(errs, WithFieldPath(fmt.Sprintf("%s.ports[%d]", path, j), err))
+				}
+			}
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleNetworkPolicy, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validateNetworkPolicyPeer checks a NetworkPolicy peer, which selects either an IP block
+// or pods and namespaces by label.
+func validateNetworkPolicyPeer(peer map[string]interface{}) error {
+	_, hasPodSelector := nestedMap(peer, "podSelector")
+	_, hasNamespaceSelector := nestedMap(peer, "namespaceSelector")
+	ipBlock, hasIPBlock := nestedMap(peer, "ipBlock")
+	switch {
+	case hasIPBlock && (hasPodSelector || hasNamespaceSelector):
+		return &ConstraintError{Message: "ipBlock cannot be combined with podSelector or namespaceSelector"}
+	case !hasIPBlock && !hasPodSelector && !hasNamespaceSelector:
+		return &ConstraintError{Rule: RuleRequired, Message: "peer must set ipBlock, podSelector, or namespaceSelector"}
+	case !hasIPBlock:
+		return nil
+	}
+
+	cidr, _ := nestedString(ipBlock, "cidr")
+	if cidr == "" {
+		return Required(NewPath("ipBlock", "cidr"), "cidr is required")
+	}
+	if err := ValidateCIDR(cidr); err != nil {
+		return WithFieldPath("ipBlock.cidr", err)
+	}
+	_, network, _ := net.ParseCIDR(cidr)
+	networkBits, _ := network.Mask.Size()
+
+	errs := make([]error, 0)
+	except, _ := nestedSlice(ipBlock, "except")
+	for i, raw := range except {
+		path := fmt.Sprintf("ipBlock.except[%d]", i)
+		value, _ := raw.(string)
+		if err := ValidateCIDR(value); err != nil {
+			errs = append(errs, WithFieldPath(path, err))
+			continue
+		}
+		_, excluded, _ := net.ParseCIDR(value)
+		excludedBits, _ := excluded.Mask.Size()
+		switch {
+		case ipFamily(value) != ipFamily(cidr):
+			errs = append(errs, &ConstraintError{FieldPath: path, BadValue: value, Message: fmt.Sprintf("except entry '%s' must be of the same IP family as cidr '%s'", value, cidr)})
+		case !network.Contains(excluded.IP) || excludedBits <= networkBits:
+			errs = append(errs, &ConstraintError{FieldPath: path, BadValue: value, Message: fmt.Sprintf("except entry '%s' must be strictly within cidr '%s'", value, cidr)})
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// validateNetworkPolicyPort checks a NetworkPolicy port: its protocol, its port number or
+// name, and the endPort of a port range.
+func validateNetworkPolicyPort(port map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	if protocol, ok := nestedString(port, "protocol"); ok {
+		if err := validateEnumField("protocol", protocol, containerProtocols); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	value, hasPort := nestedField(port, "port")
+	endPort, hasEndPort := nestedField(port, "endPort")
+	if hasPort {
+		if err := validateTargetPort(value); err != nil {
+			errs = append(errs, WithFieldPath("port", err))
+		}
+	}
+	if hasEndPort {
+		start, numeric := toInt64(value)
+		end, ok := toInt64(endPort)
+		switch {
+		case !hasPort || !numeric:
+			errs = append(errs, &ConstraintError{FieldPath: "endPort", BadValue: endPort, Message: "endPort may only be set when port is a port number"})
+		case !ok || end < start || end > maxPortNumber:
+			errs = append(errs, &ConstraintError{FieldPath: "endPort", BadValue: endPort, Message: fmt.Sprintf("endPort must be an integer between port %d and %d", start, maxPortNumber)})
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
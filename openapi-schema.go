@@ -0,0 +1,460 @@
+package k8sconstraints
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// OpenAPISchema is the subset of an OpenAPI v3 schema (as used by CRD openAPIV3Schema)
+// understood by the structural validator.
+type OpenAPISchema struct {
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	AdditionalProperties *OpenAPISchemaOrBool      `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}             `json:"enum,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+	MinLength            *int64                    `json:"minLength,omitempty"`
+	MaxLength            *int64                    `json:"maxLength,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool                      `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool                      `json:"exclusiveMaximum,omitempty"`
+	MultipleOf           *float64                  `json:"multipleOf,omitempty"`
+	MinItems             *int64                    `json:"minItems,omitempty"`
+	MaxItems             *int64                    `json:"maxItems,omitempty"`
+	UniqueItems          bool                      `json:"uniqueItems,omitempty"`
+	MinProperties        *int64                    `json:"minProperties,omitempty"`
+	MaxProperties        *int64                    `json:"maxProperties,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	AllOf                []*OpenAPISchema          `json:"allOf,omitempty"`
+	AnyOf                []*OpenAPISchema          `json:"anyOf,omitempty"`
+	OneOf                []*OpenAPISchema          `json:"oneOf,omitempty"`
+	Not                  *OpenAPISchema            `json:"not,omitempty"`
+
+	XPreserveUnknownFields *bool `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+	XEmbeddedResource      bool  `json:"x-kubernetes-embedded-resource,omitempty"`
+	XIntOrString           bool  `json:"x-kubernetes-int-or-string,omitempty"`
+
+	// XValidations holds the CEL validation rules of the field; see ValidationRule.
+	XValidations []ValidationRule `json:"x-kubernetes-validations,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// OpenAPISchemaOrBool holds additionalProperties, which is either a boolean or a schema.
+type OpenAPISchemaOrBool struct {
+	Allows bool
+	Schema *OpenAPISchema
+}
+
+// UnmarshalJSON decodes either form of additionalProperties.
+func (s *OpenAPISchemaOrBool) UnmarshalJSON(data []byte) error {
+	var allows bool
+	if err := json.Unmarshal(data, &allows); err == nil {
+		s.Allows = allows
+		return nil
+	}
+	s.Allows = true
+	return json.Unmarshal(data, &s.Schema)
+}
+
+// MarshalJSON encodes additionalProperties in its original form.
+func (s OpenAPISchemaOrBool) MarshalJSON() ([]byte, error) {
+	if s.Schema != nil {
+		return json.Marshal(s.Schema)
+	}
+	return json.Marshal(s.Allows)
+}
+
+// CompileOpenAPISchema decodes a schema from its unstructured form and compiles its patterns.
+func CompileOpenAPISchema(raw map[string]interface{}) (*OpenAPISchema, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema OpenAPISchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI schema: %v", err)
+	}
+
+	if err := schema.compile(""); err != nil {
+		return nil, err
+	}
+
+	return &schema, nil
+}
+
+// compile compiles the patterns of s and all of its subschemas.
+func (s *OpenAPISchema) compile(path string) error {
+	if s == nil {
+		return nil
+	}
+
+	errs := make([]error, 0)
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			errs = append(errs, &ConstraintError{FieldPath: path, Message: fmt.Sprintf("invalid pattern: %v", err)})
+		}
+		s.pattern = pattern
+	}
+	s.compileValidationRules()
+
+	for _, name := range sortedKeys(s.Properties) {
+		if err := s.Properties[name].compile(joinFieldPath(path, name)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := s.Items.compile(path + "[*]"); err != nil {
+		errs = append(errs, err)
+	}
+	if s.AdditionalProperties != nil {
+		if err := s.AdditionalProperties.Schema.compile(path + "[*]"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, group := range [][]*OpenAPISchema{s.AllOf, s.AnyOf, s.OneOf, {s.Not}} {
+		for _, sub := range group {
+			if err := sub.compile(path); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// Validate checks value against the schema and returns one ConstraintError per violation,
+// naming its field path.
+func (s *OpenAPISchema) Validate(value interface{}) []error {
+	return s.validate(value, nil)
+}
+
+func (s *OpenAPISchema) validate(value interface{}, path *Path) []error {
+	if s == nil {
+		return nil
+	}
+
+	errs := make([]error, 0)
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, &ConstraintError{FieldPath: path.String(), Message: fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil {
+		if !s.Nullable && s.Type != "" {
+			fail("must not be null")
+		}
+		return errs
+	}
+
+	// Type check; stop here on mismatch to avoid cascading errors
+	if !s.XIntOrString && s.Type != "" && !matchesSchemaType(s.Type, value) {
+		fail("must be of type %s, got %s", s.Type, jsonTypeName(value))
+		return errs
+	}
+	if s.XIntOrString && !matchesSchemaType("integer", value) && !matchesSchemaType("string", value) {
+		fail("must be an integer or a string, got %s", jsonTypeName(value))
+		return errs
+	}
+
+	if len(s.Enum) > 0 && !enumContains(s.Enum, value) {
+		fail("unsupported value %v; must be one of %s%s", formatSchemaValue(value), formatEnum(s.Enum), schemaEnumSuggestion(s.Enum, value))
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := int64(utf8.RuneCountInString(v))
+		if s.MinLength != nil && length < *s.MinLength {
+			fail("must be at least %d characters long", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("must be at most %d characters long", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("must match pattern '%s'", s.Pattern)
+		}
+	case map[string]interface{}:
+		errs = append(errs, s.validateObject(v, path)...)
+	case []interface{}:
+		errs = append(errs, s.validateArray(v, path)...)
+	default:
+		if n, ok := toFloat64(v); ok {
+			if s.Minimum != nil && (n < *s.Minimum || (s.ExclusiveMinimum && n == *s.Minimum)) {
+				fail("must be greater than%s %v", boundWord(s.ExclusiveMinimum), *s.Minimum)
+			}
+			if s.Maximum != nil && (n > *s.Maximum || (s.ExclusiveMaximum && n == *s.Maximum)) {
+				fail("must be less than%s %v", boundWord(s.ExclusiveMaximum), *s.Maximum)
+			}
+			if s.MultipleOf != nil && *s.MultipleOf != 0 {
+				if q := n / *s.MultipleOf; q != float64(int64(q)) {
+					fail("must be a multiple of %v", *s.MultipleOf)
+				}
+			}
+		}
+	}
+
+	errs = append(errs, s.validateRules(value, path)...)
+
+	// Composition keywords
+	for _, sub := range s.AllOf {
+		errs = append(errs, sub.validateValueOnly(value, path)...)
+	}
+	if len(s.AnyOf) > 0 {
+		matched := false
+		for _, sub := range s.AnyOf {
+			if len(sub.validateValueOnly(value, path)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("must match at least one of the anyOf schemas")
+		}
+	}
+	if len(s.OneOf) > 0 {
+		matches := 0
+		for _, sub := range s.OneOf {
+			if len(sub.validateValueOnly(value, path)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			fail("must match exactly one of the oneOf schemas, matched %d", matches)
+		}
+	}
+	if s.Not != nil && len(s.Not.validateValueOnly(value, path)) == 0 {
+		fail("must not match the 'not' schema")
+	}
+
+	return errs
+}
+
+// validateValueOnly validates value against a composition subschema. In structural schemas
+// these subschemas only constrain values, so unknown-field checks are skipped.
+func (s *OpenAPISchema) validateValueOnly(value interface{}, path *Path) []error {
+	preserve := true
+	relaxed := *s
+	relaxed.XPreserveUnknownFields = &preserve
+	return relaxed.validate(value, path)
+}
+
+// validateObject checks required, unknown, and nested properties of an object value.
+func (s *OpenAPISchema) validateObject(obj map[string]interface{}, path *Path) []error {
+	errs := make([]error, 0)
+
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			errs = append(errs, Required(path.Child(name), "required value"))
+		}
+	}
+
+	count := int64(len(obj))
+	if s.MinProperties != nil && count < *s.MinProperties {
+		errs = append(errs, &ConstraintError{FieldPath: path.String(), Message: fmt.Sprintf("must have at least %d properties", *s.MinProperties)})
+	}
+	if s.MaxProperties != nil && count > *s.MaxProperties {
+		errs = append(errs, &ConstraintError{FieldPath: path.String(), Message: fmt.Sprintf("must have at most %d properties", *s.MaxProperties)})
+	}
+
+	for _, name := range sortedKeys(obj) {
+		childPath := path.Child(name)
+		if property, ok := s.Properties[name]; ok {
+			errs = append(errs, property.validate(obj[name], childPath)...)
+			continue
+		}
+		if s.AdditionalProperties != nil {
+			if s.AdditionalProperties.Schema != nil {
+				errs = append(errs, s.AdditionalProperties.Schema.validate(obj[name], path.Key(name))...)
+			} else if !s.AdditionalProperties.Allows {
+				errs = append(errs, &ConstraintError{FieldPath: childPath.String(), Message: "unknown field"})
+			}
+			continue
+		}
+		if s.preservesUnknownFields() || (s.XEmbeddedResource && isTypeMetaField(name)) {
+			continue
+		}
+		errs = append(errs, &ConstraintError{FieldPath: childPath.String(), Message: "unknown field"})
+	}
+
+	return errs
+}
+
+// validateArray checks item count, uniqueness, and item schemas of an array value.
+func (s *OpenAPISchema) validateArray(list []interface{}, path *Path) []error {
+	errs := make([]error, 0)
+
+	count := int64(len(list))
+	if s.MinItems != nil && count < *s.MinItems {
+		errs = append(errs, &ConstraintError{FieldPath: path.String(), Message: fmt.Sprintf("must have at least %d items", *s.MinItems)})
+	}
+	if s.MaxItems != nil && count > *s.MaxItems {
+		errs = append(errs, &ConstraintError{FieldPath: path.String(), Message: fmt.Sprintf("must have at most %d items", *s.MaxItems)})
+	}
+	if s.UniqueItems {
+		for i := range list {
+			for j := 0; j < i; j++ {
+				if reflect.DeepEqual(normalizeNumber(list[i]), normalizeNumber(list[j])) {
+					errs = append(errs, &ConstraintError{FieldPath: path.Index(i).String(), Message: fmt.Sprintf("duplicate of item %d", j)})
+					break
+				}
+			}
+		}
+	}
+
+	for i, item := range list {
+		errs = append(errs, s.Items.validate(item, path.Index(i))...)
+	}
+
+	return errs
+}
+
+// preservesUnknownFields reports whether the schema keeps fields it does not declare.
+func (s *OpenAPISchema) preservesUnknownFields() bool {
+	if s.XPreserveUnknownFields != nil && *s.XPreserveUnknownFields {
+		return true
+	}
+	// An object schema without properties or additionalProperties accepts anything
+	return s.Type == "" && len(s.Properties) == 0
+}
+
+// isTypeMetaField reports whether name is one of the fields every embedded resource carries.
+func isTypeMetaField(name string) bool {
+	return name == "apiVersion" || name == "kind" || name == "metadata"
+}
+
+// matchesSchemaType reports whether a decoded value has the given OpenAPI type.
+func matchesSchemaType(typ string, value interface{}) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "integer":
+		_, ok := toInt64(value)
+		return ok
+	case "number":
+		switch value.(type) {
+		case int, int64, float64:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// jsonTypeName names the JSON type of a decoded value for error messages.
+func jsonTypeName(value interface{}) string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64:
+		return "integer"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// enumContains reports whether value equals one of the enum values, treating numbers of
+// different Go types as equal when their values are.
+func enumContains(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if reflect.DeepEqual(normalizeNumber(allowed), normalizeNumber(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeNumber converts integer types to float64 so decoded values compare equal.
+func normalizeNumber(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	}
+	return value
+}
+
+// formatEnum renders enum values for error messages.
+func formatEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, value := range enum {
+		values[i] = formatSchemaValue(value)
+	}
+	sort.Strings(values)
+	return strings.Join(values, ", ")
+}
+
+// schemaEnumSuggestion returns the enumSuggestion for a string value among the string
+// values of enum, or "" for values of other types.
+func schemaEnumSuggestion(enum []interface{}, value interface{}) string {
+	s, ok := value.(string)
+	if !ok {
+		return ""
+	}
+	allowed := make([]string, 0, len(enum))
+	for _, item := range enum {
+		if item, ok := item.(string); ok {
+			allowed = append(allowed, item)
+		}
+	}
+	return enumSuggestion(s, allowed)
+}
+
+// formatSchemaValue renders a decoded value for error messages.
+func formatSchemaValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(value)
+}
+
+// boundWord describes an inclusive or exclusive bound.
+func boundWord(exclusive bool) string {
+	if exclusive {
+		return ""
+	}
+	return " or equal to"
+}
+
+// schemaViolations joins the violations returned by OpenAPISchema.Validate, reporting them
+// under rule.
+func schemaViolations(rule string, errs []error) error {
+	return mapConstraintErrors(JoinErrors(errs), func(e *ConstraintError) {
+		e.Rule = rule
+	})
+}
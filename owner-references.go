@@ -0,0 +1,78 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Object UIDs are RFC 4122 UUIDs in their canonical textual form
+var uidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ValidateOwnerReferences validates the entries of metadata.ownerReferences: apiVersion and
+// kind must be valid, name must be a valid path segment name, and uid must be a UUID. At
+// most one entry may be the controller, and blockOwnerDeletion only takes effect for the
+// foreground deletion of a controller, so it draws a warning on other entries.
+func ValidateOwnerReferences(refs []interface{}) error {
+	errs := make([]error, 0)
+
+	controller := -1
+	for i, raw := range refs {
+		path := fmt.Sprintf("ownerReferences[%d]", i)
+		ref, ok := raw.(map[string]interface{})
+		if !ok {
+			errs = append(errs, &ConstraintError{FieldPath: path, BadValue: raw, Message: "owner reference must be an object"})
+			continue
+		}
+
+		for _, field := range []string{"apiVersion", "kind", "name", "uid"} {
+			value, _ := nestedString(ref, field)
+			if value == "" {
+				errs = append(errs, &ConstraintError{FieldPath: path + "." + field, Rule: RuleRequired, Message: field + " is required"})
+				continue
+			}
+			if err := validateOwnerReferenceField(field, value); err != nil {
+				errs = append(errs, WithFieldPath(path+"."+field, err))
+			}
+		}
+
+		isController, _ := nestedBool(ref, "controller")
+		if isController {
+			if controller >= 0 {
+				errs = append(errs, &ConstraintError{FieldPath: path + ".controller", BadValue: true, Message: fmt.Sprintf("only one owner reference can be the controller; ownerReferences[%d] already is", controller)})
+			} else {
+				controller = i
+			}
+		}
+		if block, _ := nestedBool(ref, "blockOwnerDeletion"); block && !isController {
+			errs = append(errs, &ConstraintError{FieldPath: path + ".blockOwnerDeletion", BadValue: true, Message: "warning: blockOwnerDeletion is set on an owner reference that is not the controller; set it only alongside controller: true"})
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleOwnerReferences, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validateOwnerReferenceField validates the syntax of a non-empty owner reference field.
+func validateOwnerReferenceField(field, value string) error {
+	switch field {
+	case "apiVersion":
+		return ValidateApiVersion(value)
+	case "kind":
+		return ValidateKind(value)
+	case "name":
+		// Owner names are only constrained by the rules every object name follows
+		if value == "." || value == ".." || strings.ContainsAny(value, "/%") {
+			return &ConstraintError{BadValue: value, Message: fmt.Sprintf("name '%s' may not be '.' or '..' and may not contain '/' or '%%'", value)}
+		}
+	case "uid":
+		if !uidPattern.MatchString(value) {
+			return &ConstraintError{BadValue: value, Message: fmt.Sprintf("uid '%s' must be a UUID such as 123e4567-e89b-12d3-a456-426614174000", value)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package k8sconstraints
+
+import "fmt"
+
+// controllerManagedLabels are label keys that workload controllers set on the pods and
+// revisions they own. Setting them by hand confuses the controller's bookkeeping.
+var controllerManagedLabels = []string{
+	"pod-template-hash",
+	"controller-revision-hash",
+	"pod-template-generation",
+	"statefulset.kubernetes.io/pod-name",
+	"apps.kubernetes.io/pod-index",
+	"controller-uid",
+	"batch.kubernetes.io/controller-uid",
+	"batch.kubernetes.io/job-completion-index",
+}
+
+// CheckWorkloadOwnership is the ID of the check built by WorkloadOwnershipCheck.
+const CheckWorkloadOwnership = "WorkloadOwnership"
+
+// OwnershipRules configures the bare Pod and user-managed ReplicaSet warnings. They only
+// apply to bundles marked as destined for production.
+type OwnershipRules struct {
+	Production       bool `yaml:"-" json:"production"`
+	AllowBarePods    bool `yaml:"allowBarePods,omitempty" json:"allowBarePods,omitempty"`
+	AllowReplicaSets bool `yaml:"allowReplicaSets,omitempty" json:"allowReplicaSets,omitempty"`
+}
+
+// WorkloadOwnershipCheck returns an opt-in check, not run by ValidateObject, that warns
+// about Pods and ReplicaSets not owned by a controller; see ValidateWorkloadOwnership. Run
+// it with RunChecks or pass it to the linter's Checks option.
+func WorkloadOwnershipCheck(rules OwnershipRules) Check {
+	return Check{ID: CheckWorkloadOwnership, Kinds: []string{"Pod", "ReplicaSet.apps"}, Validate: func(obj map[string]interface{}) error {
+		return ValidateWorkloadOwnership(obj, rules)
+	}}
+}
+
+// ValidateWorkloadOwnership reports advisory findings for Pods and ReplicaSets that are not
+// owned by a controller. Bare Pods are not rescheduled when their node fails, and
+// ReplicaSets should be managed through a Deployment to get rolling updates.
+func ValidateWorkloadOwnership(obj map[string]interface{}, rules OwnershipRules) error {
+	if !rules.Production {
+		return nil
+	}
+
+	kind, _ := nestedString(obj, "kind")
+	name, _ := nestedString(obj, "metadata", "name")
+	if owners, _ := nestedSlice(obj, "metadata", "ownerReferences"); len(owners) > 0 {
+		return nil
+	}
+
+	path := NewPath("metadata", "ownerReferences")
+	switch {
+	case kind == "Pod" && !rules.AllowBarePods:
+		return &ConstraintError{FieldPath: path.String(), Rule: CheckWorkloadOwnership, Message: fmt.Sprintf("warning: Pod '%s' is not managed by a controller; use a Deployment, StatefulSet, DaemonSet, or Job instead", name)}
+	case kind == "ReplicaSet" && !rules.AllowReplicaSets:
+		return &ConstraintError{FieldPath: path.String(), Rule: CheckWorkloadOwnership, Message: fmt.Sprintf("warning: ReplicaSet '%s' is managed directly; use a Deployment instead", name)}
+	}
+
+	return nil
+}
+
+// ValidateControllerManagedLabels checks that labels owned by workload controllers, such as
+// pod-template-hash and controller-revision-hash, are not set in metadata, pod templates,
+// or selectors.
+func ValidateControllerManagedLabels(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	locations := [][]string{
+		{"metadata", "labels"},
+		{"spec", "selector", "matchLabels"},
+		{"spec", "template", "metadata", "labels"},
+		{"spec", "jobTemplate", "spec", "template", "metadata", "labels"},
+	}
+	for _, fields := range locations {
+		labels, ok := nestedStringMap(obj, fields...)
+		if !ok {
+			continue
+		}
+		for _, key := range controllerManagedLabels {
+			if _, ok := labels[key]; ok {
+				path := NewPath(fields[0], fields[1:]...).Key(key)
+				errs = append(errs, &ConstraintError{FieldPath: path.String(), Rule: CheckControllerManagedLabels, BadValue: labels[key], Message: fmt.Sprintf("label '%s' is managed by the workload controller and must not be set manually", key)})
+			}
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package k8sconstraints
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSON Patch (RFC 6902) operations used by fixes.
+const (
+	PatchAdd     = "add"
+	PatchRemove  = "remove"
+	PatchReplace = "replace"
+	PatchTest    = "test"
+)
+
+// FieldChange is one step of the fix for a violation: an RFC 6902 operation on the field
+// at FieldPath, which uses the same notation and base as ConstraintError.FieldPath.
+type FieldChange struct {
+	Op        string      `json:"op"`
+	FieldPath string      `json:"fieldPath"`
+	Value     interface{} `json:"value,omitempty"`
+}
+
+// PatchOperation is an RFC 6902 JSON Patch operation addressing the object a finding was
+// reported against.
+type PatchOperation struct {
+	Op    string
+	Path  string
+	Value interface{}
+}
+
+// MarshalJSON renders the operation, with a value for add, replace, and test operations
+// only, since those require one even when it is null or empty.
+func (o PatchOperation) MarshalJSON() ([]byte, error) {
+	if o.Op == PatchRemove {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+	return json.Marshal(struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	}{o.Op, o.Path, o.Value})
+}
+
+// UnmarshalJSON decodes an operation written by MarshalJSON.
+func (o *PatchOperation) UnmarshalJSON(data []byte) error {
+	var decoded struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*o = PatchOperation{Op: decoded.Op, Path: decoded.Path, Value: decoded.Value}
+	return nil
+}
+
+// JSONPatch converts the changes of a fix into JSON Patch operations.
+func JSONPatch(changes []FieldChange) ([]PatchOperation, error) {
+	patch := make([]PatchOperation, 0, len(changes))
+	for _, change := range changes {
+		pointer, err := FieldPathToJSONPointer(change.FieldPath)
+		if err != nil {
+			return nil, err
+		}
+		patch = append(patch, PatchOperation{Op: change.Op, Path: pointer, Value: change.Value})
+	}
+	return patch, nil
+}
+
+// FieldPathToJSONPointer converts a field path such as
+// `spec.containers[0].env[1]` or `metadata.labels["app.kubernetes.io/name"]` into an
+// RFC 6901 JSON Pointer such as /spec/containers/0/env/1.
+func FieldPathToJSONPointer(path string) (string, error) {
+	var pointer strings.Builder
+	add := func(token string) {
+		token = strings.ReplaceAll(token, "~", "~0")
+		token = strings.ReplaceAll(token, "/", "~1")
+		pointer.WriteString("/" + token)
+	}
+
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if i+1 < len(path) && path[i+1] == '"' {
+				// Quoted keys may hold ']' themselves
+				key, rest, err := unquotePrefix(path[i+1:])
+				if err != nil || !strings.HasPrefix(rest, "]") {
+					return "", fmt.Errorf("invalid field path '%s': unterminated subscript", path)
+				}
+				add(key)
+				i = len(path) - len(rest) + 1
+				continue
+			}
+			if end < 0 {
+				return "", fmt.Errorf("invalid field path '%s': unterminated subscript", path)
+			}
+			add(path[i+1 : i+end])
+			i += end + 1
+		default:
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			add(path[i : i+end])
+			i += end
+		}
+	}
+	return pointer.String(), nil
+}
+
+// unquotePrefix unquotes the Go string literal s starts with and returns the remainder.
+func unquotePrefix(s string) (string, string, error) {
+	for end := 1; end < len(s); end++ {
+		switch s[end] {
+		case '\\':
+			end++
+		case '"':
+			value, err := strconv.Unquote(s[:end+1])
+			return value, s[end+1:], err
+		}
+	}
+	return "", s, fmt.Errorf("unterminated string")
+}
+
+// enumCaseFix returns the fix replacing value, found at path, with the allowed value it
+// matches up to case, e.g. "always" with "Always", or nil when there is none.
+func enumCaseFix(path string, allowed []string, value string) []FieldChange {
+	for _, candidate := range allowed {
+		if strings.EqualFold(candidate, value) {
+			return []FieldChange{{Op: PatchTest, FieldPath: path, Value: value}, {Op: PatchReplace, FieldPath: path, Value: candidate}}
+		}
+	}
+	return nil
+}
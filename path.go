@@ -0,0 +1,53 @@
+package k8sconstraints
+
+import "fmt"
+
+// Path locates a field within an object, such as spec.template.metadata.labels["app"],
+// in the style of the Kubernetes field.Path. Validators extend it as they descend into
+// nested fields, so every violation names the field it is about. Paths are immutable;
+// each method returns a new Path. The nil Path is the root of the object being validated.
+type Path struct {
+	path string
+}
+
+// NewPath returns the path of the field name, descending into the fields in more.
+func NewPath(name string, more ...string) *Path {
+	return (*Path)(nil).Child(name, more...)
+}
+
+// Child returns the path of the field name below p, descending into the fields in more.
+func (p *Path) Child(name string, more ...string) *Path {
+	path := joinFieldPath(p.String(), name)
+	for _, field := range more {
+		path = joinFieldPath(path, field)
+	}
+	return &Path{path: path}
+}
+
+// Index returns the path of the i-th element of the list at p, e.g. containers[0].
+func (p *Path) Index(i int) *Path {
+	return &Path{path: p.String() + fmt.Sprintf("[%d]", i)}
+}
+
+// Key returns the path of the entry key of the map at p, e.g. labels["app"].
+func (p *Path) Key(key string) *Path {
+	return &Path{path: p.String() + fmt.Sprintf("[%q]", key)}
+}
+
+// String renders the path, or "" for the root.
+func (p *Path) String() string {
+	if p == nil {
+		return ""
+	}
+	return p.path
+}
+
+// Required returns a violation for a missing field at path.
+func Required(path *Path, message string) *ConstraintError {
+	return &ConstraintError{FieldPath: path.String(), Rule: RuleRequired, Message: message}
+}
+
+// Invalid returns a violation for the rejected value of the field at path.
+func Invalid(path *Path, value interface{}, message string) *ConstraintError {
+	return &ConstraintError{FieldPath: path.String(), BadValue: value, Message: message}
+}
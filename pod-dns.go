@@ -0,0 +1,137 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Limits applied by Kubernetes to a pod's dnsConfig, which the kubelet writes to the
+// container's resolv.conf.
+const (
+	maxDNSNameservers     = 3
+	maxDNSSearchPaths     = 32
+	maxDNSSearchListChars = 2048
+)
+
+// dnsPolicies lists the valid dnsPolicy values of a pod.
+var dnsPolicies = []string{"ClusterFirstWithHostNet", "ClusterFirst", "Default", "None"}
+
+// dnsOptionNamePattern matches a resolv.conf option name such as ndots, edns0, or
+// single-request-reopen.
+var dnsOptionNamePattern = regexp.MustCompile(`^[a-z0-9]+([-_][a-z0-9]+)*$`)
+
+// dnsIntegerOptions maps the resolv.conf options taking an integer value to the largest
+// value the resolver honors.
+var dnsIntegerOptions = map[string]int64{"ndots": 15, "timeout": 30, "attempts": 5}
+
+// ValidatePodDNS checks the DNS settings of a pod spec: dnsPolicy is a supported value,
+// ClusterFirstWithHostNet is only used with hostNetwork, and None comes with a dnsConfig
+// naming a nameserver. dnsConfig may list at most 3 nameservers, which must be IP
+// addresses, and at most 32 search domains of at most 2048 characters in total, which
+// must be DNS subdomains; options need a name, and ndots, timeout, and attempts an integer
+// value.
+func ValidatePodDNS(spec map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	policy, _ := nestedString(spec, "dnsPolicy")
+	hostNetwork, _ := nestedBool(spec, "hostNetwork")
+	nameservers, _ := nestedSlice(spec, "dnsConfig", "nameservers")
+	if policy != "" {
+		if err := validateEnumField("dnsPolicy", policy, dnsPolicies); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	switch {
+	case policy == "ClusterFirstWithHostNet" && !hostNetwork:
+		errs = append(errs, &ConstraintError{FieldPath: "dnsPolicy", BadValue: policy, Message: "warning: dnsPolicy ClusterFirstWithHostNet only differs from ClusterFirst when hostNetwork is true", Fix: []FieldChange{
+			{Op: PatchTest, FieldPath: "dnsPolicy", Value: policy},
+			{Op: PatchReplace, FieldPath: "dnsPolicy", Value: "ClusterFirst"},
+		}})
+	case policy == "None" && len(nameservers) == 0:
+		errs = append(errs, Required(NewPath("dnsConfig", "nameservers"), "dnsConfig must list at least one nameserver when dnsPolicy is None"))
+	}
+
+	if dnsConfig, ok := nestedMap(spec, "dnsConfig"); ok {
+		if err := validatePodDNSConfig(dnsConfig); err != nil {
+			errs = append(errs, WithFieldPath("dnsConfig", err))
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RulePodDNS, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validatePodDNSConfig checks the nameservers, search domains, and options of a pod's
+// dnsConfig.
+func validatePodDNSConfig(dnsConfig map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	// Nameservers
+	nameservers, _ := nestedSlice(dnsConfig, "nameservers")
+	if len(nameservers) > maxDNSNameservers {
+		errs = append(errs, Invalid(NewPath("nameservers"), len(nameservers), fmt.Sprintf("nameservers may list at most %d addresses, but lists %d", maxDNSNameservers, len(nameservers))))
+	}
+	for i, raw := range nameservers {
+		nameserver, _ := raw.(string)
+		if err := ValidateIP(nameserver); err != nil {
+			errs = append(errs, WithFieldPath(NewPath("nameservers").Index(i).String(), err))
+		}
+	}
+
+	// Search domains, which resolv.conf holds on a single line
+	searches, _ := nestedSlice(dnsConfig, "searches")
+	domains := toStringSlice(searches)
+	if len(domains) > maxDNSSearchPaths {
+		errs = append(errs, Invalid(NewPath("searches"), len(domains), fmt.Sprintf("searches may list at most %d domains, but lists %d", maxDNSSearchPaths, len(domains))))
+	}
+	if length := len(strings.Join(domains, " ")); length > maxDNSSearchListChars {
+		errs = append(errs, Invalid(NewPath("searches"), length, fmt.Sprintf("searches must be at most %d characters in total, separated by spaces, but are %d", maxDNSSearchListChars, length)))
+	}
+	for i, domain := range domains {
+		// A trailing dot marks a fully qualified domain
+		if err := ValidateDNSSubdomain(strings.TrimSuffix(domain, ".")); err != nil {
+			errs = append(errs, WithFieldPath(NewPath("searches").Index(i).String(), withMessagePrefix("invalid search domain: ", err)))
+		}
+	}
+
+	// Options
+	options, _ := nestedSlice(dnsConfig, "options")
+	for i, raw := range options {
+		optionPath := NewPath("options").Index(i)
+		option, _ := raw.(map[string]interface{})
+		name, _ := nestedString(option, "name")
+		switch {
+		case name == "":
+			errs = append(errs, Required(optionPath.Child("name"), "name is required"))
+			continue
+		case !dnsOptionNamePattern.MatchString(name):
+			errs = append(errs, Invalid(optionPath.Child("name"), name, fmt.Sprintf("option name '%s' must consist of lowercase alphanumeric characters separated by '-' or '_', such as ndots or single-request-reopen; set its value in value", name)))
+			continue
+		}
+		limit, ok := dnsIntegerOptions[name]
+		if !ok {
+			continue
+		}
+		value, hasValue := nestedString(option, "value")
+		if !hasValue {
+			errs = append(errs, Required(optionPath.Child("value"), fmt.Sprintf("value is required for option %s", name)))
+		} else if n, err := strconv.ParseInt(value, 10, 64); err != nil || n < 0 {
+			errs = append(errs, Invalid(optionPath.Child("value"), value, fmt.Sprintf("value of option %s must be a non-negative integer", name)))
+		} else if n > limit {
+			errs = append(errs, Invalid(optionPath.Child("value"), value, fmt.Sprintf("warning: value of option %s is capped at %d by the resolver", name, limit)))
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
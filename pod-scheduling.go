@@ -0,0 +1,96 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ValidatePodSchedulingAndOverhead validates spec.schedulingGates, spec.overhead, and
+// spec.affinity of the pod spec embedded in obj (a Pod or any workload with a pod template).
+func ValidatePodSchedulingAndOverhead(obj map[string]interface{}) error {
+	spec, path, ok := findPodSpec(obj)
+	if !ok {
+		return nil
+	}
+
+	errs := make([]error, 0)
+	if err := ValidateSchedulingGates(spec); err != nil {
+		errs = append(errs, WithFieldPath(path, err))
+	}
+	if err := ValidatePodOverhead(spec); err != nil {
+		errs = append(errs, WithFieldPath(path, err))
+	}
+	if err := ValidateAffinity(spec); err != nil {
+		errs = append(errs, WithFieldPath(path, err))
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(CheckPodSchedulingAndOverhead, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// ValidateSchedulingGates checks that every schedulingGates[].name is a qualified name and
+// that no gate is listed twice.
+func ValidateSchedulingGates(spec map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	gates, _ := nestedSlice(spec, "schedulingGates")
+	seen := make(map[string]bool)
+	for i, raw := range gates {
+		namePath := NewPath("schedulingGates").Index(i).Child("name")
+		gate, _ := raw.(map[string]interface{})
+		name, _ := nestedString(gate, "name")
+		if name == "" {
+			errs = append(errs, Required(namePath, "name is required"))
+			continue
+		}
+		if err := ValidateQualifiedName(name); err != nil {
+			errs = append(errs, WithFieldPath(namePath.String(), err))
+		}
+		if seen[name] {
+			errs = append(errs, Invalid(namePath, name, fmt.Sprintf("duplicate scheduling gate '%s'", name)))
+		}
+		seen[name] = true
+	}
+
+	if len(errs) > 0 {
+		return withDefaultRule(CheckPodSchedulingAndOverhead, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// ValidatePodOverhead checks that spec.overhead holds valid, non-negative quantities keyed by
+// resource name, and that it is only set together with a runtimeClassName. The RuntimeClass
+// admission controller rejects pods that declare overhead without a RuntimeClass.
+func ValidatePodOverhead(spec map[string]interface{}) error {
+	overhead, ok := nestedMap(spec, "overhead")
+	if !ok {
+		return nil
+	}
+
+	errs := make([]error, 0)
+
+	if runtimeClass, _ := nestedString(spec, "runtimeClassName"); runtimeClass == "" {
+		errs = append(errs, errors.New("overhead must only be set when runtimeClassName is set"))
+	}
+
+	for _, name := range sortedKeys(overhead) {
+		path := NewPath("overhead").Key(name)
+		if err := ValidateQualifiedName(name); err != nil {
+			errs = append(errs, WithFieldPath(path.String(), err))
+		}
+		if err := validateNonNegativeQuantity(overhead[name]); err != nil {
+			errs = append(errs, WithFieldPath(path.String(), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return withDefaultRule(CheckPodSchedulingAndOverhead, JoinErrors(errs))
+	}
+
+	return nil
+}
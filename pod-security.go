@@ -0,0 +1,328 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckPodSecurity is the ID of the check built by PodSecurityCheck, and the rule code of
+// the violations it reports.
+const CheckPodSecurity = "PodSecurity"
+
+// The Pod Security Standards profiles, from least to most restrictive.
+const (
+	PodSecurityPrivileged = "privileged"
+	PodSecurityBaseline   = "baseline"
+	PodSecurityRestricted = "restricted"
+)
+
+// podSecurityLevels lists the Pod Security Standards profiles.
+var podSecurityLevels = []string{PodSecurityPrivileged, PodSecurityBaseline, PodSecurityRestricted}
+
+// Values the Pod Security Standards allow, as of Kubernetes 1.30.
+var (
+	// baselineCapabilities are the capabilities the baseline profile allows to be added.
+	baselineCapabilities = []string{"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD", "NET_BIND_SERVICE", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT"}
+	// baselineSELinuxTypes are the SELinux types the baseline profile allows.
+	baselineSELinuxTypes = []string{"", "container_t", "container_init_t", "container_kvm_t", "container_engine_t"}
+	// baselineSysctls are the namespaced sysctls the baseline profile considers safe.
+	baselineSysctls = []string{
+		"kernel.shm_rmid_forced", "net.ipv4.ip_local_port_range", "net.ipv4.ip_unprivileged_port_start",
+		"net.ipv4.tcp_syncookies", "net.ipv4.ping_group_range", "net.ipv4.ip_local_reserved_ports",
+		"net.ipv4.tcp_keepalive_time", "net.ipv4.tcp_fin_timeout", "net.ipv4.tcp_keepalive_intvl",
+		"net.ipv4.tcp_keepalive_probes",
+	}
+	// restrictedVolumeTypes are the volume sources the restricted profile allows.
+	restrictedVolumeTypes = []string{"configMap", "csi", "downwardAPI", "emptyDir", "ephemeral", "persistentVolumeClaim", "projected", "secret"}
+)
+
+// appArmorAnnotationPrefix is the prefix of the annotations that set the AppArmor profile
+// of a container before securityContext.appArmorProfile replaced them.
+const appArmorAnnotationPrefix = "container.apparmor.security.beta.kubernetes.io/"
+
+// PodSecurityConfig selects the Pod Security Standards profile each namespace's pods are
+// held to, as the pod-security.kubernetes.io/enforce label of a namespace does.
+type PodSecurityConfig struct {
+	// Level is the profile of the namespaces Namespaces does not list, and of objects
+	// without a namespace: privileged, baseline, or restricted. Empty is privileged.
+	Level string `yaml:"level,omitempty" json:"level,omitempty"`
+	// Namespaces maps namespace names to the profile their pods are held to.
+	Namespaces map[string]string `yaml:"namespaces,omitempty" json:"namespaces,omitempty"`
+}
+
+// Validate checks that every configured profile is privileged, baseline, or restricted.
+func (c PodSecurityConfig) Validate() error {
+	errs := make([]error, 0)
+	if c.Level != "" && !containsString(podSecurityLevels, c.Level) {
+		errs = append(errs, fmt.Errorf("level: profile '%s' is not supported; must be one of: %s", c.Level, strings.Join(podSecurityLevels, ", ")))
+	}
+	for _, namespace := range sortedKeys(c.Namespaces) {
+		if level := c.Namespaces[namespace]; !containsString(podSecurityLevels, level) {
+			errs = append(errs, fmt.Errorf("namespaces[%s]: profile '%s' is not supported; must be one of: %s", namespace, level, strings.Join(podSecurityLevels, ", ")))
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// levelFor returns the profile the pods of namespace are held to.
+func (c PodSecurityConfig) levelFor(namespace string) string {
+	if level, ok := c.Namespaces[namespace]; ok && namespace != "" {
+		return level
+	}
+	return c.Level
+}
+
+// PodSecurityCheck returns an opt-in check, not run by ValidateObject, that holds the pod
+// spec of Pods and workloads to the Pod Security Standards profile config selects for
+// their namespace; see ValidatePodSecurity. Run it with RunChecks or pass it to the
+// linter's Checks option.
+func PodSecurityCheck(config PodSecurityConfig) Check {
+	return Check{ID: CheckPodSecurity, Validate: func(obj map[string]interface{}) error {
+		namespace, _ := nestedString(obj, "metadata", "namespace")
+		return ValidatePodSecurity(obj, config.levelFor(namespace))
+	}}
+}
+
+// ValidatePodSecurity checks the pod spec embedded in obj (a Pod or any workload with a
+// pod template) against the baseline or restricted Pod Security Standards profile, as the
+// PodSecurity admission controller does. Each violation names the profile and control it
+// falls under, e.g. "baseline control 'Host Namespaces'". The privileged profile, and
+// objects without a pod spec, pass.
+//
+// The baseline profile forbids host namespaces, privileged and HostProcess containers,
+// hostPath volumes, host ports, capabilities beyond the default set, unconfined seccomp and
+// AppArmor profiles, custom SELinux users, roles, and types, unmasked /proc mounts, and
+// unsafe sysctls. The restricted profile further limits volumes to ephemeral and
+// API-backed types and requires containers to run as non-root users with privilege
+// escalation disabled, a RuntimeDefault or Localhost seccomp profile, and every capability
+// but NET_BIND_SERVICE dropped; the last three do not apply to Windows pods.
+func ValidatePodSecurity(obj map[string]interface{}, level string) error {
+	if level != PodSecurityBaseline && level != PodSecurityRestricted {
+		return nil
+	}
+	spec, specPath, ok := findPodSpec(obj)
+	if !ok {
+		return nil
+	}
+
+	audit := &podSecurityAudit{errs: make([]error, 0)}
+	audit.checkBaseline(spec)
+	if level == PodSecurityRestricted {
+		audit.checkRestricted(spec)
+	}
+	errs := make([]error, 0)
+	if len(audit.errs) > 0 {
+		errs = append(errs, WithFieldPath(specPath, JoinErrors(audit.errs)))
+	}
+
+	// AppArmor annotations live in the metadata of the pod or its template
+	metadataFields := strings.Split(specPath, ".")
+	metadataFields[len(metadataFields)-1] = "metadata"
+	annotations, _ := nestedMap(obj, append(metadataFields, "annotations")...)
+	for _, key := range sortedKeys(annotations) {
+		value, _ := annotations[key].(string)
+		if strings.HasPrefix(key, appArmorAnnotationPrefix) && value != "runtime/default" && !strings.HasPrefix(value, "localhost/") {
+			errs = append(errs, &ConstraintError{FieldPath: fmt.Sprintf("%s.annotations[%q]", strings.Join(metadataFields, "."), key), Rule: CheckPodSecurity, BadValue: value, Message: podSecurityMessage(PodSecurityBaseline, "AppArmor", fmt.Sprintf("AppArmor profile '%s' is not allowed; must be runtime/default or localhost/<profile>", value))})
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// podSecurityAudit collects the Pod Security Standards violations of a pod spec, with field
+// paths relative to the pod spec.
+type podSecurityAudit struct {
+	errs []error
+}
+
+// violation records a violation of control, a control of the given profile, at path.
+func (a *podSecurityAudit) violation(level, control, path string, value interface{}, message string) {
+	a.errs = append(a.errs, &ConstraintError{FieldPath: path, Rule: CheckPodSecurity, BadValue: value, Message: podSecurityMessage(level, control, message)})
+}
+
+// podSecurityMessage formats a violation message naming the profile and control it falls
+// under.
+func podSecurityMessage(level, control, message string) string {
+	return fmt.Sprintf("%s control '%s': %s", level, control, message)
+}
+
+// podSecurityContexts returns the pod security context of spec, at "securityContext", and
+// that of each of its containers.
+func podSecurityContexts(spec map[string]interface{}) map[string]map[string]interface{} {
+	contexts := make(map[string]map[string]interface{})
+	if securityContext, ok := nestedMap(spec, "securityContext"); ok {
+		contexts["securityContext"] = securityContext
+	}
+	for _, container := range podContainers(spec) {
+		if securityContext, ok := nestedMap(container.fields, "securityContext"); ok {
+			contexts[container.path+".securityContext"] = securityContext
+		}
+	}
+	return contexts
+}
+
+// checkBaseline checks the controls of the baseline profile.
+func (a *podSecurityAudit) checkBaseline(spec map[string]interface{}) {
+	for _, field := range []string{"hostNetwork", "hostPID", "hostIPC"} {
+		if value, _ := nestedBool(spec, field); value {
+			a.violation(PodSecurityBaseline, "Host Namespaces", field, true, fmt.Sprintf("%s must not be true", field))
+		}
+	}
+
+	volumes, _ := nestedSlice(spec, "volumes")
+	for i, raw := range volumes {
+		volume, _ := raw.(map[string]interface{})
+		if _, ok := volume["hostPath"]; ok {
+			a.violation(PodSecurityBaseline, "HostPath Volumes", fmt.Sprintf("volumes[%d].hostPath", i), nil, "hostPath volumes are not allowed")
+		}
+	}
+
+	for _, container := range podContainers(spec) {
+		ports, _ := nestedSlice(container.fields, "ports")
+		for i, raw := range ports {
+			port, _ := raw.(map[string]interface{})
+			if value, ok := nestedField(port, "hostPort"); ok {
+				if n, _ := toInt64(value); n != 0 {
+					a.violation(PodSecurityBaseline, "Host Ports", fmt.Sprintf("%s.ports[%d].hostPort", container.path, i), value, "hostPort must not be set")
+				}
+			}
+		}
+		if privileged, _ := nestedBool(container.fields, "securityContext", "privileged"); privileged {
+			a.violation(PodSecurityBaseline, "Privileged Containers", container.path+".securityContext.privileged", true, "privileged must not be true")
+		}
+		if procMount, ok := nestedString(container.fields, "securityContext", "procMount"); ok && procMount != "Default" {
+			a.violation(PodSecurityBaseline, "/proc Mount Type", container.path+".securityContext.procMount", procMount, fmt.Sprintf("procMount '%s' is not allowed; must be Default", procMount))
+		}
+		added, _ := nestedSlice(container.fields, "securityContext", "capabilities", "add")
+		for i, raw := range added {
+			capability, _ := raw.(string)
+			if !containsString(baselineCapabilities, capability) {
+				a.violation(PodSecurityBaseline, "Capabilities", fmt.Sprintf("%s.securityContext.capabilities.add[%d]", container.path, i), raw, fmt.Sprintf("capability '%v' may not be added; allowed: %s", raw, strings.Join(baselineCapabilities, ", ")))
+			}
+		}
+	}
+
+	contexts := podSecurityContexts(spec)
+	for _, path := range sortedKeys(contexts) {
+		securityContext := contexts[path]
+		if hostProcess, _ := nestedBool(securityContext, "windowsOptions", "hostProcess"); hostProcess {
+			a.violation(PodSecurityBaseline, "HostProcess", path+".windowsOptions.hostProcess", true, "hostProcess must not be true")
+		}
+		if profile, _ := nestedString(securityContext, "seccompProfile", "type"); profile == "Unconfined" {
+			a.violation(PodSecurityBaseline, "Seccomp", path+".seccompProfile.type", profile, "seccomp profile 'Unconfined' is not allowed")
+		}
+		if profile, _ := nestedString(securityContext, "appArmorProfile", "type"); profile == "Unconfined" {
+			a.violation(PodSecurityBaseline, "AppArmor", path+".appArmorProfile.type", profile, "AppArmor profile 'Unconfined' is not allowed")
+		}
+		if options, ok := nestedMap(securityContext, "seLinuxOptions"); ok {
+			if seLinuxType, _ := nestedString(options, "type"); !containsString(baselineSELinuxTypes, seLinuxType) {
+				a.violation(PodSecurityBaseline, "SELinux", path+".seLinuxOptions.type", seLinuxType, fmt.Sprintf("SELinux type '%s' is not allowed; must be one of: %s", seLinuxType, strings.Join(baselineSELinuxTypes[1:], ", ")))
+			}
+			for _, field := range []string{"user", "role"} {
+				if value, _ := nestedString(options, field); value != "" {
+					a.violation(PodSecurityBaseline, "SELinux", path+".seLinuxOptions."+field, value, fmt.Sprintf("SELinux %s must not be set", field))
+				}
+			}
+		}
+	}
+
+	sysctls, _ := nestedSlice(spec, "securityContext", "sysctls")
+	for i, raw := range sysctls {
+		sysctl, _ := raw.(map[string]interface{})
+		if name, _ := nestedString(sysctl, "name"); !containsString(baselineSysctls, name) {
+			a.violation(PodSecurityBaseline, "Sysctls", fmt.Sprintf("securityContext.sysctls[%d].name", i), name, fmt.Sprintf("sysctl '%s' is not in the safe set", name))
+		}
+	}
+}
+
+// checkRestricted checks the controls the restricted profile adds to the baseline ones.
+func (a *podSecurityAudit) checkRestricted(spec map[string]interface{}) {
+	volumes, _ := nestedSlice(spec, "volumes")
+	for i, raw := range volumes {
+		volume, _ := raw.(map[string]interface{})
+		for _, source := range sortedKeys(volume) {
+			if source != "name" && source != "hostPath" && !containsString(restrictedVolumeTypes, source) {
+				a.violation(PodSecurityRestricted, "Volume Types", fmt.Sprintf("volumes[%d].%s", i, source), nil, fmt.Sprintf("%s volumes are not allowed; must be one of: %s", source, strings.Join(restrictedVolumeTypes, ", ")))
+			}
+		}
+	}
+
+	// Running as non-root: at pod level, or else in every container
+	podNonRoot, podNonRootSet := nestedBool(spec, "securityContext", "runAsNonRoot")
+	if podNonRootSet && !podNonRoot {
+		a.violation(PodSecurityRestricted, "Running as Non-root", "securityContext.runAsNonRoot", false, "runAsNonRoot must not be false")
+	}
+	podSeccomp, _ := nestedString(spec, "securityContext", "seccompProfile", "type")
+	if user, ok := nestedField(spec, "securityContext", "runAsUser"); ok {
+		if n, ok := toInt64(user); ok && n == 0 {
+			a.violation(PodSecurityRestricted, "Running as Non-root user", "securityContext.runAsUser", user, "runAsUser must not be 0")
+		}
+	}
+	windows := false
+	if os, _ := nestedString(spec, "os", "name"); os == "windows" {
+		windows = true
+	}
+
+	for _, container := range podContainers(spec) {
+		path := container.path + ".securityContext"
+		securityContext, _ := nestedMap(container.fields, "securityContext")
+
+		nonRoot, nonRootSet := nestedBool(securityContext, "runAsNonRoot")
+		switch {
+		case nonRootSet && !nonRoot:
+			a.violation(PodSecurityRestricted, "Running as Non-root", path+".runAsNonRoot", false, "runAsNonRoot must not be false")
+		case !nonRootSet && !podNonRoot:
+			a.violation(PodSecurityRestricted, "Running as Non-root", path+".runAsNonRoot", nil, "runAsNonRoot must be true, in the container or pod securityContext")
+		}
+		if user, ok := nestedField(securityContext, "runAsUser"); ok {
+			if n, ok := toInt64(user); ok && n == 0 {
+				a.violation(PodSecurityRestricted, "Running as Non-root user", path+".runAsUser", user, "runAsUser must not be 0")
+			}
+		}
+		if windows {
+			continue
+		}
+
+		if escalation, ok := nestedBool(securityContext, "allowPrivilegeEscalation"); !ok || escalation {
+			a.violation(PodSecurityRestricted, "Privilege Escalation", path+".allowPrivilegeEscalation", nil, "allowPrivilegeEscalation must be false")
+		}
+		seccomp, _ := nestedString(securityContext, "seccompProfile", "type")
+		if seccomp == "" {
+			seccomp = podSeccomp
+		}
+		if seccomp != "RuntimeDefault" && seccomp != "Localhost" && seccomp != "Unconfined" {
+			a.violation(PodSecurityRestricted, "Seccomp", path+".seccompProfile.type", nil, "seccompProfile.type must be RuntimeDefault or Localhost, in the container or pod securityContext")
+		}
+		dropped, _ := nestedSlice(securityContext, "capabilities", "drop")
+		if !containsValue(dropped, "ALL") {
+			a.violation(PodSecurityRestricted, "Capabilities", path+".capabilities.drop", nil, "capabilities must drop ALL")
+		}
+		added, _ := nestedSlice(securityContext, "capabilities", "add")
+		for i, raw := range added {
+			if capability, _ := raw.(string); capability != "NET_BIND_SERVICE" {
+				a.violation(PodSecurityRestricted, "Capabilities", fmt.Sprintf("%s.capabilities.add[%d]", path, i), raw, fmt.Sprintf("capability '%v' may not be added; only NET_BIND_SERVICE is allowed", raw))
+			}
+		}
+	}
+}
+
+// containsValue reports whether list contains the string s.
+func containsValue(list []interface{}, s string) bool {
+	for _, item := range list {
+		if value, ok := item.(string); ok && value == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,345 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Limits applied by Kubernetes to container ports and security context IDs.
+const (
+	maxPortNumber = 65535
+	maxUserID     = 2147483647
+)
+
+// Enumerated pod spec values accepted by the API server.
+var (
+	podRestartPolicies = []string{"Always", "OnFailure", "Never"}
+	containerProtocols = []string{"TCP", "UDP", "SCTP"}
+	imagePullPolicies  = []string{"Always", "IfNotPresent", "Never"}
+)
+
+// podWorkloadKinds are the kinds embedding a pod spec that ValidatePod is registered for.
+var podWorkloadKinds = []GroupVersionKind{
+	{Kind: "Pod"},
+	{Kind: "PodTemplate"},
+	{Kind: "ReplicationController"},
+	{Group: "apps", Kind: "Deployment"},
+	{Group: "apps", Kind: "StatefulSet"},
+	{Group: "apps", Kind: "DaemonSet"},
+	{Group: "apps", Kind: "ReplicaSet"},
+	{Group: "batch", Kind: "Job"},
+	{Group: "batch", Kind: "CronJob"},
+}
+
+// ValidatePod validates the pod spec embedded in obj (a Pod or any workload with a pod
+// template) with ValidatePodSpec. For workloads, the labels and annotations of the pod
+// template are validated too, e.g. spec.template.metadata.labels["app"].
+func ValidatePod(obj map[string]interface{}) error {
+	spec, path, ok := findPodSpec(obj)
+	if !ok {
+		return nil
+	}
+
+	errs := make([]error, 0)
+
+	if err := validatePodTemplateMetadata(obj); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidatePodSpec(spec); err != nil {
+		errs = append(errs, WithFieldPath(path, err))
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RulePodSpec, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validatePodTemplateMetadata validates the labels and annotations in the metadata of the
+// pod template of a workload. A bare Pod has no template; its metadata is validated with the
+// rest of the object.
+func validatePodTemplateMetadata(obj map[string]interface{}) error {
+	kind, _ := nestedString(obj, "kind")
+	fields := podTemplateSpecPaths[kind]
+	if len(fields) < 2 {
+		return nil
+	}
+
+	templateFields := append(append([]string{}, fields[:len(fields)-1]...), "metadata")
+	metadata, ok := nestedMap(obj, templateFields...)
+	if !ok {
+		return nil
+	}
+	path := NewPath(templateFields[0], templateFields[1:]...)
+
+	errs := make([]error, 0)
+
+	if err := validateStringMapField(metadata, "labels", ValidateMetadataLabels); err != nil {
+		errs = append(errs, WithFieldPath(path.String(), err))
+	}
+	if err := validateStringMapField(metadata, "annotations", ValidateMetadataAnnotations); err != nil {
+		errs = append(errs, WithFieldPath(path.String(), err))
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// ValidatePodSpec validates a pod spec: container names, images, ports, and environment
+// variables, volumes and the mounts that refer to them, restartPolicy, the pod and
+// container security contexts, hostAliases, and the DNS settings. Field paths are relative
+// to the pod spec.
+func ValidatePodSpec(spec map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	if containers, _ := nestedSlice(spec, "containers"); len(containers) == 0 {
+		errs = append(errs, &ConstraintError{FieldPath: "containers", Rule: RuleRequired, Message: "at least one container is required"})
+	}
+
+	// Volumes, which container volumeMounts refer to by name
+	volumes, err := validatePodVolumes(spec)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	// Containers, whose names must be unique across every container list
+	names := make(map[string]bool)
+	for _, container := range podContainers(spec) {
+		name, _ := nestedString(container.fields, "name")
+		if name != "" && names[name] {
+			errs = append(errs, &ConstraintError{FieldPath: container.path + ".name", BadValue: name, Message: fmt.Sprintf("duplicate container name '%s'", name)})
+		}
+		names[name] = true
+
+		if err := ValidateContainer(container.fields, volumes); err != nil {
+			errs = append(errs, WithFieldPath(container.path, err))
+		}
+
+		// Only regular containers and sidecar init containers are probed
+		restartPolicy, _ := nestedString(container.fields, "restartPolicy")
+		for _, field := range containerProbes {
+			if _, ok := container.fields[field]; !ok {
+				continue
+			}
+			switch {
+			case strings.HasPrefix(container.path, "ephemeralContainers"):
+				errs = append(errs, &ConstraintError{FieldPath: container.path + "." + field, Message: fmt.Sprintf("%s may not be set for ephemeral containers", field)})
+			case strings.HasPrefix(container.path, "initContainers") && restartPolicy != "Always":
+				errs = append(errs, &ConstraintError{FieldPath: container.path + "." + field, Message: fmt.Sprintf("%s may only be set for init containers whose restartPolicy is Always", field)})
+			}
+		}
+	}
+
+	if restartPolicy, ok := nestedString(spec, "restartPolicy"); ok {
+		if err := validateEnumField("restartPolicy", restartPolicy, podRestartPolicies); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if securityContext, ok := nestedMap(spec, "securityContext"); ok {
+		if err := ValidatePodSecurityContext(securityContext); err != nil {
+			errs = append(errs, WithFieldPath("securityContext", err))
+		}
+	}
+
+	if err := validatePodHostAliases(spec); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidatePodDNS(spec); err != nil {
+		errs = append(errs, err)
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RulePodSpec, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validatePodHostAliases checks the entries the kubelet adds to the pod's hosts file: each
+// needs an IP address and hostnames that are DNS subdomains.
+func validatePodHostAliases(spec map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	aliases, _ := nestedSlice(spec, "hostAliases")
+	for i, raw := range aliases {
+		alias, _ := raw.(map[string]interface{})
+		path := NewPath("hostAliases").Index(i)
+		if ip, _ := nestedString(alias, "ip"); ip == "" {
+			errs = append(errs, Required(path.Child("ip"), "ip is required"))
+		} else if err := ValidateIP(ip); err != nil {
+			errs = append(errs, WithFieldPath(path.Child("ip").String(), err))
+		}
+		hostnames, _ := nestedSlice(alias, "hostnames")
+		for j, rawHostname := range hostnames {
+			hostname, _ := rawHostname.(string)
+			if err := ValidateDNSSubdomain(hostname); err != nil {
+				errs = append(errs, WithFieldPath(path.Child("hostnames").Index(j).String(), withMessagePrefix("invalid hostname: ", err)))
+			}
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// validatePodVolumes checks that every volume has a unique DNS label name and returns the
+// set of declared volume names.
+func validatePodVolumes(spec map[string]interface{}) (map[string]bool, error) {
+	errs := make([]error, 0)
+	names := make(map[string]bool)
+
+	volumes, _ := nestedSlice(spec, "volumes")
+	for i, raw := range volumes {
+		namePath := NewPath("volumes").Index(i).Child("name")
+		volume, _ := raw.(map[string]interface{})
+		name, _ := nestedString(volume, "name")
+		if name == "" {
+			errs = append(errs, Required(namePath, "name is required"))
+			continue
+		}
+		if err := ValidateDNSLabel(name); err != nil {
+			errs = append(errs, WithFieldPath(namePath.String(), err))
+		}
+		if names[name] {
+			errs = append(errs, Invalid(namePath, name, fmt.Sprintf("duplicate volume name '%s'", name)))
+		}
+		names[name] = true
+	}
+
+	if len(errs) > 0 {
+		return names, JoinErrors(errs)
+	}
+
+	return names, nil
+}
+
+// ValidateContainer validates a single container: its name, image reference and pull
+// policy, ports, env and envFrom names, resource requests and limits, volume mounts,
+// security context, and probes. volumes holds the names of the pod's volumes; mounts of any
+// other volume are reported.
+func ValidateContainer(container map[string]interface{}, volumes map[string]bool) error {
+	errs := make([]error, 0)
+
+	if name, _ := nestedString(container, "name"); name == "" {
+		errs = append(errs, &ConstraintError{FieldPath: "name", Rule: RuleRequired, Message: "name is required"})
+	} else if err := ValidateDNSLabel(name); err != nil {
+		errs = append(errs, WithFieldPath("name", err))
+	}
+
+	if image, _ := nestedString(container, "image"); image == "" {
+		errs = append(errs, &ConstraintError{FieldPath: "image", Rule: RuleRequired, Message: "image is required"})
+	} else if strings.TrimSpace(image) != image {
+		errs = append(errs, &ConstraintError{FieldPath: "image", BadValue: image, Message: "image must not have leading or trailing whitespace", Fix: []FieldChange{
+			{Op: PatchTest, FieldPath: "image", Value: image},
+			{Op: PatchReplace, FieldPath: "image", Value: strings.TrimSpace(image)},
+		}})
+	} else if err := ValidateImageReference(image); err != nil {
+		errs = append(errs, WithFieldPath("image", err))
+	}
+	if policy, ok := nestedString(container, "imagePullPolicy"); ok {
+		if err := validateEnumField("imagePullPolicy", policy, imagePullPolicies); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := validateContainerPorts(container); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateContainerEnv(container); err != nil {
+		errs = append(errs, err)
+	}
+
+	if resources, ok := nestedMap(container, "resources"); ok {
+		if err := ValidateResourceRequirements(resources); err != nil {
+			errs = append(errs, WithFieldPath("resources", err))
+		}
+	}
+
+	if err := ValidateVolumeMounts(container, volumes); err != nil {
+		errs = append(errs, err)
+	}
+
+	if securityContext, ok := nestedMap(container, "securityContext"); ok {
+		if err := ValidateContainerSecurityContext(securityContext); err != nil {
+			errs = append(errs, WithFieldPath("securityContext", err))
+		}
+	}
+
+	if err := ValidateContainerProbes(container); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return withDefaultRule(RulePodSpec, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validateContainerPorts checks the port numbers, protocols, and names of a container's
+// ports. Port names must be unique within the container.
+func validateContainerPorts(container map[string]interface{}) error {
+	errs := make([]error, 0)
+	names := make(map[string]bool)
+
+	ports, _ := nestedSlice(container, "ports")
+	for i, raw := range ports {
+		port, _ := raw.(map[string]interface{})
+		path := fmt.Sprintf("ports[%d]", i)
+
+		if value, ok := nestedField(port, "containerPort"); !ok {
+			errs = append(errs, Required(NewPath(path, "containerPort"), "containerPort is required"))
+		} else if err := validatePortNumber(value, 1); err != nil {
+			errs = append(errs, WithFieldPath(path+".containerPort", err))
+		}
+		if value, ok := nestedField(port, "hostPort"); ok {
+			if err := validatePortNumber(value, 0); err != nil {
+				errs = append(errs, WithFieldPath(path+".hostPort", err))
+			}
+		}
+		if protocol, ok := nestedString(port, "protocol"); ok {
+			if err := validateEnumField(path+".protocol", protocol, containerProtocols); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if name, _ := nestedString(port, "name"); name != "" {
+			if err := ValidatePortName(name); err != nil {
+				errs = append(errs, WithFieldPath(path+".name", err))
+			}
+			if names[name] {
+				errs = append(errs, &ConstraintError{FieldPath: path + ".name", BadValue: name, Message: fmt.Sprintf("duplicate port name '%s'", name)})
+			}
+			names[name] = true
+		}
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// validatePortNumber checks that value is an integer between min and 65535.
+func validatePortNumber(value interface{}, min int64) error {
+	port, ok := toInt64(value)
+	if !ok {
+		return &ConstraintError{Rule: RulePortNumber, BadValue: value, Message: fmt.Sprintf("port must be an integer, got %v", value)}
+	}
+	if port < min || port > maxPortNumber {
+		return &ConstraintError{Rule: RulePortNumber, BadValue: port, Message: fmt.Sprintf("port %d must be between %d and %d", port, min, maxPortNumber)}
+	}
+	return nil
+}
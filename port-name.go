@@ -0,0 +1,45 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxPortNameLength is the maximum length of an IANA service name.
+const maxPortNameLength = 15
+
+var (
+	// IANA service names: lowercase alphanumerics and '-', starting and ending with an
+	// alphanumeric
+	portNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+	// At least one letter, so names cannot be confused with port numbers
+	portNameLetterPattern = regexp.MustCompile(`[a-zA-Z]`)
+)
+
+// ValidatePortName validates a port name against the IANA_SVC_NAME format (RFC 6335)
+// Kubernetes requires of Service and container port names: at most 15 lowercase
+// alphanumerics or '-', containing at least one letter, with no leading, trailing, or
+// adjacent hyphens.
+func ValidatePortName(name string) error {
+	errs := make([]error, 0)
+	if len(name) > maxPortNameLength {
+		errs = append(errs, fmt.Errorf("port name '%s' must be no more than %d characters", name, maxPortNameLength))
+	}
+	if !portNamePattern.MatchString(name) {
+		errs = append(errs, fmt.Errorf("port name '%s' must contain only lowercase alphanumeric characters or '-' and start and end with an alphanumeric character", name))
+	}
+	if strings.Contains(name, "--") {
+		errs = append(errs, fmt.Errorf("port name '%s' must not contain adjacent hyphens", name))
+	}
+	if !portNameLetterPattern.MatchString(name) {
+		errs = append(errs, fmt.Errorf("port name '%s' must contain at least one letter", name))
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withRule(RulePortName, name, JoinErrors(errs))
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NodePortRange is the inclusive range the API server allocates Service node ports from,
+// set by its --service-node-port-range flag.
+type NodePortRange struct {
+	Min int64
+	Max int64
+}
+
+// DefaultNodePortRange is the node port range of a default API server configuration.
+var DefaultNodePortRange = NodePortRange{Min: 30000, Max: 32767}
+
+// nodePortRange is the range ValidateServicePorts checks node ports against; see
+// UseNodePortRange.
+var nodePortRange = DefaultNodePortRange
+
+// UseNodePortRange makes ValidateServicePorts, and so ValidateObject, check Service node
+// ports against nodePorts, the --service-node-port-range of the API server the manifests
+// are meant for, instead of DefaultNodePortRange. Like UseConstraintProfile, it must not be
+// called while objects are being validated.
+func UseNodePortRange(nodePorts NodePortRange) {
+	nodePortRange = nodePorts
+}
+
+// String renders the range as "30000-32767", the form of --service-node-port-range.
+func (r NodePortRange) String() string {
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+// Contains reports whether port lies within the range.
+func (r NodePortRange) Contains(port int64) bool {
+	return port >= r.Min && port <= r.Max
+}
+
+// ParseNodePortRange parses a node port range in the "min-max" form of the API server's
+// --service-node-port-range flag, e.g. "30000-32767".
+func ParseNodePortRange(s string) (NodePortRange, error) {
+	low, high, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return NodePortRange{}, fmt.Errorf("node port range '%s' must be of the form min-max", s)
+	}
+	min, errMin := strconv.ParseInt(strings.TrimSpace(low), 10, 64)
+	max, errMax := strconv.ParseInt(strings.TrimSpace(high), 10, 64)
+	if errMin != nil || errMax != nil {
+		return NodePortRange{}, fmt.Errorf("node port range '%s' must be of the form min-max", s)
+	}
+	if min < 1 || max > maxPortNumber || min > max {
+		return NodePortRange{}, fmt.Errorf("node port range '%s' must lie between 1 and %d with min not greater than max", s, maxPortNumber)
+	}
+	return NodePortRange{Min: min, Max: max}, nil
+}
+
+// ValidatePortNumber checks that value is an integer port number between 1 and 65535, as
+// required of containerPort, a Service's port, and a numeric targetPort.
+func ValidatePortNumber(value interface{}) error {
+	return validatePortNumber(value, 1)
+}
+
+// ValidateNodePort checks that value is an integer within nodePorts. Zero, which asks the
+// API server to allocate a port, is accepted.
+func ValidateNodePort(value interface{}, nodePorts NodePortRange) error {
+	port, ok := toInt64(value)
+	if !ok {
+		return &ConstraintError{Rule: RulePortNumber, BadValue: value, Message: fmt.Sprintf("nodePort must be an integer, got %v", value)}
+	}
+	if port != 0 && !nodePorts.Contains(port) {
+		return &ConstraintError{Rule: RulePortNumber, BadValue: port, Message: fmt.Sprintf("nodePort %d is outside the node port range %s", port, nodePorts)}
+	}
+	return nil
+}
+
+// CheckServiceTargetPorts is the ID of the check built by ServiceTargetPortsCheck.
+const CheckServiceTargetPorts = "ServiceTargetPorts"
+
+// ServiceTargetPortsCheck returns a bundle check that verifies that every named targetPort
+// of a Service resolves to a container port of that name in the workloads among objects the
+// Service selects. Services without a selector, and Services selecting none of objects, are
+// skipped, since their endpoints are defined elsewhere.
+func ServiceTargetPortsCheck(objects []map[string]interface{}) Check {
+	type workload struct {
+		namespace string
+		labels    map[string]string
+		ports     map[string]bool
+	}
+	workloads := make([]workload, 0)
+	for _, obj := range objects {
+		kind, _ := nestedString(obj, "kind")
+		fields, ok := podTemplateSpecPaths[kind]
+		if !ok {
+			continue
+		}
+		spec, ok := nestedMap(obj, fields...)
+		if !ok {
+			continue
+		}
+		// The pod template's labels sit next to its spec; a Pod's are its own
+		labelPath := append(append([]string{}, fields[:len(fields)-1]...), "metadata", "labels")
+		labels, _ := nestedMap(obj, labelPath...)
+		namespace, _ := nestedString(obj, "metadata", "namespace")
+
+		ports := make(map[string]bool)
+		containers, _ := nestedSlice(spec, "containers")
+		for _, item := range containers {
+			container, _ := item.(map[string]interface{})
+			list, _ := nestedSlice(container, "ports")
+			for _, raw := range list {
+				port, _ := raw.(map[string]interface{})
+				if name, _ := nestedString(port, "name"); name != "" {
+					ports[name] = true
+				}
+			}
+		}
+		workloads = append(workloads, workload{namespace: namespace, labels: toStringMap(labels), ports: ports})
+	}
+
+	return Check{ID: CheckServiceTargetPorts, Kinds: []string{"Service"}, Validate: func(obj map[string]interface{}) error {
+		selector, _ := nestedMap(obj, "spec", "selector")
+		if len(selector) == 0 {
+			return nil
+		}
+		name, _ := nestedString(obj, "metadata", "name")
+		namespace, _ := nestedString(obj, "metadata", "namespace")
+
+		selected := make([]workload, 0)
+		for _, w := range workloads {
+			if w.namespace == namespace && matchesLabels(w.labels, toStringMap(selector)) {
+				selected = append(selected, w)
+			}
+		}
+		if len(selected) == 0 {
+			return nil
+		}
+
+		errs := make([]error, 0)
+		ports, _ := nestedSlice(obj, "spec", "ports")
+		for i, raw := range ports {
+			port, _ := raw.(map[string]interface{})
+			target, ok := port["targetPort"].(string)
+			if !ok {
+				continue
+			}
+			resolved := false
+			for _, w := range selected {
+				resolved = resolved || w.ports[target]
+			}
+			if !resolved {
+				path := NewPath("spec", "ports").Index(i).Child("targetPort")
+				message := fmt.Sprintf("Service '%s' targets port '%s', which is not a named container port of the workloads it selects", name, target)
+				errs = append(errs, &ConstraintError{FieldPath: path.String(), Rule: CheckServiceTargetPorts, BadValue: target, Message: message})
+			}
+		}
+
+		// If there are errors, join and return them
+		if len(errs) > 0 {
+			return JoinErrors(errs)
+		}
+
+		return nil
+	}}
+}
+
+// ValidateServiceTargetPorts runs ServiceTargetPortsCheck against every object of a bundle.
+func ValidateServiceTargetPorts(objects []map[string]interface{}) error {
+	return RunBundleCheck(objects, ServiceTargetPortsCheck)
+}
+
+// matchesLabels reports whether labels hold every key and value of selector.
+func matchesLabels(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
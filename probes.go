@@ -0,0 +1,191 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// containerProbes lists the probe fields of a container.
+var containerProbes = []string{"livenessProbe", "readinessProbe", "startupProbe"}
+
+// probeHandlers lists the handlers a probe sets exactly one of.
+var probeHandlers = []string{"exec", "httpGet", "tcpSocket", "grpc"}
+
+// httpGetSchemes lists the valid schemes of an httpGet probe.
+var httpGetSchemes = []string{"HTTP", "HTTPS"}
+
+var (
+	// httpHeaderNamePattern matches an HTTP header field name: an RFC 7230 token.
+	httpHeaderNamePattern = regexp.MustCompile("^[-!#$%&'*+.^_`|~0-9A-Za-z]+$")
+	// grpcServiceNamePattern matches a fully qualified gRPC service name such as
+	// grpc.health.v1.Health: dot-separated identifiers.
+	grpcServiceNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+)
+
+// ValidateContainerProbes validates the liveness, readiness, and startup probes of a
+// container with ValidateProbe. Named probe ports must be declared in the container's
+// ports.
+func ValidateContainerProbes(container map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	portNames := make(map[string]bool)
+	ports, _ := nestedSlice(container, "ports")
+	for _, raw := range ports {
+		port, _ := raw.(map[string]interface{})
+		if name, _ := nestedString(port, "name"); name != "" {
+			portNames[name] = true
+		}
+	}
+	for _, field := range containerProbes {
+		probe, ok := nestedMap(container, field)
+		if !ok {
+			continue
+		}
+		if err := ValidateProbe(probe, field != "readinessProbe"); err != nil {
+			errs = append(errs, WithFieldPath(field, err))
+		}
+		for _, handler := range []string{"httpGet", "tcpSocket"} {
+			port, _ := nestedField(probe, handler, "port")
+			if name, ok := port.(string); ok && ValidatePortName(name) == nil && !portNames[name] {
+				errs = append(errs, &ConstraintError{FieldPath: fmt.Sprintf("%s.%s.port", field, handler), BadValue: name, Message: fmt.Sprintf("port name '%s' is not declared in the container's ports", name)})
+			}
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleProbe, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// ValidateProbe validates a container probe: it sets exactly one of exec, httpGet,
+// tcpSocket, and grpc; exec has a command; httpGet and tcpSocket ports are port numbers or
+// names; httpGet has an HTTP or HTTPS scheme, valid header names, and an absolute path;
+// grpc has a port number and a fully qualified service name; and the timing fields are not
+// negative, with timeoutSeconds within periodSeconds. Liveness and startup probes, for
+// which singleSuccess is true, must have a successThreshold of 1.
+func ValidateProbe(probe map[string]interface{}, singleSuccess bool) error {
+	errs := make([]error, 0)
+
+	handlers := make([]string, 0)
+	for _, handler := range probeHandlers {
+		if _, ok := nestedMap(probe, handler); ok {
+			handlers = append(handlers, handler)
+		}
+	}
+	switch len(handlers) {
+	case 0:
+		errs = append(errs, &ConstraintError{Rule: RuleRequired, Message: fmt.Sprintf("probe must set one handler: %s", strings.Join(probeHandlers, ", "))})
+	case 1:
+	default:
+		errs = append(errs, &ConstraintError{FieldPath: handlers[1], Message: fmt.Sprintf("probe may set only one handler, but sets %s", strings.Join(handlers, " and "))})
+	}
+
+	if command, ok := nestedSlice(probe, "exec", "command"); !ok || len(command) == 0 {
+		if _, ok := nestedMap(probe, "exec"); ok {
+			errs = append(errs, &ConstraintError{FieldPath: "exec.command", Rule: RuleRequired, Message: "command is required"})
+		}
+	}
+	if httpGet, ok := nestedMap(probe, "httpGet"); ok {
+		if err := validateHTTPGetAction(httpGet); err != nil {
+			errs = append(errs, WithFieldPath("httpGet", err))
+		}
+	}
+	if tcpSocket, ok := nestedMap(probe, "tcpSocket"); ok {
+		if err := validateProbePort(tcpSocket); err != nil {
+			errs = append(errs, WithFieldPath("tcpSocket", err))
+		}
+	}
+	if grpc, ok := nestedMap(probe, "grpc"); ok {
+		if port, ok := nestedField(grpc, "port"); !ok {
+			errs = append(errs, &ConstraintError{FieldPath: "grpc.port", Rule: RuleRequired, Message: "port is required"})
+		} else if err := ValidatePortNumber(port); err != nil {
+			errs = append(errs, WithFieldPath("grpc.port", err))
+		}
+		if service, ok := nestedString(grpc, "service"); ok && service != "" && !grpcServiceNamePattern.MatchString(service) {
+			errs = append(errs, &ConstraintError{FieldPath: "grpc.service", BadValue: service, Message: fmt.Sprintf("service '%s' must be a fully qualified gRPC service name such as grpc.health.v1.Health", service)})
+		}
+	}
+
+	// Timing
+	timing := make(map[string]int64)
+	for _, field := range []string{"initialDelaySeconds", "timeoutSeconds", "periodSeconds", "successThreshold", "failureThreshold", "terminationGracePeriodSeconds"} {
+		value, ok := nestedField(probe, field)
+		if !ok || value == nil {
+			continue
+		}
+		n, ok := toInt64(value)
+		min := int64(0)
+		if field == "terminationGracePeriodSeconds" {
+			min = 1
+		}
+		if !ok || n < min {
+			errs = append(errs, &ConstraintError{FieldPath: field, BadValue: value, Message: fmt.Sprintf("%s must be an integer of at least %d", field, min)})
+			continue
+		}
+		timing[field] = n
+	}
+	if n, ok := timing["successThreshold"]; ok && singleSuccess && n != 1 {
+		errs = append(errs, &ConstraintError{FieldPath: "successThreshold", BadValue: n, Message: "successThreshold must be 1 for liveness and startup probes"})
+	}
+	if timeout, period := timing["timeoutSeconds"], timing["periodSeconds"]; period > 0 && timeout > period {
+		errs = append(errs, &ConstraintError{FieldPath: "timeoutSeconds", BadValue: timeout, Message: fmt.Sprintf("warning: timeoutSeconds %d exceeds periodSeconds %d, so probes overlap", timeout, period)})
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleProbe, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validateHTTPGetAction checks the httpGet handler of a probe or lifecycle hook.
+func validateHTTPGetAction(httpGet map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	if err := validateProbePort(httpGet); err != nil {
+		errs = append(errs, err)
+	}
+	if path, ok := nestedString(httpGet, "path"); ok && path != "" && !strings.HasPrefix(path, "/") {
+		errs = append(errs, &ConstraintError{FieldPath: "path", BadValue: path, Message: fmt.Sprintf("path '%s' must start with '/'", path), Fix: []FieldChange{
+			{Op: PatchTest, FieldPath: "path", Value: path},
+			{Op: PatchReplace, FieldPath: "path", Value: "/" + path},
+		}})
+	}
+	if scheme, ok := nestedString(httpGet, "scheme"); ok {
+		if err := validateEnumField("scheme", scheme, httpGetSchemes); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	headers, _ := nestedSlice(httpGet, "httpHeaders")
+	for i, raw := range headers {
+		header, _ := raw.(map[string]interface{})
+		if name, _ := nestedString(header, "name"); !httpHeaderNamePattern.MatchString(name) {
+			errs = append(errs, &ConstraintError{FieldPath: fmt.Sprintf("httpHeaders[%d].name", i), BadValue: name, Message: fmt.Sprintf("header name '%s' must be a valid HTTP header name: letters, digits, and !#$%%&'*+-.^_`|~", name)})
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// validateProbePort checks the port of an httpGet or tcpSocket handler, which is either a
+// port number or the name of a container port.
+func validateProbePort(handler map[string]interface{}) error {
+	port, ok := nestedField(handler, "port")
+	if !ok {
+		return &ConstraintError{FieldPath: "port", Rule: RuleRequired, Message: "port is required"}
+	}
+	if err := validateTargetPort(port); err != nil {
+		return WithFieldPath("port", err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is the release of k8sconstraints. Bump it when releasing rules that are new or
+// behave differently, and record the release where such a rule is registered or gated: in
+// builtinValidators, builtinCheckReleases, or a ruleEnabled call, so that constraint
+// profiles keep the behavior of earlier releases.
+const Version = "0.1.0"
+
+// constraintReleases lists the releases a constraint profile can pin behavior to, oldest
+// first. Every rule of the first release is part of every profile.
+var constraintReleases = []string{"0.1.0"}
+
+// The constraint profile in effect; see UseConstraintProfile.
+var (
+	profileRelease, _ = parseReleaseVersion(Version)
+	profileChecks     = checksOf(profileRelease)
+)
+
+// builtinValidator is a kind-specific validator of DefaultRegistry along with the release
+// that introduced it.
+type builtinValidator struct {
+	gvk      GroupVersionKind
+	validate func(obj map[string]interface{}) error
+	since    string
+}
+
+// builtinValidators lists the built-in kind-specific validators in registration order.
+var builtinValidators = append([]builtinValidator{
+	{GroupVersionKind{Group: "apps", Kind: "DaemonSet"}, ValidateDaemonSet, "0.1.0"},
+	{GroupVersionKind{Group: "apps", Kind: "Deployment"}, ValidateDeployment, "0.1.0"},
+	{GroupVersionKind{Group: "apps", Kind: "StatefulSet"}, ValidateStatefulSet, "0.1.0"},
+	{GroupVersionKind{Kind: "ResourceQuota"}, ValidateResourceQuota, "0.1.0"},
+	{GroupVersionKind{Kind: "LimitRange"}, ValidateLimitRange, "0.1.0"},
+	{GroupVersionKind{Group: "flowcontrol.apiserver.k8s.io", Kind: "FlowSchema"}, ValidateFlowSchema, "0.1.0"},
+	{GroupVersionKind{Group: "flowcontrol.apiserver.k8s.io", Kind: "PriorityLevelConfiguration"}, ValidatePriorityLevelConfiguration, "0.1.0"},
+	{GroupVersionKind{Group: "coordination.k8s.io", Version: "v1", Kind: "Lease"}, ValidateCoordinationObject, "0.1.0"},
+	{GroupVersionKind{Group: "events.k8s.io", Version: "v1", Kind: "Event"}, ValidateCoordinationObject, "0.1.0"},
+	{GroupVersionKind{Version: "v1", Kind: "Event"}, ValidateCoordinationObject, "0.1.0"},
+	{GroupVersionKind{Version: "v1", Kind: "Service"}, ValidateService, "0.1.0"},
+	{GroupVersionKind{Group: "batch", Kind: "Job"}, ValidateJob, "0.1.0"},
+	{GroupVersionKind{Group: "batch", Kind: "CronJob"}, ValidateCronJob, "0.1.0"},
+	{GroupVersionKind{Version: "v1", Kind: "Namespace"}, ValidateNamespace, "0.1.0"},
+	{GroupVersionKind{Version: "v1", Kind: "Secret"}, ValidateSecret, "0.1.0"},
+	{GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}, ValidateIngress, "0.1.0"},
+	{GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"}, ValidateNetworkPolicy, "0.1.0"},
+	{GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"}, ValidateRole, "0.1.0"},
+	{GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}, ValidateClusterRole, "0.1.0"},
+	{GroupVersionKind{Group: "autoscaling", Version: "v2", Kind: "HorizontalPodAutoscaler"}, ValidateHorizontalPodAutoscaler, "0.1.0"},
+}, podValidators("0.1.0")...)
+
+// podValidators returns ValidatePod for every workload kind with a pod template.
+func podValidators(since string) []builtinValidator {
+	validators := make([]builtinValidator, 0, len(podWorkloadKinds))
+	for _, gvk := range podWorkloadKinds {
+		validators = append(validators, builtinValidator{gvk, ValidatePod, since})
+	}
+	return validators
+}
+
+// ConstraintProfiles returns the releases UseConstraintProfile can pin behavior to, oldest
+// first.
+func ConstraintProfiles() []string {
+	return append([]string(nil), constraintReleases...)
+}
+
+// UseConstraintProfile pins the built-in rules to those of an earlier release, so upgrading
+// k8sconstraints does not fail pipelines on rules added or tightened since. version is one
+// of ConstraintProfiles, such as "0.1" or "0.1.0". The built-in checks and the built-in
+// validators of DefaultRegistry introduced after it are skipped, and rules added to
+// existing validators since behave as they did in it. Validators registered by callers
+// always run. An empty version restores the current release. Like replacing
+// DefaultRegistry, it must not be called while objects are being validated.
+func UseConstraintProfile(version string) error {
+	if version == "" {
+		version = Version
+	}
+	pinned, err := parseConstraintProfile(version)
+	if err != nil {
+		return err
+	}
+
+	profileRelease = pinned
+	profileChecks = checksOf(pinned)
+	return nil
+}
+
+// ruleEnabled reports whether a rule introduced in release, the release a rule added to an
+// existing validator first shipped in, applies under the constraint profile in effect.
+func ruleEnabled(release string) bool {
+	return releaseIncludes(profileRelease, release)
+}
+
+// releaseIncludes reports whether pinned includes what was introduced in release.
+func releaseIncludes(pinned [3]int, release string) bool {
+	since, _ := parseReleaseVersion(release)
+	return compareReleaseVersions(since, pinned) <= 0
+}
+
+// checksOf returns the built-in checks of the release pinned.
+func checksOf(pinned [3]int) []Check {
+	checks := make([]Check, 0, len(builtinChecks))
+	for _, check := range builtinChecks {
+		if releaseIncludes(pinned, builtinCheckReleases[check.ID]) {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// parseConstraintProfile parses version and checks that it names a release between the
+// oldest constraint profile and the current release.
+func parseConstraintProfile(version string) ([3]int, error) {
+	pinned, err := parseReleaseVersion(version)
+	if err != nil {
+		return pinned, err
+	}
+	oldest, _ := parseReleaseVersion(constraintReleases[0])
+	current, _ := parseReleaseVersion(Version)
+	if compareReleaseVersions(pinned, oldest) < 0 || compareReleaseVersions(pinned, current) > 0 {
+		return pinned, fmt.Errorf("unknown constraint profile '%s'; must be one of: %s", version, strings.Join(constraintReleases, ", "))
+	}
+	return pinned, nil
+}
+
+// parseReleaseVersion parses a "major.minor" or "major.minor.patch" release version, with
+// an optional leading 'v'.
+func parseReleaseVersion(version string) ([3]int, error) {
+	var parsed [3]int
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return parsed, fmt.Errorf("invalid release version '%s'; must be of the form major.minor[.patch]", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return parsed, fmt.Errorf("invalid release version '%s'; must be of the form major.minor[.patch]", version)
+		}
+		parsed[i] = n
+	}
+	return parsed, nil
+}
+
+// compareReleaseVersions returns -1, 0, or 1 as a is older than, equal to, or newer than b.
+func compareReleaseVersions(a, b [3]int) int {
+	for i := range a {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,83 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// useTestProfile pins the constraint profile to version for the duration of the test.
+func useTestProfile(t *testing.T, version string) {
+	t.Helper()
+	if err := UseConstraintProfile(version); err != nil {
+		t.Fatalf("UseConstraintProfile(%q): %v", version, err)
+	}
+	t.Cleanup(func() {
+		if err := UseConstraintProfile(""); err != nil {
+			t.Fatalf("restoring the current profile: %v", err)
+		}
+	})
+}
+
+func TestConstraintProfiles(t *testing.T) {
+	profiles := ConstraintProfiles()
+	if latest := profiles[len(profiles)-1]; latest != Version {
+		t.Errorf("newest constraint profile is %s, want the current release %s", latest, Version)
+	}
+	for _, builtin := range builtinValidators {
+		if !containsString(profiles, builtin.since) {
+			t.Errorf("validator for %s was introduced in unknown release %s", builtin.gvk, builtin.since)
+		}
+	}
+	for _, check := range builtinChecks {
+		if !containsString(profiles, builtinCheckReleases[check.ID]) {
+			t.Errorf("built-in check %s has no known release", check.ID)
+		}
+	}
+}
+
+func TestRegistrySkipsValidatorsOfLaterReleases(t *testing.T) {
+	widget := GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+	registry := NewRegistry()
+	registry.register(widget, func(map[string]interface{}) error { return errors.New("built-in of this release") }, Version)
+	registry.register(widget, func(map[string]interface{}) error { return errors.New("built-in of a later release") }, "99.0.0")
+	registry.Register(widget, func(map[string]interface{}) error { return errors.New("registered by a caller") })
+
+	err := registry.Validate(map[string]interface{}{"apiVersion": "example.com/v1", "kind": "Widget"})
+	for _, want := range []string{"built-in of this release", "registered by a caller"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected an error containing %q, got %v", want, err)
+		}
+	}
+	if err != nil && strings.Contains(err.Error(), "later release") {
+		t.Errorf("unexpected error from a validator of a later release: %v", err)
+	}
+}
+
+func TestUseConstraintProfileKeepsRegisteredValidators(t *testing.T) {
+	registry := DefaultRegistry
+	DefaultRegistry = newDefaultRegistry()
+	t.Cleanup(func() { DefaultRegistry = registry })
+
+	DefaultRegistry.Register(GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, func(obj map[string]interface{}) error {
+		return Required(NewPath("data"), "data is required")
+	})
+	useTestProfile(t, ConstraintProfiles()[0])
+
+	obj := decodeTestObject(t, `
+apiVersion: v1
+kind: ConfigMap
+metadata: {name: web}`)
+	if err := ValidateObject(obj); err == nil || !strings.Contains(err.Error(), "data is required") {
+		t.Errorf("expected a finding containing %q, got %v", "data is required", err)
+	}
+}
+
+func TestUseConstraintProfileRejectsUnknownReleases(t *testing.T) {
+	for _, version := range []string{"0.0.9", "9.0", "latest"} {
+		if err := UseConstraintProfile(version); err == nil {
+			t.Errorf("UseConstraintProfile(%q): expected an error", version)
+			_ = UseConstraintProfile("")
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ValidateQualifiedName validates a Kubernetes qualified name: an optional DNS subdomain
+// prefix and a slash, followed by a name part (e.g. "example.com/my-name" or "MyName").
+//
+// This is the rule Kubernetes applies to label and annotation keys, taint keys, extended
+// resource names, finalizers, and device class names:
+//   - the prefix, if present, must be a non-empty DNS subdomain of at most 253 characters;
+//   - the name part must be at most 63 characters, consist of alphanumeric characters,
+//     '-', '_' or '.', and start and end with an alphanumeric character;
+//   - at most one '/' is allowed.
+func ValidateQualifiedName(s string) error {
+	// Violations of the prefix or name part are reported against the whole qualified name
+	return mapConstraintErrors(validateQualifiedName(s), func(e *ConstraintError) {
+		e.Rule = RuleQualifiedName
+		e.BadValue = s
+	})
+}
+
+// validateQualifiedName implements ValidateQualifiedName without setting rule codes.
+func validateQualifiedName(s string) error {
+	parts := strings.Split(s, "/")
+
+	var name string
+	switch len(parts) {
+	case 1:
+		name = parts[0]
+	case 2:
+		prefix := parts[0]
+		name = parts[1]
+		if prefix == "" {
+			return errors.New("prefix part must be non-empty")
+		}
+		if err := ValidateDNSSubdomain(prefix); err != nil {
+			return withMessagePrefix("invalid prefix: ", err)
+		}
+	default:
+		return errors.New("a qualified name must consist of an optional DNS subdomain prefix and '/', followed by a name part (e.g. 'MyName' or 'example.com/MyName')")
+	}
+
+	if name == "" {
+		return errors.New("name part must be non-empty")
+	}
+	if err := ValidateLabelOrAnnotationNamePart(name); err != nil {
+		return withMessagePrefix("invalid name part: ", err)
+	}
+
+	return nil
+}
+
+// ValidateLabelOrAnnotationNamePart validates the name part of a qualified name such as a
+// label or annotation key. It must match the regex: ([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]
+func ValidateLabelOrAnnotationNamePart(name string) error {
+	if len(name) > 63 {
+		return fmt.Errorf("name part exceeds maximum length of 63 characters")
+	}
+	if !isQualifiedNamePart(name) {
+		return errors.New("name part must consist of alphanumeric characters, '-', '_', or '.', and must start and end with an alphanumeric character")
+	}
+	return nil
+}
+
+// isQualifiedNamePart reports whether s matches ([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9],
+// ignoring length. This is also the syntax of a non-empty label value. Every label key and
+// value goes through it, so it is a byte loop rather than a regexp.
+func isQualifiedNamePart(s string) bool {
+	if s == "" || !isAlphanumeric(s[0]) || !isAlphanumeric(s[len(s)-1]) {
+		return false
+	}
+	for i := 1; i < len(s)-1; i++ {
+		if c := s[i]; !isAlphanumeric(c) && c != '-' && c != '_' && c != '.' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// quantityPattern matches the Kubernetes resource.Quantity grammar:
+// an optionally signed decimal number followed by a binary SI suffix (Ki, Mi, ...),
+// a decimal SI suffix (n, u, m, k, M, ...), or a decimal exponent (e3, E-2).
+var quantityPattern = regexp.MustCompile(`^([+-]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+))(Ki|Mi|Gi|Ti|Pi|Ei|n|u|m|k|M|G|T|P|E|[eE][+-]?[0-9]+)?$`)
+
+// quantitySuffixes maps each SI suffix to its multiplier.
+var quantitySuffixes = map[string]*big.Rat{
+	"Ki": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 10)),
+	"Mi": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 20)),
+	"Gi": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 30)),
+	"Ti": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 40)),
+	"Pi": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 50)),
+	"Ei": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 60)),
+	"n":  big.NewRat(1, 1000000000),
+	"u":  big.NewRat(1, 1000000),
+	"m":  big.NewRat(1, 1000),
+	"":   big.NewRat(1, 1),
+	"k":  big.NewRat(1000, 1),
+	"M":  big.NewRat(1000000, 1),
+	"G":  big.NewRat(1000000000, 1),
+	"T":  big.NewRat(1000000000000, 1),
+	"P":  big.NewRat(1000000000000000, 1),
+	"E":  big.NewRat(1000000000000000000, 1),
+}
+
+// ValidateQuantity validates a Kubernetes resource quantity such as "500m", "1.5Gi", or
+// "2e3": an optionally signed decimal number with an optional binary SI suffix (Ki, Mi,
+// ..., Ei), decimal SI suffix (n, u, m, k, M, ..., E), or decimal exponent.
+func ValidateQuantity(s string) error {
+	if _, err := parseQuantity(s); err != nil {
+		return withRule(RuleQuantity, s, err)
+	}
+	return nil
+}
+
+// parseQuantity parses a resource quantity string into an exact rational value.
+func parseQuantity(s string) (*big.Rat, error) {
+	match := quantityPattern.FindStringSubmatch(s)
+	if match == nil {
+		return nil, fmt.Errorf("quantity '%s' must match the regular expression '%s'", s, `^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$`)
+	}
+
+	number, suffix := match[1], match[2]
+	value, ok := new(big.Rat).SetString(strings.TrimPrefix(number, "+"))
+	if !ok {
+		return nil, fmt.Errorf("quantity '%s' has an invalid number", s)
+	}
+
+	if multiplier, ok := quantitySuffixes[suffix]; ok {
+		return value.Mul(value, multiplier), nil
+	}
+
+	// Decimal exponent, e.g. 2e3
+	exponent, ok := new(big.Int).SetString(strings.TrimPrefix(suffix[1:], "+"), 10)
+	if !ok || !exponent.IsInt64() || exponent.Int64() > 18 || exponent.Int64() < -18 {
+		return nil, fmt.Errorf("quantity '%s' has an out of range exponent", s)
+	}
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(abs64(exponent.Int64())), nil))
+	if exponent.Sign() < 0 {
+		return value.Quo(value, scale), nil
+	}
+	return value.Mul(value, scale), nil
+}
+
+// quantityValue parses a decoded quantity field, which may be a string or a plain number.
+func quantityValue(value interface{}) (*big.Rat, error) {
+	switch v := value.(type) {
+	case string:
+		return parseQuantity(v)
+	case int, int64, float64:
+		return parseQuantity(fmt.Sprint(v))
+	}
+	return nil, fmt.Errorf("quantity must be a string or a number, got %v", value)
+}
+
+// abs64 returns the absolute value of n.
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
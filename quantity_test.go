@@ -0,0 +1,119 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestValidateQuantity(t *testing.T) {
+	tests := []struct {
+		quantity string
+		valid    bool
+	}{
+		{"1", true},
+		{"500m", true},
+		{"1.5Gi", true},
+		{"+2", true},
+		{"-1", true},
+		{".5", true},
+		{"1.", true},
+		{"2e3", true},
+		{"1E-2", true},
+		{"100Ki", true},
+		{"1e18", true},
+		{"", false},
+		{"1.5.5", false},
+		{"1GB", false},
+		{"1gi", false},
+		{"1 Gi", false},
+		{"Mi", false},
+		{"1e19", false},
+		{"0x10", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.quantity, func(t *testing.T) {
+			err := ValidateQuantity(tt.quantity)
+			if tt.valid {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, &ConstraintError{Rule: RuleQuantity}) {
+				t.Errorf("expected a %s error, got %v", RuleQuantity, err)
+			}
+		})
+	}
+}
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		quantity string
+		want     *big.Rat
+	}{
+		{"500m", big.NewRat(1, 2)},
+		{"1Ki", big.NewRat(1024, 1)},
+		{"1.5k", big.NewRat(1500, 1)},
+		{"2e3", big.NewRat(2000, 1)},
+		{"5e-1", big.NewRat(1, 2)},
+		{"250u", big.NewRat(1, 4000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.quantity, func(t *testing.T) {
+			got, err := parseQuantity(tt.quantity)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if got.Cmp(tt.want) != 0 {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateResourceRequirements(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources map[string]interface{}
+		// finding is part of the expected message; "" expects no error
+		finding string
+	}{
+		{"requests below limits", map[string]interface{}{
+			"requests": map[string]interface{}{"cpu": "250m", "memory": "64Mi"},
+			"limits":   map[string]interface{}{"cpu": 1, "memory": "128Mi"},
+		}, ""},
+		{"request equal to limit in other units", map[string]interface{}{
+			"requests": map[string]interface{}{"cpu": "1000m"},
+			"limits":   map[string]interface{}{"cpu": "1"},
+		}, ""},
+		{"invalid quantity", map[string]interface{}{
+			"limits": map[string]interface{}{"memory": "1GB"},
+		}, `limits["memory"]: quantity '1GB' must match the regular expression`},
+		{"negative quantity", map[string]interface{}{
+			"requests": map[string]interface{}{"cpu": "-1"},
+		}, `requests["cpu"]: quantity must be greater than or equal to 0`},
+		{"request above limit", map[string]interface{}{
+			"requests": map[string]interface{}{"memory": "1Gi"},
+			"limits":   map[string]interface{}{"memory": "512Mi"},
+		}, `requests["memory"]: request 1Gi must be less than or equal to the memory limit of 512Mi`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateResourceRequirements(tt.resources)
+			if tt.finding == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.finding) {
+				t.Errorf("expected an error containing %q, got %v", tt.finding, err)
+			}
+		})
+	}
+}
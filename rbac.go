@@ -0,0 +1,198 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// rbacVerbs lists the verbs the API server and built-in authorizers check: the verbs of
+// resource requests, the HTTP methods of non-resource requests, and the special verbs of
+// RBAC, PodSecurityPolicy, impersonation, and certificate signing.
+var rbacVerbs = []string{
+	"get", "list", "watch", "create", "update", "patch", "delete", "deletecollection",
+	"post", "put", "head", "options",
+	"bind", "escalate", "use", "impersonate", "approve", "sign", "attest",
+}
+
+// rbacResourcePart matches a resource or subresource name in a policy rule, such as pods
+// or status: a lowercase plural name, possibly dotted.
+var rbacResourcePart = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
+
+// ValidateRole validates the rules of an RBAC Role with ValidatePolicyRule. Roles are
+// namespaced, so their rules may not grant nonResourceURLs.
+func ValidateRole(obj map[string]interface{}) error {
+	return validatePolicyRules(obj, false)
+}
+
+// ValidateClusterRole validates the rules of an RBAC ClusterRole with ValidatePolicyRule.
+func ValidateClusterRole(obj map[string]interface{}) error {
+	return validatePolicyRules(obj, true)
+}
+
+// validatePolicyRules validates every entry of the rules of a Role or ClusterRole.
+func validatePolicyRules(obj map[string]interface{}, clusterScoped bool) error {
+	errs := make([]error, 0)
+
+	rules, _ := nestedSlice(obj, "rules")
+	for i, raw := range rules {
+		rule, _ := raw.(map[string]interface{})
+		if err := ValidatePolicyRule(rule, clusterScoped); err != nil {
+			errs = append(errs, WithFieldPath(fmt.Sprintf("rules[%d]", i), err))
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// ValidatePolicyRule validates a single RBAC policy rule. verbs are required and expected
+// to be standard verbs; a rule grants either resources, which need apiGroups that are ""
+// for the core group or DNS subdomains, or nonResourceURLs, which only ClusterRoles may
+// grant. resources are lowercase plural names, optionally followed by '/' and a
+// subresource, and resourceNames are object names. Wildcards are reported as warnings, as
+// they also grant access to resources added later.
+func ValidatePolicyRule(rule map[string]interface{}, allowNonResourceURLs bool) error {
+	errs := make([]error, 0)
+
+	verbs := policyRuleStrings(rule, "verbs")
+	if len(verbs) == 0 {
+		errs = append(errs, &ConstraintError{FieldPath: "verbs", Rule: RuleRequired, Message: "at least one verb is required"})
+	}
+	for i, verb := range verbs {
+		path := fmt.Sprintf("verbs[%d]", i)
+		switch {
+		case verb == "*":
+			errs = append(errs, &ConstraintError{FieldPath: path, BadValue: verb, Message: "warning: verb '*' grants every verb, including escalate, bind, and impersonate; list the verbs needed"})
+		case !containsString(rbacVerbs, verb):
+			errs = append(errs, &ConstraintError{FieldPath: path, BadValue: verb, Message: fmt.Sprintf("warning: verb '%s' is not a standard verb, so it only matters to an authorizer that checks it; standard verbs are: %s", verb, strings.Join(rbacVerbs, ", ")), Fix: enumCaseFix(path, rbacVerbs, verb)})
+		}
+	}
+
+	apiGroups := policyRuleStrings(rule, "apiGroups")
+	resources := policyRuleStrings(rule, "resources")
+	resourceNames := policyRuleStrings(rule, "resourceNames")
+	nonResourceURLs := policyRuleStrings(rule, "nonResourceURLs")
+	switch {
+	case len(nonResourceURLs) > 0 && !allowNonResourceURLs:
+		errs = append(errs, &ConstraintError{FieldPath: "nonResourceURLs", Message: "nonResourceURLs may only be granted by ClusterRoles"})
+	case len(nonResourceURLs) > 0 && (len(resources) > 0 || len(apiGroups) > 0 || len(resourceNames) > 0):
+		errs = append(errs, &ConstraintError{FieldPath: "nonResourceURLs", Message: "a rule cannot grant both nonResourceURLs and resources; split it into two rules"})
+	case len(nonResourceURLs) > 0:
+		for i, url := range nonResourceURLs {
+			if err := validateNonResourceURL(url); err != nil {
+				errs = append(errs, WithFieldPath(fmt.Sprintf("nonResourceURLs[%d]", i), err))
+			}
+		}
+	case len(resources) == 0 && allowNonResourceURLs:
+		errs = append(errs, &ConstraintError{FieldPath: "resources", Rule: RuleRequired, Message: "at least one resource or non-resource URL is required"})
+	case len(resources) == 0:
+		errs = append(errs, &ConstraintError{FieldPath: "resources", Rule: RuleRequired, Message: "at least one resource is required"})
+	case len(apiGroups) == 0:
+		errs = append(errs, &ConstraintError{FieldPath: "apiGroups", Rule: RuleRequired, Message: "at least one API group is required; use \"\" for the core group"})
+	}
+
+	for i, group := range apiGroups {
+		path := fmt.Sprintf("apiGroups[%d]", i)
+		switch {
+		case group == "":
+		case group == "*":
+			errs = append(errs, &ConstraintError{FieldPath: path, BadValue: group, Message: "warning: API group '*' grants the resources of every API group, including those of CustomResourceDefinitions"})
+		case strings.Contains(group, "/"):
+			name, _, _ := strings.Cut(group, "/")
+			errs = append(errs, &ConstraintError{FieldPath: path, BadValue: group, Message: fmt.Sprintf("API group '%s' must not include a version; use '%s'", group, name), Fix: []FieldChange{
+				{Op: PatchTest, FieldPath: path, Value: group},
+				{Op: PatchReplace, FieldPath: path, Value: name},
+			}})
+		default:
+			if err := ValidateDNSSubdomain(group); err != nil {
+				errs = append(errs, WithFieldPath(path, withMessagePrefix("invalid API group: ", err)))
+			}
+		}
+	}
+
+	for i, resource := range resources {
+		path := fmt.Sprintf("resources[%d]", i)
+		if resource == "*" {
+			errs = append(errs, &ConstraintError{FieldPath: path, BadValue: resource, Message: "warning: resource '*' grants every resource and subresource, including secrets; list the resources needed"})
+			continue
+		}
+		if err := validatePolicyRuleResource(resource); err != nil {
+			errs = append(errs, WithFieldPath(path, err))
+		}
+	}
+
+	for i, name := range resourceNames {
+		path := fmt.Sprintf("resourceNames[%d]", i)
+		switch {
+		case name == "":
+			errs = append(errs, &ConstraintError{FieldPath: path, Rule: RuleRequired, Message: "resource name cannot be empty"})
+		case name == "." || name == ".." || strings.ContainsAny(name, "/%"):
+			errs = append(errs, &ConstraintError{FieldPath: path, BadValue: name, Message: fmt.Sprintf("resource name '%s' is not a valid object name; it may not be '.' or '..' or contain '/' or '%%'", name)})
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleRBAC, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validatePolicyRuleResource checks a resource of a policy rule: a resource name, or a
+// resource name or '*' followed by '/' and a subresource name or '*'.
+func validatePolicyRuleResource(resource string) error {
+	name, subresource, hasSubresource := strings.Cut(resource, "/")
+	if strings.Contains(subresource, "/") {
+		return &ConstraintError{BadValue: resource, Message: fmt.Sprintf("resource '%s' may contain at most one '/', between the resource and its subresource", resource)}
+	}
+
+	parts := []string{name}
+	if hasSubresource {
+		parts = append(parts, subresource)
+	}
+	for _, part := range parts {
+		if part == "*" && hasSubresource {
+			continue
+		}
+		if !rbacResourcePart.MatchString(part) {
+			var fix []FieldChange
+			if lower := strings.ToLower(resource); lower != resource && validatePolicyRuleResource(lower) == nil {
+				fix = []FieldChange{{Op: PatchTest, Value: resource}, {Op: PatchReplace, Value: lower}}
+			}
+			return &ConstraintError{BadValue: resource, Message: fmt.Sprintf("resource '%s' must be a lowercase plural resource name such as 'deployments', optionally followed by '/' and a subresource such as 'deployments/scale'", resource), Fix: fix}
+		}
+	}
+	return nil
+}
+
+// validateNonResourceURL checks a non-resource URL of a policy rule: a path starting with
+// '/' that may only use '*' as its final character, to match every path with its prefix.
+func validateNonResourceURL(url string) error {
+	if !strings.HasPrefix(url, "/") && url != "*" {
+		return &ConstraintError{BadValue: url, Message: fmt.Sprintf("non-resource URL '%s' must be a path starting with '/'", url)}
+	}
+	if i := strings.Index(url, "*"); i >= 0 && i != len(url)-1 {
+		return &ConstraintError{BadValue: url, Message: fmt.Sprintf("non-resource URL '%s' may only use '*' as its final character", url)}
+	}
+	if url == "*" || url == "/*" {
+		return &ConstraintError{BadValue: url, Message: fmt.Sprintf("warning: non-resource URL '%s' grants every non-resource path, including /metrics and /debug", url)}
+	}
+	return nil
+}
+
+// policyRuleStrings returns the string entries of the list field of a policy rule.
+func policyRuleStrings(rule map[string]interface{}, field string) []string {
+	list, _ := nestedSlice(rule, field)
+	values := make([]string, 0, len(list))
+	for _, raw := range list {
+		value, _ := raw.(string)
+		values = append(values, value)
+	}
+	return values
+}
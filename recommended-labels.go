@@ -0,0 +1,85 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckRecommendedLabels is the ID of the check built by RecommendedLabelsCheck.
+const CheckRecommendedLabels = "RecommendedLabels"
+
+// RecommendedLabels are the labels Kubernetes recommends for describing applications, so
+// tools such as dashboards and kubectl plugins can query objects the same way.
+var RecommendedLabels = []string{
+	"app.kubernetes.io/name",
+	"app.kubernetes.io/instance",
+	"app.kubernetes.io/version",
+	"app.kubernetes.io/component",
+	"app.kubernetes.io/part-of",
+	"app.kubernetes.io/managed-by",
+}
+
+// RecommendedLabelsConfig selects the labels the recommended labels check expects and the
+// kinds it applies to.
+type RecommendedLabelsConfig struct {
+	// Labels lists the expected label keys. When empty, RecommendedLabels are expected.
+	Labels []string `json:"labels,omitempty"`
+	// Kinds restricts the check to the given kinds. When empty it applies to workloads.
+	Kinds []string `json:"kinds,omitempty"`
+}
+
+// Validate checks that every configured label is a valid label key.
+func (c RecommendedLabelsConfig) Validate() error {
+	errs := make([]error, 0)
+	for i, key := range c.Labels {
+		if err := ValidateLabelKey(key); err != nil {
+			errs = append(errs, fmt.Errorf("labels[%d]: invalid key '%s': %v", i, key, err))
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// RecommendedLabelsCheck returns an opt-in check, not run by ValidateObject, that warns
+// about objects missing the labels of config. Run it with RunChecks or pass it to the
+// linter's Checks option.
+func RecommendedLabelsCheck(config RecommendedLabelsConfig) Check {
+	return Check{ID: CheckRecommendedLabels, Validate: func(obj map[string]interface{}) error {
+		return ValidateRecommendedLabels(obj, config)
+	}}
+}
+
+// ValidateRecommendedLabels warns about each label of config missing from the
+// metadata.labels of obj. Objects of other kinds than config selects pass.
+func ValidateRecommendedLabels(obj map[string]interface{}, config RecommendedLabelsConfig) error {
+	kinds := config.Kinds
+	if len(kinds) == 0 {
+		kinds = workloadKinds
+	}
+	if kind, _ := nestedString(obj, "kind"); !containsString(kinds, kind) {
+		return nil
+	}
+	keys := config.Labels
+	if len(keys) == 0 {
+		keys = RecommendedLabels
+	}
+
+	labels, _ := nestedStringMap(obj, "metadata", "labels")
+	missing := make([]string, 0)
+	for _, key := range keys {
+		if _, ok := labels[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	message := fmt.Sprintf("warning: missing recommended label(s): %s", strings.Join(missing, ", "))
+	return &ConstraintError{FieldPath: "metadata.labels", Message: message}
+}
@@ -0,0 +1,89 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Field paths of Secret values, e.g. data["password"]
+	secretValuePathPattern = regexp.MustCompile(`^(data|stringData)\[`)
+	// Field paths of container environment variable values, e.g.
+	// spec.template.spec.containers[0].env[2].value
+	envValuePathPattern = regexp.MustCompile(`(^|\.)env\[\d+\]\.value$`)
+	// Field paths of annotations, capturing the quoted key, e.g. metadata.annotations["a"]
+	annotationPathPattern = regexp.MustCompile(`(^|\.)annotations\[("(?:[^"\\]|\\.)*")\]$`)
+)
+
+// IsSensitiveFinding reports whether a finding's bad value was taken from a field that may
+// hold credentials: the data or stringData of a Secret, the value of a container
+// environment variable, or the value (not the key) of an annotation.
+func IsSensitiveFinding(f Finding) bool {
+	switch {
+	case f.Resource.Kind == "Secret" && f.Resource.Group() == "" && secretValuePathPattern.MatchString(f.FieldPath):
+		return true
+	case envValuePathPattern.MatchString(f.FieldPath):
+		return true
+	}
+	if match := annotationPathPattern.FindStringSubmatch(f.FieldPath); match != nil {
+		// Findings about the annotation key report the key, which is not sensitive
+		key, err := strconv.Unquote(match[2])
+		return err != nil || f.BadValue != key
+	}
+	return false
+}
+
+// RedactedValue is the placeholder that replaces a sensitive value: it keeps the value's
+// length in bytes so findings such as length violations stay actionable.
+func RedactedValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("<redacted: %d bytes>", len(s))
+	}
+	return "<redacted>"
+}
+
+// RedactFinding replaces the bad value of a sensitive finding, and every occurrence of it
+// in the message, with RedactedValue, so reports, CI logs, and audit logs do not leak
+// credentials. The field path, which locates the value, and the fingerprint, computed from
+// the original value, are kept. Findings that are not sensitive are returned unchanged;
+// related findings are redacted alike.
+func RedactFinding(f Finding) Finding {
+	if len(f.Related) > 0 {
+		related := make([]Finding, len(f.Related))
+		for i, r := range f.Related {
+			related[i] = RedactFinding(r)
+		}
+		f.Related = related
+	}
+	if f.BadValue == nil || !IsSensitiveFinding(f) {
+		return f
+	}
+
+	placeholder := RedactedValue(f.BadValue)
+	if s, ok := f.BadValue.(string); ok && s != "" {
+		f.Message = strings.ReplaceAll(f.Message, s, placeholder)
+	}
+	f.BadValue = placeholder
+	// Fixes carry the value too
+	f.Fix = nil
+	return f
+}
+
+// RedactFindings applies RedactFinding to each finding.
+func RedactFindings(findings []Finding) []Finding {
+	redacted := make([]Finding, len(findings))
+	for i, finding := range findings {
+		redacted[i] = RedactFinding(finding)
+	}
+	return redacted
+}
+
+// RedactReport applies RedactFinding to every finding in a report.
+func RedactReport(report Report) Report {
+	if report.Findings != nil {
+		report.Findings = RedactFindings(report.Findings)
+	}
+	return report
+}
@@ -0,0 +1,155 @@
+package k8sconstraints
+
+import (
+	"strings"
+	"sync"
+)
+
+// GroupVersionKind identifies a Kubernetes resource type. Group is empty for the core group.
+type GroupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// GroupVersionKindOf returns the GroupVersionKind of obj, taken from its apiVersion and kind.
+func GroupVersionKindOf(obj map[string]interface{}) GroupVersionKind {
+	apiVersion, _ := nestedString(obj, "apiVersion")
+	kind, _ := nestedString(obj, "kind")
+
+	gvk := GroupVersionKind{Version: apiVersion, Kind: kind}
+	if i := strings.LastIndex(apiVersion, "/"); i >= 0 {
+		gvk.Group, gvk.Version = apiVersion[:i], apiVersion[i+1:]
+	}
+	return gvk
+}
+
+// APIVersion returns the apiVersion of the type, "group/version" or just "version" for the
+// core group.
+func (g GroupVersionKind) APIVersion() string {
+	if g.Group == "" {
+		return g.Version
+	}
+	return g.Group + "/" + g.Version
+}
+
+// String renders the type as "apps/v1 Deployment". A type registered for every version
+// renders as "apps/* Deployment".
+func (g GroupVersionKind) String() string {
+	if g.Version == "" {
+		return strings.TrimPrefix(g.Group+"/* "+g.Kind, "/")
+	}
+	return g.APIVersion() + " " + g.Kind
+}
+
+// Registry holds kind-specific validators keyed by GroupVersionKind. It is safe for
+// concurrent use.
+type Registry struct {
+	mu         sync.RWMutex
+	validators map[GroupVersionKind][]registeredValidator
+}
+
+// registeredValidator is a validator of a Registry along with the release that introduced
+// it, for built-in validators, or "" for those registered by callers.
+type registeredValidator struct {
+	validate func(obj map[string]interface{}) error
+	since    string
+}
+
+// enabled reports whether the validator runs under the constraint profile in effect.
+func (v registeredValidator) enabled() bool {
+	return v.since == "" || ruleEnabled(v.since)
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{validators: make(map[GroupVersionKind][]registeredValidator)}
+}
+
+// DefaultRegistry holds the built-in kind-specific validators. ValidateManifest and
+// ValidateObject dispatch to it; register validators here to extend them.
+var DefaultRegistry = newDefaultRegistry()
+
+// newDefaultRegistry returns a registry holding the built-in kind-specific validators.
+func newDefaultRegistry() *Registry {
+	registry := NewRegistry()
+	for _, builtin := range builtinValidators {
+		registry.register(builtin.gvk, builtin.validate, builtin.since)
+	}
+	return registry
+}
+
+// Register adds a validator for objects of the given type. Leave Version empty to register
+// the validator for every version of the group and kind. Validators run in registration
+// order, with version-independent validators first. Validators registered this way run
+// under every constraint profile.
+func (r *Registry) Register(gvk GroupVersionKind, validate func(obj map[string]interface{}) error) {
+	r.register(gvk, validate, "")
+}
+
+// register adds a validator introduced in release since; see UseConstraintProfile.
+func (r *Registry) register(gvk GroupVersionKind, validate func(obj map[string]interface{}) error, since string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.validators[gvk] = append(r.validators[gvk], registeredValidator{validate: validate, since: since})
+}
+
+// Validators returns the validators registered for gvk, including those registered for
+// every version of its group and kind, leaving out built-in validators introduced after
+// the constraint profile in effect.
+func (r *Registry) Validators(gvk GroupVersionKind) []func(obj map[string]interface{}) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	registered := r.validators[GroupVersionKind{Group: gvk.Group, Kind: gvk.Kind}]
+	if gvk.Version != "" {
+		registered = append(registered[:len(registered):len(registered)], r.validators[gvk]...)
+	}
+	validators := make([]func(obj map[string]interface{}) error, 0, len(registered))
+	for _, validator := range registered {
+		if validator.enabled() {
+			validators = append(validators, validator.validate)
+		}
+	}
+	return validators
+}
+
+// Kinds returns the types with validators under the constraint profile in effect, sorted
+// by their string form.
+func (r *Registry) Kinds() []GroupVersionKind {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byName := make(map[string]GroupVersionKind, len(r.validators))
+	for gvk, registered := range r.validators {
+		for _, validator := range registered {
+			if validator.enabled() {
+				byName[gvk.String()] = gvk
+				break
+			}
+		}
+	}
+	kinds := make([]GroupVersionKind, 0, len(byName))
+	for _, name := range sortedKeys(byName) {
+		kinds = append(kinds, byName[name])
+	}
+	return kinds
+}
+
+// Validate runs the validators registered for obj's type. Objects of unregistered types
+// pass.
+func (r *Registry) Validate(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+	for _, validate := range r.Validators(GroupVersionKindOf(obj)) {
+		if err := validate(obj); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
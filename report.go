@@ -0,0 +1,217 @@
+package k8sconstraints
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Report aggregates the findings of a validation run over a set of files.
+type Report struct {
+	// Files is the number of files read.
+	Files int `json:"files"`
+	// Documents is the number of documents validated.
+	Documents int `json:"documents"`
+	// Resources lists every object validated, including those without findings. The
+	// items of List documents are listed individually.
+	Resources []ReportResource `json:"resources"`
+	// Findings holds every finding, in file and document order.
+	Findings []Finding `json:"findings"`
+	// Score holds the resource and bundle grades when scoring is enabled.
+	Score *ScoreSummary `json:"score,omitempty"`
+	// Targets breaks a report merged from several targets, such as the clusters of a
+	// fleet, down per target.
+	Targets []TargetSummary `json:"targets,omitempty"`
+}
+
+// TargetSummary summarizes the part of a merged report that came from one target.
+type TargetSummary struct {
+	Name      string `json:"name"`
+	Documents int    `json:"documents"`
+	Resources int    `json:"resources"`
+	Errors    int    `json:"errors"`
+	Warnings  int    `json:"warnings"`
+	// Grade is the target's bundle grade when scoring is enabled.
+	Grade string `json:"grade,omitempty"`
+	// Error records why the target could not be read completely.
+	Error string `json:"error,omitempty"`
+}
+
+// ReportResource locates an object validated during a run.
+type ReportResource struct {
+	File     string      `json:"file,omitempty"`
+	Document int         `json:"document"`
+	Line     int         `json:"line,omitempty"`
+	Resource ResourceRef `json:"resource"`
+}
+
+// Output formats understood by WriteReport.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// AddDocument records a validated document of file in the report, stamping its findings
+// with their location. The items of a streamed List count as one document, the List.
+func (r *Report) AddDocument(file string, result DocumentResult) {
+	if result.Path == "" || result.Path == "items[0]" {
+		r.Documents++
+	}
+	if result.Object != nil {
+		objects := []map[string]interface{}{result.Object}
+		if IsList(result.Object) {
+			objects = objects[:0]
+			items, _ := ExpandList(result.Object)
+			for _, item := range items {
+				objects = append(objects, item.Object)
+			}
+		}
+		for _, obj := range objects {
+			r.Resources = append(r.Resources, ReportResource{File: file, Document: result.Index, Line: result.Line, Resource: ResourceRefOf(obj)})
+		}
+	}
+	for _, finding := range result.Findings {
+		r.Findings = append(r.Findings, locateFinding(finding, file, result))
+	}
+}
+
+// AddFindings records further findings of a document already added with AddDocument, such
+// as those of bundle checks, stamping them with the document's location.
+func (r *Report) AddFindings(file string, result DocumentResult) {
+	for _, finding := range result.Findings {
+		r.Findings = append(r.Findings, locateFinding(finding, file, result))
+	}
+}
+
+// locateFinding stamps a finding and its related findings with their document's location.
+func locateFinding(finding Finding, file string, result DocumentResult) Finding {
+	finding.File = file
+	finding.Document = result.Index
+	finding.Line = result.Line
+	if len(finding.Related) > 0 {
+		related := make([]Finding, len(finding.Related))
+		for i, f := range finding.Related {
+			related[i] = locateFinding(f, file, result)
+		}
+		finding.Related = related
+	}
+	return finding
+}
+
+// Merge appends the files, documents, resources, and findings of other to the report.
+// Scores and target summaries are not merged; score the merged report instead.
+func (r *Report) Merge(other Report) {
+	r.Files += other.Files
+	r.Documents += other.Documents
+	r.Resources = append(r.Resources, other.Resources...)
+	r.Findings = append(r.Findings, other.Findings...)
+}
+
+// Sort orders the resources of the report by file and document index, and the findings
+// with SortFindings, so the report is the same whatever order documents were validated in.
+func (r *Report) Sort() {
+	sort.SliceStable(r.Resources, func(i, j int) bool {
+		a, b := r.Resources[i], r.Resources[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Document < b.Document
+	})
+	SortFindings(r.Findings)
+}
+
+// SummarizeTarget returns the summary of a target's own report.
+func SummarizeTarget(name string, report Report) TargetSummary {
+	summary := TargetSummary{Name: name, Documents: report.Documents, Resources: len(report.Resources)}
+	for _, finding := range report.Findings {
+		switch finding.Severity {
+		case SeverityError:
+			summary.Errors++
+		case SeverityWarning:
+			summary.Warnings++
+		}
+	}
+	if report.Score != nil {
+		summary.Grade = report.Score.Grade
+	}
+	return summary
+}
+
+// HasFindings reports whether the run produced any findings.
+func (r Report) HasFindings() bool {
+	return len(r.Findings) > 0
+}
+
+// WriteReport renders report to w in the given format, using the sink registered for it;
+// see RegisterOutputSink.
+func WriteReport(w io.Writer, report Report, format string) error {
+	if format == "" {
+		format = FormatText
+	}
+	sink, err := NewOutputSink(format, w)
+	if err != nil {
+		return err
+	}
+	return WriteReportTo(sink, report)
+}
+
+// writeJSONReport encodes the report as indented JSON. Every finding carries its file,
+// document index, field path, rule, severity, and message, so CI systems can consume it
+// directly; the output can also be read back with LoadFindings.
+func writeJSONReport(w io.Writer, report Report) error {
+	if report.Resources == nil {
+		report.Resources = []ReportResource{}
+	}
+	if report.Findings == nil {
+		report.Findings = []Finding{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(report)
+}
+
+// formatTargetSummary renders a target summary on one line as
+// "target NAME: N document(s), E error(s), W warning(s)[, grade G][; failed: <error>]".
+func formatTargetSummary(target TargetSummary) string {
+	text := fmt.Sprintf("target %s: %d document(s), %d error(s), %d warning(s)", target.Name, target.Documents, target.Errors, target.Warnings)
+	if target.Grade != "" {
+		text += ", grade " + target.Grade
+	}
+	if target.Error != "" {
+		text += "; failed: " + target.Error
+	}
+	return text
+}
+
+// FormatFinding renders a finding on one line as
+// "[line N: ]Kind namespace/name: <field path>: <message> [<rule>]".
+func FormatFinding(finding Finding) string {
+	text := finding.Message
+	if finding.FieldPath != "" {
+		text = finding.FieldPath + ": " + text
+	}
+	if resource := finding.Resource.String(); resource != "" {
+		text = resource + ": " + text
+	}
+	if finding.Line > 0 {
+		text = fmt.Sprintf("line %d: %s", finding.Line, text)
+	}
+	if finding.Rule != "" {
+		text += " [" + finding.Rule + "]"
+	}
+	if len(finding.Related) > 0 {
+		text += fmt.Sprintf(" (and %d related finding(s))", len(finding.Related))
+	}
+	return text
+}
+
+// displayFileName names a file in text output; findings not read from a file are listed
+// under "<input>".
+func displayFileName(file string) string {
+	if file == "" {
+		return "<input>"
+	}
+	return file
+}
@@ -0,0 +1,160 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// workloadKinds lists the kinds treated as workloads by organizational rules.
+var workloadKinds = []string{
+	"Pod",
+	"Deployment",
+	"StatefulSet",
+	"DaemonSet",
+	"ReplicaSet",
+	"ReplicationController",
+	"Job",
+	"CronJob",
+}
+
+// CheckRequiredAnnotations is the ID of the check built by RequiredAnnotationsCheck, and the
+// rule code of its findings about annotation values.
+const CheckRequiredAnnotations = "RequiredAnnotations"
+
+// AnnotationRequirement declares an annotation that must be present on matching objects.
+// Pattern and Enum are optional constraints on the annotation value.
+type AnnotationRequirement struct {
+	Key     string   `yaml:"key" json:"key"`
+	Pattern string   `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	Enum    []string `yaml:"enum,omitempty" json:"enum,omitempty"`
+	// Kinds restricts the requirement to the given kinds. When empty it applies to
+	// workloads and Namespaces.
+	Kinds []string `yaml:"kinds,omitempty" json:"kinds,omitempty"`
+}
+
+// Validate checks that the requirement names a valid annotation key and that its pattern
+// compiles.
+func (r AnnotationRequirement) Validate() error {
+	errs := make([]error, 0)
+	if err := ValidateLabelKey(r.Key); err != nil {
+		errs = append(errs, fmt.Errorf("invalid key '%s': %v", r.Key, err))
+	}
+	if r.Pattern != "" {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid pattern: %v", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// RequiredAnnotationsCheck returns an opt-in check, not run by ValidateObject, that reports
+// objects missing an annotation required for their kind, or whose value does not satisfy
+// the requirement; see ValidateRequiredAnnotations. Run it with RunChecks or pass it to the
+// linter's Checks option.
+func RequiredAnnotationsCheck(reqs []AnnotationRequirement) Check {
+	return Check{ID: CheckRequiredAnnotations, Validate: func(obj map[string]interface{}) error {
+		return ValidateRequiredAnnotations(obj, reqs)
+	}}
+}
+
+// ValidateRequiredAnnotations checks that obj carries every annotation required for its kind
+// and that each value satisfies the requirement's pattern and enum constraints.
+func ValidateRequiredAnnotations(obj map[string]interface{}, reqs []AnnotationRequirement) error {
+	errs := make([]error, 0)
+
+	kind, _ := nestedString(obj, "kind")
+	annotations, _ := nestedStringMap(obj, "metadata", "annotations")
+
+	for _, req := range reqs {
+		if !requirementAppliesToKind(req.Kinds, kind) {
+			continue
+		}
+
+		value, ok := annotations[req.Key]
+		if !ok {
+			errs = append(errs, &ConstraintError{FieldPath: "metadata.annotations", Rule: RuleRequired, Message: fmt.Sprintf("missing required annotation '%s'", req.Key)})
+			continue
+		}
+
+		if err := ValidateAnnotationRequirementValue(req, value); err != nil {
+			path := NewPath("metadata", "annotations").Key(req.Key)
+			errs = append(errs, WithFieldPath(path.String(), withRule(CheckRequiredAnnotations, value, withMessagePrefix("invalid value for required annotation: ", err))))
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// ValidateAnnotationRequirementValue checks a single annotation value against the
+// pattern and enum constraints of req.
+func ValidateAnnotationRequirementValue(req AnnotationRequirement, value string) error {
+	return validateValueConstraints(req.Pattern, req.Enum, value)
+}
+
+// userPatterns caches the compiled patterns of annotation, label, and field constraints,
+// which are checked against every object of a bundle.
+var userPatterns sync.Map
+
+// compileUserPattern compiles pattern once and returns the cached regexp on later calls.
+func compileUserPattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := userPatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	userPatterns.Store(pattern, re)
+	return re, nil
+}
+
+// validateValueConstraints checks value against an optional regex pattern and an optional
+// list of allowed values.
+func validateValueConstraints(pattern string, enum []string, value string) error {
+	if pattern != "" {
+		re, err := compileUserPattern(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %v", err)
+		}
+		if !re.MatchString(value) {
+			return &ConstraintError{BadValue: value, Message: fmt.Sprintf("value '%s' does not match pattern '%s'", value, pattern)}
+		}
+	}
+
+	if len(enum) > 0 && !containsString(enum, value) {
+		return &ConstraintError{BadValue: value, Message: fmt.Sprintf("value '%s' must be one of: %s%s", value, strings.Join(enum, ", "), enumSuggestion(value, enum))}
+	}
+
+	return nil
+}
+
+// requirementAppliesToKind reports whether a rule restricted to kinds applies to kind.
+// An empty kinds list selects workloads and Namespaces.
+func requirementAppliesToKind(kinds []string, kind string) bool {
+	if len(kinds) == 0 {
+		return kind == "Namespace" || containsString(workloadKinds, kind)
+	}
+	return containsString(kinds, kind)
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package k8sconstraints
+
+import "fmt"
+
+// CheckRequiredLabels is the ID of the check built by RequiredLabelsCheck.
+const CheckRequiredLabels = "RequiredLabels"
+
+// RequiredLabelsCheck returns an opt-in check, not run by ValidateObject, that reports
+// workloads and Namespaces missing any of the label keys; see ValidateRequiredLabels. Run
+// it with RunChecks or pass it to the linter's Checks option.
+func RequiredLabelsCheck(keys []string) Check {
+	return Check{ID: CheckRequiredLabels, Validate: func(obj map[string]interface{}) error {
+		return ValidateRequiredLabels(obj, keys)
+	}}
+}
+
+// ValidateRequiredLabels checks that a workload or Namespace carries every label in keys.
+// Objects of other kinds pass.
+func ValidateRequiredLabels(obj map[string]interface{}, keys []string) error {
+	kind, _ := nestedString(obj, "kind")
+	if !requirementAppliesToKind(nil, kind) {
+		return nil
+	}
+
+	errs := make([]error, 0)
+	labels, _ := nestedStringMap(obj, "metadata", "labels")
+	for _, key := range keys {
+		if _, ok := labels[key]; !ok {
+			errs = append(errs, &ConstraintError{FieldPath: "metadata.labels", Rule: RuleRequired, Message: fmt.Sprintf("missing required label '%s'", key)})
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckReservedPrefixes is the ID of the check built by ReservedPrefixesCheck.
+const CheckReservedPrefixes = "ReservedPrefixes"
+
+// ReservedKeyDomains are the label and annotation key prefixes Kubernetes reserves for its
+// own components, together with their subdomains, such as node.kubernetes.io.
+var ReservedKeyDomains = []string{"kubernetes.io", "k8s.io"}
+
+// DefaultReservedKeyAllowlist lists the keys under ReservedKeyDomains that users are meant
+// to set themselves. Entries ending in '/' allow every key with that prefix.
+var DefaultReservedKeyAllowlist = []string{
+	"app.kubernetes.io/",
+	"pod-security.kubernetes.io/",
+	"kubectl.kubernetes.io/default-container",
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"kubectl.kubernetes.io/restartedAt",
+	"kubernetes.io/change-cause",
+	"kubernetes.io/description",
+	"kubernetes.io/ingress.class",
+	"kubernetes.io/metadata.name",
+	"kubernetes.io/service-account.name",
+	"service.kubernetes.io/headless",
+	"service.kubernetes.io/service-proxy-name",
+	"service.kubernetes.io/topology-mode",
+}
+
+// ReservedPrefixesCheck returns an opt-in check, not run by ValidateObject, that warns
+// about labels and annotations under the ReservedKeyDomains other than the keys in
+// allowlist, in addition to DefaultReservedKeyAllowlist. Run it with RunChecks or pass it
+// to the linter's Checks option.
+func ReservedPrefixesCheck(allowlist []string) Check {
+	allowed := append(append([]string{}, DefaultReservedKeyAllowlist...), allowlist...)
+	return Check{ID: CheckReservedPrefixes, Validate: func(obj map[string]interface{}) error {
+		return ValidateReservedPrefixes(obj, allowed)
+	}}
+}
+
+// ValidateReservedPrefixes warns about the metadata.labels and metadata.annotations of obj
+// whose keys use a prefix in the ReservedKeyDomains and are not in allowlist. Entries of
+// allowlist ending in '/' allow every key with that prefix.
+func ValidateReservedPrefixes(obj map[string]interface{}, allowlist []string) error {
+	errs := make([]error, 0)
+
+	for _, field := range []string{"labels", "annotations"} {
+		values, _ := nestedStringMap(obj, "metadata", field)
+		for _, key := range sortedKeys(values) {
+			domain, ok := reservedKeyDomain(key)
+			if !ok || reservedKeyAllowed(key, allowlist) {
+				continue
+			}
+			message := fmt.Sprintf("warning: %s key '%s' uses the prefix '%s/', which is reserved for Kubernetes components", strings.TrimSuffix(field, "s"), key, domain)
+			errs = append(errs, &ConstraintError{FieldPath: fmt.Sprintf("metadata.%s[%q]", field, key), BadValue: key, Message: message})
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// reservedKeyDomain returns the prefix of key if it is one of the ReservedKeyDomains or a
+// subdomain of one.
+func reservedKeyDomain(key string) (string, bool) {
+	prefix, _, ok := strings.Cut(key, "/")
+	if !ok {
+		return "", false
+	}
+	for _, domain := range ReservedKeyDomains {
+		if prefix == domain || strings.HasSuffix(prefix, "."+domain) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// reservedKeyAllowed reports whether key is in allowlist or has one of its '/'-terminated
+// prefixes.
+func reservedKeyAllowed(key string, allowlist []string) bool {
+	for _, allowed := range allowlist {
+		if key == allowed || (strings.HasSuffix(allowed, "/") && strings.HasPrefix(key, allowed)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// ValidateResourceRequirements validates the resources of a container: every request and
+// limit must be a valid, non-negative quantity, and each request must be less than or equal
+// to the limit of the same resource. Requests without a matching limit are not bounded.
+func ValidateResourceRequirements(resources map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	quantities := make(map[string]map[string]*big.Rat)
+	for _, field := range []string{"limits", "requests"} {
+		values, _ := nestedMap(resources, field)
+		quantities[field] = make(map[string]*big.Rat)
+		for _, resource := range sortedKeys(values) {
+			path := fmt.Sprintf("%s[%q]", field, resource)
+			quantity, err := quantityValue(values[resource])
+			if err != nil {
+				errs = append(errs, WithFieldPath(path, withRule(RuleQuantity, values[resource], err)))
+				continue
+			}
+			if quantity.Sign() < 0 {
+				errs = append(errs, &ConstraintError{FieldPath: path, Rule: RuleQuantity, BadValue: values[resource], Message: "quantity must be greater than or equal to 0"})
+				continue
+			}
+			quantities[field][resource] = quantity
+		}
+	}
+
+	for _, resource := range sortedKeys(quantities["requests"]) {
+		limit, ok := quantities["limits"][resource]
+		if !ok || quantities["requests"][resource].Cmp(limit) <= 0 {
+			continue
+		}
+		limits, _ := nestedMap(resources, "limits")
+		requests, _ := nestedMap(resources, "requests")
+		errs = append(errs, &ConstraintError{
+			FieldPath: fmt.Sprintf("requests[%q]", resource),
+			BadValue:  requests[resource],
+			Message:   fmt.Sprintf("request %v must be less than or equal to the %s limit of %v", requests[resource], resource, limits[resource]),
+		})
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleResourceRequirements, JoinErrors(errs))
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// standardQuotaResourceNames are the resource names ResourceQuota accepts without a prefix.
+var standardQuotaResourceNames = []string{
+	"pods",
+	"services",
+	"replicationcontrollers",
+	"resourcequotas",
+	"secrets",
+	"configmaps",
+	"persistentvolumeclaims",
+	"services.nodeports",
+	"services.loadbalancers",
+	"cpu",
+	"memory",
+	"ephemeral-storage",
+	"requests.cpu",
+	"requests.memory",
+	"requests.storage",
+	"requests.ephemeral-storage",
+	"limits.cpu",
+	"limits.memory",
+	"limits.ephemeral-storage",
+}
+
+// resourceQuotaScopes lists the valid ResourceQuota scopes.
+var resourceQuotaScopes = []string{
+	"Terminating",
+	"NotTerminating",
+	"BestEffort",
+	"NotBestEffort",
+	"PriorityClass",
+	"CrossNamespacePodAffinity",
+	"VolumeAttributesClass",
+}
+
+// scopeSelectorOperators lists the valid scopeSelector matchExpressions operators.
+var scopeSelectorOperators = []string{"In", "NotIn", "Exists", "DoesNotExist"}
+
+var (
+	// hugePagesResourcePattern matches hugepages-<size> and requests.hugepages-<size>.
+	hugePagesResourcePattern = regexp.MustCompile(`^(requests\.)?hugepages-.+$`)
+	// countResourcePattern matches object count quotas: count/<resource>[.<group>].
+	countResourcePattern = regexp.MustCompile(`^count/[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// ValidateResourceQuota validates the spec of a v1 ResourceQuota: hard limit keys and
+// values, scopes, and the scopeSelector.
+func ValidateResourceQuota(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	specPath := NewPath("spec")
+
+	// Hard limits
+	hard, _ := nestedMap(obj, "spec", "hard")
+	for _, name := range sortedKeys(hard) {
+		hardPath := specPath.Child("hard").Key(name)
+		if err := ValidateQuotaResourceName(name); err != nil {
+			errs = append(errs, WithFieldPath(hardPath.String(), err))
+		}
+		if err := validateNonNegativeQuantity(hard[name]); err != nil {
+			errs = append(errs, WithFieldPath(hardPath.String(), err))
+		}
+	}
+
+	// Scopes
+	scopesPath := specPath.Child("scopes")
+	scopes, _ := nestedSlice(obj, "spec", "scopes")
+	scopeNames := toStringSlice(scopes)
+	for i, scope := range scopeNames {
+		if !containsString(resourceQuotaScopes, scope) {
+			errs = append(errs, WithFieldPath(scopesPath.Index(i).String(), enumViolation("scope", scope, resourceQuotaScopes)))
+		}
+	}
+	if containsString(scopeNames, "Terminating") && containsString(scopeNames, "NotTerminating") {
+		errs = append(errs, Invalid(scopesPath, scopeNames, "Terminating and NotTerminating are mutually exclusive"))
+	}
+	if containsString(scopeNames, "BestEffort") && containsString(scopeNames, "NotBestEffort") {
+		errs = append(errs, Invalid(scopesPath, scopeNames, "BestEffort and NotBestEffort are mutually exclusive"))
+	}
+
+	// Scope selector
+	expressions, _ := nestedSlice(obj, "spec", "scopeSelector", "matchExpressions")
+	for i, raw := range expressions {
+		expressionPath := specPath.Child("scopeSelector", "matchExpressions").Index(i)
+		expression, ok := raw.(map[string]interface{})
+		if !ok {
+			errs = append(errs, Invalid(expressionPath, raw, "must be an object"))
+			continue
+		}
+		if err := ValidateScopeSelectorRequirement(expression); err != nil {
+			errs = append(errs, WithFieldPath(expressionPath.String(), err))
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleResourceQuota, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// ValidateQuotaResourceName validates a ResourceQuota hard limit key. Standard resource
+// names, hugepages, and count/<resource>[.<group>] are accepted as is; anything else must be
+// a fully qualified name such as requests.nvidia.com/gpu or
+// gold.storageclass.storage.k8s.io/requests.storage.
+func ValidateQuotaResourceName(name string) error {
+	if containsString(standardQuotaResourceNames, name) || hugePagesResourcePattern.MatchString(name) {
+		return nil
+	}
+
+	if strings.HasPrefix(name, "count/") {
+		if !countResourcePattern.MatchString(name) {
+			return errors.New("object count quota must be of the form count/<resource>[.<group>] with a lowercase resource and group")
+		}
+		return nil
+	}
+
+	if err := ValidateQualifiedName(name); err != nil {
+		return err
+	}
+	if !strings.Contains(name, "/") {
+		return errors.New("must be a standard resource type or fully qualified")
+	}
+
+	return nil
+}
+
+// ValidateScopeSelectorRequirement validates one ResourceQuota scopeSelector expression.
+func ValidateScopeSelectorRequirement(expression map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	scopeName, _ := nestedString(expression, "scopeName")
+	operator, _ := nestedString(expression, "operator")
+	values, hasValues := nestedSlice(expression, "values")
+
+	if err := validateEnumField("scopeName", scopeName, resourceQuotaScopes); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateEnumField("operator", operator, scopeSelectorOperators); err != nil {
+		errs = append(errs, err)
+	}
+
+	switch operator {
+	case "In", "NotIn":
+		if scopeName != "PriorityClass" && scopeName != "VolumeAttributesClass" {
+			errs = append(errs, fmt.Errorf("operator '%s' is only supported for the PriorityClass and VolumeAttributesClass scopes", operator))
+		}
+		if len(values) == 0 {
+			errs = append(errs, fmt.Errorf("values must be specified when operator is '%s'", operator))
+		}
+	case "Exists", "DoesNotExist":
+		if hasValues && len(values) > 0 {
+			errs = append(errs, fmt.Errorf("values must not be specified when operator is '%s'", operator))
+		}
+	}
+
+	if len(errs) > 0 {
+		return withDefaultRule(RuleResourceQuota, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validateNonNegativeQuantity checks that value is a valid, non-negative quantity.
+func validateNonNegativeQuantity(value interface{}) error {
+	quantity, err := quantityValue(value)
+	if err != nil {
+		return withRule(RuleQuantity, value, err)
+	}
+	if quantity.Sign() < 0 {
+		return &ConstraintError{Rule: RuleQuantity, BadValue: value, Message: "quantity must be greater than or equal to 0"}
+	}
+	return nil
+}
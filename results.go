@@ -0,0 +1,66 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Names of the result files written by WriteResultFiles.
+const (
+	ResultStatus    = "status"
+	ResultFindings  = "findings"
+	ResultErrors    = "errors"
+	ResultWarnings  = "warnings"
+	ResultDocuments = "documents"
+	ResultScore     = "score"
+	ResultGrade     = "grade"
+)
+
+// Values of the status result.
+const (
+	ResultStatusPassed = "passed"
+	ResultStatusFailed = "failed"
+)
+
+// ResultValues returns the outcome of a run as small string values keyed by result name:
+// the status ("passed" or "failed"), the number of findings, errors, warnings, and
+// documents, and the bundle score and grade when the report was scored.
+func ResultValues(report Report) map[string]string {
+	summary := SummarizeTarget("", report)
+	status := ResultStatusPassed
+	if report.HasFindings() {
+		status = ResultStatusFailed
+	}
+
+	values := map[string]string{
+		ResultStatus:    status,
+		ResultFindings:  strconv.Itoa(len(report.Findings)),
+		ResultErrors:    strconv.Itoa(summary.Errors),
+		ResultWarnings:  strconv.Itoa(summary.Warnings),
+		ResultDocuments: strconv.Itoa(report.Documents),
+	}
+	if report.Score != nil {
+		values[ResultScore] = strconv.FormatFloat(report.Score.Score, 'f', 1, 64)
+		values[ResultGrade] = report.Score.Grade
+	}
+	return values
+}
+
+// WriteResultFiles writes each of ResultValues to a file of the same name in dir, without a
+// trailing newline, so pipeline steps can branch on violation counts without parsing logs.
+// Pointing dir at /tekton/results publishes them as Tekton task results; Argo Workflows
+// templates can read them as output parameters with valueFrom.path.
+func WriteResultFiles(dir string, report Report) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	values := ResultValues(report)
+	for _, name := range sortedKeys(values) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(values[name]), 0o644); err != nil {
+			return fmt.Errorf("writing result '%s': %v", name, err)
+		}
+	}
+	return nil
+}
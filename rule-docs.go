@@ -0,0 +1,100 @@
+package k8sconstraints
+
+// ruleDescriptions holds a one-line description of each rule code carried by the findings
+// of the built-in validators and checks, used as help text by report formats such as SARIF.
+var ruleDescriptions = map[string]string{
+	RuleMaxLength:                 "The value exceeds the maximum length Kubernetes accepts for the field.",
+	RuleRequired:                  "A required field is missing or empty.",
+	RuleDNS1123Label:              "The value must be an RFC 1123 DNS label: at most 63 lowercase alphanumeric characters or '-', starting and ending with an alphanumeric character.",
+	RuleDNS1123Subdomain:          "The value must be an RFC 1123 DNS subdomain: at most 253 characters of dot-separated DNS labels.",
+	RuleDNS1035Label:              "The value must be an RFC 1035 DNS label: at most 63 lowercase alphanumeric characters or '-', starting with a letter and ending with an alphanumeric character.",
+	RuleQualifiedName:             "The value must be a qualified name: an optional DNS subdomain prefix and '/', followed by a name of at most 63 alphanumeric characters, '-', '_', or '.'.",
+	RuleLabelValue:                "Label values must be empty or at most 63 alphanumeric characters, '-', '_', or '.', starting and ending with an alphanumeric character.",
+	RuleAnnotationValue:           "Annotation values must be valid UTF-8.",
+	RuleAPIVersion:                "apiVersion must be 'version' for the core group or 'group/version', with a version such as v1, v1beta1, or v2alpha1.",
+	RuleKind:                      "kind must be an alphanumeric CamelCase name starting with an uppercase letter.",
+	RuleImageReference:            "Container images must be valid Docker/OCI references: [registry/]repository[:tag][@digest], with a lowercase repository path.",
+	RuleEnvVarName:                "Environment variable names must consist of printable ASCII characters other than '='.",
+	RuleCIdentifier:               "The value must be a C identifier: letters, digits, or '_', not starting with a digit.",
+	RulePortName:                  "Port names must be IANA service names: at most 15 lowercase alphanumeric characters or '-', with at least one letter and no leading, trailing, or adjacent hyphens.",
+	RulePortNumber:                "Port numbers must be integers between 1 and 65535, and node ports must lie within the API server's node port range (30000-32767 by default).",
+	RuleQuantity:                  "Resource quantities must be decimal numbers with an optional SI suffix (Ki, Mi, m, k, ...) or exponent, such as 500m, 1.5Gi, or 2e3.",
+	RuleCronSchedule:              "Schedules must have five cron fields (minute, hour, day of month, month, day of week) or be a macro such as @hourly.",
+	RuleTimeZone:                  "Time zones must be IANA time zone names such as Europe/Berlin.",
+	RuleSelector:                  "Label selectors must be comma-separated requirements such as app=web, tier in (a,b), or !canary.",
+	RuleIP:                        "The value must be an IPv4 address such as 10.0.0.1 or an IPv6 address such as fd00::1, without leading zeros.",
+	RuleCIDR:                      "The value must be an IPv4 CIDR such as 10.0.0.0/8 or an IPv6 CIDR such as fd00::/64.",
+	RuleEnum:                      "The value is not one of the values Kubernetes accepts for the field, such as Recreate or RollingUpdate for a Deployment strategy.",
+	RuleGenerateName:              "metadata.name and metadata.generateName disagree: the name does not start with generateName, or both are set and generateName is ignored.",
+	RuleOwnerReferences:           "An owner reference is incomplete or invalid, or more than one owner reference is marked as the controller.",
+	RulePodSpec:                   "The pod spec or one of its containers is invalid, such as a pod without containers, a duplicate container name, or a field its kind of container may not set.",
+	RuleAffinity:                  "A node affinity, pod affinity, or pod anti-affinity term is invalid, such as an unknown operator, a missing topologyKey, or a weight outside 1-100.",
+	RulePodDNS:                    "The dnsConfig of the pod spec is invalid, such as too many nameservers or search domains, or a resolver option without a valid value.",
+	RuleProbe:                     "A liveness, readiness, or startup probe is invalid: it sets no handler or more than one, or a threshold, period, or timeout out of range.",
+	RuleResourceRequirements:      "Container resource requests and limits are invalid, such as a negative quantity or a request larger than its limit.",
+	RuleSecurityContext:           "The security context of the pod or a container is invalid, such as an unknown capability or seccomp profile type, or a user ID out of range.",
+	RuleVolumeMount:               "A volume mount is invalid: it references an undeclared volume, reuses a mountPath, or sets a subPath that leaves the volume.",
+	RuleDaemonSet:                 "The spec of a DaemonSet is invalid, such as an update strategy that is unknown or does not fit the rollingUpdate settings.",
+	RuleDeployment:                "The spec of a Deployment is invalid, such as rollingUpdate settings alongside the Recreate strategy.",
+	RuleStatefulSet:               "The spec of a StatefulSet is invalid, such as rollingUpdate settings alongside the OnDelete update strategy.",
+	RuleJob:                       "The spec of a Job is invalid, such as negative completions, parallelism, or backoffLimit, or an invalid podFailurePolicy.",
+	RuleCronJob:                   "The spec of a CronJob is invalid, such as a negative startingDeadlineSeconds or history limit, or a schedule carrying its own time zone.",
+	RuleHPA:                       "The spec of a HorizontalPodAutoscaler is invalid, such as minReplicas above maxReplicas, or a metric without a target.",
+	RuleService:                   "The spec of a Service is invalid, such as a port without a number, unnamed or duplicate names of several ports, or a clusterIP on an ExternalName Service.",
+	RuleIngress:                   "The spec of an Ingress is invalid, such as a path without a pathType or backend, or a TLS host that no rule serves.",
+	RuleNetworkPolicy:             "The spec of a NetworkPolicy is invalid, such as an unknown policy type or a peer combining ipBlock with selectors.",
+	RuleRBAC:                      "A rule of a Role or ClusterRole is invalid, such as a rule without verbs, or nonResourceURLs mixed with resources.",
+	RuleSecret:                    "A Secret is invalid: data that is not base64 encoded, an invalid key, or keys its type requires that are missing or malformed.",
+	RuleResourceQuota:             "The spec of a ResourceQuota is invalid, such as mutually exclusive scopes, a resource name that is neither standard nor fully qualified, or a negative quantity.",
+	RuleLimitRange:                "A limit of a LimitRange is invalid, such as a field its type does not allow, a default above its max, or a maxLimitRequestRatio below 1.",
+	RuleFlowControl:               "A FlowSchema or PriorityLevelConfiguration of API Priority and Fairness is invalid, such as a rule without subjects or a setting out of range.",
+	RuleLease:                     "The spec of a Lease is invalid, such as a non-positive leaseDurationSeconds or a negative leaseTransitions.",
+	RuleEvent:                     "An Event is invalid, such as an events.k8s.io/v1 Event without an eventTime or reportingController.",
+	CheckTypeMeta:                 "The apiVersion or kind of the object is missing or invalid.",
+	CheckObjectMeta:               "The metadata of the object is invalid, such as its name, namespace, labels, or annotations.",
+	CheckKindRules:                "The object violates a validator registered for its kind.",
+	CheckWindowsPod:               "The Windows options of the pod spec are invalid, or HostProcess containers are mixed with other containers or run without hostNetwork.",
+	CheckPodSchedulingAndOverhead: "The scheduling or overhead fields of the pod spec are invalid, such as a duplicate scheduling gate, or overhead without a runtimeClassName.",
+	CheckOpenAPISchema:            "The object does not match the OpenAPI schema of its type: it has unknown fields, values of the wrong type, or is missing required fields.",
+	CheckCRDSchema:                "The custom resource does not match the schema of its CustomResourceDefinition, or fails one of its x-kubernetes-validations rules; or the CustomResourceDefinition's schema does not compile.",
+	CheckServerDryRun:             "The API server rejected the object in a server-side dry run, through its validation or an admission webhook, or returned a warning for it.",
+	CheckServedKinds:              "The cluster validated against does not serve the apiVersion and kind: the API group or version is not enabled, or the CustomResourceDefinition is not installed.",
+	CheckControllerManagedLabels:  "A label owned by a workload controller, such as pod-template-hash, is set by hand in metadata, a pod template, or a selector.",
+	CheckWorkloadOwnership:        "A Pod or ReplicaSet bound for production is not owned by a controller, so it is not rescheduled or rolled out; use a Deployment or another workload.",
+	CheckLabelValueEnums:          "A label, or a label selector, uses a value outside those the configuration allows for its key.",
+	CheckFieldConstraints:         "A value selected by a JSONPath of the configuration's field constraints does not match its pattern, length, allowed values, or numeric range.",
+	CheckDaemonSetScaling:         "A DaemonSet sets spec.replicas; DaemonSets run one pod per eligible node and cannot be scaled.",
+	CheckDaemonSetScaleTargets:    "A HorizontalPodAutoscaler scales a DaemonSet, which runs one pod per eligible node and cannot be scaled.",
+	CheckHPATargets:               "A HorizontalPodAutoscaler references a workload of the bundle by the wrong kind or apiVersion, or scales a workload that also sets spec.replicas.",
+	CheckServiceTargetPorts:       "A named targetPort of a Service matches no named container port of the workloads of the bundle it selects.",
+	CheckFlowControlReferences:    "A FlowSchema references a PriorityLevelConfiguration that is neither in the bundle nor built into the API server.",
+	CheckLabelSchema:              "The labels of the object miss a key the label schema requires, carry a key it does not declare, or hold a value it does not accept.",
+	CheckRequiredAnnotations:      "The value of an annotation required by the configuration does not match its pattern or allowed values.",
+	CheckPodSecurity:              "The pod spec violates a control of the Pod Security Standards profile enforced for its namespace, such as running privileged or as root.",
+	RuleImageLatestTag:            "The container image is untagged or tagged latest, so what runs changes whenever the tag moves; pin a version tag or digest.",
+	RuleImagePullPolicyDigest:     "The container image is pinned by digest but pulled Always, which only adds registry round trips; use IfNotPresent.",
+	RuleImagePullPolicyMutableTag: "The container image has a mutable tag but is pulled IfNotPresent, so nodes may run different images; pin a version tag or digest, or pull Always.",
+	RuleDeprecatedAPI:             "The apiVersion is deprecated or no longer served by the targeted Kubernetes release; migrate to the replacement version.",
+	CheckReservedNamespaces:       "A namespaced object is deployed to a namespace reserved for Kubernetes itself, such as kube-system.",
+	CheckReservedPrefixes:         "A label or annotation key uses a prefix reserved for Kubernetes, such as kubernetes.io/, and is not one Kubernetes defines.",
+	CheckRequiredLabels:           "A workload or Namespace misses a label the configuration requires.",
+	CheckRecommendedLabels:        "The object misses one of the recommended labels of the configuration, such as app.kubernetes.io/name.",
+	CheckAllowedRegistries:        "A container pulls its image from a registry the configuration does not allow.",
+	CheckKubernetesVersionFields:  "The pod spec sets a field the targeted Kubernetes release does not serve by default, so the API server drops it silently.",
+	CheckDeprecatedAPIs:           "The apiVersion is deprecated or no longer served by the targeted Kubernetes release.",
+	CheckOfflineBundle:            "The object fails a check of the offline bundle of a cluster's schemas, CRDs, and deprecations.",
+	RuleDecode:                    "The document could not be decoded into a Kubernetes object.",
+	RuleEngineError:               "Validation of the document panicked or ran out of time.",
+}
+
+// RuleUnspecified is the rule code reports use for findings that carry no rule code.
+const RuleUnspecified = "Constraint"
+
+// RuleDescription returns the help text of a rule code, or a generic description for rules
+// that have none.
+func RuleDescription(rule string) string {
+	if description, ok := ruleDescriptions[rule]; ok {
+		return description
+	}
+	return "The manifest violates a Kubernetes constraint."
+}
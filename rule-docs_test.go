@@ -0,0 +1,114 @@
+package k8sconstraints
+
+import "testing"
+
+func TestValidateObjectRulesAreDocumented(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+	}{
+		{"Service", `
+apiVersion: v1
+kind: Service
+metadata: {name: e}
+spec:
+  type: ExternalName
+  externalName: Bad_Name
+  clusterIP: 10.0.0.1
+  ports: [{port: 80, targetPort: 0}, {port: 80, protocol: Bad}]`},
+		{"Ingress", `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata: {name: i}
+spec:
+  tls: [{hosts: [other.example.com]}]
+  rules: [{host: web.example.com, http: {paths: [{path: relative, pathType: Bad, backend: {}}]}}]`},
+		{"Role", `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata: {name: r}
+rules: [{verbs: [], apiGroups: [], resources: []}, {verbs: [get], nonResourceURLs: [/x]}]`},
+		{"Secret", `
+apiVersion: v1
+kind: Secret
+metadata: {name: s}
+type: kubernetes.io/tls
+data: {"bad key": not base64!}`},
+		{"Deployment", `
+apiVersion: apps/v1
+kind: Deployment
+metadata: {name: web, generateName: api-}
+spec:
+  selector: {matchLabels: {app: web}}
+  strategy: {type: Recreate, rollingUpdate: {maxSurge: 1}}
+  template:
+    metadata: {labels: {app: web}}
+    spec:
+      dnsPolicy: None
+      overhead: {cpu: 100m}
+      affinity: {podAffinity: {requiredDuringSchedulingIgnoredDuringExecution: [{labelSelector: {}}]}}
+      securityContext: {runAsUser: -1, seccompProfile: {type: Localhost}}
+      containers:
+      - name: web
+        image: "nginx:1.27"
+        resources: {requests: {cpu: "2"}, limits: {cpu: "1"}}
+        livenessProbe: {periodSeconds: 0}
+        volumeMounts: [{name: data, mountPath: relative}]
+      - name: web
+        image: " nginx"`},
+		{"CronJob", `
+apiVersion: batch/v1
+kind: CronJob
+metadata: {name: c}
+spec:
+  schedule: "61 * * * *"
+  concurrencyPolicy: Sometimes
+  startingDeadlineSeconds: -1
+  jobTemplate: {spec: {backoffLimit: -1, template: {spec: {restartPolicy: Always, containers: [{name: c, image: busybox}]}}}}`},
+		{"HorizontalPodAutoscaler", `
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata: {name: h}
+spec:
+  minReplicas: 5
+  maxReplicas: 2
+  scaleTargetRef: {apiVersion: apps/v1, kind: Deployment, name: web}
+  metrics: [{type: Resource}]`},
+		{"ResourceQuota", `
+apiVersion: v1
+kind: ResourceQuota
+metadata: {name: q}
+spec:
+  scopes: [Terminating, NotTerminating]
+  hard: {pods: "-1", widgets: "1"}`},
+		{"LimitRange", `
+apiVersion: v1
+kind: LimitRange
+metadata: {name: l}
+spec:
+  limits: [{type: Pod, default: {cpu: 1}, maxLimitRequestRatio: {cpu: "0.5"}}]`},
+		{"Lease", `
+apiVersion: coordination.k8s.io/v1
+kind: Lease
+metadata: {name: l}
+spec: {leaseDurationSeconds: 0, leaseTransitions: -1}`},
+		{"List", `
+apiVersion: v1
+kind: List
+items: [not an object]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := ConstraintErrors(ValidateObject(decodeTestObject(t, tt.manifest)))
+			if len(violations) == 0 {
+				t.Fatal("expected findings")
+			}
+			for _, violation := range violations {
+				if _, ok := ruleDescriptions[violation.Rule]; !ok {
+					t.Errorf("rule %q of finding %q has no description", violation.Rule, violation.Error())
+				}
+			}
+		})
+	}
+}
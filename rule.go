@@ -0,0 +1,88 @@
+package k8sconstraints
+
+import "sync"
+
+// Object is a decoded Kubernetes object, as produced by the document sources.
+type Object = map[string]interface{}
+
+// Rule is an organization-specific constraint compiled into a build of k8sconstraints.
+// Registered rules run against every object alongside the built-in checks, including the
+// items of List documents; see RegisterRule.
+type Rule interface {
+	// ID identifies the rule. It is the default rule code of its findings.
+	ID() string
+	// Check returns the violations of the rule in obj. Field paths are relative to obj.
+	// Rule, Severity, Resource, and Fingerprint are filled in when left empty, with
+	// Severity derived from a "warning: " message prefix as for built-in findings.
+	Check(obj Object) []Finding
+}
+
+var (
+	rulesMu sync.RWMutex
+	// rules holds the registered rules in registration order
+	rules []Rule
+)
+
+// RegisterRule adds rule to the rules run against every validated document, replacing any
+// rule registered under the same ID. Call it from an init function of the package defining
+// the rule.
+func RegisterRule(rule Rule) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	for i, registered := range rules {
+		if registered.ID() == rule.ID() {
+			rules[i] = rule
+			return
+		}
+	}
+	rules = append(rules, rule)
+}
+
+// UnregisterRule removes the rule registered under id, if any.
+func UnregisterRule(id string) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	for i, registered := range rules {
+		if registered.ID() == id {
+			rules = append(rules[:i:i], rules[i+1:]...)
+			return
+		}
+	}
+}
+
+// Rules returns the registered rules in registration order.
+func Rules() []Rule {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	return append([]Rule(nil), rules...)
+}
+
+// RuleFindings runs the registered rules against obj and completes their findings.
+func RuleFindings(obj Object) []Finding {
+	return ruleFindings(obj, "")
+}
+
+// ruleFindings runs the registered rules against obj, prefixing their field paths with
+// path, such as the path of a List item, and completes their findings.
+func ruleFindings(obj Object, path string) []Finding {
+	findings := make([]Finding, 0)
+	for _, rule := range Rules() {
+		for _, finding := range rule.Check(obj) {
+			finding.FieldPath = joinFieldPath(path, finding.FieldPath)
+			if finding.Rule == "" {
+				finding.Rule = rule.ID()
+			}
+			if finding.Severity == "" {
+				finding.Severity = severityOf(finding.Message)
+			}
+			if finding.Resource == (ResourceRef{}) {
+				finding.Resource = ResourceRefOf(obj)
+			}
+			if finding.Fingerprint == "" {
+				finding.Fingerprint = Fingerprint(finding)
+			}
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
@@ -0,0 +1,151 @@
+package k8sconstraints
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+)
+
+// FormatSARIF renders a report as SARIF 2.1.0 for code scanning integrations.
+const FormatSARIF = "sarif"
+
+// SARIF 2.1.0 document structure, limited to the properties WriteReport emits.
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+		Help             sarifMessage `json:"help"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID              string            `json:"ruleId"`
+		RuleIndex           int               `json:"ruleIndex"`
+		Level               string            `json:"level"`
+		Message             sarifMessage      `json:"message"`
+		Locations           []sarifLocation   `json:"locations,omitempty"`
+		PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine int `json:"startLine"`
+	}
+)
+
+// writeSARIFReport encodes the report as a SARIF 2.1.0 log with one run. Every rule code
+// becomes a SARIF rule with its help text, and finding fingerprints are passed along as
+// partial fingerprints so code scanning tracks findings across commits.
+func writeSARIFReport(w io.Writer, report Report) error {
+	ruleIDs := make(map[string]bool)
+	for _, finding := range report.Findings {
+		ruleIDs[sarifRuleID(finding)] = true
+	}
+	ids := sortedKeys(ruleIDs)
+
+	rules := make([]sarifRule, len(ids))
+	ruleIndex := make(map[string]int, len(ids))
+	for i, id := range ids {
+		description := RuleDescription(id)
+		rules[i] = sarifRule{ID: id, ShortDescription: sarifMessage{Text: description}, Help: sarifMessage{Text: description}}
+		ruleIndex[id] = i
+	}
+
+	results := make([]sarifResult, 0, len(report.Findings))
+	for _, finding := range report.Findings {
+		id := sarifRuleID(finding)
+		result := sarifResult{
+			RuleID:    id,
+			RuleIndex: ruleIndex[id],
+			Level:     sarifLevel(finding.Severity),
+			Message:   sarifMessage{Text: sarifMessageText(finding)},
+		}
+		if finding.File != "" {
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(finding.File)}}}
+			if finding.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: finding.Line}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+		if finding.Fingerprint != "" {
+			result.PartialFingerprints = map[string]string{"k8sconstraints/v1": finding.Fingerprint}
+		}
+		results = append(results, result)
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "k8sconstraints",
+				InformationURI: "https://github.com/martinflemingdev/k8s_constraints",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(log)
+}
+
+// sarifRuleID returns the SARIF rule id of a finding.
+func sarifRuleID(finding Finding) string {
+	if finding.Rule == "" {
+		return RuleUnspecified
+	}
+	return finding.Rule
+}
+
+// sarifLevel maps a severity to a SARIF result level.
+func sarifLevel(severity Severity) string {
+	switch severity {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "note"
+	}
+	return "error"
+}
+
+// sarifMessageText renders a finding's message with its resource and field path, since
+// SARIF locations only point at lines.
+func sarifMessageText(finding Finding) string {
+	text := finding.Message
+	if finding.FieldPath != "" {
+		text = finding.FieldPath + ": " + text
+	}
+	if resource := finding.Resource.String(); resource != "" {
+		text = resource + ": " + text
+	}
+	return text
+}
@@ -0,0 +1,148 @@
+//go:build ignore
+
+// generate writes the OpenAPI definitions embedded by k8sconstraints: the definitions of
+// api/openapi-spec/swagger.json of a Kubernetes release, gzip-compressed, with descriptions
+// and the x-kubernetes-* extensions the validator does not use removed. Only the
+// extensions of schemas are removed; properties named after them, such as the
+// x-kubernetes-validations field of CustomResourceDefinition schemas, are kept.
+//
+//	go run schemas/generate.go -version v1.36.3 -o schemas/kubernetes-1.36.json.gz
+//
+// -in reads swagger.json from a file instead of downloading it from GitHub.
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// unusedExtensions are the schema extensions left out of the embedded definitions.
+var unusedExtensions = []string{
+	"x-kubernetes-list-map-keys",
+	"x-kubernetes-list-type",
+	"x-kubernetes-map-type",
+	"x-kubernetes-patch-merge-key",
+	"x-kubernetes-patch-strategy",
+	"x-kubernetes-selectable-fields",
+	"x-kubernetes-unions",
+	"x-kubernetes-validations",
+}
+
+func main() {
+	version := flag.String("version", "", "Kubernetes release tag to download swagger.json of, e.g. v1.36.3")
+	in := flag.String("in", "", "read swagger.json from this file instead of downloading it")
+	out := flag.String("o", "", "file to write the gzip-compressed definitions to")
+	flag.Parse()
+	if (*version == "") == (*in == "") || *out == "" {
+		fmt.Fprintln(os.Stderr, "usage: go run schemas/generate.go (-version <tag> | -in <swagger.json>) -o <file>")
+		os.Exit(2)
+	}
+
+	if err := generate(*version, *in, *out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// generate reads swagger.json, from in or from the release tagged version, and writes its
+// cleaned definitions to out.
+func generate(version string, in string, out string) error {
+	data, err := readSwagger(version, in)
+	if err != nil {
+		return err
+	}
+
+	var document struct {
+		Definitions map[string]map[string]interface{} `json:"definitions"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return fmt.Errorf("invalid swagger.json: %v", err)
+	}
+	if len(document.Definitions) == 0 {
+		return fmt.Errorf("swagger.json has no definitions")
+	}
+	for _, definition := range document.Definitions {
+		cleanSchema(definition)
+	}
+
+	// Maps are encoded with sorted keys, so the output only changes with its input
+	cleaned, err := json.Marshal(map[string]interface{}{"definitions": document.Definitions})
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	writer, err := gzip.NewWriterLevel(file, gzip.BestCompression)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if _, err := writer.Write(cleaned); err != nil {
+		file.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// readSwagger reads swagger.json from in, or downloads that of the release tagged version.
+func readSwagger(version string, in string) ([]byte, error) {
+	if in != "" {
+		return os.ReadFile(in)
+	}
+	url := fmt.Sprintf("https://raw.githubusercontent.com/kubernetes/kubernetes/%s/api/openapi-spec/swagger.json", version)
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// cleanSchema removes the description and unused extensions of schema and of the schemas
+// nested in it, leaving the names of its properties alone.
+func cleanSchema(schema map[string]interface{}) {
+	if _, ok := schema["description"].(string); ok {
+		delete(schema, "description")
+	}
+	for _, extension := range unusedExtensions {
+		delete(schema, extension)
+	}
+
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		for _, property := range properties {
+			if property, ok := property.(map[string]interface{}); ok {
+				cleanSchema(property)
+			}
+		}
+	}
+	for _, key := range []string{"items", "additionalProperties"} {
+		if nested, ok := schema[key].(map[string]interface{}); ok {
+			cleanSchema(nested)
+		}
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		nested, _ := schema[key].([]interface{})
+		for _, item := range nested {
+			if item, ok := item.(map[string]interface{}); ok {
+				cleanSchema(item)
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package k8sconstraints
+
+import "math"
+
+// Default weights deducted from a resource's score of 100 per finding.
+const (
+	defaultErrorWeight   = 10
+	defaultWarningWeight = 3
+	defaultInfoWeight    = 0
+)
+
+// ScoringOptions configures ScoreReport. Each finding deducts its weight from the score of
+// the resource it was reported against; findings collapsed into another finding as Related
+// do not count separately.
+type ScoringOptions struct {
+	// SeverityWeights maps severities to weights. Missing severities use the defaults:
+	// 10 for errors, 3 for warnings, and 0 for info findings.
+	SeverityWeights map[Severity]float64 `json:"severityWeights,omitempty"`
+	// RuleWeights overrides the weight of individual rule codes.
+	RuleWeights map[string]float64 `json:"ruleWeights,omitempty"`
+}
+
+// weight returns the score deducted for a finding.
+func (o ScoringOptions) weight(finding Finding) float64 {
+	if weight, ok := o.RuleWeights[finding.Rule]; ok {
+		return weight
+	}
+	if weight, ok := o.SeverityWeights[finding.Severity]; ok {
+		return weight
+	}
+	switch finding.Severity {
+	case SeverityWarning:
+		return defaultWarningWeight
+	case SeverityInfo:
+		return defaultInfoWeight
+	}
+	return defaultErrorWeight
+}
+
+// ResourceScore is the score of one resource in a report.
+type ResourceScore struct {
+	ReportResource
+	Score    float64 `json:"score"`
+	Grade    string  `json:"grade"`
+	Findings int     `json:"findings"`
+}
+
+// ScoreSummary holds the per-resource scores of a report and the bundle score, which is the
+// mean of the resource scores.
+type ScoreSummary struct {
+	Score     float64         `json:"score"`
+	Grade     string          `json:"grade"`
+	Resources []ResourceScore `json:"resources"`
+}
+
+// ScoreReport scores every resource of report on a scale of 0 to 100 and grades it from A
+// to F, similar to kube-score. Findings not tied to a listed resource, such as documents
+// that failed to decode, are scored as a resource of their own.
+func ScoreReport(report Report, opts ScoringOptions) ScoreSummary {
+	type documentKey struct {
+		file     string
+		document int
+		resource ResourceRef
+	}
+
+	scores := make([]ResourceScore, 0, len(report.Resources))
+	index := make(map[documentKey]int)
+	for _, resource := range report.Resources {
+		key := documentKey{resource.File, resource.Document, resource.Resource}
+		if _, ok := index[key]; ok {
+			continue
+		}
+		index[key] = len(scores)
+		scores = append(scores, ResourceScore{ReportResource: resource, Score: 100})
+	}
+
+	for _, finding := range report.Findings {
+		key := documentKey{finding.File, finding.Document, finding.Resource}
+		i, ok := index[key]
+		if !ok {
+			i = len(scores)
+			index[key] = i
+			scores = append(scores, ResourceScore{
+				ReportResource: ReportResource{File: finding.File, Document: finding.Document, Line: finding.Line, Resource: finding.Resource},
+				Score:          100,
+			})
+		}
+		scores[i].Score = math.Max(0, scores[i].Score-opts.weight(finding))
+		scores[i].Findings++
+	}
+
+	summary := ScoreSummary{Score: 100, Resources: scores}
+	if len(scores) > 0 {
+		total := 0.0
+		for i := range scores {
+			scores[i].Grade = Grade(scores[i].Score)
+			total += scores[i].Score
+		}
+		summary.Score = math.Round(total/float64(len(scores))*10) / 10
+	}
+	summary.Grade = Grade(summary.Score)
+	return summary
+}
+
+// Grade converts a score between 0 and 100 into a letter grade: A from 90, B from 80, C from
+// 70, D from 60, and F below.
+func Grade(score float64) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	}
+	return "F"
+}
@@ -0,0 +1,176 @@
+package k8sconstraints
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Built-in Secret types.
+const (
+	SecretTypeOpaque              = "Opaque"
+	SecretTypeServiceAccountToken = "kubernetes.io/service-account-token"
+	SecretTypeDockercfg           = "kubernetes.io/dockercfg"
+	SecretTypeDockerConfigJSON    = "kubernetes.io/dockerconfigjson"
+	SecretTypeBasicAuth           = "kubernetes.io/basic-auth"
+	SecretTypeSSHAuth             = "kubernetes.io/ssh-auth"
+	SecretTypeTLS                 = "kubernetes.io/tls"
+	SecretTypeBootstrapToken      = "bootstrap.kubernetes.io/token"
+)
+
+// maxSecretKeyLength is the longest key Kubernetes accepts in the data of a Secret.
+const maxSecretKeyLength = 253
+
+// secretTypes lists the built-in Secret types.
+var secretTypes = []string{
+	SecretTypeOpaque, SecretTypeServiceAccountToken, SecretTypeDockercfg, SecretTypeDockerConfigJSON,
+	SecretTypeBasicAuth, SecretTypeSSHAuth, SecretTypeTLS, SecretTypeBootstrapToken,
+}
+
+// secretKeyPattern matches a key of the data or stringData of a Secret.
+var secretKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// ValidateSecret validates a v1 Secret: data and stringData keys are valid, data values are
+// base64, and the type, when built in, has its required keys with well-formed values:
+// tls.crt and tls.key for kubernetes.io/tls, a JSON .dockerconfigjson or .dockercfg for the
+// docker config types, username or password for kubernetes.io/basic-auth, and
+// ssh-privatekey for kubernetes.io/ssh-auth. TLS keys not holding PEM data are reported as
+// warnings, as the API server only requires the keys to be present. Other types are
+// allowed, as Secrets may carry custom types, except under the reserved kubernetes.io/
+// prefix; those close to a built-in type are reported as warnings, as they are most likely
+// misspelled. Findings never include secret values.
+func ValidateSecret(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	// Keys and values; stringData is merged over data by the API server. Values that are not
+	// base64 are kept as nil, so their keys count as present without their contents being
+	// checked.
+	values := make(map[string][]byte)
+	data, _ := nestedMap(obj, "data")
+	for _, key := range sortedKeys(data) {
+		path := NewPath("data").Key(key)
+		if err := validateSecretKey(key); err != nil {
+			errs = append(errs, WithFieldPath(path.String(), err))
+		}
+		encoded, _ := data[key].(string)
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			errs = append(errs, &ConstraintError{FieldPath: path.String(), Message: "value must be base64 encoded; put plain text values in stringData instead"})
+			decoded = nil
+		}
+		values[key] = decoded
+	}
+	stringData, _ := nestedMap(obj, "stringData")
+	for _, key := range sortedKeys(stringData) {
+		if err := validateSecretKey(key); err != nil {
+			errs = append(errs, WithFieldPath(NewPath("stringData").Key(key).String(), err))
+		}
+		value, _ := stringData[key].(string)
+		values[key] = []byte(value)
+	}
+
+	secretType, _ := nestedString(obj, "type")
+	if err := validateSecretType(secretType); err != nil {
+		errs = append(errs, WithFieldPath("type", err))
+	}
+
+	// Type-specific keys
+	switch secretType {
+	case SecretTypeTLS:
+		for _, key := range []string{"tls.crt", "tls.key"} {
+			if err := validateSecretPEM(obj, values, key); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	case SecretTypeDockerConfigJSON, SecretTypeDockercfg:
+		key := ".dockerconfigjson"
+		if secretType == SecretTypeDockercfg {
+			key = ".dockercfg"
+		}
+		value, ok := values[key]
+		if !ok {
+			errs = append(errs, Required(NewPath("data").Key(key), fmt.Sprintf("%s is required for type %s", key, secretType)))
+			break
+		}
+		config := make(map[string]interface{})
+		if err := json.Unmarshal(value, &config); value != nil && err != nil {
+			errs = append(errs, &ConstraintError{FieldPath: secretValuePath(obj, key), Message: fmt.Sprintf("%s must be a JSON object, such as the contents of ~/.docker/config.json", key)})
+		}
+	case SecretTypeBasicAuth:
+		_, hasUsername := values["username"]
+		_, hasPassword := values["password"]
+		if !hasUsername && !hasPassword {
+			errs = append(errs, Required(NewPath("data"), fmt.Sprintf("username or password is required for type %s", secretType)))
+		}
+	case SecretTypeSSHAuth:
+		if _, ok := values["ssh-privatekey"]; !ok {
+			errs = append(errs, Required(NewPath("data").Key("ssh-privatekey"), fmt.Sprintf("ssh-privatekey is required for type %s", secretType)))
+		}
+	case SecretTypeServiceAccountToken:
+		if name, _ := nestedString(obj, "metadata", "annotations", "kubernetes.io/service-account.name"); name == "" {
+			errs = append(errs, Required(NewPath("metadata", "annotations").Key("kubernetes.io/service-account.name"), fmt.Sprintf("the kubernetes.io/service-account.name annotation is required for type %s", secretType)))
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleSecret, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validateSecretKey checks a key of the data or stringData of a Secret: at most 253
+// alphanumeric characters, '-', '_', or '.', other than "." and "..".
+func validateSecretKey(key string) error {
+	switch {
+	case len(key) > maxSecretKeyLength:
+		return &ConstraintError{Rule: RuleMaxLength, BadValue: key, Message: fmt.Sprintf("key must be no more than %d characters", maxSecretKeyLength)}
+	case key == "." || key == "..":
+		return &ConstraintError{BadValue: key, Message: "key must not be '.' or '..'"}
+	case !secretKeyPattern.MatchString(key):
+		return &ConstraintError{BadValue: key, Message: "key must consist of alphanumeric characters, '-', '_', or '.'"}
+	}
+	return nil
+}
+
+// validateSecretType checks the type of a Secret. Built-in types are matched case
+// sensitively; custom types may not use the kubernetes.io/ prefix, and those close to a
+// built-in type are reported as warnings.
+func validateSecretType(secretType string) error {
+	if secretType == "" || containsString(secretTypes, secretType) {
+		return nil
+	}
+	if strings.HasPrefix(secretType, "kubernetes.io/") {
+		return enumViolation("type", secretType, secretTypes)
+	}
+	if suggestion, ok := closestEnumValue(secretType, secretTypes); ok {
+		return &ConstraintError{BadValue: secretType, Message: fmt.Sprintf("warning: type '%s' is not a built-in Secret type, so its keys are not checked; did you mean '%s'?", secretType, suggestion), Fix: enumCaseFix("", secretTypes, secretType)}
+	}
+	return nil
+}
+
+// validateSecretPEM checks that the Secret value under key is present, warning when it does
+// not hold PEM data.
+func validateSecretPEM(obj map[string]interface{}, values map[string][]byte, key string) error {
+	value, ok := values[key]
+	if !ok {
+		return Required(NewPath("data").Key(key), fmt.Sprintf("%s is required for type %s", key, SecretTypeTLS))
+	}
+	if block, _ := pem.Decode(value); value != nil && block == nil {
+		return &ConstraintError{FieldPath: secretValuePath(obj, key), Message: fmt.Sprintf("warning: %s should hold PEM encoded data, starting with a -----BEGIN line", key)}
+	}
+	return nil
+}
+
+// secretValuePath returns the path of the Secret value under key: in stringData when set
+// there, which takes precedence, and in data otherwise.
+func secretValuePath(obj map[string]interface{}, key string) string {
+	if _, ok := nestedField(obj, "stringData", key); ok {
+		return NewPath("stringData").Key(key).String()
+	}
+	return NewPath("data").Key(key).String()
+}
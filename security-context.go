@@ -0,0 +1,219 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// linuxCapabilities lists the Linux capabilities container runtimes accept, without their
+// CAP_ prefix, along with ALL.
+var linuxCapabilities = []string{
+	"ALL",
+	"AUDIT_CONTROL", "AUDIT_READ", "AUDIT_WRITE", "BLOCK_SUSPEND", "BPF", "CHECKPOINT_RESTORE",
+	"CHOWN", "DAC_OVERRIDE", "DAC_READ_SEARCH", "FOWNER", "FSETID", "IPC_LOCK", "IPC_OWNER",
+	"KILL", "LEASE", "LINUX_IMMUTABLE", "MAC_ADMIN", "MAC_OVERRIDE", "MKNOD", "NET_ADMIN",
+	"NET_BIND_SERVICE", "NET_BROADCAST", "NET_RAW", "PERFMON", "SETFCAP", "SETGID", "SETPCAP",
+	"SETUID", "SYSLOG", "SYS_ADMIN", "SYS_BOOT", "SYS_CHROOT", "SYS_MODULE", "SYS_NICE",
+	"SYS_PACCT", "SYS_PTRACE", "SYS_RAWIO", "SYS_RESOURCE", "SYS_TIME", "SYS_TTY_CONFIG",
+	"WAKE_ALARM",
+}
+
+// Enumerated securityContext values accepted by the API server.
+var (
+	procMountTypes           = []string{"Default", "Unmasked"}
+	profileTypes             = []string{"RuntimeDefault", "Unconfined", "Localhost"}
+	fsGroupChangePolicies    = []string{"OnRootMismatch", "Always"}
+	supplementalGroupsPolicy = []string{"Merge", "Strict"}
+)
+
+// ValidatePodSecurityContext validates a pod securityContext: runAsUser, runAsGroup,
+// fsGroup, and supplementalGroups must be IDs between 0 and 2147483647, runAsNonRoot must
+// not contradict runAsUser, fsGroupChangePolicy and supplementalGroupsPolicy must be
+// supported values, and seccompProfile and appArmorProfile must pass
+// ValidateSecurityProfile.
+func ValidatePodSecurityContext(securityContext map[string]interface{}) error {
+	errs := make([]error, 0)
+	for _, field := range []string{"runAsUser", "runAsGroup", "fsGroup"} {
+		if err := validateSecurityContextID(securityContext, field); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	groups, _ := nestedSlice(securityContext, "supplementalGroups")
+	for i, group := range groups {
+		if err := validateUserOrGroupID(group); err != nil {
+			errs = append(errs, WithFieldPath(fmt.Sprintf("supplementalGroups[%d]", i), err))
+		}
+	}
+	if err := validateRunAsNonRoot(securityContext); err != nil {
+		errs = append(errs, err)
+	}
+
+	if policy, ok := nestedString(securityContext, "fsGroupChangePolicy"); ok {
+		if err := validateEnumField("fsGroupChangePolicy", policy, fsGroupChangePolicies); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if policy, ok := nestedString(securityContext, "supplementalGroupsPolicy"); ok {
+		if err := validateEnumField("supplementalGroupsPolicy", policy, supplementalGroupsPolicy); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := validateSecurityProfiles(securityContext); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return withDefaultRule(RuleSecurityContext, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// ValidateContainerSecurityContext validates a container securityContext: runAsUser and
+// runAsGroup must be IDs between 0 and 2147483647, runAsNonRoot must not contradict
+// runAsUser, capabilities must be known Linux capabilities, procMount must be Default or
+// Unmasked, and seccompProfile and appArmorProfile must pass ValidateSecurityProfile.
+// Privileged containers and containers adding SYS_ADMIN always gain privileges, so they
+// may not also disable privilege escalation, which the API server rejects.
+func ValidateContainerSecurityContext(securityContext map[string]interface{}) error {
+	errs := make([]error, 0)
+	for _, field := range []string{"runAsUser", "runAsGroup"} {
+		if err := validateSecurityContextID(securityContext, field); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := validateRunAsNonRoot(securityContext); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, field := range []string{"add", "drop"} {
+		capabilities, _ := nestedSlice(securityContext, "capabilities", field)
+		for i, raw := range capabilities {
+			capability, _ := raw.(string)
+			if err := ValidateCapability(capability); err != nil {
+				errs = append(errs, WithFieldPath(fmt.Sprintf("capabilities.%s[%d]", field, i), err))
+			}
+		}
+	}
+	if procMount, ok := nestedString(securityContext, "procMount"); ok {
+		if err := validateEnumField("procMount", procMount, procMountTypes); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := validateSecurityProfiles(securityContext); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Privilege escalation
+	privileged, _ := nestedBool(securityContext, "privileged")
+	allowPrivilegeEscalation, ok := nestedBool(securityContext, "allowPrivilegeEscalation")
+	if ok && !allowPrivilegeEscalation {
+		added, _ := nestedSlice(securityContext, "capabilities", "add")
+		switch {
+		case privileged:
+			errs = append(errs, errors.New("allowPrivilegeEscalation cannot be false when privileged is true"))
+		case containsValue(added, "SYS_ADMIN") || containsValue(added, "CAP_SYS_ADMIN"):
+			errs = append(errs, errors.New("allowPrivilegeEscalation cannot be false when capabilities.add includes SYS_ADMIN"))
+		}
+	}
+
+	if len(errs) > 0 {
+		return withDefaultRule(RuleSecurityContext, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// ValidateCapability validates a Linux capability name of a container securityContext,
+// such as NET_ADMIN, or ALL. Names with the CAP_ prefix work with common runtimes but are
+// reported as warnings, as the Kubernetes convention omits it.
+func ValidateCapability(capability string) error {
+	if containsString(linuxCapabilities, capability) {
+		return nil
+	}
+	if name, ok := strings.CutPrefix(strings.ToUpper(capability), "CAP_"); ok && containsString(linuxCapabilities, name) && name != "ALL" {
+		return &ConstraintError{BadValue: capability, Message: fmt.Sprintf("warning: capability '%s' should be written without the CAP_ prefix, as '%s'", capability, name), Fix: []FieldChange{
+			{Op: PatchTest, Value: capability},
+			{Op: PatchReplace, Value: name},
+		}}
+	}
+	return &ConstraintError{BadValue: capability, Message: fmt.Sprintf("capability '%s' is not a known Linux capability, such as NET_ADMIN or ALL", capability), Fix: enumCaseFix("", linuxCapabilities, capability)}
+}
+
+// validateSecurityProfiles validates the seccompProfile and appArmorProfile of a pod or
+// container securityContext.
+func validateSecurityProfiles(securityContext map[string]interface{}) error {
+	errs := make([]error, 0)
+	for _, field := range []string{"seccompProfile", "appArmorProfile"} {
+		if profile, ok := nestedMap(securityContext, field); ok {
+			if err := ValidateSecurityProfile(profile); err != nil {
+				errs = append(errs, WithFieldPath(field, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// ValidateSecurityProfile validates a seccompProfile or appArmorProfile: its type must be
+// RuntimeDefault, Unconfined, or Localhost, and localhostProfile must be set exactly when
+// the type is Localhost.
+func ValidateSecurityProfile(profile map[string]interface{}) error {
+	profileType, ok := nestedString(profile, "type")
+	localhostProfile, _ := nestedString(profile, "localhostProfile")
+	switch {
+	case !ok || profileType == "":
+		return &ConstraintError{FieldPath: "type", Rule: RuleRequired, Message: fmt.Sprintf("type is required; must be one of: %s", strings.Join(profileTypes, ", "))}
+	case !containsString(profileTypes, profileType):
+		return validateEnumField("type", profileType, profileTypes)
+	case profileType == "Localhost" && localhostProfile == "":
+		return &ConstraintError{FieldPath: "localhostProfile", Rule: RuleRequired, Message: "localhostProfile is required when type is Localhost"}
+	case profileType != "Localhost" && localhostProfile != "":
+		return &ConstraintError{FieldPath: "localhostProfile", Rule: RuleSecurityContext, BadValue: localhostProfile, Message: fmt.Sprintf("localhostProfile may only be set when type is Localhost, not %s", profileType)}
+	}
+	return nil
+}
+
+// validateSecurityContextID checks that securityContext[field], if set, is an integer
+// between 0 and 2147483647.
+func validateSecurityContextID(securityContext map[string]interface{}, field string) error {
+	value, ok := nestedField(securityContext, field)
+	if !ok {
+		return nil
+	}
+	if err := validateUserOrGroupID(value); err != nil {
+		return WithFieldPath(field, err)
+	}
+	return nil
+}
+
+// validateUserOrGroupID checks that value is an integer between 0 and 2147483647.
+func validateUserOrGroupID(value interface{}) error {
+	id, ok := toInt64(value)
+	if !ok {
+		return &ConstraintError{BadValue: value, Message: "must be an integer"}
+	}
+	if id < 0 || id > maxUserID {
+		return &ConstraintError{BadValue: value, Message: fmt.Sprintf("must be between 0 and %d", maxUserID)}
+	}
+	return nil
+}
+
+// validateRunAsNonRoot reports runAsNonRoot set together with runAsUser 0, which the
+// kubelet refuses to start.
+func validateRunAsNonRoot(securityContext map[string]interface{}) error {
+	nonRoot, _ := nestedBool(securityContext, "runAsNonRoot")
+	user, ok := nestedField(securityContext, "runAsUser")
+	if !nonRoot || !ok {
+		return nil
+	}
+	if id, ok := toInt64(user); ok && id == 0 {
+		return errors.New("runAsUser cannot be 0 when runAsNonRoot is true")
+	}
+	return nil
+}
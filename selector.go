@@ -0,0 +1,291 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SelectorOperator is the operator of a label selector requirement.
+type SelectorOperator string
+
+// Operators of label selector requirements, in the string syntax of kubectl --selector.
+const (
+	SelectorEquals       SelectorOperator = "="
+	SelectorDoubleEquals SelectorOperator = "=="
+	SelectorNotEquals    SelectorOperator = "!="
+	SelectorIn           SelectorOperator = "in"
+	SelectorNotIn        SelectorOperator = "notin"
+	SelectorExists       SelectorOperator = "exists"
+	SelectorDoesNotExist SelectorOperator = "!"
+	SelectorGreaterThan  SelectorOperator = "gt"
+	SelectorLessThan     SelectorOperator = "lt"
+)
+
+// SelectorRequirement is a single requirement of a label selector, such as "tier in
+// (frontend,backend)".
+type SelectorRequirement struct {
+	Key      string
+	Operator SelectorOperator
+	// Values holds the value of equality and comparison requirements, the sorted set of
+	// set-based requirements, and nothing for existence requirements.
+	Values []string
+}
+
+// String renders the requirement in selector syntax.
+func (r SelectorRequirement) String() string {
+	switch r.Operator {
+	case SelectorExists:
+		return r.Key
+	case SelectorDoesNotExist:
+		return "!" + r.Key
+	case SelectorIn, SelectorNotIn:
+		return fmt.Sprintf("%s %s (%s)", r.Key, r.Operator, strings.Join(r.Values, ","))
+	case SelectorGreaterThan:
+		return r.Key + ">" + strings.Join(r.Values, "")
+	case SelectorLessThan:
+		return r.Key + "<" + strings.Join(r.Values, "")
+	}
+	return r.Key + string(r.Operator) + strings.Join(r.Values, "")
+}
+
+// Matches reports whether labels satisfy the requirement.
+func (r SelectorRequirement) Matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case SelectorExists:
+		return ok
+	case SelectorDoesNotExist:
+		return !ok
+	case SelectorEquals, SelectorDoubleEquals, SelectorIn:
+		return ok && containsString(r.Values, value)
+	case SelectorNotEquals, SelectorNotIn:
+		return !ok || !containsString(r.Values, value)
+	case SelectorGreaterThan, SelectorLessThan:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if !ok || err != nil || len(r.Values) != 1 {
+			return false
+		}
+		bound, _ := strconv.ParseInt(r.Values[0], 10, 64)
+		if r.Operator == SelectorGreaterThan {
+			return n > bound
+		}
+		return n < bound
+	}
+	return false
+}
+
+// Selector is a parsed label selector: a conjunction of requirements.
+type Selector []SelectorRequirement
+
+// String renders the selector in selector syntax.
+func (s Selector) String() string {
+	parts := make([]string, len(s))
+	for i, requirement := range s {
+		parts[i] = requirement.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// Matches reports whether labels satisfy every requirement of the selector. The empty
+// selector matches everything.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, requirement := range s {
+		if !requirement.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseSelector parses and validates a label selector in the string syntax of kubectl
+// --selector, e.g. "app=web,tier in (frontend,backend),!canary". Requirements are separated
+// by commas and take the forms key, !key, key=value, key==value, key!=value,
+// key in (v1,v2), key notin (v1,v2), key>n, and key<n. Keys must be valid label keys,
+// values valid label values, and the bounds of > and < integers. The empty string parses
+// to the empty selector.
+func ParseSelector(selector string) (Selector, error) {
+	p := &selectorParser{input: selector}
+	parsed := make(Selector, 0)
+	errs := make([]error, 0)
+
+	p.skipSpace()
+	if p.done() {
+		return parsed, nil
+	}
+	for {
+		start := p.pos
+		requirement, err := p.requirement()
+		if err != nil {
+			return nil, &ConstraintError{Rule: RuleSelector, BadValue: selector, Message: fmt.Sprintf("invalid selector '%s': %v", selector, err)}
+		}
+		if err := validateSelectorRequirement(requirement); err != nil {
+			errs = append(errs, withMessagePrefix(fmt.Sprintf("requirement '%s': ", strings.TrimSpace(selector[start:p.pos])), err))
+		}
+		parsed = append(parsed, requirement)
+
+		p.skipSpace()
+		if p.done() {
+			break
+		}
+		if !p.consume(",") {
+			return nil, &ConstraintError{Rule: RuleSelector, BadValue: selector, Message: fmt.Sprintf("invalid selector '%s': expected ',' at position %d", selector, p.pos)}
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return nil, JoinErrors(errs)
+	}
+
+	return parsed, nil
+}
+
+// ValidateSelector validates a label selector string; see ParseSelector.
+func ValidateSelector(selector string) error {
+	_, err := ParseSelector(selector)
+	return err
+}
+
+// validateSelectorRequirement checks the key and values of a parsed requirement.
+func validateSelectorRequirement(requirement SelectorRequirement) error {
+	errs := make([]error, 0)
+	if err := ValidateLabelKey(requirement.Key); err != nil {
+		errs = append(errs, withMessagePrefix("invalid label key: ", err))
+	}
+	for _, value := range requirement.Values {
+		if requirement.Operator == SelectorGreaterThan || requirement.Operator == SelectorLessThan {
+			if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+				errs = append(errs, &ConstraintError{Rule: RuleSelector, BadValue: value, Message: fmt.Sprintf("value '%s' must be an integer", value)})
+			}
+			continue
+		}
+		if err := ValidateLabelValue(value); err != nil {
+			errs = append(errs, withMessagePrefix("invalid label value: ", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// selectorParser is a hand-written scanner and parser for the label selector syntax.
+type selectorParser struct {
+	input string
+	pos   int
+}
+
+// done reports whether the whole input was consumed.
+func (p *selectorParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+// skipSpace skips whitespace.
+func (p *selectorParser) skipSpace() {
+	for !p.done() && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// consume skips token if the input continues with it.
+func (p *selectorParser) consume(token string) bool {
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+// word scans a key or value: a run of characters other than whitespace, operators,
+// commas, and parentheses.
+func (p *selectorParser) word() string {
+	start := p.pos
+	for !p.done() && !strings.ContainsRune(" \t=!<>,()", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+// requirement parses a single requirement.
+func (p *selectorParser) requirement() (SelectorRequirement, error) {
+	p.skipSpace()
+	if p.consume("!") {
+		p.skipSpace()
+		key := p.word()
+		if key == "" {
+			return SelectorRequirement{}, fmt.Errorf("expected a key after '!' at position %d", p.pos)
+		}
+		return SelectorRequirement{Key: key, Operator: SelectorDoesNotExist}, nil
+	}
+
+	key := p.word()
+	if key == "" {
+		return SelectorRequirement{}, fmt.Errorf("expected a key at position %d", p.pos)
+	}
+	p.skipSpace()
+
+	var operator SelectorOperator
+	switch {
+	case p.done() || strings.HasPrefix(p.input[p.pos:], ","):
+		return SelectorRequirement{Key: key, Operator: SelectorExists}, nil
+	case p.consume("=="):
+		operator = SelectorDoubleEquals
+	case p.consume("!="):
+		operator = SelectorNotEquals
+	case p.consume("="):
+		operator = SelectorEquals
+	case p.consume(">"):
+		operator = SelectorGreaterThan
+	case p.consume("<"):
+		operator = SelectorLessThan
+	default:
+		switch word := p.word(); word {
+		case "in":
+			operator = SelectorIn
+		case "notin":
+			operator = SelectorNotIn
+		default:
+			return SelectorRequirement{}, fmt.Errorf("expected an operator (=, ==, !=, in, notin, >, <) after '%s' at position %d", key, p.pos-len(word))
+		}
+	}
+	p.skipSpace()
+
+	if operator != SelectorIn && operator != SelectorNotIn {
+		value := p.word()
+		if value == "" && (operator == SelectorGreaterThan || operator == SelectorLessThan) {
+			return SelectorRequirement{}, fmt.Errorf("expected an integer after '%s' at position %d", operator, p.pos)
+		}
+		// An empty value is allowed, e.g. "tier=" selects objects whose tier label is empty
+		return SelectorRequirement{Key: key, Operator: operator, Values: []string{value}}, nil
+	}
+
+	if !p.consume("(") {
+		return SelectorRequirement{}, fmt.Errorf("expected '(' after '%s' at position %d", operator, p.pos)
+	}
+	values := make([]string, 0)
+	seen := make(map[string]bool)
+	for {
+		p.skipSpace()
+		value := p.word()
+		if value == "" && len(values) == 0 && strings.HasPrefix(p.input[p.pos:], ")") {
+			return SelectorRequirement{}, fmt.Errorf("the value set of '%s' cannot be empty", operator)
+		}
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+		p.skipSpace()
+		if p.consume(")") {
+			break
+		}
+		if !p.consume(",") {
+			return SelectorRequirement{}, fmt.Errorf("expected ',' or ')' at position %d", p.pos)
+		}
+	}
+	sort.Strings(values)
+	return SelectorRequirement{Key: key, Operator: operator, Values: values}, nil
+}
@@ -0,0 +1,83 @@
+package k8sconstraints
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		// want is the parsed selector rendered with Selector.String, or part of the error
+		// message when wantErr is set
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"app=web", "app=web", false},
+		{"app == web, tier!=db", "app==web,tier!=db", false},
+		{"tier in (frontend, backend),!canary", "tier in (backend,frontend),!canary", false},
+		{"env notin (dev,test),team", "env notin (dev,test),team", false},
+		{"replicas>2,replicas<10", "replicas>2,replicas<10", false},
+		{"app.kubernetes.io/name=web", "app.kubernetes.io/name=web", false},
+		{"app=web,", "invalid selector 'app=web,': expected a key at position 8", true},
+		{"app=web tier=db", "invalid selector 'app=web tier=db': expected ',' at position 8", true},
+		{"!", "expected a key after '!' at position 1", true},
+		{"Bad Key=web", "expected an operator (=, ==, !=, in, notin, >, <) after 'Bad' at position 4", true},
+		{"app=-web-", "requirement 'app=-web-': invalid label value", true},
+		{"-app=web", "requirement '-app=web': invalid label key", true},
+		{"replicas>two", "requirement 'replicas>two': value 'two' must be an integer", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			got, err := ParseSelector(tt.selector)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("expected an error containing %q, got %v", tt.want, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got.String())
+			}
+		})
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	labels := map[string]string{"app": "web", "tier": "frontend", "replicas": "3"}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"", true},
+		{"app=web", true},
+		{"app!=web", false},
+		{"app!=api", true},
+		{"tier in (frontend,backend)", true},
+		{"tier notin (frontend)", false},
+		{"env notin (prod)", true},
+		{"app,!canary", true},
+		{"canary", false},
+		{"replicas>2,replicas<4", true},
+		{"replicas>3", false},
+		{"app=web,tier=backend", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := ParseSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if got := selector.Matches(labels); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
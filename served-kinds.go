@@ -0,0 +1,37 @@
+package k8sconstraints
+
+import "fmt"
+
+// CheckServedKinds is the ID of the check built by ServedKindsCheck, and the rule code of
+// its findings.
+const CheckServedKinds = "ServedKinds"
+
+// ServedKindsCheck returns a check reporting objects whose apiVersion and kind are not
+// served by a cluster, as listed by the types of its OpenAPI document, such as one
+// fetched from the API server's /openapi/v2 endpoint. Types with a schema in any of crds
+// pass as well, so custom resources whose CustomResourceDefinition is applied along with
+// them are not reported. Objects without an apiVersion or kind are left to ValidateObject. Run it
+// with RunChecks or pass it to the linter's Checks option.
+func ServedKindsCheck(served *KubernetesSchemas, crds ...*CRDSchemas) Check {
+	return Check{ID: CheckServedKinds, Validate: func(obj map[string]interface{}) error {
+		apiVersion, _ := nestedString(obj, "apiVersion")
+		kind, _ := nestedString(obj, "kind")
+		if apiVersion == "" || kind == "" {
+			return nil
+		}
+		if _, ok := served.SchemaFor(apiVersion, kind); ok {
+			return nil
+		}
+		for _, set := range crds {
+			if _, ok := set.SchemaFor(apiVersion, kind); ok {
+				return nil
+			}
+		}
+		return &ConstraintError{
+			FieldPath: "apiVersion",
+			Rule:      CheckServedKinds,
+			BadValue:  apiVersion,
+			Message:   fmt.Sprintf("kind '%s' of apiVersion '%s' is not served by the cluster", kind, apiVersion),
+		}
+	}}
+}
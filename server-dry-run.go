@@ -0,0 +1,74 @@
+package k8sconstraints
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CheckServerDryRun is the ID of the check submitting objects to a cluster with a
+// server-side dry run, and the rule code of the rejections it reports.
+const CheckServerDryRun = "ServerDryRun"
+
+var (
+	// apiServerErrorPrefix matches the prefix kubectl puts before API server errors, such as
+	// `Error from server (Invalid): error when creating "STDIN": `.
+	apiServerErrorPrefix = regexp.MustCompile(`^Error from server \([A-Za-z]+\): (error when [a-z]+ "[^"]*": )?`)
+	// apiServerCauseStart matches the start of a cause in the aggregated causes of an
+	// Invalid error: a field path, or <nil> for the object itself, followed by ": ".
+	apiServerCauseStart = regexp.MustCompile(`^(<nil>|[A-Za-z_$][^\s:,]*): `)
+)
+
+// ParseAPIServerError converts an API server rejection, as printed by kubectl or returned
+// in a Status message, into violations of CheckServerDryRun. The causes of an Invalid
+// error, such as
+//
+//	Deployment.apps "web" is invalid: [spec.replicas: Invalid value: -1: must be greater than or equal to 0, spec.template.spec.containers[0].image: Required value]
+//
+// become one violation each, at their field path; other errors, such as a missing
+// namespace or a denial by an admission webhook, become a single violation against the
+// object.
+func ParseAPIServerError(message string) error {
+	message = strings.TrimSpace(apiServerErrorPrefix.ReplaceAllString(strings.TrimSpace(message), ""))
+	_, causes, ok := strings.Cut(message, " is invalid: ")
+	if !ok {
+		return &ConstraintError{Rule: CheckServerDryRun, Message: message}
+	}
+	if strings.HasPrefix(causes, "[") && strings.HasSuffix(causes, "]") {
+		causes = causes[1 : len(causes)-1]
+	}
+
+	errs := make([]error, 0)
+	for _, cause := range splitAPIServerCauses(causes) {
+		e := &ConstraintError{Rule: CheckServerDryRun, Message: cause}
+		if match := apiServerCauseStart.FindStringSubmatch(cause); match != nil {
+			if match[1] != "<nil>" {
+				e.FieldPath = match[1]
+			}
+			e.Message = cause[len(match[0]):]
+		}
+		errs = append(errs, e)
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
+
+// splitAPIServerCauses splits the comma-separated causes of an Invalid error. Causes are
+// only split where the next one starts with a field path, as messages such as those
+// listing supported values contain ", " themselves.
+func splitAPIServerCauses(causes string) []string {
+	parts := make([]string, 0)
+	start := 0
+	for i := 0; i < len(causes); i++ {
+		if strings.HasPrefix(causes[i:], ", ") && apiServerCauseStart.MatchString(causes[i+2:]) {
+			parts = append(parts, causes[start:i])
+			start = i + 2
+			i++
+		}
+	}
+	return append(parts, causes[start:])
+}
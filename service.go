@@ -0,0 +1,325 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Valid values of the enumerated fields of a Service spec.
+var (
+	serviceTypes                 = []string{"ClusterIP", "NodePort", "LoadBalancer", "ExternalName"}
+	serviceSessionAffinities     = []string{"None", "ClientIP"}
+	serviceExternalTrafficPolicy = []string{"Cluster", "Local"}
+)
+
+// maxSessionAffinityTimeout is the upper bound of sessionAffinityConfig.clientIP.timeoutSeconds,
+// one day.
+const maxSessionAffinityTimeout = 86400
+
+// ValidateService validates the name and spec of a core v1 Service; see ValidateServiceSpec
+// and ValidateServicePorts.
+func ValidateService(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	// Names that already fail the general metadata.name rule are reported there only
+	name, _ := nestedString(obj, "metadata", "name")
+	generateName, _ := nestedString(obj, "metadata", "generateName")
+	switch {
+	case name != "":
+		if ValidateMetadataName(name) == nil {
+			if err := ValidateServiceName(name); err != nil {
+				errs = append(errs, WithFieldPath("metadata.name", err))
+			}
+		}
+	case generateName != "":
+		if ValidateGenerateName(generateName) == nil {
+			if err := ValidateGenerateNamePrefix(generateName, ValidateServiceName); err != nil {
+				errs = append(errs, WithFieldPath("metadata.generateName", err))
+			}
+		}
+	}
+	if spec, ok := nestedMap(obj, "spec"); ok {
+		if err := ValidateServiceSpec(spec); err != nil {
+			errs = append(errs, WithFieldPath("spec", err))
+		}
+		if err := ValidateServicePorts(spec); err != nil {
+			errs = append(errs, WithFieldPath("spec", err))
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleService, JoinErrors(errs))
+	}
+	return nil
+}
+
+// ValidateServiceName validates the name of a Service. Service names become DNS names and
+// environment variable prefixes in pods, so unlike most object names they must be RFC 1035
+// DNS labels: at most 63 lowercase alphanumeric characters or '-', starting with a letter
+// and ending with an alphanumeric character.
+func ValidateServiceName(name string) error {
+	if name == "" {
+		return &ConstraintError{Rule: RuleRequired, Message: "service name cannot be empty"}
+	}
+	if err := ValidateDNS1035Label(name); err != nil {
+		return withMessagePrefix("invalid service name: ", err)
+	}
+	return nil
+}
+
+// ValidateServicePorts validates the ports of a Service spec with ValidateServicePortsInRange,
+// checking node ports against DefaultNodePortRange, or the range set with UseNodePortRange.
+func ValidateServicePorts(spec map[string]interface{}) error {
+	return ValidateServicePortsInRange(spec, nodePortRange)
+}
+
+// ValidateServicePortsInRange validates the ports of a Service spec: port and numeric
+// targetPort values must lie between 1 and 65535, named targetPorts must be valid IANA
+// service names, and nodePorts must lie within nodePorts. Port names must be valid IANA
+// service names, unique, and set on every port when there is more than one.
+func ValidateServicePortsInRange(spec map[string]interface{}, nodePorts NodePortRange) error {
+	errs := make([]error, 0)
+
+	ports, _ := nestedSlice(spec, "ports")
+	names := make(map[string]bool)
+	for i, raw := range ports {
+		port, _ := raw.(map[string]interface{})
+		if value, ok := nestedField(port, "port"); !ok {
+			errs = append(errs, &ConstraintError{FieldPath: fmt.Sprintf("ports[%d].port", i), Rule: RuleRequired, Message: "port is required"})
+		} else if err := ValidatePortNumber(value); err != nil {
+			errs = append(errs, WithFieldPath(fmt.Sprintf("ports[%d].port", i), err))
+		}
+		if value, ok := nestedField(port, "targetPort"); ok {
+			if err := validateTargetPort(value); err != nil {
+				errs = append(errs, WithFieldPath(fmt.Sprintf("ports[%d].targetPort", i), err))
+			}
+		}
+		if value, ok := nestedField(port, "nodePort"); ok {
+			if err := ValidateNodePort(value, nodePorts); err != nil {
+				errs = append(errs, WithFieldPath(fmt.Sprintf("ports[%d].nodePort", i), err))
+			}
+		}
+
+		path := fmt.Sprintf("ports[%d].name", i)
+		name, _ := nestedString(port, "name")
+		if name == "" {
+			if len(ports) > 1 {
+				errs = append(errs, &ConstraintError{FieldPath: path, Rule: RuleRequired, Message: "name is required when a Service has more than one port"})
+			}
+			continue
+		}
+		if err := ValidatePortName(name); err != nil {
+			errs = append(errs, WithFieldPath(path, err))
+		}
+		if names[name] {
+			errs = append(errs, &ConstraintError{FieldPath: path, BadValue: name, Message: fmt.Sprintf("duplicate port name '%s'", name)})
+		}
+		names[name] = true
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleService, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validateTargetPort checks a Service targetPort, which is either a port number or the name
+// of a container port.
+func validateTargetPort(value interface{}) error {
+	if name, ok := value.(string); ok {
+		return ValidatePortName(name)
+	}
+	return ValidatePortNumber(value)
+}
+
+// ValidateServiceSpec validates the fields of a Service spec other than its ports: type
+// must be ClusterIP, NodePort, LoadBalancer, or ExternalName; clusterIP and clusterIPs must
+// be IP addresses, or None for headless ClusterIP Services, with clusterIPs holding at most
+// one address per IP family and starting with clusterIP; ExternalName Services need an
+// externalName that is a DNS subdomain and no cluster IP or IP families, while other
+// Services' IP families must pass ValidateIPFamilyPolicy; externalIPs and loadBalancerIP
+// must be IP addresses and loadBalancerSourceRanges CIDRs, the latter only on LoadBalancer
+// Services; nodePorts are only allowed on NodePort and LoadBalancer Services; and
+// sessionAffinity must be None or ClientIP, with a timeout between 1 and 86400 seconds
+// configured only for ClientIP.
+func ValidateServiceSpec(spec map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	serviceType, ok := nestedString(spec, "type")
+	if !ok {
+		serviceType = "ClusterIP"
+	} else if !containsString(serviceTypes, serviceType) {
+		errs = append(errs, validateEnumField("type", serviceType, serviceTypes))
+		// Skip the rules that depend on the type
+		serviceType = ""
+	}
+
+	// Cluster IPs
+	clusterIP, _ := nestedString(spec, "clusterIP")
+	clusterIPs := make([]string, 0)
+	if list, ok := nestedSlice(spec, "clusterIPs"); ok {
+		for i, raw := range list {
+			ip, ok := raw.(string)
+			if !ok {
+				errs = append(errs, &ConstraintError{FieldPath: fmt.Sprintf("clusterIPs[%d]", i), BadValue: raw, Message: fmt.Sprintf("cluster IP must be a string, got %v", raw)})
+				continue
+			}
+			clusterIPs = append(clusterIPs, ip)
+		}
+	}
+	switch {
+	case serviceType == "ExternalName":
+		if clusterIP != "" {
+			errs = append(errs, &ConstraintError{FieldPath: "clusterIP", BadValue: clusterIP, Message: "clusterIP must not be set for ExternalName Services"})
+		}
+		if len(clusterIPs) > 0 {
+			errs = append(errs, &ConstraintError{FieldPath: "clusterIPs", Message: "clusterIPs must not be set for ExternalName Services"})
+		}
+	default:
+		if clusterIP != "" {
+			if err := validateServiceClusterIP(clusterIP, serviceType); err != nil {
+				errs = append(errs, WithFieldPath("clusterIP", err))
+			}
+		}
+		if err := validateServiceClusterIPs(clusterIPs, clusterIP, serviceType); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// IP families, which ExternalName Services have none of
+	if serviceType == "ExternalName" {
+		for _, field := range []string{"ipFamilyPolicy", "ipFamilies"} {
+			if value, ok := nestedField(spec, field); ok && value != nil {
+				errs = append(errs, &ConstraintError{FieldPath: field, Message: fmt.Sprintf("%s must not be set for ExternalName Services", field)})
+			}
+		}
+	} else if err := ValidateIPFamilyPolicy(spec); err != nil {
+		errs = append(errs, err)
+	}
+
+	// External name
+	externalName, hasExternalName := nestedString(spec, "externalName")
+	if serviceType == "ExternalName" {
+		name := strings.TrimSuffix(externalName, ".")
+		if name == "" {
+			errs = append(errs, &ConstraintError{FieldPath: "externalName", Rule: RuleRequired, Message: "externalName is required for ExternalName Services"})
+		} else if err := ValidateDNSSubdomain(name); err != nil {
+			errs = append(errs, WithFieldPath("externalName", withMessagePrefix("invalid externalName: ", err)))
+		}
+	} else if hasExternalName && externalName != "" {
+		errs = append(errs, &ConstraintError{FieldPath: "externalName", BadValue: externalName, Message: "warning: externalName is ignored unless type is ExternalName"})
+	}
+
+	// External addresses
+	if list, ok := nestedSlice(spec, "externalIPs"); ok {
+		for i, raw := range list {
+			if err := ValidateIP(fmt.Sprint(raw)); err != nil {
+				errs = append(errs, WithFieldPath(fmt.Sprintf("externalIPs[%d]", i), err))
+			}
+		}
+	}
+	if value, ok := nestedField(spec, "loadBalancerIP"); ok && value != "" {
+		if err := ValidateIP(fmt.Sprint(value)); err != nil {
+			errs = append(errs, WithFieldPath("loadBalancerIP", err))
+		}
+	}
+	if list, ok := nestedSlice(spec, "loadBalancerSourceRanges"); ok && len(list) > 0 {
+		if serviceType != "LoadBalancer" && serviceType != "" {
+			errs = append(errs, &ConstraintError{FieldPath: "loadBalancerSourceRanges", Message: "loadBalancerSourceRanges may only be set when type is LoadBalancer"})
+		}
+		for i, raw := range list {
+			if err := ValidateCIDR(strings.TrimSpace(fmt.Sprint(raw))); err != nil {
+				errs = append(errs, WithFieldPath(fmt.Sprintf("loadBalancerSourceRanges[%d]", i), err))
+			}
+		}
+	}
+	if policy, ok := nestedString(spec, "externalTrafficPolicy"); ok && policy != "" && !containsString(serviceExternalTrafficPolicy, policy) {
+		errs = append(errs, validateEnumField("externalTrafficPolicy", policy, serviceExternalTrafficPolicy))
+	}
+
+	// Node ports only exist on NodePort and LoadBalancer Services
+	if serviceType == "ClusterIP" || serviceType == "ExternalName" {
+		ports, _ := nestedSlice(spec, "ports")
+		for i, raw := range ports {
+			port, _ := raw.(map[string]interface{})
+			if value, ok := nestedField(port, "nodePort"); ok && value != nil {
+				if n, ok := toInt64(value); !ok || n != 0 {
+					errs = append(errs, &ConstraintError{FieldPath: fmt.Sprintf("ports[%d].nodePort", i), BadValue: value, Message: fmt.Sprintf("nodePort may not be set when type is %s", serviceType)})
+				}
+			}
+		}
+	}
+
+	// Session affinity
+	affinity, ok := nestedString(spec, "sessionAffinity")
+	if ok && !containsString(serviceSessionAffinities, affinity) {
+		errs = append(errs, validateEnumField("sessionAffinity", affinity, serviceSessionAffinities))
+	}
+	if config, ok := nestedMap(spec, "sessionAffinityConfig"); ok {
+		if affinity != "ClientIP" {
+			errs = append(errs, &ConstraintError{FieldPath: "sessionAffinityConfig", Message: "sessionAffinityConfig may only be set when sessionAffinity is ClientIP"})
+		} else if value, ok := nestedField(config, "clientIP", "timeoutSeconds"); ok {
+			if n, ok := toInt64(value); !ok || n < 1 || n > maxSessionAffinityTimeout {
+				errs = append(errs, &ConstraintError{FieldPath: "sessionAffinityConfig.clientIP.timeoutSeconds", BadValue: value, Message: fmt.Sprintf("timeoutSeconds must be an integer between 1 and %d", maxSessionAffinityTimeout)})
+			}
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleService, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validateServiceClusterIP checks a cluster IP, which is an IP address, or None for
+// headless Services, which must be of type ClusterIP. serviceType is empty when the type
+// is invalid.
+func validateServiceClusterIP(ip string, serviceType string) error {
+	if ip == "None" {
+		if serviceType != "ClusterIP" && serviceType != "" {
+			return &ConstraintError{BadValue: ip, Message: fmt.Sprintf("clusterIP may only be None when type is ClusterIP, not %s", serviceType)}
+		}
+		return nil
+	}
+	return ValidateIP(ip)
+}
+
+// validateServiceClusterIPs checks the clusterIPs of a Service: each a cluster IP, at most
+// one per IP family, None only on its own, and the first equal to clusterIP when both are
+// set.
+func validateServiceClusterIPs(ips []string, clusterIP string, serviceType string) error {
+	errs := make([]error, 0)
+
+	if len(ips) > 2 {
+		errs = append(errs, &ConstraintError{FieldPath: "clusterIPs", Message: fmt.Sprintf("clusterIPs may hold at most 2 addresses, one per IP family, got %d", len(ips))})
+	}
+	if len(ips) > 0 && clusterIP != "" && ips[0] != clusterIP {
+		errs = append(errs, &ConstraintError{FieldPath: "clusterIPs[0]", BadValue: ips[0], Message: fmt.Sprintf("clusterIPs[0] must equal clusterIP '%s'", clusterIP)})
+	}
+	for i, ip := range ips {
+		if ip == "None" && len(ips) > 1 {
+			errs = append(errs, &ConstraintError{FieldPath: fmt.Sprintf("clusterIPs[%d]", i), BadValue: ip, Message: "None must be the only entry of clusterIPs"})
+			continue
+		}
+		if err := validateServiceClusterIP(ip, serviceType); err != nil {
+			errs = append(errs, WithFieldPath(fmt.Sprintf("clusterIPs[%d]", i), err))
+		}
+	}
+	if len(ips) == 2 {
+		if first := ipFamily(ips[0]); first != "" && first == ipFamily(ips[1]) {
+			errs = append(errs, &ConstraintError{FieldPath: "clusterIPs[1]", BadValue: ips[1], Message: "clusterIPs of a dual-stack Service must be of different IP families"})
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return JoinErrors(errs)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"strings"
+)
+
+// severityRanks orders the severities from least to most serious.
+var severityRanks = map[Severity]int{SeverityInfo: 0, SeverityWarning: 1, SeverityError: 2}
+
+// ParseSeverity parses "error", "warning", or "info".
+func ParseSeverity(s string) (Severity, error) {
+	severity := Severity(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := severityRanks[severity]; !ok {
+		return "", fmt.Errorf("unsupported severity '%s'; must be one of: %s, %s, %s", s, SeverityError, SeverityWarning, SeverityInfo)
+	}
+	return severity, nil
+}
+
+// AtLeast reports whether s is as serious as other or more.
+func (s Severity) AtLeast(other Severity) bool {
+	return severityRanks[s] >= severityRanks[other]
+}
+
+// SeverityOverrides maps rule codes, such as RuleDeprecatedAPI, to the severity their
+// findings are reported with, so a new constraint can be rolled out as a warning or info
+// finding before it becomes blocking, or an advisory one made blocking. Findings without a
+// rule code keep their severity.
+type SeverityOverrides map[string]Severity
+
+// ParseSeverityOverrides parses comma-separated RULE=SEVERITY pairs, e.g.
+// "DeprecatedAPI=warning,PortName=info".
+func ParseSeverityOverrides(s string) (SeverityOverrides, error) {
+	overrides := make(SeverityOverrides)
+	errs := make([]error, 0)
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		rule, level, ok := strings.Cut(pair, "=")
+		if rule = strings.TrimSpace(rule); !ok || rule == "" {
+			errs = append(errs, fmt.Errorf("invalid severity override '%s'; must be of the form RULE=SEVERITY", pair))
+			continue
+		}
+		severity, err := ParseSeverity(level)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("severity override '%s': %v", pair, err))
+			continue
+		}
+		overrides[rule] = severity
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return nil, JoinErrors(errs)
+	}
+
+	return overrides, nil
+}
+
+// ApplySeverityOverrides sets the severity of every finding, including Related ones, whose
+// rule has an override. Fingerprints do not depend on severity and stay the same.
+func ApplySeverityOverrides(findings []Finding, overrides SeverityOverrides) []Finding {
+	if len(overrides) == 0 {
+		return findings
+	}
+	for i := range findings {
+		if severity, ok := overrides[findings[i].Rule]; ok && findings[i].Rule != "" {
+			findings[i].Severity = severity
+		}
+		findings[i].Related = ApplySeverityOverrides(findings[i].Related, overrides)
+	}
+	return findings
+}
+
+// HasFindingsAtLeast reports whether the run produced any finding of severity or more
+// serious, e.g. SeverityWarning to fail on warnings and errors but not info findings.
+func (r Report) HasFindingsAtLeast(severity Severity) bool {
+	for _, finding := range r.Findings {
+		if finding.Severity.AtLeast(severity) {
+			return true
+		}
+	}
+	return false
+}
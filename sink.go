@@ -0,0 +1,129 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// OutputSink receives the results of a run: Start before the first finding, Write for each
+// finding in report order, and Summary once with the complete report. Implement it to
+// stream results into other systems, such as chat notifications, ticket trackers, or
+// databases, and register it with RegisterOutputSink to make it available as an output
+// format.
+type OutputSink interface {
+	Start() error
+	Write(finding Finding) error
+	Summary(report Report) error
+}
+
+var (
+	outputSinksMu sync.RWMutex
+	// outputSinks maps output format names to the constructors of their sinks
+	outputSinks = map[string]func(w io.Writer) OutputSink{
+		FormatText:  func(w io.Writer) OutputSink { return &textSink{w: w} },
+		FormatJSON:  func(w io.Writer) OutputSink { return documentSink{w: w, write: writeJSONReport} },
+		FormatSARIF: func(w io.Writer) OutputSink { return documentSink{w: w, write: writeSARIFReport} },
+		FormatJUnit: func(w io.Writer) OutputSink { return documentSink{w: w, write: writeJUnitReport} },
+	}
+)
+
+// RegisterOutputSink makes a sink available under the output format name, replacing any
+// sink registered under it before. newSink is called once per report with the writer the
+// report is rendered to; sinks sending results elsewhere may ignore it.
+func RegisterOutputSink(name string, newSink func(w io.Writer) OutputSink) {
+	outputSinksMu.Lock()
+	defer outputSinksMu.Unlock()
+	outputSinks[name] = newSink
+}
+
+// OutputFormats returns the names of the registered output formats, sorted.
+func OutputFormats() []string {
+	outputSinksMu.RLock()
+	defer outputSinksMu.RUnlock()
+	return sortedKeys(outputSinks)
+}
+
+// NewOutputSink returns a sink for the named output format writing to w.
+func NewOutputSink(format string, w io.Writer) (OutputSink, error) {
+	outputSinksMu.RLock()
+	newSink, ok := outputSinks[format]
+	outputSinksMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported output format '%s'; must be one of: %s", format, strings.Join(OutputFormats(), ", "))
+	}
+	return newSink(w), nil
+}
+
+// WriteReportTo sends report to sink: Start, Write for each finding, then Summary.
+func WriteReportTo(sink OutputSink, report Report) error {
+	if err := sink.Start(); err != nil {
+		return err
+	}
+	for _, finding := range report.Findings {
+		if err := sink.Write(finding); err != nil {
+			return err
+		}
+	}
+	return sink.Summary(report)
+}
+
+// documentSink renders formats that describe the whole run in one document, such as JSON
+// or SARIF, once the report is complete.
+type documentSink struct {
+	w     io.Writer
+	write func(io.Writer, Report) error
+}
+
+// Start does nothing; the document is written by Summary.
+func (s documentSink) Start() error { return nil }
+
+// Write does nothing; the document is written by Summary.
+func (s documentSink) Write(Finding) error { return nil }
+
+// Summary writes the document.
+func (s documentSink) Summary(report Report) error { return s.write(s.w, report) }
+
+// textSink prints findings as they arrive, grouped per file, followed by summary lines.
+type textSink struct {
+	w       io.Writer
+	file    string
+	started bool
+}
+
+// Start resets the file grouping.
+func (s *textSink) Start() error {
+	s.file, s.started = "", false
+	return nil
+}
+
+// Write prints a finding, preceded by its file name when the file changes.
+func (s *textSink) Write(finding Finding) error {
+	if !s.started || finding.File != s.file {
+		s.file, s.started = finding.File, true
+		if _, err := fmt.Fprintf(s.w, "%s\n", displayFileName(s.file)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(s.w, "  %s\n", FormatFinding(finding))
+	return err
+}
+
+// Summary prints the finding counts, the score, and the per-target summaries.
+func (s *textSink) Summary(report Report) error {
+	if _, err := fmt.Fprintf(s.w, "%d finding(s) in %d document(s) across %d file(s)\n", len(report.Findings), report.Documents, report.Files); err != nil {
+		return err
+	}
+	if report.Score != nil {
+		if _, err := fmt.Fprintf(s.w, "score: %.1f (grade %s) across %d resource(s)\n", report.Score.Score, report.Score.Grade, len(report.Score.Resources)); err != nil {
+			return err
+		}
+	}
+	for _, target := range report.Targets {
+		if _, err := fmt.Fprintf(s.w, "%s\n", formatTargetSummary(target)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package k8sconstraints
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is one manifest produced by a Source.
+type Document struct {
+	// Source names where the document came from: a file path, "<stdin>", or a description
+	// such as "helm template ./chart".
+	Source string
+	// Index is the zero-based position of the document within its source file or stream.
+	Index int
+	// Line is the line the document starts on, when known.
+	Line int
+	// Object is the decoded document, or nil when Err is set.
+	Object map[string]interface{}
+	// Path is the field path of Object within the document when a streaming source yielded
+	// it on its own, such as items[3] for an item of a List; see NewStreamSource.
+	Path string
+	// Err is set when the document was found but could not be decoded into an object.
+	Err error
+}
+
+// Source produces manifests for validation. Next returns the next document, or io.EOF once
+// the source is exhausted. Any other error means the source failed and cannot continue;
+// per-document decode failures are reported through Document.Err instead.
+type Source interface {
+	Next() (Document, error)
+}
+
+// ValidateDocument runs ValidateObject against a document produced by a Source.
+func ValidateDocument(doc Document) DocumentResult {
+	result := DocumentResult{Index: doc.Index, Line: doc.Line, Path: doc.Path, Object: doc.Object}
+	if doc.Err != nil {
+		result.Findings = []Finding{DecodeFinding(doc.Err)}
+		return result
+	}
+	result.Findings = objectFindingsAt(doc.Object, doc.Path, ValidateObject)
+	return result
+}
+
+// yamlSource decodes a stream of `---`-separated YAML documents.
+type yamlSource struct {
+	name    string
+	decoder *yaml.Decoder
+	index   int
+	done    bool
+}
+
+// NewYAMLSource returns a Source that decodes the multi-document YAML stream r. Empty
+// documents are skipped. Since JSON is valid YAML, r may also hold JSON objects.
+func NewYAMLSource(name string, r io.Reader) Source {
+	return &yamlSource{name: name, decoder: yaml.NewDecoder(r)}
+}
+
+// Next decodes the next non-empty document. A syntax error is reported through Document.Err
+// and ends the stream, since the documents that follow cannot be located reliably.
+func (s *yamlSource) Next() (Document, error) {
+	for !s.done {
+		var node yaml.Node
+		err := s.decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			s.done = true
+			return Document{Source: s.name, Index: s.index, Err: fmt.Errorf("invalid YAML: %v", err)}, nil
+		}
+		if isEmptyYAMLDocument(&node) {
+			continue
+		}
+
+		doc := Document{Source: s.name, Index: s.index, Line: node.Content[0].Line}
+		doc.Object, doc.Err = decodeYAMLObject(&node)
+		s.index++
+		return doc, nil
+	}
+	s.done = true
+	return Document{}, io.EOF
+}
+
+// jsonSource yields the single JSON object held in a buffer.
+type jsonSource struct {
+	name string
+	data []byte
+	done bool
+}
+
+// NewJSONSource returns a Source that yields the single JSON object in data, with the same
+// rules as ValidateJSON.
+func NewJSONSource(name string, data []byte) Source {
+	return &jsonSource{name: name, data: data}
+}
+
+// Next returns the object on the first call and io.EOF afterwards.
+func (s *jsonSource) Next() (Document, error) {
+	if s.done {
+		return Document{}, io.EOF
+	}
+	s.done = true
+	obj, err := decodeJSONObject(s.data)
+	if err != nil {
+		return Document{Source: s.name, Err: err}, nil
+	}
+	return Document{Source: s.name, Line: 1, Object: obj}, nil
+}
+
+// jsonStreamSource decodes a stream of concatenated JSON objects.
+type jsonStreamSource struct {
+	name    string
+	decoder *json.Decoder
+	index   int
+	done    bool
+}
+
+// Next decodes the next JSON value. A syntax error is reported through Document.Err and
+// ends the stream.
+func (s *jsonStreamSource) Next() (Document, error) {
+	if s.done || !s.decoder.More() {
+		s.done = true
+		return Document{}, io.EOF
+	}
+
+	doc := Document{Source: s.name, Index: s.index}
+	s.index++
+
+	var value interface{}
+	if err := s.decoder.Decode(&value); err != nil {
+		s.done = true
+		doc.Err = fmt.Errorf("invalid JSON: %v", err)
+		return doc, nil
+	}
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		doc.Err = fmt.Errorf("invalid JSON: document must be an object, not a JSON %s", jsonTypeName(value))
+		return doc, nil
+	}
+	doc.Object = obj
+	return doc, nil
+}
+
+// NewReaderSource returns a Source over r that detects the input format: a stream of JSON
+// objects when the first non-whitespace character is '{' or '[', multi-document YAML
+// otherwise. This is the format detection used for standard input.
+func NewReaderSource(name string, r io.Reader) Source {
+	buffered := bufio.NewReader(r)
+	for {
+		c, err := buffered.Peek(1)
+		if err != nil || !unicode.IsSpace(rune(c[0])) {
+			if err == nil && (c[0] == '{' || c[0] == '[') {
+				return &jsonStreamSource{name: name, decoder: json.NewDecoder(buffered)}
+			}
+			return NewYAMLSource(name, buffered)
+		}
+		if _, err := buffered.ReadByte(); err != nil {
+			return NewYAMLSource(name, buffered)
+		}
+	}
+}
+
+// fileSource reads a list of files one after the other.
+type fileSource struct {
+	paths   []string
+	current Source
+}
+
+// NewFileSource returns a Source that reads the given files in order. Files ending in .json
+// are decoded as a single JSON object, Markdown and Terraform files yield the manifests
+// embedded in them (see NewEmbeddedSource), and everything else is decoded as
+// multi-document YAML.
+func NewFileSource(paths ...string) Source {
+	return &fileSource{paths: paths}
+}
+
+// Next returns the next document of the current file, opening the next file as needed.
+func (s *fileSource) Next() (Document, error) {
+	for {
+		if s.current != nil {
+			doc, err := s.current.Next()
+			if !errors.Is(err, io.EOF) {
+				return doc, err
+			}
+			s.current = nil
+		}
+		if len(s.paths) == 0 {
+			return Document{}, io.EOF
+		}
+
+		path := s.paths[0]
+		s.paths = s.paths[1:]
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Document{}, err
+		}
+		s.current = NewEmbeddedSource(path, data)
+	}
+}
+
+// multiSource concatenates several sources.
+type multiSource struct {
+	sources []Source
+}
+
+// MultiSource returns a Source that yields the documents of each source in turn.
+func MultiSource(sources ...Source) Source {
+	return &multiSource{sources: sources}
+}
+
+// Next returns the next document of the first source that is not exhausted.
+func (s *multiSource) Next() (Document, error) {
+	for len(s.sources) > 0 {
+		doc, err := s.sources[0].Next()
+		if !errors.Is(err, io.EOF) {
+			return doc, err
+		}
+		s.sources = s.sources[1:]
+	}
+	return Document{}, io.EOF
+}
@@ -0,0 +1,228 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+)
+
+// fakeAPIServer serves discovery for Namespaces and Deployments, the Deployments web and
+// api in the namespace team, and dry-run applies of Deployments, which it rejects when
+// they have negative replicas and warns about when they have no revisionHistoryLimit. It
+// writes a kubeconfig with the contexts dev and prod pointing at it, prod with the
+// default namespace team, and sets KUBECONFIG to it.
+func fakeAPIServer(t *testing.T) {
+	t.Helper()
+	responses := map[string]string{
+		"/version": `{"major":"1","minor":"30","gitVersion":"v1.30.2-eks-1234"}`,
+		"/api":     `{"kind":"APIVersions","versions":["v1"]}`,
+		"/apis":    `{"kind":"APIGroupList","groups":[{"name":"apps","versions":[{"groupVersion":"apps/v1","version":"v1"}],"preferredVersion":{"groupVersion":"apps/v1","version":"v1"}}]}`,
+		"/api/v1": `{"kind":"APIResourceList","groupVersion":"v1","resources":[
+			{"name":"namespaces","singularName":"namespace","namespaced":false,"kind":"Namespace","verbs":["get","list"]}]}`,
+		"/apis/apps/v1": `{"kind":"APIResourceList","groupVersion":"apps/v1","resources":[
+			{"name":"deployments","singularName":"deployment","namespaced":true,"kind":"Deployment","verbs":["get","list","patch"],"shortNames":["deploy"],"categories":["all"]}]}`,
+		"/api/v1/namespaces": `{"kind":"NamespaceList","apiVersion":"v1","items":[{"metadata":{"name":"team"}}]}`,
+		"/apis/apps/v1/namespaces/team/deployments": `{"kind":"DeploymentList","apiVersion":"apps/v1","items":[
+			{"metadata":{"name":"web","namespace":"team","managedFields":[{"manager":"kubectl"}]}},
+			{"metadata":{"name":"api","namespace":"team"}}]}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPatch {
+			if r.URL.Query().Get("dryRun") != "All" {
+				t.Errorf("expected a dry run, got %s", r.URL)
+			}
+			body, _ := io.ReadAll(r.Body)
+			if !strings.Contains(string(body), "revisionHistoryLimit") {
+				w.Header().Add("Warning", `299 - "spec.revisionHistoryLimit: unset"`)
+			}
+			if strings.Contains(string(body), `"replicas":-1`) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Invalid","code":422,
+					"message":"Deployment.apps \"web\" is invalid: spec.replicas: Invalid value: -1: must be greater than or equal to 0"}`)
+				return
+			}
+			w.Write(body)
+			return
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			return
+		}
+		fmt.Fprint(w, response)
+	}))
+	t.Cleanup(server.Close)
+
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	config := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters: [{name: fake, cluster: {server: %q}}]
+users: [{name: fake, user: {}}]
+contexts:
+- {name: prod, context: {cluster: fake, user: fake, namespace: team}}
+- {name: dev, context: {cluster: fake, user: fake}}
+current-context: prod
+`, server.URL)
+	if err := os.WriteFile(kubeconfig, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KUBECONFIG", kubeconfig)
+}
+
+func TestCluster(t *testing.T) {
+	fakeAPIServer(t)
+
+	tests := []struct {
+		name string
+		opts ClusterOptions
+		// want holds the kind and name of each object, in order
+		want []string
+		// err is part of the expected error message; "" expects no error
+		err string
+	}{
+		{"all in the default namespace", ClusterOptions{}, []string{"Deployment web", "Deployment api"}, ""},
+		{"short name and cluster-scoped resource", ClusterOptions{Context: "prod", Resources: []string{"deploy", "namespaces"}},
+			[]string{"Deployment web", "Deployment api", "Namespace team"}, ""},
+		{"duplicate resource type", ClusterOptions{Namespace: "team", Resources: []string{"deployments.apps", "all"}},
+			[]string{"Deployment web", "Deployment api"}, ""},
+		{"unknown resource type", ClusterOptions{Resources: []string{"widgets"}}, nil, "cluster prod: unknown resource type 'widgets'"},
+		{"unknown context", ClusterOptions{Context: "staging"}, nil, `context "staging" does not exist`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := Cluster(context.Background(), tt.opts)
+			got := make([]string, 0)
+			for {
+				doc, err := src.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					if tt.err == "" || !strings.Contains(err.Error(), tt.err) {
+						t.Fatalf("expected an error containing %q, got %v", tt.err, err)
+					}
+					return
+				}
+				metadata, _ := doc.Object["metadata"].(map[string]interface{})
+				if metadata["managedFields"] != nil {
+					t.Errorf("expected managed fields to be dropped, got %v", metadata["managedFields"])
+				}
+				got = append(got, fmt.Sprintf("%v %v", doc.Object["kind"], metadata["name"]))
+			}
+			if tt.err != "" {
+				t.Fatalf("expected an error containing %q, got none", tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestContexts(t *testing.T) {
+	fakeAPIServer(t)
+
+	tests := []struct {
+		patterns []string
+		// want holds the matched contexts, or part of the expected error message
+		want    string
+		wantErr bool
+	}{
+		{[]string{"*"}, "dev,prod", false},
+		{[]string{"prod", "d*"}, "dev,prod", false},
+		{[]string{"staging-*"}, "context pattern 'staging-*' matched no kubeconfig context", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.patterns, ","), func(t *testing.T) {
+			got, err := Contexts(context.Background(), tt.patterns...)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("expected an error containing %q, got %v", tt.want, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("expected %s, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClusterVersion(t *testing.T) {
+	fakeAPIServer(t)
+
+	got, err := ClusterVersion(context.Background(), "dev")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got != "1.30.2" {
+		t.Errorf("expected 1.30.2, got %s", got)
+	}
+}
+
+func TestServerDryRunCheck(t *testing.T) {
+	fakeAPIServer(t)
+	check := ServerDryRunCheck(context.Background(), DryRunOptions{})
+
+	tests := []struct {
+		name     string
+		manifest string
+		// findings are the expected messages, in order
+		findings []string
+	}{
+		{"accepted", `
+apiVersion: apps/v1
+kind: Deployment
+metadata: {name: web}
+spec: {replicas: 1, revisionHistoryLimit: 3}`, nil},
+		{"warning", `
+apiVersion: apps/v1
+kind: Deployment
+metadata: {name: web}
+spec: {replicas: 1}`, []string{"warning: spec.revisionHistoryLimit: unset"}},
+		{"rejected", `
+apiVersion: apps/v1
+kind: Deployment
+metadata: {name: web, namespace: team}
+spec: {replicas: -1, revisionHistoryLimit: 3}`, []string{"spec.replicas: Invalid value: -1: must be greater than or equal to 0"}},
+		{"kind not served", `
+apiVersion: example.com/v1
+kind: Widget
+metadata: {name: web}`, []string{"cluster prod does not serve Widget"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := k8sconstraints.NewYAMLSource(tt.name, strings.NewReader(tt.manifest)).Next()
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			violations := k8sconstraints.ConstraintErrors(check.Validate(doc.Object))
+			if len(violations) != len(tt.findings) {
+				t.Fatalf("expected %d findings, got %v", len(tt.findings), violations)
+			}
+			for i, violation := range violations {
+				if violation.Rule != k8sconstraints.CheckServerDryRun || !strings.Contains(violation.Error(), tt.findings[i]) {
+					t.Errorf("expected a %s finding containing %q, got %v", k8sconstraints.CheckServerDryRun, tt.findings[i], violation)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+)
+
+// ClusterOpenAPI fetches the types served by the API server of the kubeconfig context, or
+// of the current context when empty, from its OpenAPI v2 document; see
+// ClusterOpenAPIDocument. They include those of installed CRDs and aggregated APIs, with
+// the schemas of the cluster's own release.
+func ClusterOpenAPI(ctx context.Context, kubeContext string) (*k8sconstraints.KubernetesSchemas, error) {
+	document, err := ClusterOpenAPIDocument(ctx, kubeContext)
+	if err != nil {
+		return nil, err
+	}
+	schemas, err := k8sconstraints.LoadKubernetesOpenAPI(document)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document served by the cluster: %v", err)
+	}
+	return schemas, nil
+}
+
+// ClusterOpenAPIDocument fetches the OpenAPI v2 document served by the API server of the
+// kubeconfig context, or of the current context when empty.
+func ClusterOpenAPIDocument(ctx context.Context, kubeContext string) ([]byte, error) {
+	client, err := newClusterClient(kubeContext)
+	if err != nil {
+		return nil, err
+	}
+	document, err := client.discovery.RESTClient().Get().AbsPath("/openapi/v2").SetHeader("Accept", "application/json").Do(ctx).Raw()
+	if err != nil {
+		return nil, fmt.Errorf("%s: fetching the OpenAPI document: %v", client, err)
+	}
+	return document, nil
+}
+
+// ClusterVersion returns the Kubernetes release of the API server of the kubeconfig
+// context, or of the current context when empty, such as "1.30.2". Distribution suffixes
+// such as -eks-1234 are dropped.
+func ClusterVersion(ctx context.Context, kubeContext string) (string, error) {
+	client, err := newClusterClient(kubeContext)
+	if err != nil {
+		return "", err
+	}
+	return client.serverVersion(ctx)
+}
+
+// ClusterCRDs returns the CustomResourceDefinitions installed in the cluster of the
+// kubeconfig context, or of the current context when empty.
+func ClusterCRDs(ctx context.Context, kubeContext string) ([]map[string]interface{}, error) {
+	client, err := newClusterClient(kubeContext)
+	if err != nil {
+		return nil, err
+	}
+	resource := schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+	list, err := client.dynamic.Resource(resource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("%s: listing CustomResourceDefinitions: %v", client, err)
+	}
+
+	crds := make([]map[string]interface{}, 0, len(list.Items))
+	for _, item := range list.Items {
+		if k8sconstraints.IsCustomResourceDefinition(item.Object) {
+			crds = append(crds, item.Object)
+		}
+	}
+	return crds, nil
+}
+
+// CheckClusterAccess verifies that the API server of the kubeconfig context, or of the
+// current context when empty, can be reached, so that an unreachable cluster is reported
+// once rather than as a rejection of every object.
+func CheckClusterAccess(ctx context.Context, kubeContext string) error {
+	client, err := newClusterClient(kubeContext)
+	if err != nil {
+		return err
+	}
+	_, err = client.serverVersion(ctx)
+	return err
+}
+
+// clusterClient holds the clients for the API server of a kubeconfig context.
+type clusterClient struct {
+	// context is the name of the kubeconfig context.
+	context string
+	// namespace is the default namespace of the context.
+	namespace string
+	discovery discovery.DiscoveryInterface
+	mapper    meta.RESTMapper
+	dynamic   dynamic.Interface
+}
+
+// newClusterClient returns the clients for the API server of the kubeconfig context, or of
+// the current context when empty. The kubeconfig is loaded as kubectl loads it: from the
+// files listed in $KUBECONFIG, or from ~/.kube/config, falling back to the in-cluster
+// configuration when running in a pod.
+func newClusterClient(kubeContext string) (*clusterClient, error) {
+	loader := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{CurrentContext: kubeContext})
+	config, err := loader.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig: %v", err)
+	}
+	namespace, _, err := loader.Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig: %v", err)
+	}
+	if kubeContext == "" {
+		if raw, err := loader.RawConfig(); err == nil {
+			kubeContext = raw.CurrentContext
+		}
+	}
+	config.WarningHandlerWithContext = contextWarnings{}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig: %v", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig: %v", err)
+	}
+	cached := memory.NewMemCacheClient(discoveryClient)
+	return &clusterClient{
+		context:   kubeContext,
+		namespace: namespace,
+		discovery: cached,
+		mapper:    restmapper.NewShortcutExpander(restmapper.NewDeferredDiscoveryRESTMapper(cached), cached, nil),
+		dynamic:   dynamicClient,
+	}, nil
+}
+
+// String names the cluster by its kubeconfig context, for error messages and the source
+// name of the objects read from it.
+func (c *clusterClient) String() string {
+	if c.context == "" {
+		return "cluster"
+	}
+	return "cluster " + c.context
+}
+
+// serverVersion returns the Kubernetes release of the API server; see ClusterVersion.
+func (c *clusterClient) serverVersion(ctx context.Context) (string, error) {
+	body, err := c.discovery.RESTClient().Get().AbsPath("/version").Do(ctx).Raw()
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", c, err)
+	}
+	var info version.Info
+	if err := json.Unmarshal(body, &info); err != nil {
+		return "", fmt.Errorf("%s: invalid server version: %v", c, err)
+	}
+	release := strings.TrimPrefix(info.GitVersion, "v")
+	if i := strings.IndexAny(release, "-+"); i >= 0 {
+		release = release[:i]
+	}
+	if release == "" {
+		return "", fmt.Errorf("%s: the server version is unknown", c)
+	}
+	return release, nil
+}
+
+// warningsKey is the context key of the slice contextWarnings appends warnings to.
+type warningsKey struct{}
+
+// withWarnings returns a context whose requests append the API server's warnings to
+// warnings.
+func withWarnings(ctx context.Context, warnings *[]string) context.Context {
+	return context.WithValue(ctx, warningsKey{}, warnings)
+}
+
+// contextWarnings collects the warnings of requests made with withWarnings, and drops
+// the others.
+type contextWarnings struct{}
+
+// HandleWarningHeaderWithContext appends a warning to the slice of ctx, if any. Only
+// warnings with code 299 are reported by API servers; others come from proxies.
+func (contextWarnings) HandleWarningHeaderWithContext(ctx context.Context, code int, _ string, text string) {
+	if warnings, ok := ctx.Value(warningsKey{}).(*[]string); ok && code == 299 && text != "" {
+		*warnings = append(*warnings, text)
+	}
+}
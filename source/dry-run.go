@@ -0,0 +1,92 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+)
+
+// dryRunFieldManager is the field manager the dry-run applies are made as.
+const dryRunFieldManager = "k8sconstraints"
+
+// DryRunOptions configures ServerDryRunCheck.
+type DryRunOptions struct {
+	// Context is the kubeconfig context to use. Defaults to the current context.
+	Context string
+}
+
+// ServerDryRunCheck returns a check that submits every object to the cluster as a
+// server-side apply with dryRun=All, so it passes through the API server's validation,
+// defaulting, and admission webhooks without being persisted, and reports the rejections
+// as violations of k8sconstraints.CheckServerDryRun; see k8sconstraints.ParseAPIServerError.
+// Warnings returned by the API server, such as those for deprecated APIs, are reported as
+// warnings. Namespaced objects without a namespace are submitted to the default namespace
+// of the kubeconfig context.
+//
+// Objects are submitted one at a time, so objects that depend on others in the same
+// manifests, such as those in a Namespace that does not exist in the cluster yet, are
+// rejected. Run it with RunChecks or pass it to the linter's Checks option.
+func ServerDryRunCheck(ctx context.Context, opts DryRunOptions) k8sconstraints.Check {
+	var (
+		once      sync.Once
+		client    *clusterClient
+		clientErr error
+	)
+	return k8sconstraints.Check{ID: k8sconstraints.CheckServerDryRun, Validate: func(obj map[string]interface{}) error {
+		once.Do(func() {
+			client, clientErr = newClusterClient(opts.Context)
+		})
+		if clientErr != nil {
+			return &k8sconstraints.ConstraintError{Rule: k8sconstraints.CheckServerDryRun, Message: clientErr.Error()}
+		}
+		return client.serverDryRun(ctx, obj)
+	}}
+}
+
+// serverDryRun applies obj with a server-side dry run and converts the warnings and the
+// rejection of the API server into violations.
+func (c *clusterClient) serverDryRun(ctx context.Context, obj map[string]interface{}) error {
+	object := &unstructured.Unstructured{Object: obj}
+	gvk := object.GroupVersionKind()
+	mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return &k8sconstraints.ConstraintError{Rule: k8sconstraints.CheckServerDryRun, Message: fmt.Sprintf("%s does not serve %s: %v", c, gvk.Kind, err)}
+	}
+	var resource dynamic.ResourceInterface = c.dynamic.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		namespace := object.GetNamespace()
+		if namespace == "" {
+			namespace = c.namespace
+		}
+		resource = c.dynamic.Resource(mapping.Resource).Namespace(namespace)
+	}
+
+	warnings := make([]string, 0)
+	_, applyErr := resource.Apply(withWarnings(ctx, &warnings), object.GetName(), object, metav1.ApplyOptions{
+		DryRun:       []string{metav1.DryRunAll},
+		FieldManager: dryRunFieldManager,
+		Force:        true,
+	})
+
+	errs := make([]error, 0)
+	for _, warning := range warnings {
+		errs = append(errs, &k8sconstraints.ConstraintError{Rule: k8sconstraints.CheckServerDryRun, Message: "warning: " + warning})
+	}
+	if applyErr != nil {
+		errs = append(errs, k8sconstraints.ParseAPIServerError(applyErr.Error()))
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return k8sconstraints.JoinErrors(errs)
+	}
+
+	return nil
+}
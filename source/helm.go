@@ -0,0 +1,211 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/registry"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+)
+
+// helmSourcePrefix starts the comment helm template writes above every document, naming
+// the template that rendered it, e.g. "# Source: app/templates/deployment.yaml".
+const helmSourcePrefix = "# Source: "
+
+// helmDocument is one `---`-separated document of helm template output.
+type helmDocument struct {
+	// template is the path from the document's Source comment, or "" without one.
+	template string
+	// line is the line of the document's first body line within the rendered template.
+	line int
+	body []byte
+}
+
+// helmTemplateSource yields the documents of helm template output, attributed to their
+// templates.
+type helmTemplateSource struct {
+	name      string
+	chart     string
+	documents []helmDocument
+	current   k8sconstraints.Source
+	document  helmDocument
+	indexes   map[string]int
+}
+
+// HelmTemplateSource returns a Source over output, the manifests written by `helm template`
+// for chart. Each document is attributed to the template named by the "# Source:" comment
+// helm writes above it: Document.Source is the template's path, below the chart directory
+// when chart is a local directory, and Document.Index and Document.Line count within the
+// template's rendered output. Lines match the template file as long as the template
+// actions above a document do not add or remove lines. Documents without a Source comment
+// are attributed to name.
+func HelmTemplateSource(name, chart string, output []byte) k8sconstraints.Source {
+	return &helmTemplateSource{name: name, chart: chart, documents: splitHelmOutput(output), indexes: make(map[string]int)}
+}
+
+// Next returns the next document, moving on to the next rendered document as needed.
+func (s *helmTemplateSource) Next() (k8sconstraints.Document, error) {
+	for {
+		if s.current != nil {
+			doc, err := s.current.Next()
+			if !errors.Is(err, io.EOF) {
+				if err == nil {
+					doc.Index = s.indexes[doc.Source]
+					s.indexes[doc.Source]++
+					if doc.Line > 0 {
+						doc.Line += s.document.line - 1
+					}
+				}
+				return doc, err
+			}
+			s.current = nil
+		}
+		if len(s.documents) == 0 {
+			return k8sconstraints.Document{}, io.EOF
+		}
+
+		s.document = s.documents[0]
+		s.documents = s.documents[1:]
+		s.current = k8sconstraints.NewYAMLSource(s.templatePath(s.document.template), bytes.NewReader(s.document.body))
+	}
+}
+
+// templatePath maps a template path from a Source comment, which starts with the chart's
+// name, to the template file below the chart directory. Charts that are not local
+// directories keep the path helm reported.
+func (s *helmTemplateSource) templatePath(template string) string {
+	if template == "" {
+		return s.name
+	}
+	_, rest, found := strings.Cut(template, "/")
+	if !found {
+		return template
+	}
+	if info, err := os.Stat(s.chart); err != nil || !info.IsDir() {
+		return template
+	}
+	return filepath.Join(s.chart, filepath.FromSlash(rest))
+}
+
+// splitHelmOutput splits helm template output into its documents. helm writes every
+// document of a template as "---", the Source comment, and the document, so the documents
+// of a template follow one another and the line numbers of each continue from the
+// previous one, counting the "---" the template separated them with.
+func splitHelmOutput(output []byte) []helmDocument {
+	documents := make([]helmDocument, 0)
+	lines := make(map[string]int)
+	var current *helmDocument
+
+	finish := func() {
+		if current == nil {
+			return
+		}
+		if len(current.body) > 0 {
+			lines[current.template] = current.line + bytes.Count(current.body, []byte("\n")) + 1
+			if len(bytes.TrimSpace(current.body)) > 0 {
+				documents = append(documents, *current)
+			}
+		}
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(output)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimRight(line, " \t") == "---" {
+			finish()
+			current = &helmDocument{}
+			continue
+		}
+		if current == nil {
+			current = &helmDocument{}
+		}
+		if len(current.body) == 0 && current.template == "" && strings.HasPrefix(line, helmSourcePrefix) {
+			current.template = strings.TrimSpace(strings.TrimPrefix(line, helmSourcePrefix))
+			continue
+		}
+		if len(current.body) == 0 {
+			current.line = max(lines[current.template], 1)
+		}
+		current.body = append(current.body, line...)
+		current.body = append(current.body, '\n')
+	}
+	finish()
+	return documents
+}
+
+// renderHelmChart renders chart as `helm template` does: on the client, against the
+// default capabilities, with the chart's hooks written after its manifests.
+func renderHelmChart(ctx context.Context, chart string, opts HelmOptions) ([]byte, error) {
+	settings := cli.New()
+	registryClient, err := registry.NewClient(
+		registry.ClientOptEnableCache(true),
+		registry.ClientOptWriter(io.Discard),
+		registry.ClientOptCredentialsFile(settings.RegistryConfig),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	install := action.NewInstall(&action.Configuration{Log: func(string, ...interface{}) {}})
+	install.SetRegistryClient(registryClient)
+	install.DryRun = true
+	install.DryRunOption = "client"
+	install.ClientOnly = true
+	install.Replace = true
+	install.ReleaseName = opts.ReleaseName
+	if install.ReleaseName == "" {
+		install.ReleaseName = "release"
+	}
+	install.Namespace = opts.Namespace
+	if install.Namespace == "" {
+		install.Namespace = settings.Namespace()
+	}
+	install.Version = opts.Version
+
+	chartPath, err := install.LocateChart(chart, settings)
+	if err != nil {
+		return nil, err
+	}
+	vals, err := (&values.Options{ValueFiles: opts.ValuesFiles, Values: opts.Set}).MergeValues(getter.All(settings))
+	if err != nil {
+		return nil, err
+	}
+	loaded, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, err
+	}
+	if chartType := loaded.Metadata.Type; chartType != "" && chartType != "application" {
+		return nil, fmt.Errorf("%s charts cannot be rendered", chartType)
+	}
+	if dependencies := loaded.Metadata.Dependencies; dependencies != nil {
+		if err := action.CheckDependencies(loaded, dependencies); err != nil {
+			return nil, fmt.Errorf("%v; run helm dependency build first", err)
+		}
+	}
+
+	rendered, err := install.RunWithContext(ctx, loaded, vals)
+	if err != nil {
+		return nil, err
+	}
+	var output bytes.Buffer
+	fmt.Fprintln(&output, strings.TrimSpace(rendered.Manifest))
+	for _, hook := range rendered.Hooks {
+		fmt.Fprintf(&output, "---\n%s%s\n%s\n", helmSourcePrefix, hook.Path, hook.Manifest)
+	}
+	return output.Bytes(), nil
+}
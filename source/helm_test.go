@@ -0,0 +1,103 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestChart writes a chart named app with the given templates to a temporary directory
+// and returns its path.
+func writeTestChart(t *testing.T, templates map[string]string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "app")
+	files := map[string]string{
+		"Chart.yaml":  "apiVersion: v2\nname: app\nversion: 0.1.0\n",
+		"values.yaml": "component: web\n",
+	}
+	for name, template := range templates {
+		files[filepath.Join("templates", name)] = template
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestHelm(t *testing.T) {
+	chart := writeTestChart(t, map[string]string{
+		"deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}-{{ .Values.component }}
+  namespace: {{ .Release.Namespace }}
+`,
+		"job.yaml": `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: {{ .Release.Name }}-migrate
+  annotations: {"helm.sh/hook": pre-install}
+`,
+	})
+
+	tests := []struct {
+		name string
+		opts HelmOptions
+		// want holds the template and object name of each document, in order
+		want []string
+	}{
+		{"defaults", HelmOptions{},
+			[]string{"app/templates/deployment.yaml", "release-web", "app/templates/job.yaml", "release-migrate"}},
+		{"release name and overrides", HelmOptions{ReleaseName: "shop", Namespace: "apps", Set: []string{"component=api"}},
+			[]string{"app/templates/deployment.yaml", "shop-api", "app/templates/job.yaml", "shop-migrate"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := Helm(context.Background(), chart, tt.opts)
+			got := make([]string, 0)
+			for {
+				doc, err := src.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				metadata, _ := doc.Object["metadata"].(map[string]interface{})
+				name, _ := metadata["name"].(string)
+				got = append(got, doc.Source, name)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d documents, got %q", len(tt.want)/2, got)
+			}
+			for i := 0; i < len(got); i += 2 {
+				if !strings.HasSuffix(got[i], tt.want[i]) {
+					t.Errorf("expected document %d from %q, got %q", i/2, tt.want[i], got[i])
+				}
+				if got[i+1] != tt.want[i+1] {
+					t.Errorf("expected document %d to be named %q, got %q", i/2, tt.want[i+1], got[i+1])
+				}
+			}
+		})
+	}
+}
+
+func TestHelmRenderError(t *testing.T) {
+	chart := writeTestChart(t, map[string]string{"broken.yaml": "{{ .Values.missing.field }}\n"})
+
+	_, err := Helm(context.Background(), chart, HelmOptions{}).Next()
+	if err == nil || !strings.Contains(err.Error(), "helm template "+chart) {
+		t.Errorf("expected an error naming the chart, got %v", err)
+	}
+}
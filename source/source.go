@@ -0,0 +1,336 @@
+// Package source provides k8sconstraints.Source implementations for the places manifests
+// usually come from: standard input, Helm charts (including charts in OCI registries),
+// kustomizations, and live clusters, and a check validating manifests against a live
+// cluster with a server-side dry run. Helm charts are rendered with the Helm SDK, and
+// clusters are reached with client-go using the kubeconfig kubectl uses; only the
+// kustomize source runs a binary, kubectl, found on PATH.
+package source
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+
+	k8sconstraints "github.com/martinflemingdev/k8s_constraints"
+)
+
+// Stdin returns a Source over the manifests piped to standard input, which may be YAML or
+// JSON; see k8sconstraints.NewReaderSource.
+func Stdin() k8sconstraints.Source {
+	return k8sconstraints.NewReaderSource("<stdin>", os.Stdin)
+}
+
+// commandSource runs a command on the first call to Next and decodes its standard output
+// with decode.
+type commandSource struct {
+	ctx    context.Context
+	name   string
+	args   []string
+	decode func(name string, output []byte) k8sconstraints.Source
+	output k8sconstraints.Source
+}
+
+// Command returns a Source over the YAML a command writes to standard output. The command
+// runs on the first call to Next; a non-zero exit is returned as an error that includes
+// its standard error.
+func Command(ctx context.Context, name string, args ...string) k8sconstraints.Source {
+	return &commandSource{ctx: ctx, name: name, args: args, decode: decodeYAML}
+}
+
+// decodeYAML decodes command output as multi-document YAML.
+func decodeYAML(name string, output []byte) k8sconstraints.Source {
+	return k8sconstraints.NewYAMLSource(name, bytes.NewReader(output))
+}
+
+// Next runs the command if it has not run yet and returns its next document.
+func (s *commandSource) Next() (k8sconstraints.Document, error) {
+	if s.output == nil {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(s.ctx, s.name, s.args...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			s.output = k8sconstraints.MultiSource()
+			if message := strings.TrimSpace(stderr.String()); message != "" {
+				return k8sconstraints.Document{}, fmt.Errorf("%s: %v: %s", s.String(), err, message)
+			}
+			return k8sconstraints.Document{}, fmt.Errorf("%s: %v", s.String(), err)
+		}
+		s.output = s.decode(s.String(), stdout.Bytes())
+	}
+	return s.output.Next()
+}
+
+// String renders the command line, which is also used as the documents' source name.
+func (s *commandSource) String() string {
+	return strings.Join(append([]string{s.name}, s.args...), " ")
+}
+
+// HelmOptions configures how a chart is rendered.
+type HelmOptions struct {
+	// ReleaseName is the release name the chart is rendered as. Defaults to "release".
+	ReleaseName string
+	// Namespace is the release namespace. Defaults to the namespace of the current
+	// kubeconfig context, as with helm template.
+	Namespace string
+	// Version pins the chart version for repository and OCI charts.
+	Version string
+	// ValuesFiles are merged in order, as --values files are.
+	ValuesFiles []string
+	// Set holds key=value overrides applied in order, as --set overrides are.
+	Set []string
+}
+
+// helmSource renders a chart on the first call to Next.
+type helmSource struct {
+	ctx    context.Context
+	chart  string
+	opts   HelmOptions
+	output k8sconstraints.Source
+}
+
+// Helm returns a Source over the manifests `helm template` renders for chart, which may be
+// a local path, a repo/chart reference, or an oci:// reference. The chart is rendered with
+// the Helm SDK on the first call to Next, reading repositories and registry credentials
+// from the Helm configuration helm itself uses. Documents are attributed to the template
+// that rendered them; see HelmTemplateSource.
+func Helm(ctx context.Context, chart string, opts HelmOptions) k8sconstraints.Source {
+	return &helmSource{ctx: ctx, chart: chart, opts: opts}
+}
+
+// Next renders the chart if it has not been rendered yet and returns its next document.
+func (s *helmSource) Next() (k8sconstraints.Document, error) {
+	if s.output == nil {
+		output, err := renderHelmChart(s.ctx, s.chart, s.opts)
+		if err != nil {
+			s.output = k8sconstraints.MultiSource()
+			return k8sconstraints.Document{}, fmt.Errorf("%s: %v", s.String(), err)
+		}
+		s.output = HelmTemplateSource(s.String(), s.chart, output)
+	}
+	return s.output.Next()
+}
+
+// String names the chart, which is also used as the source name of documents rendered
+// without a Source comment.
+func (s *helmSource) String() string {
+	return "helm template " + s.chart
+}
+
+// OCI returns a Source over a Helm chart stored in an OCI registry, such as
+// oci://registry.example.com/charts/app. Only charts are supported: plain manifest
+// artifacts have no standard media type to render them from.
+func OCI(ctx context.Context, ref string, opts HelmOptions) k8sconstraints.Source {
+	if !strings.HasPrefix(ref, "oci://") {
+		ref = "oci://" + ref
+	}
+	return Helm(ctx, ref, opts)
+}
+
+// Kustomize returns a Source over the manifests built by `kubectl kustomize` for the
+// kustomization in dir.
+func Kustomize(ctx context.Context, dir string) k8sconstraints.Source {
+	return Command(ctx, "kubectl", "kustomize", dir)
+}
+
+// ClusterOptions selects the live objects read by Cluster.
+type ClusterOptions struct {
+	// Context is the kubeconfig context to use. Defaults to the current context.
+	Context string
+	// Namespace restricts the query to one namespace. Ignored when AllNamespaces is set.
+	Namespace string
+	// AllNamespaces queries every namespace.
+	AllNamespaces bool
+	// Resources are the resource types to read, e.g. "deployments" or "ingresses.networking.k8s.io".
+	Resources []string
+}
+
+// clusterSource reads the objects selected by opts on the first call to Next.
+type clusterSource struct {
+	ctx     context.Context
+	opts    ClusterOptions
+	objects []k8sconstraints.Document
+	err     error
+	read    bool
+}
+
+// Cluster returns a Source over the live objects of the resource types opts selects, read
+// with the dynamic client on the first call to Next. Resources are named as kubectl get
+// names them, including short names such as "deploy" and categories such as "all", the
+// default. Without a namespace, namespaced resources are read from the default namespace
+// of the kubeconfig context. Managed fields are dropped, as kubectl get drops them.
+func Cluster(ctx context.Context, opts ClusterOptions) k8sconstraints.Source {
+	return &clusterSource{ctx: ctx, opts: opts}
+}
+
+// Next reads the objects if they have not been read yet and returns the next one.
+func (s *clusterSource) Next() (k8sconstraints.Document, error) {
+	if !s.read {
+		s.read = true
+		s.objects, s.err = listClusterObjects(s.ctx, s.opts)
+	}
+	if s.err != nil {
+		err := s.err
+		s.err = nil
+		return k8sconstraints.Document{}, err
+	}
+	if len(s.objects) == 0 {
+		return k8sconstraints.Document{}, io.EOF
+	}
+	doc := s.objects[0]
+	s.objects = s.objects[1:]
+	return doc, nil
+}
+
+// listClusterObjects lists the objects of the resource types opts selects, in the order
+// the types are named, as documents of a source named after the cluster.
+func listClusterObjects(ctx context.Context, opts ClusterOptions) ([]k8sconstraints.Document, error) {
+	client, err := newClusterClient(opts.Context)
+	if err != nil {
+		return nil, err
+	}
+	names := opts.Resources
+	if len(names) == 0 {
+		names = []string{"all"}
+	}
+	resources, err := client.resolveResources(names)
+	if err != nil {
+		return nil, err
+	}
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = client.namespace
+	}
+
+	docs := make([]k8sconstraints.Document, 0)
+	for _, resource := range resources {
+		var list *unstructured.UnstructuredList
+		if resource.namespaced && !opts.AllNamespaces {
+			list, err = client.dynamic.Resource(resource.gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		} else {
+			list, err = client.dynamic.Resource(resource.gvr).List(ctx, metav1.ListOptions{})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: listing %s: %v", client, resource.gvr.GroupResource(), err)
+		}
+		for _, item := range list.Items {
+			unstructured.RemoveNestedField(item.Object, "metadata", "managedFields")
+			docs = append(docs, k8sconstraints.Document{Source: client.String(), Index: len(docs), Object: item.Object})
+		}
+	}
+	return docs, nil
+}
+
+// clusterResource is a resource type served by a cluster.
+type clusterResource struct {
+	gvr        schema.GroupVersionResource
+	namespaced bool
+}
+
+// resolveResources resolves resource type names, such as "deployments",
+// "ingresses.networking.k8s.io", "deploy", or the category "all", to the resource types
+// they name, in order and without duplicates.
+func (c *clusterClient) resolveResources(names []string) ([]clusterResource, error) {
+	categories := restmapper.NewDiscoveryCategoryExpander(c.discovery)
+	resources := make([]clusterResource, 0)
+	seen := make(map[schema.GroupVersionResource]bool)
+	for _, name := range names {
+		partial := make([]schema.GroupVersionResource, 0)
+		if groupResources, ok := categories.Expand(name); ok {
+			for _, groupResource := range groupResources {
+				partial = append(partial, groupResource.WithVersion(""))
+			}
+		} else if gvr, groupResource := schema.ParseResourceArg(name); gvr != nil && c.serves(*gvr) {
+			partial = append(partial, *gvr)
+		} else {
+			partial = append(partial, groupResource.WithVersion(""))
+		}
+
+		for _, resource := range partial {
+			gvr, err := c.mapper.ResourceFor(resource)
+			if err != nil {
+				return nil, fmt.Errorf("%s: unknown resource type '%s': %v", c, name, err)
+			}
+			if seen[gvr] {
+				continue
+			}
+			seen[gvr] = true
+			gvk, err := c.mapper.KindFor(gvr)
+			if err != nil {
+				return nil, fmt.Errorf("%s: unknown resource type '%s': %v", c, name, err)
+			}
+			mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+			if err != nil {
+				return nil, fmt.Errorf("%s: unknown resource type '%s': %v", c, name, err)
+			}
+			resources = append(resources, clusterResource{gvr: gvr, namespaced: mapping.Scope.Name() == meta.RESTScopeNameNamespace})
+		}
+	}
+	return resources, nil
+}
+
+// serves reports whether the cluster serves the resource type gvr.
+func (c *clusterClient) serves(gvr schema.GroupVersionResource) bool {
+	_, err := c.mapper.ResourceFor(gvr)
+	return err == nil
+}
+
+// Reader returns a Source over the multi-document YAML or the JSON objects read from r.
+func Reader(name string, r io.Reader) k8sconstraints.Source {
+	return k8sconstraints.NewReaderSource(name, r)
+}
+
+// Contexts returns the kubeconfig contexts whose names match any of the glob patterns,
+// sorted by name, as kubectl config get-contexts lists them. Patterns use path.Match
+// syntax, e.g. "prod-*". A pattern that matches no context is an error, so typos do not
+// silently shrink a fleet scan.
+func Contexts(ctx context.Context, patterns ...string) ([]string, error) {
+	config, err := clientcmd.NewDefaultClientConfigLoadingRules().Load()
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig: %v", err)
+	}
+	names := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	matched := make(map[string]bool, len(names))
+	for _, pattern := range patterns {
+		found := false
+		for _, name := range names {
+			ok, err := path.Match(pattern, name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid context pattern '%s': %v", pattern, err)
+			}
+			if ok {
+				matched[name] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("context pattern '%s' matched no kubeconfig context", pattern)
+		}
+	}
+
+	contexts := make([]string, 0, len(matched))
+	for _, name := range names {
+		if matched[name] {
+			contexts = append(contexts, name)
+		}
+	}
+	return contexts, nil
+}
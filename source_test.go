@@ -0,0 +1,57 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNewReaderSource(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		// want holds, for each document, its kind, or part of its decode error
+		want []string
+	}{
+		{"YAML", "kind: A\n---\nkind: B\n", []string{"A", "B"}},
+		{"JSON object", `{"kind": "A"}`, []string{"A"}},
+		{"concatenated JSON objects", `{"kind": "A"}{"kind": "B"}` + "\n" + `{"kind": "C"}`, []string{"A", "B", "C"}},
+		{"JSON after whitespace", "\n\t  {\"kind\": \"A\"}", []string{"A"}},
+		{"JSON array", `[{"kind": "A"}]`, []string{"invalid JSON: document must be an object, not a JSON array"}},
+		{"invalid JSON", `{"kind": "A"} {"kind": `, []string{"A", "invalid JSON"}},
+		{"YAML starting with a comment", "# {\"kind\": \"A\"}\nkind: B\n", []string{"B"}},
+		{"empty", "", nil},
+		{"whitespace only", " \n\t", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := NewReaderSource("<stdin>", strings.NewReader(tt.input))
+			got := make([]string, 0)
+			for {
+				doc, err := source.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				if doc.Err != nil {
+					got = append(got, doc.Err.Error())
+					continue
+				}
+				kind, _ := doc.Object["kind"].(string)
+				got = append(got, kind)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d documents %v, got %v", len(tt.want), tt.want, got)
+			}
+			for i := range tt.want {
+				if !strings.Contains(got[i], tt.want[i]) {
+					t.Errorf("document %d: expected %q, got %q", i, tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package k8sconstraints
+
+var (
+	// statefulSetUpdateStrategyTypes lists the valid spec.updateStrategy.type values of a
+	// StatefulSet.
+	statefulSetUpdateStrategyTypes = []string{"RollingUpdate", "OnDelete"}
+
+	// podManagementPolicies lists the valid spec.podManagementPolicy values of a StatefulSet.
+	podManagementPolicies = []string{"OrderedReady", "Parallel"}
+)
+
+// ValidateStatefulSet validates the spec of an apps/v1 StatefulSet: the update strategy
+// type, that rollingUpdate is only set for the RollingUpdate strategy, and the pod
+// management policy.
+func ValidateStatefulSet(obj map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	strategyPath := NewPath("spec", "updateStrategy")
+	strategyType, _ := nestedString(obj, "spec", "updateStrategy", "type")
+	if strategyType != "" {
+		if err := validateEnumField(strategyPath.Child("type").String(), strategyType, statefulSetUpdateStrategyTypes); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if _, ok := nestedMap(obj, "spec", "updateStrategy", "rollingUpdate"); ok && strategyType == "OnDelete" {
+		errs = append(errs, Invalid(strategyPath.Child("rollingUpdate"), nil, "rollingUpdate may only be set when type is RollingUpdate"))
+	}
+
+	if policy, _ := nestedString(obj, "spec", "podManagementPolicy"); policy != "" {
+		if err := validateEnumField("spec.podManagementPolicy", policy, podManagementPolicies); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleStatefulSet, JoinErrors(errs))
+	}
+
+	return nil
+}
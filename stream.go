@@ -0,0 +1,630 @@
+package k8sconstraints
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlItemsKeyPattern matches a top-level items key whose value starts on the next line.
+var yamlItemsKeyPattern = regexp.MustCompile(`^items:[ \t]*(#.*)?$`)
+
+// NewStreamSource returns a Source over r that holds at most one object in memory at a
+// time, for exports too large to load whole, such as kubectl get -A -o yaml. It detects
+// the format like NewReaderSource. The items of a List document are decoded and yielded
+// one by one as separate documents, with Document.Path naming the item, e.g. items[3];
+// an empty List is yielded whole. Items that omit apiVersion or kind are held back until
+// the end of the List, which may declare the type they inherit after the items, as kubectl
+// does. A document is treated as a List when it has a top-level items sequence; in YAML,
+// that sequence must be in block style.
+func NewStreamSource(name string, r io.Reader) Source {
+	buffered := bufio.NewReader(r)
+	for {
+		c, err := buffered.Peek(1)
+		if err != nil || !unicode.IsSpace(rune(c[0])) {
+			if err == nil && (c[0] == '{' || c[0] == '[') {
+				return newJSONItemSource(name, buffered)
+			}
+			return &yamlItemSource{name: name, reader: buffered}
+		}
+		if _, err := buffered.ReadByte(); err != nil {
+			return &yamlItemSource{name: name, reader: buffered}
+		}
+	}
+}
+
+// streamFileSource reads a list of files one after the other with NewStreamSource.
+type streamFileSource struct {
+	paths   []string
+	file    *os.File
+	current Source
+}
+
+// NewStreamFileSource returns a Source that reads the given files in order like
+// NewFileSource, but streams YAML and JSON files with NewStreamSource instead of reading
+// them whole. Markdown and Terraform files are read whole, as with NewFileSource.
+func NewStreamFileSource(paths ...string) Source {
+	return &streamFileSource{paths: paths}
+}
+
+// Next returns the next document of the current file, opening the next file as needed.
+func (s *streamFileSource) Next() (Document, error) {
+	for {
+		if s.current != nil {
+			doc, err := s.current.Next()
+			if !errors.Is(err, io.EOF) {
+				return doc, err
+			}
+			s.close()
+		}
+		if len(s.paths) == 0 {
+			return Document{}, io.EOF
+		}
+
+		path := s.paths[0]
+		s.paths = s.paths[1:]
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".md", ".markdown", ".tf":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return Document{}, err
+			}
+			s.current = NewEmbeddedSource(path, data)
+		default:
+			file, err := os.Open(path)
+			if err != nil {
+				return Document{}, err
+			}
+			s.file = file
+			s.current = NewStreamSource(path, file)
+		}
+	}
+}
+
+// close releases the current file.
+func (s *streamFileSource) close() {
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+	s.current = nil
+}
+
+// listItem is a streamed List item held back until the List's type is known.
+type listItem struct {
+	doc  Document
+	item map[string]interface{}
+}
+
+// listStream tracks the List document being streamed by a yamlItemSource or jsonItemSource.
+type listStream struct {
+	// sawItems is set once the document turned out to have an items sequence
+	sawItems bool
+	// yielded counts the items yielded so far
+	yielded int
+	// held holds the items waiting for the List's type
+	held []listItem
+}
+
+// add yields doc, the item at index of the List, unless it has to wait for the List's type.
+func (l *listStream) add(doc Document, queue *[]Document) {
+	l.sawItems = true
+	if doc.Object != nil {
+		_, hasAPIVersion := doc.Object["apiVersion"]
+		_, hasKind := doc.Object["kind"]
+		if !hasAPIVersion || !hasKind {
+			l.held = append(l.held, listItem{doc: doc, item: doc.Object})
+			return
+		}
+	}
+	l.yielded++
+	*queue = append(*queue, doc)
+}
+
+// finish releases the held items with the type meta they inherit from the List header.
+func (l *listStream) finish(header map[string]interface{}, queue *[]Document) {
+	kind, _ := nestedString(header, "kind")
+	apiVersion, _ := nestedString(header, "apiVersion")
+	itemKind := ""
+	if strings.HasSuffix(kind, "List") {
+		itemKind = strings.TrimSuffix(kind, "List")
+	}
+	for _, held := range l.held {
+		doc := held.doc
+		if itemKind != "" {
+			doc.Object = withDefaultTypeMeta(held.item, apiVersion, itemKind)
+		}
+		l.yielded++
+		*queue = append(*queue, doc)
+	}
+	*l = listStream{}
+}
+
+// listItemDocument returns the document of a List item, or a decode error for items that
+// are not objects.
+func listItemDocument(name string, index int, line int, path string, value interface{}) Document {
+	doc := Document{Source: name, Index: index, Line: line, Path: path}
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		doc.Err = &ConstraintError{FieldPath: path, Rule: RuleDecode, Message: "list item must be an object"}
+		return doc
+	}
+	doc.Object = obj
+	return doc
+}
+
+// yamlItemSource splits a multi-document YAML stream into documents line by line, so that
+// the top-level items sequence of a List can be decoded one item at a time.
+type yamlItemSource struct {
+	name   string
+	reader *bufio.Reader
+	line   int
+	index  int
+	queue  []Document
+	done   bool
+	// next holds a line to process before reading on, such as the content after ---
+	next    string
+	hasNext bool
+
+	// header holds the lines of the current document outside the items sequence
+	header      []string
+	headerStart int
+	// pendingItems holds the items key and the blank or comment lines after it while it is
+	// not yet known whether a block sequence follows
+	pendingItems []string
+	pendingStart int
+	awaiting     bool
+	// the items sequence being read
+	inItems    bool
+	seqIndent  int
+	item       []string
+	itemStart  int
+	itemNumber int
+	list       listStream
+}
+
+// Next returns the next document or List item.
+func (s *yamlItemSource) Next() (Document, error) {
+	for {
+		if len(s.queue) > 0 {
+			doc := s.queue[0]
+			s.queue = s.queue[1:]
+			return doc, nil
+		}
+		if s.done {
+			return Document{}, io.EOF
+		}
+
+		line, err := s.readLine()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				return Document{}, err
+			}
+			s.endDocument()
+			s.done = true
+			continue
+		}
+		s.processLine(line)
+	}
+}
+
+// readLine returns the next line without its line ending.
+func (s *yamlItemSource) readLine() (string, error) {
+	if s.hasNext {
+		s.hasNext = false
+		return s.next, nil
+	}
+	line, err := s.reader.ReadString('\n')
+	if line == "" && err != nil {
+		return "", err
+	}
+	s.line++
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// processLine files one line under the current document.
+func (s *yamlItemSource) processLine(line string) {
+	// Document boundaries
+	if line == "---" || line == "..." || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "---\t") {
+		s.endDocument()
+		// Content after --- on the same line, such as a tag or a flow mapping, starts the
+		// next document
+		if rest := strings.TrimSpace(strings.TrimPrefix(line, "---")); line != "..." && rest != "" && !strings.HasPrefix(rest, "#") {
+			s.next, s.hasNext = rest, true
+		}
+		return
+	}
+	if strings.HasPrefix(line, "%") && len(s.header) == 0 {
+		// Directives only apply to the YAML parser
+		return
+	}
+
+	significant := strings.TrimSpace(line) != "" && !strings.HasPrefix(strings.TrimSpace(line), "#")
+	indent := len(line) - len(strings.TrimLeft(line, " "))
+
+	if s.inItems {
+		switch {
+		case !significant || indent > s.seqIndent:
+			s.item = append(s.item, line)
+			return
+		case indent == s.seqIndent && isYAMLSequenceEntry(line[indent:]):
+			s.flushItem()
+			s.startItem(line)
+			return
+		}
+		// The sequence ended; the line belongs to the header
+		s.flushItem()
+		s.inItems = false
+	}
+
+	if s.awaiting {
+		if !significant {
+			s.pendingItems = append(s.pendingItems, line)
+			return
+		}
+		s.awaiting = false
+		if isYAMLSequenceEntry(line[indent:]) {
+			s.pendingItems = nil
+			s.inItems = true
+			s.seqIndent = indent
+			s.startItem(line)
+			return
+		}
+		// items is not a block sequence; keep the document whole
+		s.appendHeader(s.pendingStart, s.pendingItems...)
+		s.pendingItems = nil
+	}
+
+	if !s.list.sawItems && yamlItemsKeyPattern.MatchString(line) {
+		s.awaiting = true
+		s.pendingItems = append(s.pendingItems[:0], line)
+		s.pendingStart = s.line
+		return
+	}
+	s.appendHeader(s.line, line)
+}
+
+// isYAMLSequenceEntry reports whether s, with indentation removed, starts a block sequence
+// entry.
+func isYAMLSequenceEntry(s string) bool {
+	return s == "-" || strings.HasPrefix(s, "- ") || strings.HasPrefix(s, "-\t")
+}
+
+// appendHeader adds lines, the first of which is line start, to the header of the current
+// document.
+func (s *yamlItemSource) appendHeader(start int, lines ...string) {
+	if len(s.header) == 0 {
+		s.headerStart = start
+	}
+	s.header = append(s.header, lines...)
+}
+
+// startItem begins a new item of the items sequence at line.
+func (s *yamlItemSource) startItem(line string) {
+	// Replacing the dash with a space keeps the item's own indentation consistent
+	s.item = []string{line[:s.seqIndent] + " " + line[s.seqIndent+1:]}
+	s.itemStart = s.line
+}
+
+// flushItem decodes and yields the item being read.
+func (s *yamlItemSource) flushItem() {
+	if s.item == nil {
+		return
+	}
+	path := fmt.Sprintf("items[%d]", s.itemNumber)
+	s.itemNumber++
+
+	var node yaml.Node
+	err := yaml.Unmarshal([]byte(strings.Join(s.item, "\n")), &node)
+	s.item = nil
+	if err != nil {
+		s.list.add(Document{Source: s.name, Index: s.index, Line: s.itemStart, Path: path, Err: fmt.Errorf("invalid YAML in %s: %v", path, err)}, &s.queue)
+		return
+	}
+	shiftYAMLLines(&node, s.itemStart-1)
+
+	var value interface{}
+	if !isEmptyYAMLDocument(&node) {
+		if node.Content[0].Kind != yaml.MappingNode {
+			value = node.Content[0].Value
+		} else if obj, err := decodeYAMLObject(&node); err != nil {
+			s.list.add(Document{Source: s.name, Index: s.index, Line: s.itemStart, Path: path, Err: err}, &s.queue)
+			return
+		} else {
+			value = obj
+		}
+	}
+	s.list.add(listItemDocument(s.name, s.index, s.itemStart, path, value), &s.queue)
+}
+
+// endDocument finishes the current document, yielding it whole or releasing the held
+// items of a List.
+func (s *yamlItemSource) endDocument() {
+	if s.inItems {
+		s.flushItem()
+		s.inItems = false
+	}
+	if s.awaiting {
+		s.appendHeader(s.pendingStart, s.pendingItems...)
+		s.pendingItems = nil
+		s.awaiting = false
+	}
+	header, start := s.header, s.headerStart
+	s.header = nil
+	s.itemNumber = 0
+
+	var node yaml.Node
+	err := yaml.Unmarshal([]byte(strings.Join(header, "\n")), &node)
+	if err == nil {
+		shiftYAMLLines(&node, start-1)
+	}
+
+	if s.list.sawItems {
+		var obj map[string]interface{}
+		if err == nil && !isEmptyYAMLDocument(&node) && node.Content[0].Kind == yaml.MappingNode {
+			obj, _ = decodeYAMLObject(&node)
+		}
+		s.list.finish(obj, &s.queue)
+		s.index++
+		return
+	}
+
+	if err != nil {
+		s.queue = append(s.queue, Document{Source: s.name, Index: s.index, Line: start, Err: fmt.Errorf("invalid YAML: %v", err)})
+		s.index++
+		return
+	}
+	if isEmptyYAMLDocument(&node) {
+		return
+	}
+	doc := Document{Source: s.name, Index: s.index, Line: node.Content[0].Line}
+	doc.Object, doc.Err = decodeYAMLObject(&node)
+	s.queue = append(s.queue, doc)
+	s.index++
+}
+
+// shiftYAMLLines moves every node below node down by delta lines, so that nodes decoded
+// from part of a file report their line in the file.
+func shiftYAMLLines(node *yaml.Node, delta int) {
+	if node.Line > 0 {
+		node.Line += delta
+	}
+	for _, child := range node.Content {
+		shiftYAMLLines(child, delta)
+	}
+}
+
+// jsonItemSource decodes a stream of JSON objects token by token, so that the top-level
+// items array of a List can be decoded one item at a time.
+type jsonItemSource struct {
+	name    string
+	lines   *lineCounter
+	decoder *json.Decoder
+	index   int
+	queue   []Document
+	done    bool
+
+	// the top-level object being read, without its items
+	inObject   bool
+	header     map[string]interface{}
+	line       int
+	inItems    bool
+	itemNumber int
+	list       listStream
+}
+
+// newJSONItemSource returns a jsonItemSource over r.
+func newJSONItemSource(name string, r io.Reader) *jsonItemSource {
+	lines := &lineCounter{r: r}
+	return &jsonItemSource{name: name, lines: lines, decoder: json.NewDecoder(lines)}
+}
+
+// Next returns the next document or List item. A syntax error is reported through
+// Document.Err and ends the stream.
+func (s *jsonItemSource) Next() (Document, error) {
+	for {
+		if len(s.queue) > 0 {
+			doc := s.queue[0]
+			s.queue = s.queue[1:]
+			return doc, nil
+		}
+		if s.done {
+			return Document{}, io.EOF
+		}
+		if err := s.step(); err != nil {
+			s.done = true
+			var syntaxErr *json.SyntaxError
+			if errors.Is(err, io.EOF) && !s.inObject {
+				continue
+			}
+			if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) || errors.As(err, &syntaxErr) {
+				s.queue = append(s.queue, Document{Source: s.name, Index: s.index, Line: s.line, Err: fmt.Errorf("invalid JSON: %v", err)})
+				continue
+			}
+			return Document{}, err
+		}
+	}
+}
+
+// step reads the next item, field, or top-level value.
+func (s *jsonItemSource) step() error {
+	switch {
+	case s.inItems:
+		if s.decoder.More() {
+			line := s.nextLine()
+			var value interface{}
+			if err := s.decoder.Decode(&value); err != nil {
+				return err
+			}
+			path := fmt.Sprintf("items[%d]", s.itemNumber)
+			s.itemNumber++
+			s.list.add(listItemDocument(s.name, s.index, line, path, value), &s.queue)
+			return nil
+		}
+		if _, err := s.decoder.Token(); err != nil {
+			return err
+		}
+		s.inItems = false
+		return nil
+
+	case s.inObject:
+		token, err := s.decoder.Token()
+		if err != nil {
+			return err
+		}
+		if token == json.Delim('}') {
+			s.endObject()
+			return nil
+		}
+		key, _ := token.(string)
+		value, err := s.decoder.Token()
+		if err != nil {
+			return err
+		}
+		if key == "items" && value == json.Delim('[') {
+			s.inItems = true
+			s.list.sawItems = true
+			return nil
+		}
+		decoded, err := decodeJSONTokens(s.decoder, value)
+		if err != nil {
+			return err
+		}
+		s.header[key] = decoded
+		return nil
+	}
+
+	if !s.decoder.More() {
+		return io.EOF
+	}
+	s.line = s.nextLine()
+	token, err := s.decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != json.Delim('{') {
+		value, err := decodeJSONTokens(s.decoder, token)
+		if err != nil {
+			return err
+		}
+		s.queue = append(s.queue, Document{Source: s.name, Index: s.index, Line: s.line, Err: fmt.Errorf("invalid JSON: document must be an object, not a JSON %s", jsonTypeName(value))})
+		s.index++
+		return nil
+	}
+	s.inObject = true
+	s.header = make(map[string]interface{})
+	return nil
+}
+
+// endObject finishes the top-level object, yielding it whole or releasing the held items
+// of a List.
+func (s *jsonItemSource) endObject() {
+	if s.list.sawItems {
+		if s.list.yielded == 0 && len(s.list.held) == 0 {
+			// An empty List has no items to stand for it
+			s.header["items"] = []interface{}{}
+			s.queue = append(s.queue, Document{Source: s.name, Index: s.index, Line: s.line, Object: s.header})
+		}
+		s.list.finish(s.header, &s.queue)
+	} else {
+		s.queue = append(s.queue, Document{Source: s.name, Index: s.index, Line: s.line, Object: s.header})
+	}
+	s.inObject = false
+	s.header = nil
+	s.itemNumber = 0
+	s.index++
+}
+
+// nextLine returns the line of the next value.
+func (s *jsonItemSource) nextLine() int {
+	return s.lines.lineAt(s.decoder.InputOffset())
+}
+
+// decodeJSONTokens decodes the JSON value starting with token, reading the rest of it from
+// decoder token by token.
+func decodeJSONTokens(decoder *json.Decoder, token json.Token) (interface{}, error) {
+	switch token {
+	case json.Delim('{'):
+		obj := make(map[string]interface{})
+		for {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			if key == json.Delim('}') {
+				return obj, nil
+			}
+			next, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSONTokens(decoder, next)
+			if err != nil {
+				return nil, err
+			}
+			obj[key.(string)] = value
+		}
+	case json.Delim('['):
+		list := make([]interface{}, 0)
+		for {
+			next, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			if next == json.Delim(']') {
+				return list, nil
+			}
+			value, err := decodeJSONTokens(decoder, next)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+	}
+	return token, nil
+}
+
+// lineCounter maps byte offsets of the stream it reads to line numbers. It only keeps the
+// bytes that have been read but not yet passed, so it works on streams of any size as long
+// as offsets are looked up in increasing order.
+type lineCounter struct {
+	r io.Reader
+	// data holds the bytes read from offset start on
+	data  []byte
+	start int64
+	line  int
+}
+
+// Read reads from the underlying reader, keeping a copy of the bytes read.
+func (c *lineCounter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.data = append(c.data, p[:n]...)
+	return n, err
+}
+
+// lineAt returns the one-based line of the value at or after offset, skipping whitespace
+// and a separating comma, since decoders may report the offset of either. offset must not
+// be less than any offset looked up before.
+func (c *lineCounter) lineAt(offset int64) int {
+	skip := int(offset - c.start)
+	if skip < 0 {
+		skip = 0
+	}
+	for skip < len(c.data) && (c.data[skip] == ',' || unicode.IsSpace(rune(c.data[skip]))) {
+		skip++
+	}
+	c.line += bytes.Count(c.data[:skip], []byte{'\n'})
+	c.data = append(c.data[:0], c.data[skip:]...)
+	c.start += int64(skip)
+	return c.line + 1
+}
@@ -0,0 +1,163 @@
+package k8sconstraints
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Kinds of per-namespace tenant reports built by TenantReportObject.
+const (
+	TenantReportPolicyReport = "PolicyReport"
+	TenantReportConfigMap    = "ConfigMap"
+	TenantReportSecret       = "Secret"
+)
+
+// TenantReportName is the metadata.name of the objects built by TenantReportObject.
+const TenantReportName = "k8sconstraints-report"
+
+// tenantReportDataKey is the ConfigMap and Secret key holding the JSON report.
+const tenantReportDataKey = "report.json"
+
+// PartitionByNamespace splits a report into one report per namespace, so each tenant can be
+// shown only the resources and findings of its own namespace. Cluster-scoped resources and
+// findings not tied to a namespaced resource are keyed by "". Documents counts the
+// documents contributing resources or findings to each partition; Files, Score, and Targets
+// are not carried over.
+func PartitionByNamespace(report Report) map[string]Report {
+	type documentKey struct {
+		file     string
+		document int
+	}
+
+	partitions := make(map[string]Report)
+	documents := make(map[string]map[documentKey]bool)
+	partition := func(namespace string, key documentKey) Report {
+		if _, ok := partitions[namespace]; !ok {
+			partitions[namespace] = Report{Resources: []ReportResource{}, Findings: []Finding{}}
+			documents[namespace] = make(map[documentKey]bool)
+		}
+		documents[namespace][key] = true
+		return partitions[namespace]
+	}
+
+	for _, resource := range report.Resources {
+		namespace := resource.Resource.Namespace
+		p := partition(namespace, documentKey{resource.File, resource.Document})
+		p.Resources = append(p.Resources, resource)
+		partitions[namespace] = p
+	}
+	for _, finding := range report.Findings {
+		namespace := finding.Resource.Namespace
+		p := partition(namespace, documentKey{finding.File, finding.Document})
+		p.Findings = append(p.Findings, finding)
+		partitions[namespace] = p
+	}
+
+	for namespace, p := range partitions {
+		p.Documents = len(documents[namespace])
+		partitions[namespace] = p
+	}
+	return partitions
+}
+
+// TenantReportObject builds the object that publishes a namespace's report to its tenant:
+// a wgpolicyk8s.io/v1alpha2 PolicyReport, or a ConfigMap or Secret holding the report as
+// JSON under "report.json". The object is named TenantReportName and lives in namespace,
+// so tenants can read it with namespace-scoped permissions only.
+func TenantReportObject(kind, namespace string, report Report) (map[string]interface{}, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("tenant reports require a namespace")
+	}
+	metadata := map[string]interface{}{
+		"name":      TenantReportName,
+		"namespace": namespace,
+		"labels":    map[string]interface{}{"app.kubernetes.io/managed-by": "k8sconstraints"},
+	}
+
+	switch kind {
+	case TenantReportPolicyReport:
+		return policyReportObject(metadata, report), nil
+	case TenantReportConfigMap, TenantReportSecret:
+		if report.Resources == nil {
+			report.Resources = []ReportResource{}
+		}
+		if report.Findings == nil {
+			report.Findings = []Finding{}
+		}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		obj := map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       kind,
+			"metadata":   metadata,
+		}
+		if kind == TenantReportSecret {
+			obj["type"] = "Opaque"
+			obj["stringData"] = map[string]interface{}{tenantReportDataKey: string(data)}
+		} else {
+			obj["data"] = map[string]interface{}{tenantReportDataKey: string(data)}
+		}
+		return obj, nil
+	}
+	return nil, fmt.Errorf("unsupported tenant report kind '%s'; must be one of: %s, %s, %s", kind, TenantReportPolicyReport, TenantReportConfigMap, TenantReportSecret)
+}
+
+// policyReportObject renders a report as a PolicyReport of the Kubernetes Policy working
+// group. Every finding becomes a fail or warn result, and every resource without findings
+// counts as a pass.
+func policyReportObject(metadata map[string]interface{}, report Report) map[string]interface{} {
+	failed := make(map[ResourceRef]bool)
+	results := make([]interface{}, 0, len(report.Findings))
+	summary := map[string]interface{}{"pass": 0, "fail": 0, "warn": 0, "error": 0, "skip": 0}
+	for _, finding := range report.Findings {
+		failed[finding.Resource] = true
+
+		result, severity := "fail", "high"
+		switch finding.Severity {
+		case SeverityWarning:
+			result, severity = "warn", "medium"
+		case SeverityInfo:
+			result, severity = "warn", "info"
+		}
+		summary[result] = summary[result].(int) + 1
+
+		entry := map[string]interface{}{
+			"source":   "k8sconstraints",
+			"policy":   "k8sconstraints",
+			"rule":     sarifRuleID(finding),
+			"result":   result,
+			"severity": severity,
+			"message":  finding.Message,
+			"resources": []interface{}{map[string]interface{}{
+				"apiVersion": finding.Resource.APIVersion,
+				"kind":       finding.Resource.Kind,
+				"namespace":  finding.Resource.Namespace,
+				"name":       finding.Resource.Name,
+			}},
+		}
+		properties := map[string]interface{}{"fingerprint": finding.Fingerprint}
+		if finding.FieldPath != "" {
+			properties["fieldPath"] = finding.FieldPath
+		}
+		entry["properties"] = properties
+		results = append(results, entry)
+	}
+
+	passed := make(map[ResourceRef]bool)
+	for _, resource := range report.Resources {
+		if !failed[resource.Resource] && !passed[resource.Resource] {
+			passed[resource.Resource] = true
+			summary["pass"] = summary["pass"].(int) + 1
+		}
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "wgpolicyk8s.io/v1alpha2",
+		"kind":       TenantReportPolicyReport,
+		"metadata":   metadata,
+		"summary":    summary,
+		"results":    results,
+	}
+}
@@ -0,0 +1,513 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// hclTokenKind classifies the tokens of the HCL subset understood by NewTerraformSource.
+type hclTokenKind int
+
+const (
+	hclIdent hclTokenKind = iota
+	hclString
+	hclHeredoc
+	hclNumber
+	hclPunct
+	hclNewline
+	hclEOF
+)
+
+// hclToken is a token of a Terraform file. For strings and heredocs, text holds the
+// unescaped contents.
+type hclToken struct {
+	kind hclTokenKind
+	text string
+	line int
+}
+
+// terraformSource yields the manifests of the kubernetes_manifest resources of a Terraform
+// file.
+type terraformSource struct {
+	name   string
+	tokens []hclToken
+	pos    int
+	index  int
+	queue  []Source
+	err    error
+}
+
+// NewTerraformSource returns a Source over the manifests of the kubernetes_manifest
+// resources in a Terraform file. The manifest attribute may be an HCL object literal or a
+// yamldecode call on a string or heredoc; manifests read from other files, such as
+// yamldecode(file("app.yaml")), are skipped since those files are linted on their own.
+// Object attributes whose value cannot be evaluated statically, such as var.namespace or
+// "app-${local.env}", are left out rather than reported as invalid. Documents are located
+// at the line of their manifest attribute, or of the YAML they were decoded from. Manifests
+// that cannot be read are reported as documents that failed to decode.
+func NewTerraformSource(name string, data []byte) Source {
+	tokens, err := tokenizeHCL(string(data))
+	return &terraformSource{name: name, tokens: tokens, err: err}
+}
+
+// Next returns the manifest of the next kubernetes_manifest resource.
+func (s *terraformSource) Next() (Document, error) {
+	for {
+		if len(s.queue) > 0 {
+			doc, err := s.queue[0].Next()
+			if errors.Is(err, io.EOF) {
+				s.queue = s.queue[1:]
+				continue
+			}
+			if err != nil {
+				// A manifest that cannot be read is reported, and the rest of it skipped
+				s.queue = s.queue[1:]
+				doc = Document{Source: s.name, Index: s.index, Err: err}
+				s.index++
+			}
+			return doc, nil
+		}
+		if s.err != nil {
+			err := s.err
+			s.err, s.tokens, s.pos = nil, nil, 0
+			doc := Document{Source: s.name, Index: s.index, Err: fmt.Errorf("invalid Terraform: %v", err)}
+			s.index++
+			return doc, nil
+		}
+		if !s.findManifestResource() {
+			return Document{}, io.EOF
+		}
+		s.parseResourceBody()
+	}
+}
+
+// findManifestResource advances to the body of the next `resource "kubernetes_manifest"
+// "name" {` block, reporting whether one was found.
+func (s *terraformSource) findManifestResource() bool {
+	for ; s.pos+3 < len(s.tokens); s.pos++ {
+		t := s.tokens[s.pos : s.pos+4]
+		if t[0].kind == hclIdent && t[0].text == "resource" && t[1].kind == hclString && t[1].text == "kubernetes_manifest" && t[2].kind == hclString && t[3].kind == hclPunct && t[3].text == "{" {
+			s.pos += 4
+			return true
+		}
+	}
+	s.pos = len(s.tokens)
+	return false
+}
+
+// parseResourceBody reads the attributes of a resource block up to its closing brace,
+// queueing the documents of its manifest attribute.
+func (s *terraformSource) parseResourceBody() {
+	p := &hclParser{tokens: s.tokens, pos: s.pos}
+	for {
+		p.skipNewlines()
+		t := p.peek()
+		if t.kind == hclEOF || t.text == "}" {
+			p.next()
+			break
+		}
+		if t.kind != hclIdent {
+			p.skipBalanced()
+			continue
+		}
+		p.next()
+		if p.peek().text != "=" {
+			// A nested block such as lifecycle or field_manager
+			for p.peek().kind != hclEOF && p.peek().text != "{" {
+				p.next()
+			}
+			p.skipBalanced()
+			continue
+		}
+		p.next()
+		if t.text != "manifest" {
+			p.parseExpression()
+			continue
+		}
+		s.queue = append(s.queue, s.manifestSource(p, t.line))
+	}
+	s.pos = p.pos
+}
+
+// manifestSource parses the value of a manifest attribute starting on line.
+func (s *terraformSource) manifestSource(p *hclParser, line int) Source {
+	start := p.pos
+	if call := p.next(); call.kind == hclIdent && call.text == "yamldecode" && p.next().text == "(" {
+		p.skipNewlines()
+		arg := p.next()
+		p.skipNewlines()
+		if (arg.kind == hclString || arg.kind == hclHeredoc) && p.next().text == ")" {
+			yamlLine := arg.line
+			if arg.kind == hclHeredoc {
+				yamlLine = arg.line + 1
+			}
+			return offsetSource{name: s.name, source: NewYAMLSource(s.name, strings.NewReader(arg.text)), line: yamlLine - 1, index: &s.index}
+		}
+	}
+	p.pos = start
+
+	if p.peek().text != "{" {
+		p.parseExpression()
+		return MultiSource()
+	}
+	doc := Document{Source: s.name, Index: s.index, Line: line}
+	s.index++
+	value := p.parseExpression()
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		doc.Err = fmt.Errorf("line %d: manifest must be an object", line)
+	}
+	doc.Object = obj
+	return &singleDocumentSource{doc: doc}
+}
+
+// singleDocumentSource yields one document.
+type singleDocumentSource struct {
+	doc  Document
+	done bool
+}
+
+// Next returns the document on the first call and io.EOF afterwards.
+func (s *singleDocumentSource) Next() (Document, error) {
+	if s.done {
+		return Document{}, io.EOF
+	}
+	s.done = true
+	return s.doc, nil
+}
+
+// hclParser evaluates HCL expressions made of literals, objects, and tuples.
+type hclParser struct {
+	tokens []hclToken
+	pos    int
+}
+
+// peek returns the current token.
+func (p *hclParser) peek() hclToken {
+	return p.peekAt(0)
+}
+
+// peekAt returns the token offset tokens ahead.
+func (p *hclParser) peekAt(offset int) hclToken {
+	if p.pos+offset < len(p.tokens) {
+		return p.tokens[p.pos+offset]
+	}
+	return hclToken{kind: hclEOF}
+}
+
+// next consumes and returns the current token.
+func (p *hclParser) next() hclToken {
+	t := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return t
+}
+
+// skipNewlines consumes newlines and commas between items.
+func (p *hclParser) skipNewlines() {
+	for t := p.peek(); t.kind == hclNewline || t.text == ","; t = p.peek() {
+		p.next()
+	}
+}
+
+// skipBalanced consumes a token, or a whole bracketed group if it opens one.
+func (p *hclParser) skipBalanced() {
+	depth := 0
+	for {
+		t := p.next()
+		switch t.text {
+		case "{", "[", "(":
+			depth++
+		case "}", "]", ")":
+			depth--
+		}
+		if t.kind == hclEOF || depth <= 0 {
+			return
+		}
+	}
+}
+
+// parseExpression evaluates the expression at the current position. Literals, objects,
+// and tuples are converted to Go values; anything else, such as references, function
+// calls, and operators, is returned as its source text wrapped in "${...}".
+func (p *hclParser) parseExpression() interface{} {
+	start := p.pos
+	value, literal := p.parsePrimary()
+	if literal && p.atExpressionEnd() {
+		return value
+	}
+
+	// Not a plain literal: consume the rest of the expression and keep its text
+	p.pos = start
+	parts := make([]string, 0)
+	for !p.atExpressionEnd() {
+		t := p.peek()
+		if t.text == "{" || t.text == "[" || t.text == "(" {
+			from := p.pos
+			p.skipBalanced()
+			parts = append(parts, hclSourceText(p.tokens[from:p.pos]))
+			continue
+		}
+		parts = append(parts, hclSourceText([]hclToken{p.next()}))
+	}
+	return "${" + strings.Join(parts, " ") + "}"
+}
+
+// atExpressionEnd reports whether the current token ends an expression.
+func (p *hclParser) atExpressionEnd() bool {
+	t := p.peek()
+	return t.kind == hclEOF || t.kind == hclNewline || t.text == "," || t.text == "}" || t.text == "]" || t.text == ")"
+}
+
+// parsePrimary parses a literal, object, or tuple, reporting whether the value is fully
+// literal.
+func (p *hclParser) parsePrimary() (interface{}, bool) {
+	t := p.next()
+	switch t.kind {
+	case hclString, hclHeredoc:
+		return t.text, true
+	case hclNumber:
+		if n, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return n, true
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		return f, err == nil
+	case hclIdent:
+		switch t.text {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		case "null":
+			return nil, true
+		}
+		return nil, false
+	}
+
+	switch t.text {
+	case "{":
+		obj := make(map[string]interface{})
+		literal := true
+		for {
+			p.skipNewlines()
+			key := p.next()
+			if key.kind == hclEOF {
+				return obj, false
+			}
+			if key.text == "}" {
+				return obj, literal
+			}
+			if key.kind != hclIdent && key.kind != hclString {
+				return obj, false
+			}
+			if sep := p.next(); sep.text != "=" && sep.text != ":" {
+				return obj, false
+			}
+			if value := p.parseExpression(); !isUnresolvedHCL(value) {
+				obj[key.text] = value
+			}
+		}
+	case "[":
+		list := make([]interface{}, 0)
+		for {
+			p.skipNewlines()
+			if t := p.peek(); t.text == "]" || t.kind == hclEOF {
+				p.next()
+				return list, t.kind != hclEOF
+			}
+			list = append(list, p.parseExpression())
+		}
+	}
+	return nil, false
+}
+
+// isUnresolvedHCL reports whether value is a string holding an expression or template
+// interpolation that could not be evaluated statically.
+func isUnresolvedHCL(value interface{}) bool {
+	s, ok := value.(string)
+	return ok && (strings.Contains(s, "${") || strings.Contains(s, "%{"))
+}
+
+// hclSourceText reconstructs the source text of tokens, for expressions kept verbatim.
+func hclSourceText(tokens []hclToken) string {
+	parts := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		switch t.kind {
+		case hclString:
+			parts = append(parts, strconv.Quote(t.text))
+		case hclNewline:
+		default:
+			parts = append(parts, t.text)
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// tokenizeHCL splits a Terraform file into tokens, dropping comments.
+func tokenizeHCL(src string) ([]hclToken, error) {
+	tokens := make([]hclToken, 0)
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			tokens = append(tokens, hclToken{kind: hclNewline, text: "\n", line: line})
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#' || strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return tokens, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case c == '"':
+			text, n, err := scanHCLString(src[i:])
+			if err != nil {
+				return tokens, fmt.Errorf("line %d: %v", line, err)
+			}
+			tokens = append(tokens, hclToken{kind: hclString, text: text, line: line})
+			line += strings.Count(src[i:i+n], "\n")
+			i += n
+		case strings.HasPrefix(src[i:], "<<"):
+			text, n, err := scanHCLHeredoc(src[i:])
+			if err != nil {
+				return tokens, fmt.Errorf("line %d: %v", line, err)
+			}
+			tokens = append(tokens, hclToken{kind: hclHeredoc, text: text, line: line})
+			line += strings.Count(src[i:i+n], "\n")
+			i += n
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.' || src[i] == 'e' || src[i] == 'E') {
+				i++
+			}
+			tokens = append(tokens, hclToken{kind: hclNumber, text: src[start:i], line: line})
+		case c == '_' || unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(src) && (src[i] == '_' || src[i] == '-' || src[i] == '.' || src[i] == '*' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			tokens = append(tokens, hclToken{kind: hclIdent, text: src[start:i], line: line})
+		default:
+			tokens = append(tokens, hclToken{kind: hclPunct, text: string(c), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// scanHCLString scans the quoted string at the start of src, returning its unescaped
+// contents and length. Template interpolations are kept verbatim.
+func scanHCLString(src string) (string, int, error) {
+	var text strings.Builder
+	for i := 1; i < len(src); i++ {
+		switch c := src[i]; {
+		case c == '"':
+			return text.String(), i + 1, nil
+		case c == '\n':
+			return "", 0, fmt.Errorf("unterminated string")
+		case c == '\\' && i+1 < len(src):
+			i++
+			switch src[i] {
+			case 'n':
+				text.WriteByte('\n')
+			case 't':
+				text.WriteByte('\t')
+			case 'r':
+				text.WriteByte('\r')
+			default:
+				text.WriteByte(src[i])
+			}
+		case (c == '$' || c == '%') && strings.HasPrefix(src[i+1:], "{"):
+			// Copy the interpolation, which may itself contain quotes and braces
+			depth := 0
+			start := i
+			for ; i < len(src); i++ {
+				if src[i] == '{' {
+					depth++
+				} else if src[i] == '}' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			if i >= len(src) {
+				return "", 0, fmt.Errorf("unterminated template interpolation")
+			}
+			text.WriteString(src[start : i+1])
+		default:
+			text.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// scanHCLHeredoc scans the heredoc at the start of src, returning its contents and length.
+// Indented heredocs (<<-) have their common leading whitespace removed.
+func scanHCLHeredoc(src string) (string, int, error) {
+	header, _, found := strings.Cut(src, "\n")
+	if !found {
+		return "", 0, fmt.Errorf("unterminated heredoc")
+	}
+	marker := strings.TrimSpace(strings.TrimPrefix(header, "<<"))
+	indented := strings.HasPrefix(marker, "-")
+	marker = strings.TrimPrefix(marker, "-")
+	if marker == "" {
+		return "", 0, fmt.Errorf("heredoc is missing its marker")
+	}
+
+	lines := make([]string, 0)
+	offset := len(header) + 1
+	for offset <= len(src) {
+		line, _, _ := strings.Cut(src[offset:], "\n")
+		if strings.TrimSpace(line) == marker {
+			offset += len(line)
+			if indented {
+				lines = trimCommonIndent(lines)
+			}
+			text := strings.Join(lines, "\n")
+			if len(lines) > 0 {
+				text += "\n"
+			}
+			return text, offset, nil
+		}
+		lines = append(lines, line)
+		offset += len(line) + 1
+	}
+	return "", 0, fmt.Errorf("heredoc is missing its closing %s marker", marker)
+}
+
+// trimCommonIndent removes the leading whitespace shared by every non-blank line.
+func trimCommonIndent(lines []string) []string {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	trimmed := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			line = line[indent:]
+		}
+		trimmed[i] = line
+	}
+	return trimmed
+}
@@ -0,0 +1,177 @@
+package k8sconstraints
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// RunSummary records the outcome of one validation run for trend tracking.
+type RunSummary struct {
+	// Key identifies what was scanned, such as a repository or cluster name.
+	Key       string    `json:"key"`
+	Time      time.Time `json:"time"`
+	Files     int       `json:"files"`
+	Documents int       `json:"documents"`
+	Findings  int       `json:"findings"`
+	// RuleCounts counts findings per rule code.
+	RuleCounts map[string]int `json:"ruleCounts"`
+	// Score is the bundle score, when the run was scored.
+	Score *float64 `json:"score,omitempty"`
+}
+
+// SummarizeReport builds the trend summary of a report. Related findings are not counted
+// separately.
+func SummarizeReport(key string, at time.Time, report Report) RunSummary {
+	summary := RunSummary{
+		Key:        key,
+		Time:       at.UTC(),
+		Files:      report.Files,
+		Documents:  report.Documents,
+		Findings:   len(report.Findings),
+		RuleCounts: make(map[string]int),
+	}
+	for _, finding := range report.Findings {
+		summary.RuleCounts[sarifRuleID(finding)]++
+	}
+	if report.Score != nil {
+		score := report.Score.Score
+		summary.Score = &score
+	}
+	return summary
+}
+
+// TrendStore persists run summaries. Implement it to keep trends in a database; FileTrendStore
+// is the built-in implementation.
+type TrendStore interface {
+	// Record stores a run summary.
+	Record(ctx context.Context, summary RunSummary) error
+	// Recent returns up to n of the most recent summaries recorded for key, oldest first.
+	Recent(ctx context.Context, key string, n int) ([]RunSummary, error)
+}
+
+// FileTrendStore stores run summaries as JSON lines appended to a file, which can be
+// committed or kept as a CI artifact.
+type FileTrendStore struct {
+	Path string
+}
+
+// Record appends summary to the file, creating it if needed.
+func (s FileTrendStore) Record(ctx context.Context, summary RunSummary) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Recent reads the file and returns the last n summaries recorded for key, oldest first.
+// A missing file holds no summaries.
+func (s FileTrendStore) Recent(ctx context.Context, key string, n int) ([]RunSummary, error) {
+	f, err := os.Open(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []RunSummary{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	summaries := make([]RunSummary, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var summary RunSummary
+		if err := json.Unmarshal(scanner.Bytes(), &summary); err != nil {
+			return nil, fmt.Errorf("invalid trend store '%s': line %d: %v", s.Path, line, err)
+		}
+		if summary.Key == key {
+			summaries = append(summaries, summary)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if n > 0 && len(summaries) > n {
+		summaries = summaries[len(summaries)-n:]
+	}
+	return summaries, nil
+}
+
+// WriteTrend renders run summaries as a table with one row per rule and one column per run,
+// followed by the totals and, for scored runs, the scores.
+func WriteTrend(w io.Writer, runs []RunSummary) error {
+	if len(runs) == 0 {
+		_, err := fmt.Fprintln(w, "no runs recorded")
+		return err
+	}
+
+	rules := make(map[string]bool)
+	for _, run := range runs {
+		for rule := range run.RuleCounts {
+			rules[rule] = true
+		}
+	}
+
+	width := len("findings")
+	for rule := range rules {
+		width = max(width, len(rule))
+	}
+
+	row := func(label string, cell func(RunSummary) string) error {
+		cells := make([]string, len(runs))
+		for i, run := range runs {
+			cells[i] = fmt.Sprintf("%16s", cell(run))
+		}
+		_, err := fmt.Fprintf(w, "%-*s %s\n", width, label, strings.Join(cells, " "))
+		return err
+	}
+
+	if err := row("rule", func(run RunSummary) string { return run.Time.Format("2006-01-02 15:04") }); err != nil {
+		return err
+	}
+	for _, rule := range sortedKeys(rules) {
+		if err := row(rule, func(run RunSummary) string { return fmt.Sprint(run.RuleCounts[rule]) }); err != nil {
+			return err
+		}
+	}
+	if err := row("findings", func(run RunSummary) string { return fmt.Sprint(run.Findings) }); err != nil {
+		return err
+	}
+	for _, run := range runs {
+		if run.Score != nil {
+			return row("score", func(run RunSummary) string {
+				if run.Score == nil {
+					return "-"
+				}
+				return fmt.Sprintf("%.1f", *run.Score)
+			})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// nestedField walks obj along the given keys and returns the value found there, if any.
+func nestedField(obj map[string]interface{}, fields ...string) (interface{}, bool) {
+	var current interface{} = obj
+	for _, field := range fields {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[field]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// nestedMap returns the map found at the given path in obj.
+func nestedMap(obj map[string]interface{}, fields ...string) (map[string]interface{}, bool) {
+	value, ok := nestedField(obj, fields...)
+	if !ok {
+		return nil, false
+	}
+	m, ok := value.(map[string]interface{})
+	return m, ok
+}
+
+// nestedString returns the string found at the given path in obj.
+func nestedString(obj map[string]interface{}, fields ...string) (string, bool) {
+	value, ok := nestedField(obj, fields...)
+	if !ok {
+		return "", false
+	}
+	s, ok := value.(string)
+	return s, ok
+}
+
+// nestedSlice returns the list found at the given path in obj.
+func nestedSlice(obj map[string]interface{}, fields ...string) ([]interface{}, bool) {
+	value, ok := nestedField(obj, fields...)
+	if !ok {
+		return nil, false
+	}
+	s, ok := value.([]interface{})
+	return s, ok
+}
+
+// nestedStringMap returns the string-valued map (labels, annotations, selectors) found at
+// the given path in obj. Non-string values are skipped.
+func nestedStringMap(obj map[string]interface{}, fields ...string) (map[string]string, bool) {
+	m, ok := nestedMap(obj, fields...)
+	if !ok {
+		return nil, false
+	}
+	return toStringMap(m), true
+}
+
+// toInt64 converts a decoded YAML/JSON number into an int64.
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		if v != float64(int64(v)) {
+			return 0, false
+		}
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// joinFieldPath appends a field name or a subscript such as `[0]` to a dotted field path.
+func joinFieldPath(path string, field string) string {
+	if path == "" {
+		return field
+	}
+	if field == "" {
+		return path
+	}
+	if strings.HasPrefix(field, "[") {
+		return path + field
+	}
+	return path + "." + field
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// allStringValues reports whether every value in m is a string.
+func allStringValues(m map[string]interface{}) bool {
+	for _, value := range m {
+		if _, ok := value.(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// toStringMap keeps the string values of m.
+func toStringMap(m map[string]interface{}) map[string]string {
+	result := make(map[string]string, len(m))
+	for key, value := range m {
+		if s, ok := value.(string); ok {
+			result[key] = s
+		}
+	}
+	return result
+}
+
+// toStringSlice keeps the string items of list.
+func toStringSlice(list []interface{}) []string {
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// podTemplateSpecPaths lists where each workload kind keeps its pod spec.
+var podTemplateSpecPaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"PodTemplate":           {"template", "spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// findPodSpec returns the pod spec embedded in obj along with its dotted field path.
+func findPodSpec(obj map[string]interface{}) (map[string]interface{}, string, bool) {
+	kind, _ := nestedString(obj, "kind")
+	fields, ok := podTemplateSpecPaths[kind]
+	if !ok {
+		return nil, "", false
+	}
+	spec, ok := nestedMap(obj, fields...)
+	if !ok {
+		return nil, "", false
+	}
+	return spec, strings.Join(fields, "."), true
+}
+
+// podContainer is a container found in a pod spec along with its field path relative to
+// the pod spec, e.g. containers[0] or initContainers[1].
+type podContainer struct {
+	path   string
+	fields map[string]interface{}
+}
+
+// podContainers returns every init, regular, and ephemeral container in a pod spec.
+func podContainers(spec map[string]interface{}) []podContainer {
+	containers := make([]podContainer, 0)
+	for _, field := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		list, _ := nestedSlice(spec, field)
+		for i, item := range list {
+			container, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			containers = append(containers, podContainer{path: fmt.Sprintf("%s[%d]", field, i), fields: container})
+		}
+	}
+	return containers
+}
+
+// nestedBool returns the bool found at the given path in obj.
+func nestedBool(obj map[string]interface{}, fields ...string) (bool, bool) {
+	value, ok := nestedField(obj, fields...)
+	if !ok {
+		return false, false
+	}
+	b, ok := value.(bool)
+	return b, ok
+}
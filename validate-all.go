@@ -0,0 +1,128 @@
+package k8sconstraints
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// WithConcurrency sets how many documents Validator.ValidateAll validates at once. It
+// defaults to GOMAXPROCS, so a large set of manifests keeps every core busy.
+func WithConcurrency(n int) Option {
+	return func(v *Validator) {
+		v.concurrency = n
+	}
+}
+
+// ValidateDocument validates a document produced by a Source like the package-level
+// ValidateDocument, running the checks of the Validator in place of ValidateObject.
+func (v *Validator) ValidateDocument(doc Document) DocumentResult {
+	result := DocumentResult{Index: doc.Index, Line: doc.Line, Path: doc.Path, Object: doc.Object}
+	if doc.Err != nil {
+		result.Findings = []Finding{DecodeFinding(doc.Err)}
+		return result
+	}
+	result.Findings = objectFindingsAt(doc.Object, doc.Path, func(obj map[string]interface{}) error {
+		return RunChecks(obj, v.checks)
+	})
+	return result
+}
+
+// validateJob is a document read by ValidateAll, numbered in the order it was read.
+type validateJob struct {
+	seq int
+	doc Document
+}
+
+// ValidateAll validates every document of sources with ValidateDocument, fanning them out
+// to a pool of WithConcurrency workers. Sources are read one document at a time, in order,
+// and the report lists documents in that order whatever order the workers finish in; it is
+// sorted with Report.Sort. A validation that panics is reported as an EngineError finding.
+// If a source fails or ctx is cancelled, ValidateAll stops reading and returns the report
+// of the documents read so far along with the error.
+func (v *Validator) ValidateAll(ctx context.Context, sources []Source) (Report, error) {
+	concurrency := v.concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
+	jobs := make(chan validateJob, concurrency)
+	var mu sync.Mutex
+	docs := make([]Document, 0)
+	results := make(map[int]DocumentResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				result := v.validateDocumentRecovered(job.doc)
+				mu.Lock()
+				results[job.seq] = result
+				mu.Unlock()
+			}
+		}()
+	}
+
+	// Feed the workers from a single reader, since a Source is not safe for concurrent use
+	readErr := func() error {
+		defer close(jobs)
+		for _, source := range sources {
+			for {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				doc, err := source.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					return err
+				}
+				job := validateJob{seq: len(docs), doc: doc}
+				docs = append(docs, doc)
+				select {
+				case jobs <- job:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+		return nil
+	}()
+	wg.Wait()
+
+	// Merge in reading order, so the report does not depend on scheduling
+	report := Report{Findings: []Finding{}}
+	seen := make(map[string]bool)
+	for seq, doc := range docs {
+		result, ok := results[seq]
+		if !ok {
+			// Read but never validated because ctx was cancelled
+			continue
+		}
+		if !seen[doc.Source] {
+			seen[doc.Source] = true
+			report.Files++
+		}
+		report.AddDocument(doc.Source, result)
+	}
+	report.Sort()
+
+	return report, readErr
+}
+
+// validateDocumentRecovered runs ValidateDocument, reporting a panic as an EngineError
+// finding so that one document cannot take down the whole run.
+func (v *Validator) validateDocumentRecovered(doc Document) (result DocumentResult) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result = engineErrorResult(doc, fmt.Sprintf("validation panicked: %v", recovered))
+		}
+	}()
+	return v.ValidateDocument(doc)
+}
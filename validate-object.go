@@ -0,0 +1,91 @@
+package k8sconstraints
+
+// IDs of the built-in checks run by ValidateObject.
+const (
+	CheckTypeMeta                 = "TypeMeta"
+	CheckObjectMeta               = "ObjectMeta"
+	CheckControllerManagedLabels  = "ControllerManagedLabels"
+	CheckWindowsPod               = "WindowsPod"
+	CheckPodSchedulingAndOverhead = "PodSchedulingAndOverhead"
+	CheckKindRules                = "KindRules"
+	CheckDaemonSetScaling         = "DaemonSetScaling"
+)
+
+// builtinChecks are the checks run by ValidateObject. Spec-level checks, the kind-specific
+// validators of DefaultRegistry among them, depend on TypeMeta only, since they interpret
+// the object according to its kind; invalid metadata leaves the spec readable, so it does
+// not hide their findings.
+var builtinChecks = []Check{
+	{ID: CheckTypeMeta, Validate: validateTypeMeta},
+	{ID: CheckObjectMeta, Validate: validateObjectMeta},
+	{ID: CheckControllerManagedLabels, DependsOn: []string{CheckTypeMeta}, Validate: ValidateControllerManagedLabels},
+	{ID: CheckWindowsPod, DependsOn: []string{CheckTypeMeta}, Validate: ValidateWindowsPod},
+	{ID: CheckPodSchedulingAndOverhead, DependsOn: []string{CheckTypeMeta}, Validate: ValidatePodSchedulingAndOverhead},
+	{ID: CheckKindRules, DependsOn: []string{CheckTypeMeta}, Validate: func(obj map[string]interface{}) error {
+		return DefaultRegistry.Validate(obj)
+	}},
+	{ID: CheckDaemonSetScaling, DependsOn: []string{CheckTypeMeta}, Kinds: []string{"DaemonSet.apps"}, Validate: ValidateDaemonSetReplicas},
+}
+
+// builtinBundleChecks are the checks spanning the objects of a bundle; see
+// BuiltinBundleChecks.
+var builtinBundleChecks = []BundleCheck{
+	DaemonSetScaleTargetsCheck,
+	HPATargetsCheck,
+	ServiceTargetPortsCheck,
+	FlowControlReferencesCheck,
+}
+
+// builtinCheckReleases records the release that introduced each built-in check and bundle
+// check, so constraint profiles of earlier releases leave it out; see UseConstraintProfile.
+var builtinCheckReleases = map[string]string{
+	CheckTypeMeta:                 "0.1.0",
+	CheckObjectMeta:               "0.1.0",
+	CheckControllerManagedLabels:  "0.1.0",
+	CheckWindowsPod:               "0.1.0",
+	CheckPodSchedulingAndOverhead: "0.1.0",
+	CheckKindRules:                "0.1.0",
+	CheckDaemonSetScaling:         "0.1.0",
+	CheckDaemonSetScaleTargets:    "0.1.0",
+	CheckHPATargets:               "0.1.0",
+	CheckServiceTargetPorts:       "0.1.0",
+	CheckFlowControlReferences:    "0.1.0",
+}
+
+// BuiltinChecks returns a copy of the checks run by ValidateObject under the constraint
+// profile in effect, for callers that want to run them alongside their own checks with
+// RunChecks.
+func BuiltinChecks() []Check {
+	return append([]Check(nil), profileChecks...)
+}
+
+// BuiltinBundleChecks returns the built-in checks spanning the objects of a bundle, built
+// from objects, under the constraint profile in effect. Run them against each of objects.
+func BuiltinBundleChecks(objects []map[string]interface{}) []Check {
+	checks := make([]Check, 0, len(builtinBundleChecks))
+	for _, bundleCheck := range builtinBundleChecks {
+		if check := bundleCheck(objects); ruleEnabled(builtinCheckReleases[check.ID]) {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// groupKind returns the Kind.group key of obj used by Check.Kinds.
+func groupKind(obj map[string]interface{}) string {
+	ref := ResourceRefOf(obj)
+	if group := ref.Group(); group != "" {
+		return ref.Kind + "." + group
+	}
+	return ref.Kind
+}
+
+// ValidateObject runs every built-in check that applies to obj: ValidateManifest, the pod
+// spec validators, and the validators DefaultRegistry holds for obj's kind, in dependency
+// order. List documents are expanded and each item is validated.
+func ValidateObject(obj map[string]interface{}) error {
+	if IsList(obj) {
+		return ValidateListItems(obj, ValidateObject)
+	}
+	return RunChecks(obj, profileChecks)
+}
@@ -0,0 +1,47 @@
+package k8sconstraints
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateObjectKindRulesDependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		// want and hidden are parts of messages that are expected to be reported, and not
+		want, hidden []string
+	}{
+		{"invalid name", `
+apiVersion: v1
+kind: Secret
+metadata: {name: Bad_Name}
+data: {password: not base64!}`,
+			[]string{"metadata.name", `data["password"]: value must be base64 encoded`}, nil},
+		{"invalid apiVersion", `
+apiVersion: v1/beta/1
+kind: Secret
+metadata: {name: db}
+data: {password: not base64!}`,
+			[]string{"apiVersion"}, []string{"base64"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateObject(decodeTestObject(t, tt.manifest))
+			if err == nil {
+				t.Fatal("expected findings")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected a finding containing %q, got %v", want, err)
+				}
+			}
+			for _, hidden := range tt.hidden {
+				if strings.Contains(err.Error(), hidden) {
+					t.Errorf("unexpected finding containing %q in %v", hidden, err)
+				}
+			}
+		})
+	}
+}
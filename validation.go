@@ -0,0 +1,15 @@
+package k8sconstraints
+
+import "fmt"
+
+// ValidateLength checks if a string exceeds the maximum allowed length.
+func ValidateLength(input string, maxLength int) error {
+	if len(input) > maxLength {
+		return &ConstraintError{
+			Rule:     RuleMaxLength,
+			BadValue: input,
+			Message:  fmt.Sprintf("input exceeds maximum length of %d characters", maxLength),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package k8sconstraints
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// windowsDrivePattern matches the drive letter prefix of a Windows path, such as C:\ or C:/.
+var windowsDrivePattern = regexp.MustCompile(`^[A-Za-z]:[\\/]`)
+
+// ValidateVolumeMounts validates the volumeMounts of a container: each names a volume
+// declared in volumes, the names of the pod's volumes, and has a unique absolute mountPath
+// without ':', apart from the drive letter of a Windows path. subPath and subPathExpr are
+// mutually exclusive relative paths that may not contain '..' elements.
+func ValidateVolumeMounts(container map[string]interface{}, volumes map[string]bool) error {
+	errs := make([]error, 0)
+
+	mountPaths := make(map[string]bool)
+	mounts, _ := nestedSlice(container, "volumeMounts")
+	for i, raw := range mounts {
+		path := NewPath("volumeMounts").Index(i)
+		mount, _ := raw.(map[string]interface{})
+		name, _ := nestedString(mount, "name")
+		if name == "" {
+			errs = append(errs, Required(path.Child("name"), "name is required"))
+		} else if !volumes[name] {
+			errs = append(errs, Invalid(path.Child("name"), name, fmt.Sprintf("volume '%s' is not declared in the pod's volumes", name)))
+		}
+
+		mountPath, _ := nestedString(mount, "mountPath")
+		switch {
+		case mountPath == "":
+			errs = append(errs, Required(path.Child("mountPath"), "mountPath is required"))
+		case !strings.HasPrefix(mountPath, "/") && !windowsDrivePattern.MatchString(mountPath):
+			errs = append(errs, Invalid(path.Child("mountPath"), mountPath, fmt.Sprintf("mountPath '%s' must be an absolute path", mountPath)))
+		case strings.Contains(strings.TrimPrefix(mountPath, windowsDrivePattern.FindString(mountPath)), ":"):
+			errs = append(errs, Invalid(path.Child("mountPath"), mountPath, fmt.Sprintf("mountPath '%s' must not contain ':'", mountPath)))
+		case mountPaths[mountPath]:
+			errs = append(errs, Invalid(path.Child("mountPath"), mountPath, fmt.Sprintf("duplicate mountPath '%s'", mountPath)))
+		}
+		mountPaths[mountPath] = true
+
+		subPath, hasSubPath := nestedString(mount, "subPath")
+		subPathExpr, hasSubPathExpr := nestedString(mount, "subPathExpr")
+		if hasSubPath && hasSubPathExpr && subPath != "" && subPathExpr != "" {
+			errs = append(errs, Invalid(path.Child("subPathExpr"), subPathExpr, "subPath and subPathExpr are mutually exclusive"))
+		}
+		if err := validateSubPath("subPath", subPath); err != nil {
+			errs = append(errs, WithFieldPath(path.Child("subPath").String(), err))
+		}
+		if err := validateSubPath("subPathExpr", subPathExpr); err != nil {
+			errs = append(errs, WithFieldPath(path.Child("subPathExpr").String(), err))
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(RuleVolumeMount, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// validateSubPath checks that a subPath or subPathExpr, named field, is a relative path that
+// does not step out of the volume through '..'.
+func validateSubPath(field, subPath string) error {
+	if strings.HasPrefix(subPath, "/") || windowsDrivePattern.MatchString(subPath) {
+		return &ConstraintError{BadValue: subPath, Message: fmt.Sprintf("%s '%s' must be a relative path", field, subPath)}
+	}
+	for _, element := range strings.FieldsFunc(subPath, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if element == ".." {
+			return &ConstraintError{BadValue: subPath, Message: fmt.Sprintf("%s '%s' must not contain '..'", field, subPath)}
+		}
+	}
+	return nil
+}
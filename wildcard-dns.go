@@ -0,0 +1,38 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ValidateWildcardDNSSubdomain validates a wildcard DNS subdomain as accepted by Ingress
+// hosts, Gateway listener hostnames, and APIService wildcard matching: exactly one leading
+// `*.` label followed by a valid DNS subdomain (e.g. "*.example.com"), max 253 characters.
+func ValidateWildcardDNSSubdomain(subdomain string) error {
+	if len(subdomain) > 253 {
+		return fmt.Errorf("wildcard subdomain exceeds maximum length of 253 characters")
+	}
+	if !strings.HasPrefix(subdomain, "*.") {
+		return errors.New("wildcard subdomain must start with '*.' (e.g. '*.example.com')")
+	}
+
+	rest := subdomain[2:]
+	if strings.Contains(rest, "*") {
+		return errors.New("wildcard subdomain may contain only one '*', as the leading label")
+	}
+	if err := ValidateDNSSubdomain(rest); err != nil {
+		return fmt.Errorf("wildcard subdomain must be '*.' followed by a valid DNS subdomain: %v", err)
+	}
+
+	return nil
+}
+
+// ValidateDNSSubdomainOrWildcard accepts either a plain DNS subdomain or a wildcard DNS
+// subdomain, which is the rule for host fields such as Ingress rules[].host.
+func ValidateDNSSubdomainOrWildcard(subdomain string) error {
+	if strings.HasPrefix(subdomain, "*") {
+		return ValidateWildcardDNSSubdomain(subdomain)
+	}
+	return ValidateDNSSubdomain(subdomain)
+}
@@ -0,0 +1,258 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Limits applied by Kubernetes to securityContext.windowsOptions.runAsUserName.
+const (
+	maxRunAsUserNameDomainLength = 256
+	maxRunAsUserNameUserLength   = 104
+)
+
+var (
+	// NetBIOS domain names: up to 15 characters, no reserved characters, no leading '.'
+	windowsNetBIOSDomainPattern = regexp.MustCompile(`^[^\\/:*?"<>|.][^\\/:*?"<>|]{0,14}$`)
+	// DNS domain names made of dot-separated labels
+	windowsDNSDomainPattern = regexp.MustCompile(`^[a-zA-Z0-9]([-a-zA-Z0-9]*[a-zA-Z0-9])?(\.[a-zA-Z0-9]([-a-zA-Z0-9]*[a-zA-Z0-9])?)*$`)
+	// Characters that may not appear in the user part of a Windows user name
+	windowsInvalidUserCharsPattern = regexp.MustCompile(`["/\\:;|=,+*?<>@\[\]]`)
+	windowsDotsAndSpacesPattern    = regexp.MustCompile(`^[. ]+$`)
+	controlCharsPattern            = regexp.MustCompile(`[[:cntrl:]]`)
+)
+
+// linuxOnlyPodSecurityFields are pod securityContext fields that Windows nodes do not support.
+var linuxOnlyPodSecurityFields = []string{
+	"runAsUser",
+	"runAsGroup",
+	"seLinuxOptions",
+	"seccompProfile",
+	"appArmorProfile",
+	"fsGroup",
+	"fsGroupChangePolicy",
+	"supplementalGroups",
+	"sysctls",
+}
+
+// linuxOnlyContainerSecurityFields are container securityContext fields that Windows nodes
+// do not support.
+var linuxOnlyContainerSecurityFields = []string{
+	"runAsUser",
+	"runAsGroup",
+	"seLinuxOptions",
+	"seccompProfile",
+	"appArmorProfile",
+	"capabilities",
+	"readOnlyRootFilesystem",
+	"privileged",
+	"allowPrivilegeEscalation",
+	"procMount",
+}
+
+// ValidateWindowsPod validates the Windows-specific configuration of the pod spec embedded
+// in obj (a Pod or any workload with a pod template).
+func ValidateWindowsPod(obj map[string]interface{}) error {
+	spec, path, ok := findPodSpec(obj)
+	if !ok {
+		return nil
+	}
+	if err := ValidateWindowsPodSpec(spec); err != nil {
+		return WithFieldPath(path, err)
+	}
+	return nil
+}
+
+// ValidateWindowsPodSpec validates securityContext.windowsOptions at pod and container level,
+// the HostProcess container constraints, and flags Linux-only fields on pods that target
+// Windows nodes and windowsOptions on pods whose os.name is linux.
+func ValidateWindowsPodSpec(spec map[string]interface{}) error {
+	errs := make([]error, 0)
+	containers := podContainers(spec)
+
+	// Validate windowsOptions wherever they appear
+	if options, ok := nestedMap(spec, "securityContext", "windowsOptions"); ok {
+		if err := ValidateWindowsOptions(options); err != nil {
+			errs = append(errs, WithFieldPath("securityContext.windowsOptions", err))
+		}
+	}
+	for _, container := range containers {
+		if options, ok := nestedMap(container.fields, "securityContext", "windowsOptions"); ok {
+			if err := ValidateWindowsOptions(options); err != nil {
+				errs = append(errs, WithFieldPath(fmt.Sprintf("%s.securityContext.windowsOptions", container.path), err))
+			}
+		}
+	}
+
+	// HostProcess pods must be consistent across all containers
+	if err := ValidateWindowsHostProcess(spec); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Windows options are rejected on pods declared to run on Linux
+	if osName, _ := nestedString(spec, "os", "name"); osName == "linux" {
+		if _, ok := nestedField(spec, "securityContext", "windowsOptions"); ok {
+			errs = append(errs, Invalid(NewPath("securityContext", "windowsOptions"), nil, "is not supported when os.name is linux"))
+		}
+		for _, container := range containers {
+			if _, ok := nestedField(container.fields, "securityContext", "windowsOptions"); ok {
+				errs = append(errs, Invalid(NewPath(container.path, "securityContext", "windowsOptions"), nil, "is not supported when os.name is linux"))
+			}
+		}
+	}
+
+	// Linux-only fields are ignored or rejected on Windows nodes
+	if TargetsWindows(spec) {
+		for _, field := range linuxOnlyPodSecurityFields {
+			if _, ok := nestedField(spec, "securityContext", field); ok {
+				errs = append(errs, Invalid(NewPath("securityContext", field), nil, "is not supported on Windows nodes"))
+			}
+		}
+		for _, container := range containers {
+			for _, field := range linuxOnlyContainerSecurityFields {
+				if _, ok := nestedField(container.fields, "securityContext", field); ok {
+					errs = append(errs, Invalid(NewPath(container.path, "securityContext", field), nil, "is not supported on Windows nodes"))
+				}
+			}
+		}
+	}
+
+	// If there are errors, join and return them
+	if len(errs) > 0 {
+		return withDefaultRule(CheckWindowsPod, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// TargetsWindows reports whether a pod spec is scheduled onto Windows nodes, either through
+// spec.os.name or a kubernetes.io/os nodeSelector.
+func TargetsWindows(spec map[string]interface{}) bool {
+	if osName, ok := nestedString(spec, "os", "name"); ok && osName == "windows" {
+		return true
+	}
+	if nodeOS, ok := nestedString(spec, "nodeSelector", "kubernetes.io/os"); ok && nodeOS == "windows" {
+		return true
+	}
+	return false
+}
+
+// ValidateWindowsOptions validates a securityContext.windowsOptions block.
+func ValidateWindowsOptions(options map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	if name, ok := nestedString(options, "gmsaCredentialSpecName"); ok {
+		if err := ValidateGMSACredentialSpecName(name); err != nil {
+			errs = append(errs, WithFieldPath("gmsaCredentialSpecName", err))
+		}
+	}
+
+	if spec, ok := nestedString(options, "gmsaCredentialSpec"); ok && spec == "" {
+		errs = append(errs, errors.New("gmsaCredentialSpec cannot be an empty string"))
+	}
+
+	if userName, ok := nestedString(options, "runAsUserName"); ok {
+		if err := ValidateRunAsUserName(userName); err != nil {
+			errs = append(errs, WithFieldPath("runAsUserName", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return withDefaultRule(CheckWindowsPod, JoinErrors(errs))
+	}
+
+	return nil
+}
+
+// ValidateGMSACredentialSpecName validates the name of a GMSACredentialSpec object,
+// which must be a DNS subdomain.
+func ValidateGMSACredentialSpecName(name string) error {
+	if name == "" {
+		return errors.New("gmsaCredentialSpecName cannot be empty")
+	}
+	return ValidateDNSSubdomain(name)
+}
+
+// ValidateRunAsUserName validates a Windows user name of the form `User` or `DOMAIN\User`,
+// where DOMAIN is a NetBIOS or DNS domain name.
+func ValidateRunAsUserName(userName string) error {
+	if userName == "" {
+		return errors.New("runAsUserName cannot be empty")
+	}
+	if controlCharsPattern.MatchString(userName) {
+		return errors.New("runAsUserName cannot contain control characters")
+	}
+	if len(userName) > maxRunAsUserNameDomainLength+maxRunAsUserNameUserLength+1 {
+		return fmt.Errorf("runAsUserName exceeds maximum length of %d characters", maxRunAsUserNameDomainLength+maxRunAsUserNameUserLength+1)
+	}
+
+	parts := strings.Split(userName, `\`)
+	if len(parts) > 2 {
+		return errors.New(`runAsUserName may contain at most one backslash (\)`)
+	}
+
+	user := parts[len(parts)-1]
+	if len(parts) == 2 {
+		domain := parts[0]
+		if len(domain) > maxRunAsUserNameDomainLength {
+			return fmt.Errorf("runAsUserName domain exceeds maximum length of %d characters", maxRunAsUserNameDomainLength)
+		}
+		if !windowsNetBIOSDomainPattern.MatchString(domain) && !windowsDNSDomainPattern.MatchString(domain) {
+			return errors.New("runAsUserName domain must be a valid NetBIOS or DNS domain name")
+		}
+	}
+
+	if user == "" {
+		return errors.New("runAsUserName user cannot be empty")
+	}
+	if len(user) > maxRunAsUserNameUserLength {
+		return fmt.Errorf("runAsUserName user exceeds maximum length of %d characters", maxRunAsUserNameUserLength)
+	}
+	if windowsInvalidUserCharsPattern.MatchString(user) {
+		return errors.New(`runAsUserName user cannot contain any of the characters " / \ : ; | = , + * ? < > @ [ ]`)
+	}
+	if windowsDotsAndSpacesPattern.MatchString(user) {
+		return errors.New("runAsUserName user cannot consist only of periods and spaces")
+	}
+
+	return nil
+}
+
+// ValidateWindowsHostProcess enforces the HostProcess container rules: if any container is a
+// HostProcess container then every container must be one, and the pod must use hostNetwork.
+func ValidateWindowsHostProcess(spec map[string]interface{}) error {
+	errs := make([]error, 0)
+
+	podHostProcess, podSet := nestedBool(spec, "securityContext", "windowsOptions", "hostProcess")
+
+	anyHostProcess := podSet && podHostProcess
+	allHostProcess := true
+	for _, container := range podContainers(spec) {
+		hostProcess, set := nestedBool(container.fields, "securityContext", "windowsOptions", "hostProcess")
+		if !set {
+			// Containers inherit the pod-level setting
+			hostProcess = podSet && podHostProcess
+		} else if podSet && podHostProcess && !hostProcess {
+			errs = append(errs, Invalid(NewPath(container.path, "securityContext", "windowsOptions", "hostProcess"), false, "cannot be false when the pod sets hostProcess to true"))
+		}
+		anyHostProcess = anyHostProcess || hostProcess
+		allHostProcess = allHostProcess && hostProcess
+	}
+
+	if anyHostProcess {
+		if !allHostProcess {
+			errs = append(errs, errors.New("if any container is a HostProcess container, all containers in the pod must be HostProcess containers"))
+		}
+		if hostNetwork, _ := nestedBool(spec, "hostNetwork"); !hostNetwork {
+			errs = append(errs, errors.New("hostNetwork must be true for pods with HostProcess containers"))
+		}
+	}
+
+	if len(errs) > 0 {
+		return withDefaultRule(CheckWindowsPod, JoinErrors(errs))
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package k8sconstraints
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RuleDecode is the rule code of findings for documents that could not be decoded into an
+// object.
+const RuleDecode = "Decode"
+
+// DocumentResult holds the outcome of validating one document of a multi-document input.
+type DocumentResult struct {
+	// Index is the zero-based position of the document in the input, ignoring empty documents.
+	Index int `json:"index"`
+	// Line is the line the document starts on, when known.
+	Line int `json:"line,omitempty"`
+	// Path is the field path of Object within the document, when it was streamed on its own;
+	// see Document.Path.
+	Path string `json:"path,omitempty"`
+	// Object is the decoded document, or nil if it could not be decoded.
+	Object map[string]interface{} `json:"-"`
+	// Findings holds the violations found in the document.
+	Findings []Finding `json:"findings"`
+}
+
+// Valid reports whether the document produced no findings.
+func (d DocumentResult) Valid() bool {
+	return len(d.Findings) == 0
+}
+
+// ValidateYAML decodes `---`-separated multi-document YAML from r and runs ValidateObject
+// against every document. Empty documents are skipped. Documents that are not mappings
+// produce a Decode finding. A syntax error also produces a Decode finding and ends the
+// stream, since the documents that follow cannot be located reliably.
+func ValidateYAML(r io.Reader) ([]DocumentResult, error) {
+	source := NewYAMLSource("", r)
+	results := make([]DocumentResult, 0)
+
+	for {
+		doc, err := source.Next()
+		if errors.Is(err, io.EOF) {
+			return results, nil
+		}
+		if err != nil {
+			return results, err
+		}
+		results = append(results, ValidateDocument(doc))
+	}
+}
+
+// isEmptyYAMLDocument reports whether node is a document holding nothing but comments.
+func isEmptyYAMLDocument(node *yaml.Node) bool {
+	if node.Kind != yaml.DocumentNode || len(node.Content) == 0 {
+		return true
+	}
+	content := node.Content[0]
+	return content.Kind == yaml.ScalarNode && content.Tag == "!!null"
+}
+
+// decodeYAMLObject decodes a YAML document node into an object.
+func decodeYAMLObject(node *yaml.Node) (map[string]interface{}, error) {
+	if content := node.Content[0]; content.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: document must be a mapping, not a %s", content.Line, yamlNodeKindName(content.Kind))
+	}
+	stringifyYAMLTimestamps(node)
+	var obj map[string]interface{}
+	if err := node.Decode(&obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// stringifyYAMLTimestamps retags implicit timestamps as strings, so fields such as
+// creationTimestamp decode the way the API server's JSON decoding would.
+func stringifyYAMLTimestamps(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!timestamp" && node.Style&yaml.TaggedStyle == 0 {
+		node.Tag = "!!str"
+	}
+	for _, child := range node.Content {
+		stringifyYAMLTimestamps(child)
+	}
+}
+
+// yamlNodeKindName names a YAML node kind for error messages.
+func yamlNodeKindName(kind yaml.Kind) string {
+	switch kind {
+	case yaml.SequenceNode:
+		return "sequence"
+	case yaml.ScalarNode:
+		return "scalar"
+	case yaml.AliasNode:
+		return "alias"
+	}
+	return "mapping"
+}
+
+// DecodeFinding returns the finding reported for a document that could not be decoded.
+func DecodeFinding(err error) Finding {
+	finding := Finding{Rule: RuleDecode, Severity: SeverityError, Message: err.Error()}
+	finding.Fingerprint = Fingerprint(finding)
+	return finding
+}